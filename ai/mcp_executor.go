@@ -0,0 +1,582 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/internal/sse"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// MCPProtocolVersion is the MCP protocol version sent during the initialize handshake
+const MCPProtocolVersion = "2024-11-05"
+
+// MCPTransport carries JSON-RPC messages between the executor and an MCP server
+type MCPTransport interface {
+	// RoundTrip sends a JSON-RPC request and returns the response with the same id
+	RoundTrip(ctx context.Context, request []byte) ([]byte, error)
+	// Notify sends a JSON-RPC notification, which has no response
+	Notify(ctx context.Context, notification []byte) error
+	// Close releases the transport's resources
+	Close() error
+}
+
+// MCPToolExecutor implements the ToolExecutor interface by forwarding tool calls to an MCP server.
+// Tools are discovered with tools/list when the executor is created and executed with tools/call.
+type MCPToolExecutor struct {
+	transport MCPTransport
+	nextID    atomic.Int64
+
+	mu    sync.RWMutex
+	tools []provider.Tool
+}
+
+// NewMCPToolExecutor performs the MCP initialize handshake over the transport and loads the server's tools
+func NewMCPToolExecutor(ctx context.Context, transport MCPTransport) (*MCPToolExecutor, error) {
+	executor := &MCPToolExecutor{transport: transport}
+
+	if err := executor.initialize(ctx); err != nil {
+		transport.Close()
+		return nil, err
+	}
+
+	if err := executor.RefreshTools(ctx); err != nil {
+		transport.Close()
+		return nil, err
+	}
+
+	return executor, nil
+}
+
+// NewMCPStdioToolExecutor starts an MCP server as a subprocess and talks to it over stdin/stdout
+func NewMCPStdioToolExecutor(ctx context.Context, command string, args ...string) (*MCPToolExecutor, error) {
+	transport, err := NewMCPStdioTransport(command, args...)
+	if err != nil {
+		return nil, err
+	}
+	return NewMCPToolExecutor(ctx, transport)
+}
+
+// NewMCPHTTPToolExecutor connects to an MCP server over HTTP.
+// Headers are sent with every request, e.g. for authorization.
+func NewMCPHTTPToolExecutor(ctx context.Context, url string, headers map[string]string) (*MCPToolExecutor, error) {
+	return NewMCPToolExecutor(ctx, NewMCPHTTPTransport(url, headers, 0))
+}
+
+// mcpRequest is a JSON-RPC 2.0 request or notification
+type mcpRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// mcpResponse is a JSON-RPC 2.0 response
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *mcpError       `json:"error"`
+}
+
+// mcpError is a JSON-RPC 2.0 error object
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// call sends a JSON-RPC request and decodes its result into result
+func (e *MCPToolExecutor) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	id := e.nextID.Add(1)
+	request, err := json.Marshal(mcpRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	data, err := e.transport.RoundTrip(ctx, request)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+
+	var response mcpResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+
+	if response.Error != nil {
+		return fmt.Errorf("%s failed with code %d: %s", method, response.Error.Code, response.Error.Message)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("failed to parse %s result: %w", method, err)
+		}
+	}
+
+	return nil
+}
+
+// notify sends a JSON-RPC notification
+func (e *MCPToolExecutor) notify(ctx context.Context, method string) error {
+	notification, err := json.Marshal(mcpRequest{JSONRPC: "2.0", Method: method})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s notification: %w", method, err)
+	}
+	return e.transport.Notify(ctx, notification)
+}
+
+// initialize performs the MCP initialize handshake
+func (e *MCPToolExecutor) initialize(ctx context.Context) error {
+	params := map[string]interface{}{
+		"protocolVersion": MCPProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "go-llm-router",
+			"version": "1.0",
+		},
+	}
+
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := e.call(ctx, "initialize", params, &result); err != nil {
+		return err
+	}
+
+	return e.notify(ctx, "notifications/initialized")
+}
+
+// RefreshTools reloads the tool list from the MCP server
+func (e *MCPToolExecutor) RefreshTools(ctx context.Context) error {
+	var tools []provider.Tool
+	cursor := ""
+
+	for {
+		var params map[string]interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+
+		var result struct {
+			Tools []struct {
+				Name        string                 `json:"name"`
+				Description string                 `json:"description"`
+				InputSchema map[string]interface{} `json:"inputSchema"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := e.call(ctx, "tools/list", params, &result); err != nil {
+			return err
+		}
+
+		for _, tool := range result.Tools {
+			tools = append(tools, provider.Tool{
+				Type: "function",
+				Function: provider.ToolFunction{
+					Name:        tool.Name,
+					Description: tool.Description,
+					Parameters:  tool.InputSchema,
+				},
+			})
+		}
+
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	e.mu.Lock()
+	e.tools = tools
+	e.mu.Unlock()
+
+	return nil
+}
+
+// ExecuteTool executes a tool call on the MCP server and returns the result.
+// Text content is joined into a single string; other content types are returned as decoded JSON objects.
+func (e *MCPToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	arguments := toolCall.Function.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	var result struct {
+		Content []map[string]interface{} `json:"content"`
+		IsError bool                     `json:"isError"`
+	}
+	err := e.call(ctx, "tools/call", map[string]interface{}{
+		"name":      toolCall.Function.Name,
+		"arguments": arguments,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var texts []string
+	allText := true
+	for _, item := range result.Content {
+		if item["type"] != "text" {
+			allText = false
+			break
+		}
+		text, _ := item["text"].(string)
+		texts = append(texts, text)
+	}
+
+	if result.IsError {
+		return nil, fmt.Errorf("tool %s returned an error: %s", toolCall.Function.Name, strings.Join(texts, "\n"))
+	}
+
+	var content interface{} = result.Content
+	if allText {
+		content = strings.Join(texts, "\n")
+	}
+
+	return &provider.ToolCallResult{
+		ID:      toolCall.ID,
+		Type:    "function",
+		Content: content,
+	}, nil
+}
+
+// GetAvailableTools returns the tools advertised by the MCP server
+func (e *MCPToolExecutor) GetAvailableTools() []provider.Tool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tools
+}
+
+// Close closes the connection to the MCP server
+func (e *MCPToolExecutor) Close() error {
+	return e.transport.Close()
+}
+
+// MCPStreamTransport exchanges newline-delimited JSON-RPC messages over a reader and writer,
+// as used by the MCP stdio transport
+type MCPStreamTransport struct {
+	writeMu sync.Mutex
+	writer  io.Writer
+	closers []io.Closer
+
+	mu      sync.Mutex
+	pending map[int64]chan []byte
+	readErr error
+	done    chan struct{}
+}
+
+// NewMCPStreamTransport creates a transport that writes requests to w and reads responses from r
+func NewMCPStreamTransport(r io.Reader, w io.Writer) *MCPStreamTransport {
+	transport := &MCPStreamTransport{
+		writer:  w,
+		pending: make(map[int64]chan []byte),
+		done:    make(chan struct{}),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		transport.closers = append(transport.closers, closer)
+	}
+	if closer, ok := r.(io.Closer); ok {
+		transport.closers = append(transport.closers, closer)
+	}
+
+	go transport.readLoop(bufio.NewReader(r))
+	return transport
+}
+
+// NewMCPStdioTransport starts the command and communicates with it over its stdin and stdout
+func NewMCPStdioTransport(command string, args ...string) (*MCPStreamTransport, error) {
+	cmd := exec.Command(command, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MCP server stdin: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MCP server stdout: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	transport := NewMCPStreamTransport(stdout, stdin)
+	transport.closers = append(transport.closers, processCloser{cmd: cmd})
+	return transport, nil
+}
+
+// processCloser waits for the MCP server process to exit once its stdin is closed
+type processCloser struct {
+	cmd *exec.Cmd
+}
+
+func (p processCloser) Close() error {
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		p.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+// readLoop delivers responses to the requests waiting for them.
+// Server notifications and requests are ignored.
+func (t *MCPStreamTransport) readLoop(reader *bufio.Reader) {
+	defer close(t.done)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.mu.Lock()
+			t.readErr = fmt.Errorf("failed to read from MCP server: %w", err)
+			t.mu.Unlock()
+			return
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var response struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(line, &response); err != nil || response.Method != "" || response.ID == nil {
+			continue
+		}
+
+		t.mu.Lock()
+		ch, ok := t.pending[*response.ID]
+		delete(t.pending, *response.ID)
+		t.mu.Unlock()
+
+		if ok {
+			ch <- line
+		}
+	}
+}
+
+// RoundTrip writes the request and waits for the response with the same id
+func (t *MCPStreamTransport) RoundTrip(ctx context.Context, request []byte) ([]byte, error) {
+	var header struct {
+		ID *int64 `json:"id"`
+	}
+	if err := json.Unmarshal(request, &header); err != nil || header.ID == nil {
+		return nil, fmt.Errorf("request has no id")
+	}
+	id := *header.ID
+
+	ch := make(chan []byte, 1)
+	t.mu.Lock()
+	if t.readErr != nil {
+		err := t.readErr
+		t.mu.Unlock()
+		return nil, err
+	}
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(request); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-ch:
+		return response, nil
+	case <-t.done:
+		// The response may have been delivered just before the stream ended
+		select {
+		case response := <-ch:
+			return response, nil
+		default:
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return nil, t.readErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Notify writes a notification
+func (t *MCPStreamTransport) Notify(ctx context.Context, notification []byte) error {
+	return t.write(notification)
+}
+
+// write sends a single newline-terminated message
+func (t *MCPStreamTransport) write(message []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	if _, err := t.writer.Write(append(message, '\n')); err != nil {
+		return fmt.Errorf("failed to write to MCP server: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying streams and, for stdio servers, waits for the process to exit
+func (t *MCPStreamTransport) Close() error {
+	var firstErr error
+	for _, closer := range t.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// MCPHTTPTransport sends JSON-RPC messages to an MCP server with HTTP POST requests.
+// Responses may be plain JSON or a server-sent event stream.
+type MCPHTTPTransport struct {
+	// IdleTimeout fails a request whose event stream sends nothing, not even a keepalive
+	// comment, for this long (default 30s)
+	IdleTimeout time.Duration
+
+	url     string
+	headers map[string]string
+	timeout time.Duration
+	client  httpclient.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// NewMCPHTTPTransport creates an HTTP transport for the MCP server at url.
+// A zero timeout means no timeout beyond the request context.
+func NewMCPHTTPTransport(url string, headers map[string]string, timeout time.Duration) *MCPHTTPTransport {
+	return &MCPHTTPTransport{
+		url:     url,
+		headers: headers,
+		timeout: timeout,
+		client:  httpclient.New("go-llm-router/1.0"),
+	}
+}
+
+// RoundTrip posts the request and returns the JSON-RPC response
+func (t *MCPHTTPTransport) RoundTrip(ctx context.Context, request []byte) ([]byte, error) {
+	resp, err := t.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return sseResponse(sse.NewReader(resp.Body, t.IdleTimeout))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("MCP request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// Notify posts a notification
+func (t *MCPHTTPTransport) Notify(ctx context.Context, notification []byte) error {
+	resp, err := t.post(ctx, notification)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("MCP notification failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// post sends a message, tracking the session id assigned by the server
+func (t *MCPHTTPTransport) post(ctx context.Context, message []byte) (*http.Response, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json, text/event-stream",
+	}
+	for key, value := range t.headers {
+		headers[key] = value
+	}
+
+	t.mu.Lock()
+	if t.sessionID != "" {
+		headers["Mcp-Session-Id"] = t.sessionID
+	}
+	t.mu.Unlock()
+
+	resp, _, err := t.client.Do(ctx, t.url, "POST", headers, bytes.NewReader(message), t.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send MCP request: %w", err)
+	}
+
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// Close is a no-op for the HTTP transport
+func (t *MCPHTTPTransport) Close() error {
+	return nil
+}
+
+// sseResponse reads a server-sent event stream until an event carries a JSON-RPC response, so
+// a server that keeps the stream open afterwards doesn't hold up the request. If none does, the
+// data of the last event is returned.
+func sseResponse(events *sse.Reader) ([]byte, error) {
+	defer events.Close()
+
+	var data []byte
+	for {
+		event, err := events.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MCP event stream: %w", err)
+		}
+
+		data = []byte(strings.TrimSpace(event.Data))
+		var message struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		if json.Unmarshal(data, &message) == nil && message.ID != nil && (message.Result != nil || message.Error != nil) {
+			return data, nil
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no data in MCP event stream")
+	}
+	return data, nil
+}
@@ -6,19 +6,28 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/FramnkRulez/go-llm-router/provider"
 )
 
-// SimpleToolExecutor implements the ToolExecutor interface with basic tools
+// ToolHandler executes a registered tool call
+type ToolHandler func(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error)
+
+// SimpleToolExecutor implements the ToolExecutor interface with basic tools.
+// Additional tools can be added at runtime with RegisterTool.
 type SimpleToolExecutor struct {
 	tools []provider.Tool
+
+	mu              sync.RWMutex
+	registeredTools []provider.Tool
+	handlers        map[string]ToolHandler
 }
 
 // NewSimpleToolExecutor creates a new simple tool executor with basic tools
 func NewSimpleToolExecutor() *SimpleToolExecutor {
-	executor := &SimpleToolExecutor{}
+	executor := &SimpleToolExecutor{handlers: make(map[string]ToolHandler)}
 	executor.tools = []provider.Tool{
 		{
 			Type: "function",
@@ -81,8 +90,52 @@ func NewSimpleToolExecutor() *SimpleToolExecutor {
 	return executor
 }
 
+// RegisterTool adds a tool and the handler that executes it.
+// Registering a tool with the same name as an existing one replaces it, including the built-in tools.
+func (e *SimpleToolExecutor) RegisterTool(tool provider.Tool, handler ToolHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name := tool.Function.Name
+	if _, exists := e.handlers[name]; exists {
+		for i, registered := range e.registeredTools {
+			if registered.Function.Name == name {
+				e.registeredTools[i] = tool
+				break
+			}
+		}
+	} else {
+		e.registeredTools = append(e.registeredTools, tool)
+	}
+	e.handlers[name] = handler
+}
+
+// UnregisterTool removes a tool added with RegisterTool. Built-in tools cannot be removed.
+func (e *SimpleToolExecutor) UnregisterTool(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.handlers[name]; !exists {
+		return
+	}
+	delete(e.handlers, name)
+	for i, registered := range e.registeredTools {
+		if registered.Function.Name == name {
+			e.registeredTools = append(e.registeredTools[:i], e.registeredTools[i+1:]...)
+			break
+		}
+	}
+}
+
 // ExecuteTool executes a tool call and returns the result
 func (e *SimpleToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	e.mu.RLock()
+	handler, ok := e.handlers[toolCall.Function.Name]
+	e.mu.RUnlock()
+	if ok {
+		return handler(ctx, toolCall)
+	}
+
 	switch toolCall.Function.Name {
 	case "get_current_time":
 		return e.executeGetCurrentTime(toolCall)
@@ -95,9 +148,18 @@ func (e *SimpleToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.
 	}
 }
 
-// GetAvailableTools returns the list of available tools
+// GetAvailableTools returns the built-in tools followed by the registered tools
 func (e *SimpleToolExecutor) GetAvailableTools() []provider.Tool {
-	return e.tools
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	tools := make([]provider.Tool, 0, len(e.tools)+len(e.registeredTools))
+	for _, tool := range e.tools {
+		if _, overridden := e.handlers[tool.Function.Name]; !overridden {
+			tools = append(tools, tool)
+		}
+	}
+	return append(tools, e.registeredTools...)
 }
 
 // executeGetCurrentTime handles the get_current_time tool
@@ -0,0 +1,177 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouter_StopsOnNonRetryableAPIError(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity} {
+		primary := &mockProvider{name: "primary", rank: 2, err: provider.NewAPIError(status, `{"error":"rejected"}`)}
+		backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+		router, err := gollmrouter.NewRouter(primary, backup)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+
+		_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+		var apiErr *gollmrouter.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status || apiErr.Retryable {
+			t.Errorf("status %d: expected a non-retryable APIError, got %v", status, err)
+		}
+		if backup.callCount() != 0 {
+			t.Errorf("status %d: expected the router not to fall back", status)
+		}
+	}
+}
+
+func TestRouter_FallsBackOnRetryableAPIError(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		primary := &mockProvider{name: "primary", rank: 2, err: provider.NewAPIError(status, "try again later")}
+		backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+		router, err := gollmrouter.NewRouter(primary, backup)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+
+		result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+		if err != nil || result.Content != "ok" {
+			t.Errorf("status %d: expected fallback to succeed, got %v, %v", status, result, err)
+		}
+	}
+}
+
+func TestRouter_FallsBackOnProviderSpecificClientError(t *testing.T) {
+	// A model one provider doesn't serve or an account out of credits may succeed elsewhere
+	for _, status := range []int{http.StatusNotFound, http.StatusPaymentRequired} {
+		primary := &mockProvider{name: "primary", rank: 2, err: provider.NewAPIError(status, `{"error":"model not found"}`)}
+		backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+		router, err := gollmrouter.NewRouter(primary, backup)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+
+		result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+		if err != nil || result.Content != "ok" || backup.callCount() != 1 {
+			t.Errorf("status %d: expected fallback to succeed, got %v, %v", status, result, err)
+		}
+	}
+}
+
+func TestRouter_AlwaysFallback(t *testing.T) {
+	primary := &mockProvider{name: "primary", rank: 2, err: provider.NewAPIError(http.StatusBadRequest, "invalid tool schema")}
+	backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{primary, backup}, gollmrouter.WithAlwaysFallback(true))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "ok" || backup.callCount() != 1 {
+		t.Error("Expected the router to fall back when always fallback is enabled")
+	}
+}
+
+func TestFunctionCallingProvider_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"No auth credentials found"}}`))
+	}))
+	defer server.Close()
+
+	p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "bad-key",
+		URL:    server.URL,
+		Models: []string{"test-model"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	var apiErr *gollmrouter.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Retryable || apiErr.Body == "" {
+		t.Errorf("Unexpected APIError: %+v", apiErr)
+	}
+	if apiErr.Message != "No auth credentials found" {
+		t.Errorf("Expected the message from the JSON error body, got %q", apiErr.Message)
+	}
+}
+
+func TestNewAPIError_ParsesErrorBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+		errType string
+		code    string
+		err     string
+	}{
+		{"OpenAI", `{"error":{"message":"Invalid model","type":"invalid_request_error","code":"model_not_found"}}`,
+			"Invalid model", "invalid_request_error", "model_not_found", "API request failed with status 400: Invalid model (invalid_request_error)"},
+		{"numeric code", `{"error":{"message":"No auth credentials found","code":401}}`,
+			"No auth credentials found", "", "401", "API request failed with status 400: No auth credentials found"},
+		{"top-level fields", `{"object":"error","message":"Unknown model","type":"invalid_model","code":null}`,
+			"Unknown model", "invalid_model", "", "API request failed with status 400: Unknown model (invalid_model)"},
+		{"string error", `{"error":"rejected"}`, "rejected", "", "", "API request failed with status 400: rejected"},
+		{"HTML", "<html><body><h1>502 Bad Gateway</h1></body></html>",
+			"", "", "", "API request failed with status 400: <html><body><h1>502 Bad Gateway</h1></body></html>"},
+		{"JSON without a message", `{"detail":"nope"}`, "", "", "", `API request failed with status 400: {"detail":"nope"}`},
+	}
+
+	for _, test := range tests {
+		apiErr := provider.NewAPIError(http.StatusBadRequest, test.body)
+		if apiErr.Message != test.message || apiErr.Type != test.errType || apiErr.Code != test.code {
+			t.Errorf("%s: expected message %q, type %q and code %q, got %+v", test.name, test.message, test.errType, test.code, apiErr)
+		}
+		if apiErr.Body != test.body {
+			t.Errorf("%s: expected the raw body to be kept, got %q", test.name, apiErr.Body)
+		}
+		if apiErr.Error() != test.err {
+			t.Errorf("%s: unexpected error string %q", test.name, apiErr.Error())
+		}
+	}
+}
+
+func TestFunctionCallingProvider_HTMLErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body>Bad Gateway</body></html>"))
+	}))
+	defer server.Close()
+
+	p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		URL:    server.URL,
+		Models: []string{"test-model"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	var apiErr *gollmrouter.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadGateway || apiErr.Message != "" || apiErr.Body != "<html><body>Bad Gateway</body></html>" {
+		t.Errorf("Expected the HTML body to be kept as is, got %+v", apiErr)
+	}
+}
@@ -0,0 +1,92 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestConstructors_RejectMissingAPIKey(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	constructors := map[string]func() (provider.Provider, error){
+		"Gemini": func() (provider.Provider, error) {
+			return gollmrouter.NewGeminiProvider(gollmrouter.GeminiConfig{Models: []string{"gemini-2.0-flash"}})
+		},
+		"OpenRouter": func() (provider.Provider, error) {
+			return gollmrouter.NewOpenRouterProvider(gollmrouter.OpenRouterConfig{Models: []string{"openai/gpt-4o"}})
+		},
+		"OpenAI": func() (provider.Provider, error) {
+			return gollmrouter.NewOpenAIProvider(gollmrouter.OpenAIConfig{Models: []string{"gpt-4o"}})
+		},
+		"Mistral": func() (provider.Provider, error) {
+			return gollmrouter.NewMistralProvider(gollmrouter.MistralConfig{Models: []string{"mistral-large-latest"}})
+		},
+	}
+
+	for name, newProvider := range constructors {
+		p, err := newProvider()
+		if !errors.Is(err, gollmrouter.ErrMissingAPIKey) {
+			t.Errorf("%s: expected ErrMissingAPIKey, got %v", name, err)
+		}
+		if p != nil {
+			t.Errorf("%s: expected no provider without an API key", name)
+		}
+	}
+}
+
+func TestNewGeminiProvider_APIKeyFromEnvironment(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "env-key")
+
+	p, err := gollmrouter.NewGeminiProvider(gollmrouter.GeminiConfig{Models: []string{"gemini-2.0-flash"}})
+	if err != nil {
+		t.Fatalf("Expected the key from GEMINI_API_KEY to be accepted, got %v", err)
+	}
+	p.Close()
+}
+
+func TestNewFunctionCallingProvider_RequiresURL(t *testing.T) {
+	_, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		Models: []string{"test-model"},
+	})
+	if err == nil {
+		t.Errorf("Expected an error without a URL")
+	}
+}
+
+func TestNewFunctionCallingProvider_WithoutAPIKey(t *testing.T) {
+	authorization := "unset"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]interface{}{"role": "assistant", "content": "ok"}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	// Local servers such as Ollama don't need a key
+	p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		URL:    server.URL,
+		Models: []string{"llama3"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no API key to be allowed, got %v", err)
+	}
+	defer p.Close()
+
+	if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if authorization != "" {
+		t.Errorf("Expected no Authorization header without an API key, got %q", authorization)
+	}
+}
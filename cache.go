@@ -0,0 +1,190 @@
+package gollmrouter
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// Cache stores query results so identical requests can be answered without calling a provider.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the cached result for key, if present and not expired
+	Get(key string) (*QueryResult, bool)
+	// Set stores result under key for ttl (0 means no expiry)
+	Set(key string, result *QueryResult, ttl time.Duration)
+}
+
+// cacheConfig holds the router's cache settings
+type cacheConfig struct {
+	cache          Cache
+	ttl            time.Duration
+	maxTemperature float64
+}
+
+// WithCache makes the router answer repeated requests from the cache.
+// Only requests that set a temperature of 0 are cached, see WithCacheMaxTemperature. Requests
+// without a temperature use the provider's default, which usually samples, and are not cached.
+// Results containing tool calls are never cached.
+func WithCache(cache Cache, ttl time.Duration) RouterOption {
+	return func(r *Router) {
+		r.cache.cache = cache
+		r.cache.ttl = ttl
+	}
+}
+
+// WithCacheMaxTemperature caches requests with a temperature up to and including maxTemperature
+// (default 0, meaning only deterministic requests are cached)
+func WithCacheMaxTemperature(maxTemperature float64) RouterOption {
+	return func(r *Router) {
+		r.cache.maxTemperature = maxTemperature
+	}
+}
+
+// cacheKey returns the cache key for a request, or false if the request should not be cached
+func (r *Router) cacheKey(messages []provider.Message, options provider.QueryOptions) (string, bool) {
+	if r.cache.cache == nil || !options.HasTemperature() || options.Temperature > r.cache.maxTemperature {
+		return "", false
+	}
+
+	key, err := requestHash(messages, options)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// requestHash returns a hash of the messages and every option that affects the response
+func requestHash(messages []provider.Message, options provider.QueryOptions) (string, error) {
+	data, err := json.Marshal(struct {
+		Messages    []provider.Message `json:"messages"`
+		Model       string             `json:"model"`
+		Provider    string             `json:"provider"`
+		Temperature float64            `json:"temperature"`
+		TempSet     bool               `json:"temperature_set"`
+		Tools       []provider.Tool    `json:"tools"`
+		ToolChoice  string             `json:"tool_choice"`
+		Parallel    *bool              `json:"parallel_tool_calls"`
+		System      string             `json:"system"`
+		N           int                `json:"n"`
+		Seed        *int               `json:"seed"`
+		LogProbs    bool               `json:"logprobs"`
+		TopLogProbs int                `json:"top_logprobs"`
+		Thinking    *int               `json:"thinking_budget"`
+		Effort      string             `json:"reasoning_effort"`
+		JSONMode    bool               `json:"json_mode"`
+	}{
+		Messages:    messages,
+		Model:       options.ForceModel,
+		Provider:    options.ForceProvider,
+		Temperature: options.Temperature,
+		TempSet:     options.TemperatureSet,
+		Tools:       options.Tools,
+		ToolChoice:  options.ToolChoice,
+		Parallel:    options.ParallelToolCalls,
+		System:      options.SystemPrompt,
+		N:           options.N,
+		Seed:        options.Seed,
+		LogProbs:    options.LogProbs,
+		TopLogProbs: options.TopLogProbs,
+		Thinking:    options.ThinkingBudget,
+		Effort:      options.ReasoningEffort,
+		JSONMode:    options.JSONMode,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry when full
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+}
+
+type lruEntry struct {
+	key       string
+	result    *QueryResult
+	expiresAt time.Time
+}
+
+// NewLRUCache creates an in-memory cache holding at most capacity results
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns a deep copy of the cached result for key
+func (c *LRUCache) Get(key string) (*QueryResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.result.Clone(), true
+}
+
+// Set stores a deep copy of result under key
+func (c *LRUCache) Set(key string, result *QueryResult, ttl time.Duration) {
+	if result == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.result = result.Clone()
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, result: result.Clone(), expiresAt: expiresAt})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of cached entries, including expired ones not yet evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,173 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouter_CacheHit(t *testing.T) {
+	mock := &mockProvider{name: "only", content: "cached answer"}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{mock}, gollmrouter.WithCache(gollmrouter.NewLRUCache(10), time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "What is 2 + 2?"}}
+	first, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{TemperatureSet: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What is 2 + 2?"}}, provider.QueryOptions{TemperatureSet: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if mock.callCount() != 1 {
+		t.Errorf("Expected the second identical query to be served from cache, provider called %d times", mock.callCount())
+	}
+	if second.Content != first.Content || second.Model != first.Model {
+		t.Errorf("Expected cached result %+v, got %+v", first, second)
+	}
+
+	// A different prompt is a cache miss
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What is 3 + 3?"}}, provider.QueryOptions{TemperatureSet: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mock.callCount() != 2 {
+		t.Errorf("Expected a different prompt to reach the provider, called %d times", mock.callCount())
+	}
+}
+
+func TestRouter_CacheSkipsNonZeroTemperature(t *testing.T) {
+	mock := &mockProvider{name: "only", content: "answer"}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{mock}, gollmrouter.WithCache(gollmrouter.NewLRUCache(10), time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "Tell me a story"}}
+	for i := 0; i < 2; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: 0.7}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if mock.callCount() != 2 {
+		t.Errorf("Expected queries above the temperature threshold not to be cached, provider called %d times", mock.callCount())
+	}
+
+	router, err = gollmrouter.NewRouterWithOptions([]provider.Provider{mock},
+		gollmrouter.WithCache(gollmrouter.NewLRUCache(10), time.Minute),
+		gollmrouter.WithCacheMaxTemperature(1.0),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: 0.7}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if mock.callCount() != 3 {
+		t.Errorf("Expected a raised threshold to cache the query, provider called %d times", mock.callCount())
+	}
+}
+
+func TestRouter_CacheSkipsUnsetTemperature(t *testing.T) {
+	mock := &mockProvider{name: "only", content: "answer"}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{mock}, gollmrouter.WithCache(gollmrouter.NewLRUCache(10), time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	// Without TemperatureSet the provider's default temperature applies, which isn't deterministic
+	messages := []provider.Message{{Role: "user", Content: "Tell me a story"}}
+	for i := 0; i < 2; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if mock.callCount() != 2 {
+		t.Errorf("Expected queries without a temperature not to be cached, provider called %d times", mock.callCount())
+	}
+}
+
+func TestRouter_CacheSkipsToolCalls(t *testing.T) {
+	mock := &mockProvider{name: "only", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		return &provider.QueryResult{
+			Model:     "tool-model",
+			ToolCalls: []provider.ToolCall{{ID: "call_1", Type: "function", Function: provider.ToolCallFunction{Name: "lookup"}}},
+		}, nil
+	}}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{mock}, gollmrouter.WithCache(gollmrouter.NewLRUCache(10), time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "Look something up"}}
+	for i := 0; i < 2; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{TemperatureSet: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if mock.callCount() != 2 {
+		t.Errorf("Expected results with tool calls not to be cached, provider called %d times", mock.callCount())
+	}
+}
+
+func TestLRUCache_EvictionAndTTL(t *testing.T) {
+	cache := gollmrouter.NewLRUCache(2)
+
+	for i := 0; i < 3; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), &provider.QueryResult{Content: fmt.Sprintf("value%d", i)}, 0)
+	}
+	if _, ok := cache.Get("key0"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if result, ok := cache.Get("key2"); !ok || result.Content != "value2" {
+		t.Errorf("Expected newest entry to be cached, got %v", result)
+	}
+
+	cache.Set("short", &provider.QueryResult{Content: "short-lived"}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected expired entry to be a miss")
+	}
+}
+
+func TestLRUCache_CopiesResults(t *testing.T) {
+	cache := gollmrouter.NewLRUCache(1)
+	stored := &provider.QueryResult{
+		Content:       "cached",
+		ToolCalls:     []provider.ToolCall{{ID: "call_1", Function: provider.ToolCallFunction{Name: "lookup", Arguments: map[string]interface{}{"city": "Paris"}}}},
+		LogProbs:      []provider.TokenLogProb{{Token: "cached", TopAlternatives: []provider.TokenAlternative{{Token: "saved"}}}},
+		ModelAttempts: []provider.ModelAttempt{{Model: "model-a"}},
+		Raw:           []byte(`{"id": "1"}`),
+		Usage:         &provider.Usage{TotalTokens: 10},
+	}
+	cache.Set("key", stored, 0)
+
+	// Changes to the stored result or a returned one must not reach the cache
+	stored.ToolCalls[0].Function.Arguments["city"] = "Berlin"
+	got, _ := cache.Get("key")
+	got.LogProbs[0].TopAlternatives[0].Token = "changed"
+	got.ModelAttempts[0].Model = "changed"
+	got.Raw[0] = '['
+	got.Usage.TotalTokens = 0
+
+	again, _ := cache.Get("key")
+	if again.ToolCalls[0].Function.Arguments["city"] != "Paris" {
+		t.Errorf("Expected the cached tool call arguments to be unchanged, got %v", again.ToolCalls[0].Function.Arguments)
+	}
+	if again.LogProbs[0].TopAlternatives[0].Token != "saved" || again.ModelAttempts[0].Model != "model-a" {
+		t.Errorf("Expected the cached log probs and model attempts to be unchanged, got %+v %+v", again.LogProbs, again.ModelAttempts)
+	}
+	if string(again.Raw) != `{"id": "1"}` || again.Usage.TotalTokens != 10 {
+		t.Errorf("Expected the cached raw response and usage to be unchanged, got %s %+v", again.Raw, again.Usage)
+	}
+}
@@ -0,0 +1,89 @@
+package gollmrouter_test
+
+import (
+	"strings"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// longHistory builds a conversation with a system prompt and alternating turns of roughly 100 tokens each
+func longHistory(turns int) []provider.Message {
+	messages := []provider.Message{{Role: "system", Content: "You are a helpful assistant."}}
+	for i := 0; i < turns; i++ {
+		messages = append(messages,
+			provider.Message{Role: "user", Content: strings.Repeat("question ", 45)},
+			provider.Message{Role: "assistant", Content: strings.Repeat("answer ", 55)},
+		)
+	}
+	return append(messages, provider.Message{Role: "user", Content: "And finally, what is 2 + 2?"})
+}
+
+func sameMessage(a, b provider.Message) bool {
+	return a.Role == b.Role && a.Content == b.Content
+}
+
+func TestTrimToContextWindow(t *testing.T) {
+	messages := longHistory(20)
+	maxTokens := 500
+
+	trimmed := gollmrouter.TrimToContextWindow(messages, maxTokens, true)
+
+	if got := provider.DefaultTokenEstimator.EstimateTokens(trimmed); got > maxTokens {
+		t.Errorf("Expected trimmed history to fit in %d tokens, got %d", maxTokens, got)
+	}
+	if len(trimmed) >= len(messages) || len(trimmed) < 3 {
+		t.Fatalf("Expected some but not all messages to be dropped, got %d of %d", len(trimmed), len(messages))
+	}
+	if !sameMessage(trimmed[0], messages[0]) {
+		t.Errorf("Expected system prompt to be kept first, got %+v", trimmed[0])
+	}
+	if !sameMessage(trimmed[len(trimmed)-1], messages[len(messages)-1]) {
+		t.Errorf("Expected latest user message to be kept last, got %+v", trimmed[len(trimmed)-1])
+	}
+
+	// The oldest turns are dropped, so the kept history is the tail of the conversation
+	tail := messages[len(messages)-len(trimmed)+1:]
+	for i, message := range trimmed[1:] {
+		if !sameMessage(message, tail[i]) {
+			t.Fatalf("Expected the most recent messages to be kept in order, mismatch at %d", i)
+		}
+	}
+
+	if len(messages) != 42 || messages[1].Role != "user" {
+		t.Error("Expected the input slice not to be modified")
+	}
+}
+
+func TestTrimToContextWindow_KeepsLatestTurnWhenOverBudget(t *testing.T) {
+	messages := longHistory(3)
+
+	trimmed := gollmrouter.TrimToContextWindow(messages, 1, true)
+	if len(trimmed) != 2 || trimmed[0].Role != "system" || !sameMessage(trimmed[1], messages[len(messages)-1]) {
+		t.Errorf("Expected only the system prompt and latest user message, got %+v", trimmed)
+	}
+
+	trimmed = gollmrouter.TrimToContextWindow(messages, 1, false)
+	if len(trimmed) != 1 || !sameMessage(trimmed[0], messages[len(messages)-1]) {
+		t.Errorf("Expected only the latest user message without keepSystem, got %+v", trimmed)
+	}
+
+	if trimmed := gollmrouter.TrimToContextWindow(messages, 0, true); len(trimmed) != len(messages) {
+		t.Errorf("Expected no trimming with a zero limit, got %d messages", len(trimmed))
+	}
+}
+
+func TestTrimToContextWindow_DropsToolResultsWithTheirCall(t *testing.T) {
+	messages := []provider.Message{
+		{Role: "system", Content: "You are a helpful assistant."},
+		{Role: "user", Content: "What's the weather in Paris?"},
+		{Role: "assistant", Content: strings.Repeat("Let me look that up. ", 20), ToolCalls: []provider.ToolCall{{ID: "call_1", Type: "function", Function: provider.ToolCallFunction{Name: "get_weather", Arguments: map[string]interface{}{"city": "Paris"}}}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+	}
+
+	trimmed := gollmrouter.TrimToContextWindow(messages, 30, true)
+	if len(trimmed) != 2 || trimmed[0].Role != "system" || trimmed[1].Role != "user" {
+		t.Errorf("Expected the tool result to be dropped with its call, got %+v", trimmed)
+	}
+}
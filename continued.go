@@ -0,0 +1,60 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// continuePrompt asks the model to pick up an answer that was cut off by the output token limit
+const continuePrompt = "Continue exactly where you left off. Do not repeat anything you already wrote."
+
+// QueryContinued sends the query like QueryWithOptions and, while the answer is cut off by the
+// output token limit (a FinishLength finish reason, e.g. "length" or "MAX_TOKENS"), sends the
+// conversation again with the partial answer as an assistant message and a request to continue,
+// up to maxContinuations times.
+//
+// The returned result has the pieces' content and reasoning concatenated, their usage, cost and
+// model attempts summed, and the finish reason, model and tool calls of the last piece, so a
+// FinishLength finish reason means the cap was hit. Only the first choice is continued, so
+// Completions is cleared once a continuation was needed. If a continuation fails, the content
+// gathered so far is returned along with the error.
+func (r *Router) QueryContinued(ctx context.Context, messages []provider.Message, options provider.QueryOptions, maxContinuations int) (*provider.QueryResult, error) {
+	result, err := r.QueryWithOptions(ctx, messages, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clone the slices appended to below so the first result, which may be cached, keeps its own
+	combined := *result
+	combined.LogProbs = slices.Clone(result.LogProbs)
+	combined.ModelAttempts = slices.Clone(result.ModelAttempts)
+	conversation := append([]provider.Message(nil), messages...)
+	for i := 0; i < maxContinuations && result.NormalizedFinishReason() == provider.FinishLength; i++ {
+		conversation = append(conversation,
+			provider.Message{Role: "assistant", Content: result.Content},
+			provider.Message{Role: "user", Content: continuePrompt},
+		)
+
+		result, err = r.QueryWithOptions(ctx, conversation, options)
+		if err != nil {
+			return &combined, fmt.Errorf("continuation %d failed: %w", i+1, err)
+		}
+
+		combined.Content += result.Content
+		combined.Reasoning += result.Reasoning
+		combined.Usage = provider.AddUsage(combined.Usage, result.Usage)
+		combined.CostUSD += result.CostUSD
+		combined.LogProbs = append(combined.LogProbs, result.LogProbs...)
+		combined.ModelAttempts = append(combined.ModelAttempts, result.ModelAttempts...)
+		combined.Model = result.Model
+		combined.FinishReason = result.FinishReason
+		combined.ToolCalls = result.ToolCalls
+		combined.SystemFingerprint = result.SystemFingerprint
+		combined.Raw = result.Raw
+		combined.Completions = nil
+	}
+	return &combined, nil
+}
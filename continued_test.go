@@ -0,0 +1,133 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// piecesProvider answers with the next piece on each query, cut off by the token limit until the last
+func piecesProvider(pieces []string, finishReason string, conversations *[][]provider.Message) *mockProvider {
+	return &mockProvider{name: "mock", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		i := len(*conversations)
+		*conversations = append(*conversations, messages)
+		if i >= len(pieces) {
+			return nil, errors.New("no more pieces")
+		}
+		reason := "stop"
+		if i < len(pieces)-1 {
+			reason = finishReason
+		}
+		return &provider.QueryResult{
+			Content:      pieces[i],
+			Model:        "mock-model",
+			FinishReason: reason,
+			Usage:        &provider.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		}, nil
+	}}
+}
+
+func TestRouter_QueryContinued(t *testing.T) {
+	var conversations [][]provider.Message
+	router, err := gollmrouter.NewRouter(piecesProvider([]string{"Once upon a ", "time."}, "length", &conversations))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "Tell me a story."}}
+	result, err := router.QueryContinued(context.Background(), messages, provider.QueryOptions{}, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Content != "Once upon a time." {
+		t.Errorf("Expected the pieces to be concatenated, got %q", result.Content)
+	}
+	if result.NormalizedFinishReason() != provider.FinishStop {
+		t.Errorf("Expected the last piece's finish reason, got %q", result.FinishReason)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 30 || result.Usage.CompletionTokens != 10 {
+		t.Errorf("Expected the usage of both requests, got %+v", result.Usage)
+	}
+
+	if len(conversations) != 2 {
+		t.Fatalf("Expected one continuation, got %d requests", len(conversations))
+	}
+	continued := conversations[1]
+	if len(continued) != 3 || continued[1].Role != "assistant" || continued[1].Content != "Once upon a " || continued[2].Role != "user" {
+		t.Errorf("Expected the partial answer and a request to continue, got %+v", continued)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected the caller's messages to be left unchanged, got %d", len(messages))
+	}
+}
+
+func TestRouter_QueryContinuedKeepsFirstResult(t *testing.T) {
+	// The first result's log probs have room to grow, so appending to them in place would
+	// overwrite memory the provider still holds
+	first := &provider.QueryResult{
+		Content:      "Once upon a ",
+		FinishReason: "length",
+		LogProbs:     append(make([]provider.TokenLogProb, 0, 4), provider.TokenLogProb{Token: "Once"}),
+	}
+	calls := 0
+	router, err := gollmrouter.NewRouter(&mockProvider{name: "mock", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		calls++
+		if calls == 1 {
+			return first, nil
+		}
+		return &provider.QueryResult{Content: "time.", FinishReason: "stop", LogProbs: []provider.TokenLogProb{{Token: "time"}}}, nil
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryContinued(context.Background(), []provider.Message{{Role: "user", Content: "Tell me a story."}}, provider.QueryOptions{}, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.LogProbs) != 2 || result.LogProbs[1].Token != "time" {
+		t.Errorf("Expected the log probs of both pieces, got %+v", result.LogProbs)
+	}
+	if spare := first.LogProbs[:2]; spare[1].Token != "" {
+		t.Errorf("Expected the first result's backing array to be left alone, got %+v", spare)
+	}
+}
+
+func TestRouter_QueryContinuedStopsAtCap(t *testing.T) {
+	var conversations [][]provider.Message
+	router, err := gollmrouter.NewRouter(piecesProvider([]string{"a", "b", "c", "d"}, "MAX_TOKENS", &conversations))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryContinued(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "abc" || len(conversations) != 3 {
+		t.Errorf("Expected 2 continuations, got %q after %d requests", result.Content, len(conversations))
+	}
+	if result.NormalizedFinishReason() != provider.FinishLength {
+		t.Errorf("Expected the length finish reason when the cap is hit, got %q", result.FinishReason)
+	}
+}
+
+func TestRouter_QueryContinuedWithoutTruncation(t *testing.T) {
+	var conversations [][]provider.Message
+	router, err := gollmrouter.NewRouter(piecesProvider([]string{"Short answer."}, "length", &conversations))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryContinued(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "Short answer." || len(conversations) != 1 {
+		t.Errorf("Expected a complete answer to be returned as is, got %q after %d requests", result.Content, len(conversations))
+	}
+}
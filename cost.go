@@ -0,0 +1,148 @@
+package gollmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// ModelPricing is the price of a model in US dollars per 1000 tokens
+type ModelPricing struct {
+	InputPer1K  float64
+	OutputPer1K float64
+}
+
+// PricingTable maps model names, as reported in QueryResult.Model, to their prices
+type PricingTable map[string]ModelPricing
+
+// Cost returns the cost in US dollars of the token usage on the model.
+// Unpriced models and results without usage cost zero.
+func (t PricingTable) Cost(model string, usage *provider.Usage) float64 {
+	pricing, ok := t[model]
+	if !ok || usage == nil {
+		return 0
+	}
+	return float64(usage.PromptTokens)/1000*pricing.InputPer1K + float64(usage.CompletionTokens)/1000*pricing.OutputPer1K
+}
+
+// WithPricing makes the router set QueryResult.CostUSD from the usage reported by the provider
+// and the model's price, and add it to TotalCostUSD
+func WithPricing(table PricingTable) RouterOption {
+	return func(r *Router) {
+		r.pricing = make(PricingTable, len(table))
+		for model, pricing := range table {
+			r.pricing[model] = pricing
+		}
+	}
+}
+
+// costTracker accumulates the cost of successful requests
+type costTracker struct {
+	mu    sync.Mutex
+	total float64
+}
+
+func (c *costTracker) add(cost float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total += cost
+}
+
+// TotalCostUSD returns the estimated cost of all successful provider requests made by the router.
+// Responses served from the cache are not counted again.
+func (r *Router) TotalCostUSD() float64 {
+	r.cost.mu.Lock()
+	defer r.cost.mu.Unlock()
+	return r.cost.total
+}
+
+// defaultEstimatedOutputTokens is the length of the response assumed by EstimateCost unless
+// WithEstimatedOutputTokens is used
+const defaultEstimatedOutputTokens = 500
+
+// CostEstimate is the expected cost of a query, computed before it is sent
+type CostEstimate struct {
+	Provider     string  // Provider that would receive the query
+	Model        string  // Model the estimate is priced for
+	InputTokens  int     // Estimated with the provider's token estimator
+	OutputTokens int     // Assumed response length (see WithEstimatedOutputTokens)
+	CostUSD      float64 // Estimated cost in US dollars
+}
+
+// WithEstimatedOutputTokens sets the response length, in tokens, that EstimateCost assumes.
+// The default is 500.
+func WithEstimatedOutputTokens(tokens int) RouterOption {
+	return func(r *Router) {
+		r.outputEstimate = tokens
+	}
+}
+
+// EstimateCost estimates the cost of a query without sending it. The estimate is for the first
+// model of the provider the router would try first: the highest ranked provider that can handle
+// the query's tools and has quota left, or the forced provider and model when the options name
+// them. Input tokens come from the provider's token estimator and prices from WithPricing.
+// If the model has no price, the estimate is returned with zero cost and an error.
+func (r *Router) EstimateCost(messages []provider.Message, options provider.QueryOptions) (CostEstimate, error) {
+	options = mergeOptions(r.defaults, options)
+	if options.SystemPrompt != "" {
+		messages = append([]provider.Message{{Role: "system", Content: options.SystemPrompt}}, messages...)
+	}
+
+	p, err := r.estimateProvider(messages, options)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	estimate := CostEstimate{
+		Provider:     p.Name(),
+		Model:        options.ForceModel,
+		InputTokens:  estimateTokens(p, messages),
+		OutputTokens: r.outputEstimate,
+	}
+	if estimate.Model == "" {
+		if models := p.Models(); len(models) > 0 {
+			estimate.Model = models[0]
+		}
+	}
+	if estimate.OutputTokens <= 0 {
+		estimate.OutputTokens = defaultEstimatedOutputTokens
+	}
+
+	if _, ok := r.pricing[estimate.Model]; !ok {
+		return estimate, fmt.Errorf("no pricing configured for model %q", estimate.Model)
+	}
+	estimate.CostUSD = r.pricing.Cost(estimate.Model, &provider.Usage{
+		PromptTokens:     estimate.InputTokens,
+		CompletionTokens: estimate.OutputTokens,
+	})
+	return estimate, nil
+}
+
+// estimateProvider returns the provider the router would try first for the query. Unlike a
+// query, it doesn't advance the round-robin or weighted rotation, so providers of the same rank
+// are considered in the order they were added.
+func (r *Router) estimateProvider(messages []provider.Message, options provider.QueryOptions) (provider.Provider, error) {
+	var errs []error
+	for _, p := range r.getProviders() {
+		if options.ForceProvider != "" && p.Name() != options.ForceProvider {
+			continue
+		}
+		if err := checkToolSupport(p, options); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := checkRateLimits(context.Background(), p, messages); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return p, nil
+	}
+
+	if options.ForceProvider != "" && len(errs) == 0 {
+		return nil, fmt.Errorf("forced provider %q is not configured", options.ForceProvider)
+	}
+	return nil, fmt.Errorf("no provider is available for the query: %w", errors.Join(errs...))
+}
@@ -0,0 +1,112 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouter_CostTracking(t *testing.T) {
+	model := "priced-model"
+	mock := &mockProvider{name: "only", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		return &provider.QueryResult{
+			Content: "ok",
+			Model:   model,
+			Usage:   &provider.Usage{PromptTokens: 2000, CompletionTokens: 500, TotalTokens: 2500},
+		}, nil
+	}}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{mock}, gollmrouter.WithPricing(gollmrouter.PricingTable{
+		"priced-model": {InputPer1K: 0.15, OutputPer1K: 0.6},
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	result, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 2000 input tokens at $0.15/1K plus 500 output tokens at $0.60/1K
+	if math.Abs(result.CostUSD-0.6) > 1e-9 {
+		t.Errorf("Expected a cost of $0.60, got %v", result.CostUSD)
+	}
+
+	model = "unpriced-model"
+	result, err = router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.CostUSD != 0 {
+		t.Errorf("Expected zero cost for an unpriced model, got %v", result.CostUSD)
+	}
+
+	if math.Abs(router.TotalCostUSD()-0.6) > 1e-9 {
+		t.Errorf("Expected a total cost of $0.60, got %v", router.TotalCostUSD())
+	}
+}
+
+// countingTokensProvider is a mock provider that estimates a fixed number of tokens per message
+type countingTokensProvider struct {
+	*mockProvider
+	tokensPerMessage int
+}
+
+func (p *countingTokensProvider) EstimateTokens(messages []provider.Message) int {
+	return len(messages) * p.tokensPerMessage
+}
+
+func TestRouter_EstimateCost(t *testing.T) {
+	exhausted := &mockProvider{name: "premium", rank: 2, exhausted: true}
+	backup := &countingTokensProvider{mockProvider: &mockProvider{name: "backup", rank: 1}, tokensPerMessage: 1000}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{exhausted, backup},
+		gollmrouter.WithPricing(gollmrouter.PricingTable{
+			"backup-model": {InputPer1K: 0.15, OutputPer1K: 0.6},
+			"big-model":    {InputPer1K: 2.5, OutputPer1K: 10},
+		}),
+		gollmrouter.WithEstimatedOutputTokens(250))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "Summarize this report."}}
+	estimate, err := router.EstimateCost(messages, provider.QueryOptions{SystemPrompt: "Be brief."})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if estimate.Provider != "backup" || estimate.Model != "backup-model" {
+		t.Errorf("Expected the estimate for the first available provider, got %s/%s", estimate.Provider, estimate.Model)
+	}
+	// The system prompt and the user message, then 250 assumed output tokens
+	if estimate.InputTokens != 2000 || estimate.OutputTokens != 250 {
+		t.Errorf("Expected 2000 input and 250 output tokens, got %d and %d", estimate.InputTokens, estimate.OutputTokens)
+	}
+	// 2000 input tokens at $0.15/1K plus 250 output tokens at $0.60/1K
+	if math.Abs(estimate.CostUSD-0.45) > 1e-9 {
+		t.Errorf("Expected an estimate of $0.45, got %v", estimate.CostUSD)
+	}
+
+	estimate, err = router.EstimateCost(messages, provider.QueryOptions{ForceModel: "big-model"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(estimate.CostUSD-5) > 1e-9 {
+		t.Errorf("Expected the forced model's price, got %v", estimate.CostUSD)
+	}
+
+	estimate, err = router.EstimateCost(messages, provider.QueryOptions{ForceModel: "unpriced-model"})
+	if err == nil || estimate.CostUSD != 0 || estimate.InputTokens != 1000 {
+		t.Errorf("Expected an error and a zero-cost estimate for an unpriced model, got %+v (%v)", estimate, err)
+	}
+
+	if _, err := router.EstimateCost(messages, provider.QueryOptions{ForceProvider: "premium"}); err == nil {
+		t.Error("Expected an error when the forced provider has no quota left")
+	}
+	if backup.callCount() != 0 || exhausted.callCount() != 0 {
+		t.Error("Expected no queries to be sent")
+	}
+}
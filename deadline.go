@@ -0,0 +1,52 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// WithMinAttemptTime makes the router skip providers when less than d is left before the
+// context deadline, instead of starting an attempt that is likely to be cut short.
+// Providers are always skipped once the deadline has passed.
+func WithMinAttemptTime(d time.Duration) RouterOption {
+	return func(r *Router) {
+		r.minAttemptTime = d
+	}
+}
+
+// checkDeadline returns an error if too little time is left before the context deadline to attempt a provider
+func (r *Router) checkDeadline(ctx context.Context) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 || remaining < r.minAttemptTime {
+		return fmt.Errorf("insufficient time before deadline: %v left, need at least %v", remaining.Round(time.Millisecond), r.minAttemptTime)
+	}
+	return nil
+}
+
+// attemptContext bounds a provider attempt by the provider's own timeout. The caller's deadline
+// still applies, so the effective timeout is whichever is shorter.
+func attemptContext(ctx context.Context, p provider.Provider) (context.Context, context.CancelFunc) {
+	if timed, ok := p.(provider.TimeoutAware); ok {
+		if timeout := timed.GetTimeout(); timeout > 0 {
+			return context.WithTimeout(ctx, timeout)
+		}
+	}
+	return context.WithCancel(ctx)
+}
+
+// queryAttemptContext bounds a query attempt like attemptContext, except that the query's
+// PerRequestTimeout replaces the provider's own timeout when it is set
+func queryAttemptContext(ctx context.Context, p provider.Provider, options provider.QueryOptions) (context.Context, context.CancelFunc) {
+	if options.PerRequestTimeout > 0 {
+		return context.WithTimeout(ctx, options.PerRequestTimeout)
+	}
+	return attemptContext(ctx, p)
+}
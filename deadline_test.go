@@ -0,0 +1,125 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// timeoutProvider is a mock provider with a configured per-request timeout
+type timeoutProvider struct {
+	*mockProvider
+	timeout time.Duration
+}
+
+func (p *timeoutProvider) GetTimeout() time.Duration {
+	return p.timeout
+}
+
+func TestRouter_SkipsProvidersThatCannotFinishBeforeDeadline(t *testing.T) {
+	slow := &timeoutProvider{mockProvider: &mockProvider{name: "slow", rank: 2, content: "ok"}, timeout: 30 * time.Second}
+	backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{slow, backup}, gollmrouter.WithMinAttemptTime(time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = router.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok {
+		t.Fatalf("Expected a RouterError, got %v", err)
+	}
+	if len(routerErr.Errors) != 2 {
+		t.Fatalf("Expected both providers to be skipped, got %v", routerErr.Errors)
+	}
+	for _, providerErr := range routerErr.Errors {
+		if !strings.Contains(providerErr.Error.Error(), "deadline") {
+			t.Errorf("Expected a deadline error for %s, got %v", providerErr.ProviderName, providerErr.Error)
+		}
+	}
+	if slow.callCount() != 0 || backup.callCount() != 0 {
+		t.Error("Expected no provider to be attempted")
+	}
+}
+
+func TestRouter_ClampsProviderTimeoutToDeadline(t *testing.T) {
+	var attemptDeadline time.Time
+	slow := &timeoutProvider{mockProvider: &mockProvider{name: "slow", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		attemptDeadline, _ = ctx.Deadline()
+		return &provider.QueryResult{Content: "ok"}, nil
+	}}, timeout: 30 * time.Second}
+
+	router, err := gollmrouter.NewRouter(slow)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	callerDeadline, _ := ctx.Deadline()
+
+	if _, err := router.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !attemptDeadline.Equal(callerDeadline) {
+		t.Errorf("Expected the attempt to be bounded by the caller's deadline %v, got %v", callerDeadline, attemptDeadline)
+	}
+}
+
+func TestRouter_AppliesProviderTimeout(t *testing.T) {
+	var attemptErr error
+	hanging := &timeoutProvider{mockProvider: &mockProvider{name: "hanging", rank: 2, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		<-ctx.Done()
+		attemptErr = ctx.Err()
+		return nil, ctx.Err()
+	}}, timeout: 20 * time.Millisecond}
+	backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+	router, err := gollmrouter.NewRouter(hanging, backup)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attemptErr != context.DeadlineExceeded {
+		t.Errorf("Expected the hanging attempt to time out, got %v", attemptErr)
+	}
+	if result.Content != "ok" || backup.callCount() != 1 {
+		t.Error("Expected the router to fall back after the provider timeout")
+	}
+}
+
+func TestRouter_PerRequestTimeout(t *testing.T) {
+	slow := &timeoutProvider{mockProvider: &mockProvider{name: "slow", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return &provider.QueryResult{Content: "ok"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}}, timeout: 5 * time.Second}
+
+	router, err := gollmrouter.NewRouter(slow)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{PerRequestTimeout: 10 * time.Millisecond}); err == nil || !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("Expected the call to time out, got %v", err)
+	}
+	if result, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil || result.Content != "ok" {
+		t.Errorf("Expected a call without a per-request timeout to use the provider's timeout, got %v (%v)", result, err)
+	}
+}
@@ -0,0 +1,66 @@
+package gollmrouter
+
+import "github.com/FramnkRulez/go-llm-router/provider"
+
+// WithDefaultOptions sets query options used for every field a call leaves unset. Fields set in
+// the per-call QueryOptions always take precedence over the defaults; a field counts as unset
+// when it has its zero value. Temperature is unset when it is 0 and TemperatureSet is false,
+// so a call can still request a temperature of exactly 0 by setting TemperatureSet.
+//
+// Defaults are applied before middlewares run, so middlewares see the merged options.
+func WithDefaultOptions(defaults provider.QueryOptions) RouterOption {
+	return func(r *Router) {
+		r.defaults = defaults
+	}
+}
+
+// mergeOptions fills the unset fields of options from defaults
+func mergeOptions(defaults, options provider.QueryOptions) provider.QueryOptions {
+	if !options.HasTemperature() {
+		options.Temperature = defaults.Temperature
+		options.TemperatureSet = defaults.TemperatureSet
+	}
+	if options.ForceModel == "" {
+		options.ForceModel = defaults.ForceModel
+	}
+	if options.ForceProvider == "" {
+		options.ForceProvider = defaults.ForceProvider
+	}
+	if options.Tools == nil {
+		options.Tools = defaults.Tools
+	}
+	if options.ToolChoice == "" {
+		options.ToolChoice = defaults.ToolChoice
+	}
+	if options.ParallelToolCalls == nil {
+		options.ParallelToolCalls = defaults.ParallelToolCalls
+	}
+	if options.SystemPrompt == "" {
+		options.SystemPrompt = defaults.SystemPrompt
+	}
+	if options.N == 0 {
+		options.N = defaults.N
+	}
+	if options.Seed == nil {
+		options.Seed = defaults.Seed
+	}
+	if !options.LogProbs {
+		options.LogProbs = defaults.LogProbs
+	}
+	if options.TopLogProbs == 0 {
+		options.TopLogProbs = defaults.TopLogProbs
+	}
+	if options.PerRequestTimeout == 0 {
+		options.PerRequestTimeout = defaults.PerRequestTimeout
+	}
+	if options.ThinkingBudget == nil {
+		options.ThinkingBudget = defaults.ThinkingBudget
+	}
+	if options.ReasoningEffort == "" {
+		options.ReasoningEffort = defaults.ReasoningEffort
+	}
+	if !options.JSONMode {
+		options.JSONMode = defaults.JSONMode
+	}
+	return options
+}
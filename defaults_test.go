@@ -0,0 +1,104 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouter_DefaultOptions(t *testing.T) {
+	var received provider.QueryOptions
+	mock := &mockProvider{name: "only", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		received = options
+		return &provider.QueryResult{Content: "ok"}, nil
+	}}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{mock}, gollmrouter.WithDefaultOptions(provider.QueryOptions{
+		Temperature:  0.7,
+		ToolChoice:   "auto",
+		SystemPrompt: "Be brief.",
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Tools: tools}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received.Temperature != 0.7 || received.ToolChoice != "auto" || received.SystemPrompt != "Be brief." || len(received.Tools) != 1 {
+		t.Errorf("Expected a call that only sets tools to inherit the defaults, got %+v", received)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: 0.2, SystemPrompt: "Be thorough."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received.Temperature != 0.2 || received.SystemPrompt != "Be thorough." || received.ToolChoice != "auto" {
+		t.Errorf("Expected per-call options to take precedence over the defaults, got %+v", received)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{TemperatureSet: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received.Temperature != 0 || !received.HasTemperature() {
+		t.Errorf("Expected an explicit temperature of 0 to be kept, got %+v", received)
+	}
+}
+
+func TestQuery_SendsZeroTemperature(t *testing.T) {
+	var temperatures []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		temperature, ok := body["temperature"]
+		if !ok {
+			temperature = "unset"
+		}
+		temperatures = append(temperatures, temperature)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	config := gollmrouter.FunctionCallingConfig{APIKey: "test-key", URL: server.URL, Models: []string{"test-model"}}
+	fc, err := gollmrouter.NewFunctionCallingProvider(config)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	pool, err := gollmrouter.WithKeyRotation(config, []string{"key-a"})
+	if err != nil {
+		t.Fatalf("Failed to create key pool: %v", err)
+	}
+	recorded, err := gollmrouter.WithRecorder(fc, filepath.Join(t.TempDir(), "cassette.json"))
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{fc}, gollmrouter.WithDefaultOptions(provider.QueryOptions{Temperature: 0.7}))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	// The legacy positional temperature of 0.0 means deterministic, not the provider's default
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for _, p := range []interface {
+		Query(context.Context, []provider.Message, float64, string) (string, string, error)
+	}{fc, pool, recorded, router} {
+		if _, _, err := p.Query(context.Background(), messages, 0, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(temperatures) != 4 {
+		t.Fatalf("Expected four requests, got %d", len(temperatures))
+	}
+	for i, temperature := range temperatures {
+		if temperature != float64(0) {
+			t.Errorf("Request %d: expected temperature 0 to be sent, got %v", i+1, temperature)
+		}
+	}
+}
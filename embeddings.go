@@ -0,0 +1,100 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// EmbeddingRequest asks for vector embeddings of one or more texts
+type EmbeddingRequest = provider.EmbeddingRequest
+
+// EmbeddingResult holds one vector per input text, in the order of the request
+type EmbeddingResult = provider.EmbeddingResult
+
+// Embedder is implemented by providers that can create embeddings
+type Embedder = provider.Embedder
+
+// Embeddings creates vector embeddings using the first available provider that supports them.
+// Providers are tried in the same order as for queries; providers without embedding support are skipped.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - request: The texts to embed and, optionally, the embedding model to use
+//
+// Returns:
+//   - result: One vector per input text
+//   - error: A RouterError if every embedding provider failed
+func (r *Router) Embeddings(ctx context.Context, request EmbeddingRequest) (*EmbeddingResult, error) {
+	ctx, span := r.startQuerySpan(ctx, "Router.Embeddings")
+	defer span.End()
+
+	messages := make([]provider.Message, 0, len(request.Input))
+	for _, text := range request.Input {
+		messages = append(messages, provider.Message{Role: "user", Content: text})
+	}
+
+	var routerError RouterError
+	supported := 0
+
+	for i, p := range r.orderProviders(r.getProviders()) {
+		embedder, ok := p.(provider.Embedder)
+		if !ok || !embedder.SupportsEmbeddings() {
+			continue
+		}
+		supported++
+
+		providerName := providerDisplayName(p, i)
+
+		if err := r.checkDeadline(ctx); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeSkipped, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		if err := checkRateLimits(ctx, p, messages); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeRateLimited, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		attemptCtx, cancel := attemptContext(ctx, p)
+		start := time.Now()
+		result, err := embedder.Embeddings(attemptCtx, request)
+		r.metrics.ObserveLatency(providerName, time.Since(start))
+		cancel()
+		if err != nil {
+			r.metrics.IncRequest(providerName, request.Model, OutcomeError)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			if r.isTerminal(err) {
+				break
+			}
+			continue
+		}
+
+		r.metrics.IncRequest(providerName, result.Model, OutcomeSuccess)
+		r.metrics.IncTokens(providerName, result.Usage.TotalTokens)
+		span.SetAttributes(provider.Attr("provider.name", providerName), provider.Attr("llm.model", result.Model))
+		return result, nil
+	}
+
+	if supported == 0 {
+		err := fmt.Errorf("no configured provider supports embeddings")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.RecordError(&routerError)
+	return nil, &routerError
+}
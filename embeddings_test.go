@@ -0,0 +1,90 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// mockEmbedder is a mockProvider that can also create embeddings
+type mockEmbedder struct {
+	*mockProvider
+	supported bool
+	embedErr  error
+}
+
+func (m *mockEmbedder) SupportsEmbeddings() bool {
+	return m.supported
+}
+
+func (m *mockEmbedder) Embeddings(ctx context.Context, request provider.EmbeddingRequest) (*provider.EmbeddingResult, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+
+	if m.embedErr != nil {
+		return nil, m.embedErr
+	}
+
+	vectors := make([][]float32, len(request.Input))
+	for i := range vectors {
+		vectors[i] = []float32{float32(i)}
+	}
+	return &provider.EmbeddingResult{Vectors: vectors, Model: m.name + "-embed"}, nil
+}
+
+func TestRouter_EmbeddingsFallback(t *testing.T) {
+	chatOnly := &mockProvider{name: "chat-only", rank: 3}
+	disabled := &mockEmbedder{mockProvider: &mockProvider{name: "disabled", rank: 2}}
+	failing := &mockEmbedder{mockProvider: &mockProvider{name: "failing", rank: 2}, supported: true, embedErr: errors.New("boom")}
+	working := &mockEmbedder{mockProvider: &mockProvider{name: "working", rank: 1}, supported: true}
+
+	router, err := gollmrouter.NewRouter(chatOnly, disabled, failing, working)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.Embeddings(context.Background(), gollmrouter.EmbeddingRequest{Input: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Model != "working-embed" || len(result.Vectors) != 2 {
+		t.Errorf("Expected the working provider's vectors, got %+v", result)
+	}
+	if disabled.callCount() != 0 || chatOnly.callCount() != 0 {
+		t.Error("Expected providers without embedding support to be skipped")
+	}
+	if failing.callCount() != 1 {
+		t.Errorf("Expected failing provider to be tried once, got %d", failing.callCount())
+	}
+}
+
+func TestRouter_EmbeddingsAllFail(t *testing.T) {
+	failing := &mockEmbedder{mockProvider: &mockProvider{name: "failing"}, supported: true, embedErr: errors.New("boom")}
+
+	router, err := gollmrouter.NewRouter(failing)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	_, err = router.Embeddings(context.Background(), gollmrouter.EmbeddingRequest{Input: []string{"a"}})
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok || len(routerErr.Errors) != 1 || routerErr.Errors[0].ProviderName != "failing" {
+		t.Errorf("Expected RouterError for the failing provider, got %v", err)
+	}
+}
+
+func TestRouter_EmbeddingsUnsupported(t *testing.T) {
+	router, err := gollmrouter.NewRouter(&mockProvider{name: "chat-only"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.Embeddings(context.Background(), gollmrouter.EmbeddingRequest{Input: []string{"a"}}); err == nil {
+		t.Error("Expected error when no provider supports embeddings")
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	gollmrouter "github.com/FramnkRulez/go-llm-router"
 	"github.com/FramnkRulez/go-llm-router/ai"
+	"github.com/FramnkRulez/go-llm-router/provider"
 )
 
 func enhancedRateLimitingExample() {
@@ -77,12 +78,12 @@ func enhancedRateLimitingExample() {
 
 	// Create router with all providers
 	// Providers will be automatically sorted by rank (highest first)
-	router, err := gollmrouter.NewRouter(
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{
 		openRouterProvider,      // Rank 1 (lowest)
 		functionCallingProvider, // Rank 2
 		geminiProvider2,         // Rank 2
 		geminiProvider1,         // Rank 3 (highest)
-	)
+	}, gollmrouter.WithEmptyResponseIsError(true)) // Fall back when a provider returns nothing
 	if err != nil {
 		log.Fatalf("Failed to create router: %v", err)
 	}
@@ -147,7 +148,7 @@ func enhancedRateLimitingExample() {
 
 	// Example 4: Test empty response handling
 	fmt.Println("=== Example 4: Empty Response Handling ===")
-	fmt.Println("Note: Empty responses are now treated as errors and will cause fallback to next provider")
+	fmt.Println("Note: With WithEmptyResponseIsError enabled, empty responses cause fallback to next provider")
 
 	// This would normally trigger an empty response scenario
 	// In a real scenario, some models might return empty responses to indicate failure
@@ -178,7 +179,7 @@ func enhancedRateLimitingExample() {
 	fmt.Println("The router automatically tries providers in order of rank:")
 	fmt.Println("- Higher ranked providers are tried first")
 	fmt.Println("- If a provider hits rate limits, it moves to the next")
-	fmt.Println("- Empty responses are treated as errors and trigger fallback")
+	fmt.Println("- Empty responses trigger fallback when WithEmptyResponseIsError is enabled")
 	fmt.Println("- All rate limits (daily, per-minute, tokens) are checked")
 	fmt.Println()
 
@@ -203,6 +204,6 @@ func enhancedRateLimitingExample() {
 	fmt.Println("   - MaxTokensPerMinute: Set to your per-minute token limit")
 	fmt.Println()
 	fmt.Println("3. Use 0 for unlimited (no limit enforced)")
-	fmt.Println("4. Empty responses are automatically treated as errors")
+	fmt.Println("4. Enable WithEmptyResponseIsError to treat empty responses as errors")
 	fmt.Println("5. Providers are automatically sorted by rank on router creation")
 }
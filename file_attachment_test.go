@@ -1,6 +1,9 @@
 package gollmrouter_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	gollmrouter "github.com/FramnkRulez/go-llm-router"
@@ -71,3 +74,56 @@ func TestNewMessageWithoutFiles(t *testing.T) {
 		t.Errorf("Expected 0 files, got %d", len(message.Files))
 	}
 }
+
+func TestNewFileAttachmentFromPathTooLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, make([]byte, 2048), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	_, err := gollmrouter.NewFileAttachmentFromPathWithMaxSize(path, 1024)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum attachment size") {
+		t.Errorf("Expected size limit error, got %v", err)
+	}
+
+	if _, err := gollmrouter.NewFileAttachmentFromPathWithMaxSize(path, 4096); err != nil {
+		t.Errorf("Expected file within the limit to be accepted, got %v", err)
+	}
+}
+
+func TestNewFileAttachmentFromPathSniffsContentType(t *testing.T) {
+	// A PNG signature in a file without an extension
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	path := filepath.Join(t.TempDir(), "screenshot")
+	if err := os.WriteFile(path, png, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	file, err := gollmrouter.NewFileAttachmentFromPath(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if file.MimeType != "image/png" {
+		t.Errorf("Expected detected MIME type 'image/png', got '%s'", file.MimeType)
+	}
+	if file.Type != "image" {
+		t.Errorf("Expected file type 'image', got '%s'", file.Type)
+	}
+}
+
+func TestNewFileAttachmentFromURL(t *testing.T) {
+	file := gollmrouter.NewFileAttachmentFromURL("https://example.com/images/cat.png?size=large", "image/png")
+
+	if file.URL != "https://example.com/images/cat.png?size=large" {
+		t.Errorf("Expected URL to be stored, got '%s'", file.URL)
+	}
+	if file.Type != "image" {
+		t.Errorf("Expected file type 'image', got '%s'", file.Type)
+	}
+	if file.Name != "cat.png" {
+		t.Errorf("Expected name 'cat.png', got '%s'", file.Name)
+	}
+	if len(file.Data) != 0 {
+		t.Errorf("Expected no data for a URL attachment, got %d bytes", len(file.Data))
+	}
+}
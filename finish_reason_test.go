@@ -0,0 +1,63 @@
+package gollmrouter_test
+
+import (
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+func TestNormalizeFinishReason(t *testing.T) {
+	testCases := []struct {
+		provider string
+		raw      string
+		expected gollmrouter.FinishReason
+	}{
+		{"OpenAI", "stop", gollmrouter.FinishStop},
+		{"OpenAI", "length", gollmrouter.FinishLength},
+		{"OpenAI", "tool_calls", gollmrouter.FinishToolCalls},
+		{"OpenAI", "function_call", gollmrouter.FinishToolCalls},
+		{"OpenAI", "content_filter", gollmrouter.FinishContentFilter},
+		{"Gemini", "STOP", gollmrouter.FinishStop},
+		{"Gemini", "MAX_TOKENS", gollmrouter.FinishLength},
+		{"Gemini", "SAFETY", gollmrouter.FinishContentFilter},
+		{"Gemini", "RECITATION", gollmrouter.FinishContentFilter},
+		{"Gemini", "PROHIBITED_CONTENT", gollmrouter.FinishContentFilter},
+		{"Gemini", "MALFORMED_FUNCTION_CALL", gollmrouter.FinishOther},
+		{"Bedrock", "end_turn", gollmrouter.FinishStop},
+		{"Bedrock", "stop_sequence", gollmrouter.FinishStop},
+		{"Bedrock", "max_tokens", gollmrouter.FinishLength},
+		{"Bedrock", "tool_use", gollmrouter.FinishToolCalls},
+		{"Bedrock", "guardrail_intervened", gollmrouter.FinishContentFilter},
+		{"Missing", "", gollmrouter.FinishOther},
+	}
+
+	for _, tc := range testCases {
+		if got := gollmrouter.NormalizeFinishReason(tc.raw); got != tc.expected {
+			t.Errorf("%s %q: expected %s, got %s", tc.provider, tc.raw, tc.expected, got)
+		}
+	}
+}
+
+func TestQueryResult_NormalizedFinishReason(t *testing.T) {
+	result := gollmrouter.QueryResult{FinishReason: "MAX_TOKENS"}
+	if result.NormalizedFinishReason() != gollmrouter.FinishLength {
+		t.Errorf("Expected FinishLength, got %s", result.NormalizedFinishReason())
+	}
+	if result.FinishReason != "MAX_TOKENS" {
+		t.Errorf("Expected the raw finish reason to be kept, got %q", result.FinishReason)
+	}
+
+	// Gemini reports STOP when it returns function calls
+	withToolCalls := gollmrouter.QueryResult{
+		FinishReason: "STOP",
+		ToolCalls:    []gollmrouter.ToolCall{gollmrouter.NewToolCall("get_weather", "get_weather", nil)},
+	}
+	if withToolCalls.NormalizedFinishReason() != gollmrouter.FinishToolCalls {
+		t.Errorf("Expected FinishToolCalls for a stop with tool calls, got %s", withToolCalls.NormalizedFinishReason())
+	}
+
+	completion := gollmrouter.Completion{FinishReason: "content_filter"}
+	if completion.NormalizedFinishReason() != gollmrouter.FinishContentFilter {
+		t.Errorf("Expected FinishContentFilter, got %s", completion.NormalizedFinishReason())
+	}
+}
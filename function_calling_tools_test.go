@@ -0,0 +1,574 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// sleepyToolExecutor answers every tool call with its name after a delay, tracking peak concurrency
+type sleepyToolExecutor struct {
+	delay   time.Duration
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (e *sleepyToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	running := e.running.Add(1)
+	defer e.running.Add(-1)
+	for {
+		peak := e.peak.Load()
+		if running <= peak || e.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return gollmrouter.NewToolCallResult(toolCall.ID, toolCall.Function.Name), nil
+}
+
+func (e *sleepyToolExecutor) GetAvailableTools() []provider.Tool {
+	return nil
+}
+
+// newToolCallServer returns a server that asks for the given tool calls on the first request
+// and answers "done" on the next one, recording the request bodies it received
+func newToolCallServer(t *testing.T, toolNames []string) (*httptest.Server, *[]map[string]interface{}) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request map[string]interface{}
+		json.Unmarshal(body, &request)
+
+		mu.Lock()
+		requests = append(requests, request)
+		first := len(requests) == 1
+		mu.Unlock()
+
+		message := map[string]interface{}{"role": "assistant", "content": "done"}
+		if first {
+			toolCalls := make([]map[string]interface{}, 0, len(toolNames))
+			for _, name := range toolNames {
+				toolCalls = append(toolCalls, map[string]interface{}{
+					"id":       "call_" + name,
+					"type":     "function",
+					"function": map[string]interface{}{"name": name, "arguments": map[string]interface{}{}},
+				})
+			}
+			message = map[string]interface{}{"role": "assistant", "content": "", "tool_calls": toolCalls}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": message, "finish_reason": "stop"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestFunctionCallingProvider_ConcurrentToolCalls(t *testing.T) {
+	toolNames := []string{"first", "second", "third", "fourth"}
+	server, requests := newToolCallServer(t, toolNames)
+	executor := &sleepyToolExecutor{delay: 200 * time.Millisecond}
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:       "test-key",
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		ToolExecutor: executor,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	start := time.Now()
+	result, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if result.Content != "done" {
+		t.Errorf("Expected final answer, got %q", result.Content)
+	}
+	if executor.peak.Load() < 2 {
+		t.Errorf("Expected tool calls to overlap, peak concurrency was %d", executor.peak.Load())
+	}
+	if elapsed >= 4*executor.delay {
+		t.Errorf("Expected tool calls to run concurrently, took %v", elapsed)
+	}
+
+	// Results are sent back in the order of the tool calls
+	messages := (*requests)[1]["messages"].([]interface{})
+	toolResults := messages[len(messages)-len(toolNames):]
+	for i, name := range toolNames {
+		if got := toolResults[i].(map[string]interface{})["content"]; got != name {
+			t.Errorf("Expected result %d to be %q, got %v", i, name, got)
+		}
+	}
+}
+
+func TestFunctionCallingProvider_ToolResultMessages(t *testing.T) {
+	server, requests := newToolCallServer(t, []string{"first", "second"})
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:       "test-key",
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		ToolExecutor: &sleepyToolExecutor{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The follow-up request replays the conversation in the shape the OpenAI API expects
+	messages, _ := json.Marshal((*requests)[1]["messages"])
+	expected := `[` +
+		`{"content":"hi","role":"user"},` +
+		`{"content":"","role":"assistant","tool_calls":[` +
+		`{"function":{"arguments":"{}","name":"first"},"id":"call_first","type":"function"},` +
+		`{"function":{"arguments":"{}","name":"second"},"id":"call_second","type":"function"}]},` +
+		`{"content":"first","role":"tool","tool_call_id":"call_first"},` +
+		`{"content":"second","role":"tool","tool_call_id":"call_second"}]`
+	if string(messages) != expected {
+		t.Errorf("Unexpected follow-up messages\n%s\nexpected\n%s", messages, expected)
+	}
+}
+
+// structuredToolExecutor answers the "map" tool with a map and the "json" tool with the same
+// value through NewToolCallResultJSON
+type structuredToolExecutor struct{}
+
+func (structuredToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	weather := map[string]interface{}{"temperature": 21, "unit": "celsius"}
+	if toolCall.Function.Name == "json" {
+		return gollmrouter.NewToolCallResultJSON(toolCall.ID, weather)
+	}
+	return gollmrouter.NewToolCallResult(toolCall.ID, weather), nil
+}
+
+func (structuredToolExecutor) GetAvailableTools() []provider.Tool {
+	return nil
+}
+
+func TestFunctionCallingProvider_StructuredToolResults(t *testing.T) {
+	server, requests := newToolCallServer(t, []string{"map", "json"})
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:       "test-key",
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		ToolExecutor: structuredToolExecutor{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both results go out as JSON strings in standard tool messages
+	messages := (*requests)[1]["messages"].([]interface{})
+	for _, message := range messages[len(messages)-2:] {
+		toolMessage := message.(map[string]interface{})
+		if toolMessage["role"] != "tool" || toolMessage["content"] != `{"temperature":21,"unit":"celsius"}` {
+			t.Errorf("Expected the result as a JSON string, got %v", toolMessage)
+		}
+	}
+
+	if _, err := gollmrouter.NewToolCallResultJSON("call_1", make(chan int)); err == nil {
+		t.Error("Expected an error for a value that can't be encoded")
+	}
+}
+
+func TestFunctionCallingProvider_MaxConcurrentTools(t *testing.T) {
+	server, _ := newToolCallServer(t, []string{"first", "second", "third", "fourth"})
+	executor := &sleepyToolExecutor{delay: 50 * time.Millisecond}
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:             "test-key",
+		URL:                server.URL,
+		Models:             []string{"test-model"},
+		ToolExecutor:       executor,
+		MaxConcurrentTools: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if peak := executor.peak.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 concurrent tool calls, got %d", peak)
+	}
+}
+
+func TestFunctionCallingProvider_ParallelToolCalls(t *testing.T) {
+	server, requests := newToolCallServer(t, []string{"first", "second", "third"})
+	executor := &sleepyToolExecutor{delay: 20 * time.Millisecond}
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:       "test-key",
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		ToolExecutor: executor,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	// The model sends several calls despite parallel_tool_calls=false, so they run one at a time
+	parallel := false
+	options := provider.QueryOptions{Tools: []provider.Tool{gollmrouter.NewTool("first", "", nil)}, ParallelToolCalls: &parallel}
+	result, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "done" {
+		t.Errorf("Expected the answer after the tool results, got %q", result.Content)
+	}
+	if peak := executor.peak.Load(); peak != 1 {
+		t.Errorf("Expected tool calls to run one at a time, got %d at once", peak)
+	}
+	for i, request := range *requests {
+		if request["parallel_tool_calls"] != false {
+			t.Errorf("Expected parallel_tool_calls=false in request %d, got %v", i, request["parallel_tool_calls"])
+		}
+	}
+}
+
+func TestFunctionCallingProvider_ToolCallsStopOnCancel(t *testing.T) {
+	server, requests := newToolCallServer(t, []string{"first", "second"})
+	executor := &sleepyToolExecutor{delay: 5 * time.Second}
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:       "test-key",
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		ToolExecutor: executor,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := fc.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err == nil {
+		t.Fatal("Expected error for canceled request")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected cancellation to stop tool work, took %v", elapsed)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("Expected no follow-up request after cancellation, got %d requests", len(*requests))
+	}
+}
+
+// cancelingToolExecutor cancels the query from its first tool call and, like an executor that
+// ignores its context, still answers every call it receives
+type cancelingToolExecutor struct {
+	cancel context.CancelFunc
+	calls  atomic.Int32
+}
+
+func (e *cancelingToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	e.calls.Add(1)
+	e.cancel()
+	return gollmrouter.NewToolCallResult(toolCall.ID, toolCall.Function.Name), nil
+}
+
+func (e *cancelingToolExecutor) GetAvailableTools() []provider.Tool {
+	return nil
+}
+
+func TestFunctionCallingProvider_CancelMidToolLoop(t *testing.T) {
+	server, requests := newToolCallServer(t, []string{"first", "second", "third"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executor := &cancelingToolExecutor{cancel: cancel}
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:             "test-key",
+		URL:                server.URL,
+		Models:             []string{"test-model", "backup-model"},
+		ToolExecutor:       executor,
+		MaxConcurrentTools: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = fc.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a context error, got %v", err)
+	}
+	if calls := executor.calls.Load(); calls != 1 {
+		t.Errorf("Expected no tools to start after cancellation, got %d calls", calls)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("Expected no follow-up request after cancellation, got %d requests", len(*requests))
+	}
+}
+
+// blockingToolExecutor blocks the "slow" tool until its context is done and answers other tools immediately
+type blockingToolExecutor struct{}
+
+func (blockingToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	if toolCall.Function.Name == "slow" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return gollmrouter.NewToolCallResult(toolCall.ID, toolCall.Function.Name), nil
+}
+
+func (blockingToolExecutor) GetAvailableTools() []provider.Tool {
+	return nil
+}
+
+func TestFunctionCallingProvider_ToolTimeout(t *testing.T) {
+	server, requests := newToolCallServer(t, []string{"slow", "fast"})
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:            "test-key",
+		URL:               server.URL,
+		Models:            []string{"test-model"},
+		ToolExecutor:      blockingToolExecutor{},
+		ToolTimeout:       50 * time.Millisecond,
+		ToolFailurePolicy: gollmrouter.ToolFailureContinue,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Expected query to continue after a tool timeout, got %v", err)
+	}
+	if result.Content != "done" {
+		t.Errorf("Expected final answer, got %q", result.Content)
+	}
+
+	messages := (*requests)[1]["messages"].([]interface{})
+	toolMessage := messages[len(messages)-1].(map[string]interface{})
+	if toolMessage["role"] != "tool" || toolMessage["content"] != "fast" || messages[len(messages)-2].(map[string]interface{})["role"] != "assistant" {
+		t.Errorf("Expected only the fast tool's result, got %v", messages)
+	}
+	assertToolCallsAnswered(t, messages)
+}
+
+// assertToolCallsAnswered checks that every tool call in the sent messages has exactly one tool
+// message answering it and every tool message answers a call, as OpenAI-compatible APIs require
+func assertToolCallsAnswered(t *testing.T, messages []interface{}) {
+	t.Helper()
+
+	calls := make(map[string]int)
+	for _, message := range messages {
+		message := message.(map[string]interface{})
+		toolCalls, _ := message["tool_calls"].([]interface{})
+		for _, call := range toolCalls {
+			calls[call.(map[string]interface{})["id"].(string)]++
+		}
+		if message["role"] == "tool" {
+			calls[message["tool_call_id"].(string)]--
+		}
+	}
+	for id, unanswered := range calls {
+		if unanswered != 0 {
+			t.Errorf("Expected tool call %q to have exactly one response, got %v", id, messages)
+		}
+	}
+}
+
+// failingToolExecutor fails the "broken" tool and answers other tools with their name
+type failingToolExecutor struct{}
+
+func (failingToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	if toolCall.Function.Name == "broken" {
+		return nil, errors.New("service unavailable")
+	}
+	return gollmrouter.NewToolCallResult(toolCall.ID, toolCall.Function.Name), nil
+}
+
+func (failingToolExecutor) GetAvailableTools() []provider.Tool {
+	return nil
+}
+
+func TestFunctionCallingProvider_ToolFailurePolicies(t *testing.T) {
+	newProvider := func(t *testing.T, policy gollmrouter.ToolFailurePolicy) (provider.Provider, *[]map[string]interface{}) {
+		server, requests := newToolCallServer(t, []string{"broken", "working"})
+		fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+			APIKey:            "test-key",
+			URL:               server.URL,
+			Models:            []string{"test-model"},
+			ToolExecutor:      failingToolExecutor{},
+			ToolFailurePolicy: policy,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		return fc, requests
+	}
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+
+	t.Run("ReturnToModel", func(t *testing.T) {
+		fc, requests := newProvider(t, gollmrouter.ToolFailureReturnToModel)
+		if _, err := fc.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		sent := (*requests)[1]["messages"].([]interface{})
+		toolResults := sent[len(sent)-2:]
+		broken := toolResults[0].(map[string]interface{})
+		if broken["tool_call_id"] != "call_broken" || !strings.Contains(broken["content"].(string), "service unavailable") {
+			t.Errorf("Expected the error as the broken tool's result, got %v", broken)
+		}
+		if toolResults[1].(map[string]interface{})["content"] != "working" {
+			t.Errorf("Expected the working tool's result, got %v", toolResults[1])
+		}
+		assertToolCallsAnswered(t, sent)
+	})
+
+	t.Run("Continue", func(t *testing.T) {
+		fc, requests := newProvider(t, gollmrouter.ToolFailureContinue)
+		if _, err := fc.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		sent := (*requests)[1]["messages"].([]interface{})
+		last := sent[len(sent)-1].(map[string]interface{})
+		if last["tool_call_id"] != "call_working" || sent[len(sent)-2].(map[string]interface{})["role"] != "assistant" {
+			t.Errorf("Expected only the working tool's result, got %v", sent)
+		}
+		assertToolCallsAnswered(t, sent)
+	})
+
+	t.Run("AbortQuery", func(t *testing.T) {
+		fc, requests := newProvider(t, gollmrouter.ToolFailureAbortQuery)
+		_, err := fc.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+
+		var toolErr *gollmrouter.ToolExecutionError
+		if !errors.As(err, &toolErr) || toolErr.ToolName != "broken" || toolErr.ToolCallID != "call_broken" {
+			t.Fatalf("Expected a ToolExecutionError for the broken tool, got %v", err)
+		}
+		if len(*requests) != 1 {
+			t.Errorf("Expected no follow-up request after the failure, got %d requests", len(*requests))
+		}
+
+		// The router doesn't retry the query with another provider
+		fc, _ = newProvider(t, gollmrouter.ToolFailureAbortQuery)
+		backup := &mockProvider{name: "backup", content: "ok"}
+		router, _ := gollmrouter.NewRouter(fc, backup)
+		if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); !errors.As(err, &toolErr) {
+			t.Errorf("Expected the query to fail with the tool error, got %v", err)
+		}
+		if backup.callCount() != 0 {
+			t.Errorf("Expected no fallback after an aborted query, got %d calls", backup.callCount())
+		}
+	})
+}
+
+// catalogToolExecutor offers a fixed set of tools and answers every call with the tool's name
+type catalogToolExecutor struct {
+	tools []provider.Tool
+}
+
+func (e catalogToolExecutor) ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+	return gollmrouter.NewToolCallResult(toolCall.ID, toolCall.Function.Name), nil
+}
+
+func (e catalogToolExecutor) GetAvailableTools() []provider.Tool {
+	return e.tools
+}
+
+func TestFunctionCallingProvider_MergesToolSets(t *testing.T) {
+	server, requests := newToolCallServer(t, nil)
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		URL:    server.URL,
+		Models: []string{"test-model"},
+		ToolExecutor: catalogToolExecutor{tools: []provider.Tool{
+			gollmrouter.NewTool("search", "Search from the executor", nil),
+			gollmrouter.NewTool("calculator", "Do arithmetic", nil),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	options := provider.QueryOptions{Tools: []provider.Tool{
+		gollmrouter.NewTool("weather", "Get the weather", nil),
+		gollmrouter.NewTool("search", "Search from the query", nil),
+		gollmrouter.NewTool("weather", "Duplicate weather", nil),
+	}}
+	if _, err := fc.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := (*requests)[0]["tools"].([]interface{})
+	var names, descriptions []string
+	for _, tool := range sent {
+		function := tool.(map[string]interface{})["function"].(map[string]interface{})
+		names = append(names, function["name"].(string))
+		descriptions = append(descriptions, function["description"].(string))
+	}
+	if strings.Join(names, ",") != "calculator,search,weather" {
+		t.Fatalf("Expected one sorted declaration per tool, got %v", names)
+	}
+	if descriptions[1] != "Search from the query" || descriptions[2] != "Get the weather" {
+		t.Errorf("Expected the query's tools to take precedence, got %v", descriptions)
+	}
+}
+
+func TestFunctionCallingProvider_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	for _, configured := range []string{"", "my-app/2.0"} {
+		p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+			APIKey:    "test-key",
+			URL:       server.URL,
+			Models:    []string{"test-model"},
+			UserAgent: configured,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(userAgents) != 2 || userAgents[0] != "go-llm-router/1.0" || userAgents[1] != "my-app/2.0" {
+		t.Errorf("Expected the default and then the configured User-Agent, got %v", userAgents)
+	}
+}
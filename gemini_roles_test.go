@@ -17,6 +17,14 @@ func TestGeminiRoleValidation(t *testing.T) {
 		}
 	}
 
+	// Tool results are valid when they name the call they answer
+	if err := gollmrouter.ValidateGeminiMessage(gollmrouter.Message{Role: "tool", ToolCallID: "get_weather", Content: "21"}); err != nil {
+		t.Errorf("Expected a tool message with a ToolCallID to be valid, got %v", err)
+	}
+	if err := gollmrouter.ValidateGeminiMessage(gollmrouter.Message{Role: "tool", Content: "21"}); err == nil {
+		t.Error("Expected a tool message without a ToolCallID to be invalid")
+	}
+
 	// Test invalid roles
 	invalidRoles := []string{"invalid", "bot", "admin", "moderator"}
 	for _, role := range invalidRoles {
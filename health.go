@@ -0,0 +1,65 @@
+package gollmrouter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// HealthChecker is implemented by providers that can verify their credentials and connectivity
+type HealthChecker = provider.HealthChecker
+
+// Warmer is implemented by providers that can open their API connection ahead of the first request
+type Warmer = provider.Warmer
+
+// HealthCheckAll runs the health checks of all providers concurrently and returns the result
+// for each provider by name, nil meaning healthy. Providers that don't implement HealthChecker
+// are not included. Providers sharing a name are told apart by their position in the router.
+func (r *Router) HealthCheckAll(ctx context.Context) map[string]error {
+	return runForEachProvider(r.getProviders(), func(checker provider.HealthChecker) error {
+		return checker.HealthCheck(ctx)
+	})
+}
+
+// Warmup opens a connection to each provider's API concurrently, so the first queries don't pay
+// for DNS lookups and TLS handshakes. It is meant to be called at startup and doesn't count
+// against the rate limits. The result for each provider is returned by name, nil meaning warmed
+// up; providers that don't implement Warmer are not included. It is safe to call concurrently.
+func (r *Router) Warmup(ctx context.Context) map[string]error {
+	return runForEachProvider(r.getProviders(), func(warmer provider.Warmer) error {
+		return warmer.Warmup(ctx)
+	})
+}
+
+// runForEachProvider calls fn concurrently for each provider implementing T and returns the
+// errors by provider name
+func runForEachProvider[T any](providers []provider.Provider, fn func(T) error) map[string]error {
+	names := uniqueProviderNames(providers)
+
+	targets := make(map[string]T)
+	for i, p := range providers {
+		if target, ok := p.(T); ok && implements[T](p) {
+			targets[names[i]] = target
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]error, len(targets))
+
+	for name, target := range targets {
+		wg.Add(1)
+		go func(name string, target T) {
+			defer wg.Done()
+			err := fn(target)
+
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, target)
+	}
+
+	wg.Wait()
+	return results
+}
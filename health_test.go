@@ -0,0 +1,121 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+// healthCheckProvider is a mock provider whose health check returns a configured error
+type healthCheckProvider struct {
+	*mockProvider
+	healthErr error
+	delay     time.Duration
+	running   *atomic.Int32
+	peak      *atomic.Int32
+}
+
+func (p *healthCheckProvider) HealthCheck(ctx context.Context) error {
+	if p.running != nil {
+		running := p.running.Add(1)
+		defer p.running.Add(-1)
+		for {
+			peak := p.peak.Load()
+			if running <= peak || p.peak.CompareAndSwap(peak, running) {
+				break
+			}
+		}
+	}
+	time.Sleep(p.delay)
+	return p.healthErr
+}
+
+func TestRouter_HealthCheckAll(t *testing.T) {
+	var running, peak atomic.Int32
+	healthy := &healthCheckProvider{mockProvider: &mockProvider{name: "healthy"}, delay: 50 * time.Millisecond, running: &running, peak: &peak}
+	unhealthy := &healthCheckProvider{mockProvider: &mockProvider{name: "unhealthy"}, healthErr: errors.New("invalid API key"), delay: 50 * time.Millisecond, running: &running, peak: &peak}
+	unchecked := &mockProvider{name: "unchecked"}
+
+	router, err := gollmrouter.NewRouter(healthy, unhealthy, unchecked)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	results := router.HealthCheckAll(context.Background())
+
+	if len(results) != 2 {
+		t.Fatalf("Expected results for the two checkable providers, got %v", results)
+	}
+	if err, ok := results["healthy"]; !ok || err != nil {
+		t.Errorf("Expected healthy provider to report nil, got %v (present %v)", err, ok)
+	}
+	if err := results["unhealthy"]; err == nil || err.Error() != "invalid API key" {
+		t.Errorf("Expected the configured health check error, got %v", err)
+	}
+	if _, ok := results["unchecked"]; ok {
+		t.Error("Expected providers without health checks to be left out")
+	}
+	if peak.Load() != 2 {
+		t.Errorf("Expected health checks to run concurrently, peak concurrency %d", peak.Load())
+	}
+}
+
+func TestRouter_HealthCheckAllDuplicateNames(t *testing.T) {
+	first := &healthCheckProvider{mockProvider: &mockProvider{name: "OpenRouter"}}
+	second := &healthCheckProvider{mockProvider: &mockProvider{name: "OpenRouter"}, healthErr: errors.New("down")}
+
+	router, err := gollmrouter.NewRouter(first, second)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	results := router.HealthCheckAll(context.Background())
+	if len(results) != 2 || results["OpenRouter"] != nil || results["OpenRouter (2)"] == nil {
+		t.Errorf("Expected both providers to be reported separately, got %v", results)
+	}
+}
+
+// warmupProvider is a mock provider that counts its warmups
+type warmupProvider struct {
+	*mockProvider
+	warmups atomic.Int32
+	err     error
+}
+
+func (p *warmupProvider) Warmup(ctx context.Context) error {
+	p.warmups.Add(1)
+	return p.err
+}
+
+func TestRouter_Warmup(t *testing.T) {
+	first := &warmupProvider{mockProvider: &mockProvider{name: "first"}}
+	second := &warmupProvider{mockProvider: &mockProvider{name: "second"}, err: errors.New("dial tcp: no such host")}
+	cold := &mockProvider{name: "cold"}
+
+	router, err := gollmrouter.NewRouter(first, second, cold)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	// Concurrent calls are safe; each warms up every provider
+	done := make(chan map[string]error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- router.Warmup(context.Background()) }()
+	}
+	results := <-done
+	<-done
+
+	if len(results) != 2 || results["first"] != nil || results["second"] == nil {
+		t.Errorf("Expected results for the two warmable providers, got %v", results)
+	}
+	if first.warmups.Load() != 2 || second.warmups.Load() != 2 {
+		t.Errorf("Expected a warmup per provider per call, got %d/%d", first.warmups.Load(), second.warmups.Load())
+	}
+	if first.callCount() != 0 || cold.callCount() != 0 {
+		t.Errorf("Expected warmups not to send queries")
+	}
+}
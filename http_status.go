@@ -0,0 +1,88 @@
+package gollmrouter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// HTTPStatus suggests the status code an HTTP handler should answer with when the router fails,
+// based on why the providers failed:
+//   - 429 when every provider was rate limited, by its own limits or by the API
+//   - 503 when every provider was rate limited, busy, closed or unavailable, or none was available
+//   - 504 when every provider timed out
+//   - 400 when every provider rejected the request itself (400 or 422, or a temperature out of range)
+//   - 502 otherwise, including when the providers rejected their credentials
+func (r *RouterError) HTTPStatus() int {
+	switch {
+	case len(r.Errors) == 0:
+		return http.StatusServiceUnavailable
+	case r.AllRateLimited():
+		return http.StatusTooManyRequests
+	case r.all(isUnavailableError):
+		return http.StatusServiceUnavailable
+	case r.all(isTimeoutError):
+		return http.StatusGatewayTimeout
+	case r.all(isInvalidRequestError):
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// AllRateLimited reports whether every provider failed or was skipped because of a rate limit:
+// its request limits or token budget, or a 429 from the API
+func (r *RouterError) AllRateLimited() bool {
+	return len(r.Errors) > 0 && r.all(isRateLimitError)
+}
+
+// all reports whether every provider error matches
+func (r *RouterError) all(match func(error) bool) bool {
+	for _, e := range r.Errors {
+		if !match(e.Error) {
+			return false
+		}
+	}
+	return true
+}
+
+// apiStatus returns the status code of an APIError in the chain, or 0 if there is none
+func apiStatus(err error) int {
+	var apiErr *provider.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// isRateLimitError reports whether the provider was held back by a rate limit
+func isRateLimitError(err error) bool {
+	return errors.Is(err, ErrRateLimitExceeded) || errors.Is(err, ErrTokenBudgetExceeded) ||
+		apiStatus(err) == http.StatusTooManyRequests
+}
+
+// isUnavailableError reports whether the provider was temporarily unable to take the request
+func isUnavailableError(err error) bool {
+	status := apiStatus(err)
+	return isRateLimitError(err) || errors.Is(err, ErrProviderBusy) || errors.Is(err, ErrProviderClosed) ||
+		status == http.StatusServiceUnavailable || status == 529 // 529 is Anthropic's "overloaded"
+}
+
+// isTimeoutError reports whether the provider's attempt timed out
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	status := apiStatus(err)
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) ||
+		status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout
+}
+
+// isInvalidRequestError reports whether the API, or the provider before sending it, rejected
+// the request itself
+func isInvalidRequestError(err error) bool {
+	status := apiStatus(err)
+	return status == http.StatusBadRequest || status == http.StatusUnprocessableEntity ||
+		errors.Is(err, ErrTemperatureOutOfRange)
+}
@@ -0,0 +1,78 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouterError_HTTPStatus(t *testing.T) {
+	rateLimited := fmt.Errorf("%w: no daily requests left", gollmrouter.ErrRateLimitExceeded)
+	tooManyRequests := provider.NewAPIError(http.StatusTooManyRequests, "slow down")
+	unauthorized := provider.NewAPIError(http.StatusUnauthorized, "bad key")
+	forbidden := provider.NewAPIError(http.StatusForbidden, "no access")
+	unavailable := provider.NewAPIError(http.StatusServiceUnavailable, "overloaded")
+	badRequest := provider.NewAPIError(http.StatusBadRequest, "invalid schema")
+	timeout := fmt.Errorf("request failed: %w", context.DeadlineExceeded)
+
+	tests := []struct {
+		name           string
+		errs           []error
+		status         int
+		allRateLimited bool
+	}{
+		{"no providers", nil, http.StatusServiceUnavailable, false},
+		{"all API rate limits", []error{tooManyRequests, tooManyRequests}, http.StatusTooManyRequests, true},
+		{"local and API rate limits", []error{rateLimited, tooManyRequests}, http.StatusTooManyRequests, true},
+		{"token budget", []error{fmt.Errorf("%w: too long", gollmrouter.ErrTokenBudgetExceeded)}, http.StatusTooManyRequests, true},
+		{"rate limited and unavailable", []error{rateLimited, unavailable}, http.StatusServiceUnavailable, false},
+		{"rate limited and busy", []error{tooManyRequests, gollmrouter.ErrProviderBusy}, http.StatusServiceUnavailable, false},
+		{"all auth failures", []error{unauthorized, forbidden}, http.StatusBadGateway, false},
+		{"rate limited and auth failure", []error{rateLimited, unauthorized}, http.StatusBadGateway, false},
+		{"all timeouts", []error{timeout, provider.NewAPIError(http.StatusGatewayTimeout, "")}, http.StatusGatewayTimeout, false},
+		{"invalid request", []error{badRequest}, http.StatusBadRequest, false},
+		{"temperature out of range", []error{badRequest, fmt.Errorf("%w: 5 is outside 0-2", gollmrouter.ErrTemperatureOutOfRange)}, http.StatusBadRequest, false},
+		{"invalid request and timeout", []error{badRequest, timeout}, http.StatusBadGateway, false},
+		{"server errors", []error{provider.NewAPIError(http.StatusInternalServerError, ""), errors.New("connection reset")}, http.StatusBadGateway, false},
+	}
+
+	for _, test := range tests {
+		routerErr := &gollmrouter.RouterError{}
+		for i, err := range test.errs {
+			routerErr.Errors = append(routerErr.Errors, gollmrouter.ProviderError{ProviderName: fmt.Sprintf("p%d", i), Error: err})
+		}
+		if status := routerErr.HTTPStatus(); status != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, status)
+		}
+		if routerErr.AllRateLimited() != test.allRateLimited {
+			t.Errorf("%s: expected AllRateLimited %v", test.name, test.allRateLimited)
+		}
+	}
+}
+
+func TestRouterError_HTTPStatusForExhaustedProviders(t *testing.T) {
+	primary := &mockProvider{name: "primary", rank: 2, exhausted: true}
+	backup := &mockProvider{name: "backup", rank: 1, exhausted: true}
+
+	router, err := gollmrouter.NewRouter(primary, backup)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok {
+		t.Fatalf("Expected a RouterError, got %v", err)
+	}
+	if !errors.Is(err, gollmrouter.ErrRateLimitExceeded) {
+		t.Errorf("Expected the skipped providers to report ErrRateLimitExceeded, got %v", err)
+	}
+	if !routerErr.AllRateLimited() || routerErr.HTTPStatus() != http.StatusTooManyRequests {
+		t.Errorf("Expected all providers rate limited and status 429, got %d", routerErr.HTTPStatus())
+	}
+}
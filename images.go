@@ -0,0 +1,99 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// ImageRequest asks for one or more images generated from a prompt
+type ImageRequest = provider.ImageRequest
+
+// ImageResult holds the generated images
+type ImageResult = provider.ImageResult
+
+// ImageGenerator is implemented by providers that can generate images
+type ImageGenerator = provider.ImageGenerator
+
+// GenerateImage generates images using the first available provider that supports image generation.
+// Providers are tried in the same order as for queries; providers without image support are skipped.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - request: The prompt and, optionally, the model, size and number of images
+//
+// Returns:
+//   - result: The generated images
+//   - error: A RouterError if every image provider failed
+func (r *Router) GenerateImage(ctx context.Context, request ImageRequest) (*ImageResult, error) {
+	ctx, span := r.startQuerySpan(ctx, "Router.GenerateImage")
+	defer span.End()
+
+	messages := []provider.Message{{Role: "user", Content: request.Prompt}}
+
+	var routerError RouterError
+	supported := 0
+
+	for i, p := range r.orderProviders(r.getProviders()) {
+		generator, ok := p.(provider.ImageGenerator)
+		if !ok || !generator.SupportsImageGeneration() {
+			continue
+		}
+		supported++
+
+		providerName := providerDisplayName(p, i)
+
+		if err := r.checkDeadline(ctx); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeSkipped, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		if err := checkRateLimits(ctx, p, messages); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeRateLimited, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		attemptCtx, cancel := attemptContext(ctx, p)
+		start := time.Now()
+		result, err := generator.GenerateImage(attemptCtx, request)
+		r.metrics.ObserveLatency(providerName, time.Since(start))
+		cancel()
+		if err != nil {
+			r.metrics.IncRequest(providerName, request.Model, OutcomeError)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			if r.isTerminal(err) {
+				break
+			}
+			continue
+		}
+
+		r.metrics.IncRequest(providerName, result.Model, OutcomeSuccess)
+		if result.Usage != nil {
+			r.metrics.IncTokens(providerName, result.Usage.TotalTokens)
+		}
+		span.SetAttributes(provider.Attr("provider.name", providerName), provider.Attr("llm.model", result.Model))
+		return result, nil
+	}
+
+	if supported == 0 {
+		err := fmt.Errorf("no configured provider supports image generation")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.RecordError(&routerError)
+	return nil, &routerError
+}
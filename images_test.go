@@ -0,0 +1,98 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// mockImageGenerator is a mockProvider that can also generate images
+type mockImageGenerator struct {
+	*mockProvider
+	imageErr error
+}
+
+func (m *mockImageGenerator) SupportsImageGeneration() bool {
+	return true
+}
+
+func (m *mockImageGenerator) GenerateImage(ctx context.Context, request provider.ImageRequest) (*provider.ImageResult, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+
+	if m.imageErr != nil {
+		return nil, m.imageErr
+	}
+	return &provider.ImageResult{
+		Images: []provider.File{{Type: "image", MimeType: "image/png", Data: []byte(m.name)}},
+		Model:  m.name + "-image",
+	}, nil
+}
+
+func TestRouter_GenerateImageFallback(t *testing.T) {
+	chatOnly := &mockProvider{name: "chat-only", rank: 3}
+	failing := &mockImageGenerator{mockProvider: &mockProvider{name: "failing", rank: 2}, imageErr: errors.New("boom")}
+	working := &mockImageGenerator{mockProvider: &mockProvider{name: "working", rank: 1}}
+
+	router, err := gollmrouter.NewRouter(chatOnly, failing, working)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.GenerateImage(context.Background(), gollmrouter.ImageRequest{Prompt: "a lighthouse"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Model != "working-image" || string(result.Images[0].Data) != "working" {
+		t.Errorf("Expected the working provider's image, got %+v", result)
+	}
+	if chatOnly.callCount() != 0 || failing.callCount() != 1 {
+		t.Error("Expected the chat-only provider to be skipped and the failing one to be tried once")
+	}
+}
+
+func TestRouter_GenerateImageUnsupported(t *testing.T) {
+	router, err := gollmrouter.NewRouter(&mockProvider{name: "chat-only"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.GenerateImage(context.Background(), gollmrouter.ImageRequest{Prompt: "a lighthouse"}); err == nil {
+		t.Error("Expected an error when no provider supports image generation")
+	}
+}
+
+func TestRouter_GenerateImageOpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"b64_json":"` + base64.StdEncoding.EncodeToString([]byte("png")) + `"}]}`))
+	}))
+	defer server.Close()
+
+	openAI, err := gollmrouter.NewOpenAIProvider(gollmrouter.OpenAIConfig{
+		APIKey:  "sk-test",
+		Models:  []string{"gpt-4o-mini"},
+		BaseURL: server.URL + "/v1",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(openAI)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.GenerateImage(context.Background(), gollmrouter.ImageRequest{Prompt: "a lighthouse"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Images) != 1 || string(result.Images[0].Data) != "png" {
+		t.Errorf("Expected the decoded image, got %+v", result.Images)
+	}
+}
@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// modelAttempts records the models a provider tries while serving one query, for
+// QueryResult.ModelAttempts
+type modelAttempts struct {
+	attempts []provider.ModelAttempt
+	start    time.Time
+}
+
+// next starts an attempt with model, ending the previous one with the error it failed with
+func (a *modelAttempts) next(model string, lastErr error) {
+	a.end(lastErr)
+	a.attempts = append(a.attempts, provider.ModelAttempt{Model: model})
+	a.start = time.Now()
+}
+
+// succeeded ends the current attempt as the one that served the query and returns all attempts
+func (a *modelAttempts) succeeded() []provider.ModelAttempt {
+	a.end(nil)
+	return a.attempts
+}
+
+// end records the outcome and duration of the current attempt
+func (a *modelAttempts) end(err error) {
+	if len(a.attempts) == 0 {
+		return
+	}
+	current := &a.attempts[len(a.attempts)-1]
+	current.Duration = time.Since(a.start)
+	if err != nil {
+		current.Error = err.Error()
+	}
+}
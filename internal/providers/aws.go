@@ -0,0 +1,228 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// AWSCredentials are the keys used to sign requests to AWS
+type AWSCredentials = provider.AWSCredentials
+
+// staticAWSCredentials always returns the same credentials
+type staticAWSCredentials AWSCredentials
+
+// Retrieve returns the credentials
+func (s staticAWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return AWSCredentials(s), nil
+}
+
+// awsCredentialChain resolves the environment and shared credentials file again on every call,
+// so credentials rotated by an external tool are picked up without a restart
+type awsCredentialChain struct {
+	profile string
+}
+
+// Retrieve resolves the current credentials
+func (c awsCredentialChain) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return resolveAWSCredentials(AWSCredentials{}, c.profile)
+}
+
+// resolveAWSCredentials follows a subset of the AWS credential chain: explicit credentials, then
+// the AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY environment variables, then the profile (AWS_PROFILE
+// or "default" when empty) in the shared credentials file. Instance and container roles, web
+// identity, SSO and credential_process are not supported; use an AWSCredentialsProvider for them.
+func resolveAWSCredentials(explicit AWSCredentials, profile string) (AWSCredentials, error) {
+	if explicit.AccessKeyID != "" {
+		if explicit.SecretAccessKey == "" {
+			return AWSCredentials{}, fmt.Errorf("AWS secret access key is required with access key id %s", explicit.AccessKeyID)
+		}
+		return explicit, nil
+	}
+
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return AWSCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, fmt.Errorf("no AWS credentials found: %w", err)
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	credentials, err := readSharedCredentials(path, profile)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("no AWS credentials found in the config, the environment or %s: %w", path, err)
+	}
+	return credentials, nil
+}
+
+// readSharedCredentials reads a profile from an AWS shared credentials file
+func readSharedCredentials(path string, profile string) (AWSCredentials, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	defer file.Close()
+
+	var credentials AWSCredentials
+	inProfile := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inProfile = strings.TrimSpace(line[1:len(line)-1]) == profile
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			credentials.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			credentials.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			credentials.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return AWSCredentials{}, err
+	}
+
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("profile %q has no access keys", profile)
+	}
+	return credentials, nil
+}
+
+// signAWSRequest adds AWS Signature Version 4 headers to a request. Every header in headers is
+// signed along with the host, so they must not change after signing.
+func signAWSRequest(method string, rawURL string, headers map[string]string, body []byte, credentials AWSCredentials, region, service string, now time.Time) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	headers["X-Amz-Date"] = amzDate
+	if credentials.SessionToken != "" {
+		headers["X-Amz-Security-Token"] = credentials.SessionToken
+	}
+
+	canonicalHeaders := map[string]string{"host": u.Host}
+	for name, value := range headers {
+		canonicalHeaders[strings.ToLower(name)] = strings.Join(strings.Fields(value), " ")
+	}
+	names := make([]string, 0, len(canonicalHeaders))
+	for name := range canonicalHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headerLines strings.Builder
+	for _, name := range names {
+		headerLines.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(u),
+		headerLines.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	headers["Authorization"] = fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature)
+	return nil
+}
+
+// canonicalURI encodes each segment of the already escaped request path again, as SigV4
+// requires for every service except S3
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts and encodes the query parameters
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes everything except the unreserved characters
+func awsURIEncode(s string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
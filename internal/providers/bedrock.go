@@ -0,0 +1,572 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// BedrockProvider implements the Provider interface for AWS Bedrock through the Converse API
+type BedrockProvider struct {
+	region         string
+	endpoint       string
+	credentials    provider.AWSCredentialsProvider
+	timeout        time.Duration
+	maxResponse    int64 // Response body size limit
+	client         httpclient.Client
+	models         []string
+	rank           int
+	weight         int
+	tokenEstimator provider.TokenEstimator
+	contextWindow  int
+	limiter        *rateLimiter
+	modelLimits    modelRateLimiters // Limiters of the models with their own ModelLimits
+	debug          *debugRecorder    // nil unless debug mode is enabled
+	lifecycle      *lifecycle
+	selector       modelSelector
+	toolsDisabled  bool
+	images         *imageResizer       // nil unless AutoResizeImages is set
+	includeRaw     bool                // Sets QueryResult.Raw on responses
+	clampTemp      bool                // Clamps out-of-range temperatures instead of failing
+	concurrency    *concurrencyLimiter // nil unless MaxConcurrent is set
+}
+
+var _ provider.Provider = (*BedrockProvider)(nil)
+var _ provider.TokenEstimator = (*BedrockProvider)(nil)
+var _ provider.Weighted = (*BedrockProvider)(nil)
+var _ provider.ToolCapable = (*BedrockProvider)(nil)
+var _ provider.TimeoutAware = (*BedrockProvider)(nil)
+var _ provider.DebugRecorder = (*BedrockProvider)(nil)
+var _ provider.Warmer = (*BedrockProvider)(nil)
+
+// bedrockImageFormats maps image MIME types to Converse image formats
+var bedrockImageFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// bedrockDocumentFormats maps document MIME types to Converse document formats
+var bedrockDocumentFormats = map[string]string{
+	"application/pdf":          "pdf",
+	"text/csv":                 "csv",
+	"text/html":                "html",
+	"text/plain":               "txt",
+	"text/markdown":            "md",
+	"application/msword":       "doc",
+	"application/vnd.ms-excel": "xls",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "docx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       "xlsx",
+}
+
+// newBedrockProvider creates a new Bedrock provider. The region falls back to AWS_REGION and
+// AWS_DEFAULT_REGION, and endpoint, when empty, to the bedrock-runtime endpoint of the region.
+// Requests are signed with credentialsProvider when it is set, otherwise with the explicit
+// credentials or the environment and shared credentials file, resolved again for every request.
+func newBedrockProvider(config provider.Config, region string, endpoint string, credentials AWSCredentials, profile string, credentialsProvider provider.AWSCredentialsProvider) (provider.Provider, error) {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is required for Bedrock")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+
+	if credentialsProvider == nil {
+		// Resolve once so missing credentials fail here rather than on the first request
+		resolved, err := resolveAWSCredentials(credentials, profile)
+		if err != nil {
+			return nil, err
+		}
+		// The access key id identifies the account's quota in the rate store
+		config.APIKey = resolved.AccessKeyID
+
+		credentialsProvider = awsCredentialChain{profile: profile}
+		if credentials.AccessKeyID != "" {
+			credentialsProvider = staticAWSCredentials(resolved)
+		}
+	}
+
+	limiter, err := newConfiguredRateLimiter(config, "Bedrock", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	modelLimits, err := newModelRateLimiters(config, "Bedrock", endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = httpclient.New("go-llm-router/1.0")
+	}
+
+	debug := newDebugRecorder(config, "Bedrock")
+	return &BedrockProvider{
+		region:         region,
+		endpoint:       strings.TrimSuffix(endpoint, "/"),
+		credentials:    credentialsProvider,
+		timeout:        config.Timeout,
+		maxResponse:    maxResponseBytesOrDefault(config.MaxResponseBytes),
+		client:         debug.wrap(client),
+		models:         config.Models,
+		rank:           config.Rank,
+		weight:         config.Weight,
+		tokenEstimator: tokenEstimatorOrDefault(config.TokenEstimator),
+		contextWindow:  config.MaxContextTokens,
+		limiter:        limiter,
+		modelLimits:    modelLimits,
+		debug:          debug,
+		lifecycle:      newLifecycle(),
+		selector:       modelSelector{strategy: config.ModelStrategy},
+		toolsDisabled:  config.DisableTools,
+		images:         newImageResizer(config),
+		includeRaw:     config.IncludeRawResponse,
+		clampTemp:      config.ClampTemperature,
+		concurrency:    newConcurrencyLimiter(config),
+	}, nil
+}
+
+// EstimateTokens estimates the tokens in the messages using the provider's token estimator
+func (b *BedrockProvider) EstimateTokens(messages []provider.Message) int {
+	return b.tokenEstimator.EstimateTokens(messages)
+}
+
+// Query sends a prompt to Bedrock and returns the response (legacy method)
+func (b *BedrockProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
+	options := provider.QueryOptions{
+		Temperature:    temperature,
+		TemperatureSet: true,
+		ForceModel:     forceModel,
+	}
+
+	result, err := b.QueryWithOptions(ctx, messages, options)
+	if err != nil {
+		return "", "", err
+	}
+
+	return result.Content, result.Model, nil
+}
+
+// QueryWithOptions sends a prompt to Bedrock's Converse API with advanced options including tool calls
+func (b *BedrockProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	ctx, done, err := b.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := b.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, cancel, timeout := withQueryTimeout(ctx, b.timeout, options)
+	defer cancel()
+
+	messages = withSystemPrompt(messages, options.SystemPrompt)
+	messages = provider.TrimToContextWindowWithEstimator(b.tokenEstimator, messages, b.contextWindow, true)
+	messages, err = b.images.apply(messages)
+	if err != nil {
+		return nil, err
+	}
+	options, err = converseTemperatureRange.apply(options, b.clampTemp)
+	if err != nil {
+		return nil, err
+	}
+	options.Tools = mergeTools(options.Tools)
+
+	requestBody, err := buildConverseRequest(messages, options)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	modelsToUse := b.selector.order(b.models, options.ForceModel)
+	if len(modelsToUse) == 0 {
+		return nil, noModelsError(b.Name())
+	}
+
+	var outerErr error
+	var attempts modelAttempts
+	for _, model := range modelsToUse {
+		attempts.next(model, outerErr)
+
+		// Models with their own limits are skipped once they have used them up
+		if err := b.modelLimits.checkModel(model, b.limiter, b, messages); err != nil {
+			outerErr = err
+			continue
+		}
+
+		url := b.endpoint + "/model/" + awsURIEncode(model) + "/converse"
+		headers := map[string]string{
+			"Content-Type": "application/json",
+			"Accept":       "application/json",
+		}
+		credentials, err := b.credentials.Retrieve(ctx)
+		if err != nil {
+			outerErr = fmt.Errorf("failed to get AWS credentials: %w", err)
+			continue
+		}
+		if err := signAWSRequest("POST", url, headers, jsonData, credentials, b.region, "bedrock", time.Now()); err != nil {
+			outerErr = fmt.Errorf("failed to sign request: %w", err)
+			continue
+		}
+
+		resp, _, err := b.client.Do(ctx, url, "POST", headers, bytes.NewReader(jsonData), timeout)
+		if err != nil {
+			outerErr = fmt.Errorf("failed to create request: %w", err)
+			continue
+		}
+
+		body, err := readResponse(resp.Body, b.maxResponse)
+		resp.Body.Close()
+		if err != nil {
+			outerErr = err
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			outerErr = provider.NewAPIError(resp.StatusCode, string(body))
+			continue
+		}
+
+		// Update rate limiting counters
+		limiter := b.modelLimits.forModel(model, b.limiter)
+		limiter.recordRequest()
+		limiter.recordTokens(b.EstimateTokens(messages))
+
+		result, err := parseConverseResponse(body)
+		if err != nil {
+			outerErr = err
+			continue
+		}
+		result.Model = model
+		if b.includeRaw {
+			result.Raw = body
+		}
+		result.ModelAttempts = attempts.succeeded()
+		return result, nil
+	}
+
+	return nil, outerErr
+}
+
+// converseCachePoint returns the block that marks the end of a cacheable prompt prefix, for
+// models that support prompt caching
+func converseCachePoint() map[string]interface{} {
+	return map[string]interface{}{"cachePoint": map[string]interface{}{"type": "default"}}
+}
+
+// buildConverseRequest converts messages and options to a Converse request. System messages are
+// sent as the system prompt, and consecutive messages with the same role are merged since
+// Converse requires alternating user and assistant turns.
+func buildConverseRequest(messages []provider.Message, options provider.QueryOptions) (map[string]interface{}, error) {
+	var system []map[string]interface{}
+	var conversation []map[string]interface{}
+
+	for _, message := range messages {
+		if message.Role == "system" {
+			if message.Content != "" {
+				system = append(system, map[string]interface{}{"text": message.Content})
+			}
+			if message.Cacheable && len(system) > 0 {
+				system = append(system, converseCachePoint())
+			}
+			continue
+		}
+
+		// Converse only has user and assistant turns; tool results and other roles are sent as user input
+		role := "user"
+		if message.Role == "assistant" {
+			role = "assistant"
+		}
+
+		var content []map[string]interface{}
+		if message.ToolCallID != "" {
+			// A tool result from the history answers the toolUse block with the same id
+			content = append(content, map[string]interface{}{"toolResult": map[string]interface{}{
+				"toolUseId": message.ToolCallID,
+				"content":   []map[string]interface{}{{"text": message.Content}},
+			}})
+		} else if message.Content != "" {
+			content = append(content, map[string]interface{}{"text": message.Content})
+		}
+		for _, toolCall := range message.ToolCalls {
+			input := toolCall.Function.Arguments
+			if input == nil {
+				input = map[string]interface{}{}
+			}
+			content = append(content, map[string]interface{}{"toolUse": map[string]interface{}{
+				"toolUseId": toolCall.ID,
+				"name":      toolCall.Function.Name,
+				"input":     input,
+			}})
+		}
+		for _, file := range message.Files {
+			block, err := converseFileBlock(file)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, block)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		if message.Cacheable {
+			content = append(content, converseCachePoint())
+		}
+
+		if last := len(conversation) - 1; last >= 0 && conversation[last]["role"] == role {
+			conversation[last]["content"] = append(conversation[last]["content"].([]map[string]interface{}), content...)
+			continue
+		}
+		conversation = append(conversation, map[string]interface{}{
+			"role":    role,
+			"content": content,
+		})
+	}
+
+	requestBody := map[string]interface{}{
+		"messages": conversation,
+	}
+	if len(system) > 0 {
+		requestBody["system"] = system
+	}
+	if options.HasTemperature() {
+		requestBody["inferenceConfig"] = map[string]interface{}{"temperature": options.Temperature}
+	}
+
+	// Converse has no way to disable tools for a request, so "none" leaves them out
+	if len(options.Tools) > 0 && options.ToolChoice != "none" {
+		tools := make([]map[string]interface{}, 0, len(options.Tools))
+		for _, tool := range options.Tools {
+			schema := tool.Function.Parameters
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+			}
+			tools = append(tools, map[string]interface{}{
+				"toolSpec": map[string]interface{}{
+					"name":        tool.Function.Name,
+					"description": tool.Function.Description,
+					"inputSchema": map[string]interface{}{"json": schema},
+				},
+			})
+		}
+
+		toolConfig := map[string]interface{}{"tools": tools}
+		switch options.ToolChoice {
+		case "", "auto":
+			toolConfig["toolChoice"] = map[string]interface{}{"auto": map[string]interface{}{}}
+		case "required", "any":
+			toolConfig["toolChoice"] = map[string]interface{}{"any": map[string]interface{}{}}
+		default:
+			toolConfig["toolChoice"] = map[string]interface{}{"tool": map[string]interface{}{"name": options.ToolChoice}}
+		}
+		requestBody["toolConfig"] = toolConfig
+	}
+
+	return requestBody, nil
+}
+
+// converseFileBlock converts a file attachment to an image or document content block
+func converseFileBlock(file provider.File) (map[string]interface{}, error) {
+	if len(file.Data) == 0 {
+		return nil, fmt.Errorf("file %s has no data; Bedrock requires attachments to be sent inline", file.Name)
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(strings.Split(file.MimeType, ";")[0]))
+	if format, ok := bedrockImageFormats[mimeType]; ok {
+		return map[string]interface{}{
+			"image": map[string]interface{}{
+				"format": format,
+				"source": map[string]interface{}{"bytes": file.Data},
+			},
+		}, nil
+	}
+	if format, ok := bedrockDocumentFormats[mimeType]; ok {
+		name := file.Name
+		if name == "" {
+			name = "document"
+		}
+		return map[string]interface{}{
+			"document": map[string]interface{}{
+				"format": format,
+				"name":   name,
+				"source": map[string]interface{}{"bytes": file.Data},
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported file type for Bedrock: %s (%s)", file.Name, file.MimeType)
+}
+
+// parseConverseResponse converts a Converse response to a query result
+func parseConverseResponse(body []byte) (*provider.QueryResult, error) {
+	var response struct {
+		Output struct {
+			Message struct {
+				Content []struct {
+					Text             string `json:"text"`
+					ReasoningContent *struct {
+						ReasoningText struct {
+							Text string `json:"text"`
+						} `json:"reasoningText"`
+					} `json:"reasoningContent"`
+					ToolUse *struct {
+						ToolUseID string                 `json:"toolUseId"`
+						Name      string                 `json:"name"`
+						Input     map[string]interface{} `json:"input"`
+					} `json:"toolUse"`
+				} `json:"content"`
+			} `json:"message"`
+		} `json:"output"`
+		StopReason string `json:"stopReason"`
+		Usage      *struct {
+			InputTokens  int `json:"inputTokens"`
+			OutputTokens int `json:"outputTokens"`
+			TotalTokens  int `json:"totalTokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	result := &provider.QueryResult{FinishReason: bedrockFinishReason(response.StopReason)}
+	for _, block := range response.Output.Message.Content {
+		result.Content += block.Text
+		if block.ReasoningContent != nil {
+			result.Reasoning += block.ReasoningContent.ReasoningText.Text
+		}
+		if block.ToolUse != nil {
+			result.ToolCalls = append(result.ToolCalls, provider.ToolCall{
+				ID:   block.ToolUse.ToolUseID,
+				Type: "function",
+				Function: provider.ToolCallFunction{
+					Name:      block.ToolUse.Name,
+					Arguments: block.ToolUse.Input,
+				},
+			})
+		}
+	}
+	if response.Usage != nil {
+		result.Usage = &provider.Usage{
+			PromptTokens:     response.Usage.InputTokens,
+			CompletionTokens: response.Usage.OutputTokens,
+			TotalTokens:      response.Usage.TotalTokens,
+		}
+	}
+	return result, nil
+}
+
+// bedrockFinishReason maps Converse stop reasons to the OpenAI-style finish reasons used elsewhere
+func bedrockFinishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return "stop"
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "content_filtered", "guardrail_intervened":
+		return "content_filter"
+	default:
+		return stopReason
+	}
+}
+
+// Warmup opens a connection to the Bedrock runtime endpoint ahead of the first query. It doesn't
+// count against the rate limits.
+func (b *BedrockProvider) Warmup(ctx context.Context) error {
+	ctx, done, err := b.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return warmConnection(ctx, b.client, b.endpoint, b.timeout)
+}
+
+// Close closes the Bedrock provider and saves its final rate-limit counters to the RateStore, if any.
+// Later calls do nothing.
+func (b *BedrockProvider) Close() {
+	if b.lifecycle.close() {
+		b.limiter.flush()
+		b.modelLimits.flush()
+	}
+}
+
+// HasRemainingRequests checks if the provider has remaining requests
+func (b *BedrockProvider) HasRemainingRequests(ctx context.Context) bool {
+	return b.modelLimits.anyRemaining(b.limiter, (*rateLimiter).hasRemainingRequests)
+}
+
+// HasRemainingRequestsPerMinute checks if the provider has remaining requests per minute
+func (b *BedrockProvider) HasRemainingRequestsPerMinute(ctx context.Context) bool {
+	return b.modelLimits.anyRemaining(b.limiter, (*rateLimiter).hasRemainingRequestsPerMinute)
+}
+
+// HasRemainingTokensPerMinute checks if the provider has remaining tokens per minute
+func (b *BedrockProvider) HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool {
+	return b.modelLimits.anyRemaining(b.limiter, func(l *rateLimiter) bool {
+		return l.hasRemainingTokensPerMinute(estimatedTokens)
+	})
+}
+
+// LastRawExchange returns the last raw request and response, or nil unless debug mode is enabled
+func (b *BedrockProvider) LastRawExchange() *provider.RawExchange {
+	return b.debug.lastExchange()
+}
+
+// Models returns the configured model ids
+func (b *BedrockProvider) Models() []string {
+	return append([]string(nil), b.models...)
+}
+
+// ResetLimits clears the provider's rate-limit counters
+func (b *BedrockProvider) ResetLimits() {
+	b.limiter.reset()
+	b.modelLimits.reset()
+}
+
+// GetRank returns the provider's rank
+func (b *BedrockProvider) GetRank() int {
+	return b.rank
+}
+
+// GetWeight returns the provider's load-balancing weight
+func (b *BedrockProvider) GetWeight() int {
+	return b.weight
+}
+
+// SupportsTools reports whether tool calls are enabled for the provider
+func (b *BedrockProvider) SupportsTools() bool {
+	return !b.toolsDisabled
+}
+
+// GetTimeout returns the provider's per-request timeout, 0 if none is configured
+func (b *BedrockProvider) GetTimeout() time.Duration {
+	return b.timeout
+}
+
+// Name returns the name of the provider
+func (b *BedrockProvider) Name() string {
+	return "Bedrock"
+}
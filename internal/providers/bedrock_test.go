@@ -0,0 +1,288 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestSignAWSRequest_TestSuiteVector(t *testing.T) {
+	// "get-vanilla" from the AWS Signature Version 4 test suite
+	headers := map[string]string{}
+	credentials := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	if err := signAWSRequest("GET", "https://example.amazonaws.com/", headers, nil, credentials, "us-east-1", "service", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if headers["Authorization"] != expected {
+		t.Errorf("Expected authorization\n%s\ngot\n%s", expected, headers["Authorization"])
+	}
+	if headers["X-Amz-Date"] != "20150830T123600Z" {
+		t.Errorf("Expected X-Amz-Date header, got %q", headers["X-Amz-Date"])
+	}
+}
+
+func TestBedrockProvider_Converse(t *testing.T) {
+	var path, authorization string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		authorization = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &request)
+
+		w.Write([]byte(`{
+			"output": {"message": {"role": "assistant", "content": [
+				{"text": "Let me check."},
+				{"toolUse": {"toolUseId": "tooluse_1", "name": "get_weather", "input": {"city": "Paris"}}}
+			]}},
+			"stopReason": "tool_use",
+			"usage": {"inputTokens": 12, "outputTokens": 8, "totalTokens": 20}
+		}`))
+	}))
+	defer server.Close()
+
+	p, err := newBedrockProvider(provider.Config{
+		Models: []string{"anthropic.claude-3-haiku-20240307-v1:0"},
+	}, "us-east-1", server.URL, AWSCredentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Hi"},
+		{Role: "user", Content: "What's the weather in Paris?"},
+	}
+	result, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{
+		Tools: []provider.Tool{{Type: "function", Function: provider.ToolFunction{
+			Name:        "get_weather",
+			Description: "Current weather",
+			Parameters:  map[string]interface{}{"type": "object", "properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}}},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != "/model/anthropic.claude-3-haiku-20240307-v1%3A0/converse" {
+		t.Errorf("Unexpected request path %q", path)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(authorization, "/us-east-1/bedrock/aws4_request") {
+		t.Errorf("Expected a SigV4 authorization header, got %q", authorization)
+	}
+
+	system := request["system"].([]interface{})
+	if len(system) != 1 || system[0].(map[string]interface{})["text"] != "Be brief." {
+		t.Errorf("Expected the system message in the system field, got %v", request["system"])
+	}
+	conversation := request["messages"].([]interface{})
+	if len(conversation) != 1 || len(conversation[0].(map[string]interface{})["content"].([]interface{})) != 2 {
+		t.Errorf("Expected consecutive user messages to be merged into one turn, got %v", conversation)
+	}
+	toolConfig := request["toolConfig"].(map[string]interface{})
+	spec := toolConfig["tools"].([]interface{})[0].(map[string]interface{})["toolSpec"].(map[string]interface{})
+	if spec["name"] != "get_weather" || spec["inputSchema"].(map[string]interface{})["json"] == nil {
+		t.Errorf("Expected the tool to be sent as a toolSpec, got %v", spec)
+	}
+
+	if result.Content != "Let me check." || result.Model != "anthropic.claude-3-haiku-20240307-v1:0" || result.FinishReason != "tool_calls" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].ID != "tooluse_1" || result.ToolCalls[0].Function.Arguments["city"] != "Paris" {
+		t.Errorf("Expected the tool use to be returned as a tool call, got %+v", result.ToolCalls)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 20 {
+		t.Errorf("Expected usage to be parsed, got %+v", result.Usage)
+	}
+}
+
+func TestBedrockProvider_Close(t *testing.T) {
+	p, err := newBedrockProvider(provider.Config{
+		Models: []string{"anthropic.claude-3-haiku-20240307-v1:0"},
+	}, "us-east-1", "http://127.0.0.1:0", AWSCredentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	p.Close()
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, ""); !errors.Is(err, provider.ErrProviderClosed) {
+		t.Errorf("Expected ErrProviderClosed after Close, got %v", err)
+	}
+}
+
+func TestBuildConverseRequest_ToolCallHistory(t *testing.T) {
+	request, err := buildConverseRequest(toolCallHistory(), provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	conversation := request["messages"].([]map[string]interface{})
+	if len(conversation) != 3 {
+		t.Fatalf("Expected user, assistant and user turns, got %v", conversation)
+	}
+
+	toolUse := conversation[1]["content"].([]map[string]interface{})[0]["toolUse"].(map[string]interface{})
+	if conversation[1]["role"] != "assistant" || toolUse["toolUseId"] != "call_1" || toolUse["name"] != "get_weather" {
+		t.Errorf("Expected the assistant's tool call as a toolUse block, got %v", conversation[1])
+	}
+
+	toolResult := conversation[2]["content"].([]map[string]interface{})[0]["toolResult"].(map[string]interface{})
+	if conversation[2]["role"] != "user" || toolResult["toolUseId"] != "call_1" {
+		t.Errorf("Expected the tool result as a toolResult block, got %v", conversation[2])
+	}
+}
+
+func TestBuildConverseRequest_CachePoints(t *testing.T) {
+	messages := []provider.Message{
+		{Role: "system", Content: "A long, reusable system prompt.", Cacheable: true},
+		{Role: "user", Content: "Here is a long document.", Cacheable: true},
+		{Role: "user", Content: "Summarize it."},
+	}
+	request, err := buildConverseRequest(messages, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	system, _ := json.Marshal(request["system"])
+	if string(system) != `[{"text":"A long, reusable system prompt."},{"cachePoint":{"type":"default"}}]` {
+		t.Errorf("Expected a cache point after the system prompt, got %s", system)
+	}
+	conversation, _ := json.Marshal(request["messages"])
+	expected := `[{"content":[{"text":"Here is a long document."},{"cachePoint":{"type":"default"}},{"text":"Summarize it."}],"role":"user"}]`
+	if string(conversation) != expected {
+		t.Errorf("Expected a cache point after the cacheable message\n%s\nexpected\n%s", conversation, expected)
+	}
+}
+
+func TestResolveAWSCredentials_SharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	os.WriteFile(path, []byte(`[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+[work]
+aws_access_key_id = AKIDWORK
+aws_secret_access_key = work-secret
+aws_session_token = work-token
+`), 0o600)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+
+	credentials, err := resolveAWSCredentials(AWSCredentials{}, "work")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if credentials.AccessKeyID != "AKIDWORK" || credentials.SessionToken != "work-token" {
+		t.Errorf("Expected the named profile, got %+v", credentials)
+	}
+
+	credentials, err = resolveAWSCredentials(AWSCredentials{}, "")
+	if err != nil || credentials.AccessKeyID != "AKIDDEFAULT" {
+		t.Errorf("Expected the default profile, got %+v (%v)", credentials, err)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	if credentials, _ := resolveAWSCredentials(AWSCredentials{}, "work"); credentials.AccessKeyID != "AKIDENV" {
+		t.Errorf("Expected environment credentials to take precedence over the file, got %+v", credentials)
+	}
+}
+
+// rotatingCredentials returns new temporary credentials on every call
+type rotatingCredentials struct {
+	calls int
+}
+
+func (r *rotatingCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	r.calls++
+	return AWSCredentials{
+		AccessKeyID:     fmt.Sprintf("ASIATEMP%d", r.calls),
+		SecretAccessKey: "secret",
+		SessionToken:    fmt.Sprintf("token-%d", r.calls),
+	}, nil
+}
+
+// newConverseServer answers every Converse request and records the request headers
+func newConverseServer(t *testing.T) (*httptest.Server, *[]http.Header) {
+	t.Helper()
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.Write([]byte(`{"output": {"message": {"role": "assistant", "content": [{"text": "ok"}]}}, "stopReason": "end_turn"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &headers
+}
+
+func TestBedrockProvider_CredentialsProvider(t *testing.T) {
+	server, headers := newConverseServer(t)
+	credentials := &rotatingCredentials{}
+	p, err := newBedrockProvider(provider.Config{Models: []string{"test-model"}}, "us-east-1", server.URL, AWSCredentials{}, "", credentials)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for i := 0; i < 2; i++ {
+		if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Each request is signed with the credentials current at the time
+	for i, header := range *headers {
+		if !strings.Contains(header.Get("Authorization"), fmt.Sprintf("Credential=ASIATEMP%d/", i+1)) || header.Get("X-Amz-Security-Token") != fmt.Sprintf("token-%d", i+1) {
+			t.Errorf("Request %d: expected the refreshed credentials, got %q with token %q", i+1, header.Get("Authorization"), header.Get("X-Amz-Security-Token"))
+		}
+	}
+}
+
+func TestBedrockProvider_RereadsSharedCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	writeCredentials := func(id string) {
+		os.WriteFile(path, []byte("[default]\naws_access_key_id = "+id+"\naws_secret_access_key = secret\naws_session_token = token\n"), 0o600)
+	}
+	writeCredentials("ASIAFIRST")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+
+	server, headers := newConverseServer(t)
+	p, err := newBedrockProvider(provider.Config{Models: []string{"test-model"}}, "us-east-1", server.URL, AWSCredentials{}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// An external tool rotates the temporary credentials
+	writeCredentials("ASIASECOND")
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(*headers) != 2 || !strings.Contains((*headers)[1].Get("Authorization"), "Credential=ASIASECOND/") {
+		t.Errorf("Expected the second request to use the rotated credentials, got %v", *headers)
+	}
+}
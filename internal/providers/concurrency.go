@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"context"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// concurrencyLimiter bounds the number of requests in flight to a provider. A nil limiter
+// doesn't limit anything.
+type concurrencyLimiter struct {
+	slots    chan struct{}
+	failFast bool
+}
+
+// newConcurrencyLimiter returns the limiter described by the config, or nil unless MaxConcurrent is set
+func newConcurrencyLimiter(config provider.Config) *concurrencyLimiter {
+	if config.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &concurrencyLimiter{
+		slots:    make(chan struct{}, config.MaxConcurrent),
+		failFast: config.FailWhenBusy,
+	}
+}
+
+// acquire waits for a free slot and returns the function that releases it. It returns ctx's
+// error if ctx is done first, or provider.ErrProviderBusy at once when the limiter fails fast.
+func (c *concurrencyLimiter) acquire(ctx context.Context) (func(), error) {
+	if c == nil {
+		return func() {}, nil
+	}
+
+	release := func() { <-c.slots }
+	select {
+	case c.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if c.failFast {
+		return nil, provider.ErrProviderBusy
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
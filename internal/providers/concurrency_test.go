@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// slowHTTPClient answers every request after a delay, or once release is closed when it is set,
+// tracking the peak number of requests in flight
+type slowHTTPClient struct {
+	delay    time.Duration
+	release  chan struct{}
+	started  chan struct{}
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *slowHTTPClient) Do(ctx context.Context, url string, method string, headers map[string]string, body io.Reader, timeout time.Duration) (*http.Response, string, error) {
+	running := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if running <= peak || c.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	if c.started != nil {
+		c.started <- struct{}{}
+	}
+
+	if c.release != nil {
+		<-c.release
+	} else {
+		time.Sleep(c.delay)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`)),
+	}, url, nil
+}
+
+func newConcurrencyTestProvider(t *testing.T, client *slowHTTPClient, failWhenBusy bool) provider.Provider {
+	t.Helper()
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:        "sk-test",
+		Models:        []string{"gpt-4o-mini"},
+		HTTPClient:    client,
+		MaxConcurrent: 2,
+		FailWhenBusy:  failWhenBusy,
+	}, "", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return p
+}
+
+func TestConcurrencyLimiter_BoundsRequestsInFlight(t *testing.T) {
+	client := &slowHTTPClient{delay: 20 * time.Millisecond}
+	p := newConcurrencyTestProvider(t, client, false)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected waiting requests to succeed, got %v", err)
+		}
+	}
+	if peak := client.peak.Load(); peak != 2 {
+		t.Errorf("Expected at most 2 requests in flight (and the limit reached), got %d", peak)
+	}
+}
+
+func TestConcurrencyLimiter_WhenBusy(t *testing.T) {
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for _, failWhenBusy := range []bool{true, false} {
+		client := &slowHTTPClient{release: make(chan struct{}), started: make(chan struct{}, 2)}
+		p := newConcurrencyTestProvider(t, client, failWhenBusy)
+
+		// Fill both slots
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+			}()
+			<-client.started
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := p.QueryWithOptions(ctx, messages, provider.QueryOptions{})
+		cancel()
+		if failWhenBusy && !errors.Is(err, provider.ErrProviderBusy) {
+			t.Errorf("Expected ErrProviderBusy with FailWhenBusy, got %v", err)
+		}
+		if !failWhenBusy && !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the waiting request to end with its context, got %v", err)
+		}
+
+		close(client.release)
+		wg.Wait()
+		if peak := client.peak.Load(); peak != 2 {
+			t.Errorf("Expected the third request never to be sent, got %d in flight", peak)
+		}
+	}
+}
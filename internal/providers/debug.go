@@ -0,0 +1,191 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// redactedHeaders are the credential headers replaced in debug output
+var redactedHeaders = map[string]bool{
+	"authorization":        true,
+	"x-api-key":            true,
+	"x-goog-api-key":       true,
+	"x-amz-security-token": true,
+}
+
+// debugRecorder logs the raw requests and responses of a provider in debug mode and keeps the last one
+type debugRecorder struct {
+	name string
+
+	mu   sync.Mutex
+	last *provider.RawExchange
+}
+
+// newDebugRecorder returns a recorder if debug mode is enabled and nil otherwise
+func newDebugRecorder(config provider.Config, name string) *debugRecorder {
+	if !config.Debug {
+		return nil
+	}
+	return &debugRecorder{name: name}
+}
+
+// logf logs a debug message; it does nothing unless debug mode is enabled
+func (d *debugRecorder) logf(format string, args ...interface{}) {
+	if d == nil {
+		return
+	}
+	log.Printf("[%s] debug %s", d.name, fmt.Sprintf(format, args...))
+}
+
+func (d *debugRecorder) record(exchange provider.RawExchange) {
+	log.Printf("[%s] debug %s %s headers=%v request=%s", d.name, exchange.Method, exchange.URL, exchange.RequestHeaders, exchange.RequestBody)
+	if exchange.Error != "" {
+		log.Printf("[%s] debug error=%s", d.name, exchange.Error)
+	} else {
+		log.Printf("[%s] debug status=%d response=%s", d.name, exchange.StatusCode, exchange.ResponseBody)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.last = &exchange
+}
+
+// lastExchange returns a copy of the last exchange; it is safe to call on a nil recorder
+func (d *debugRecorder) lastExchange() *provider.RawExchange {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.last == nil {
+		return nil
+	}
+	exchange := *d.last
+	return &exchange
+}
+
+// wrap returns a client that records its exchanges, or client itself if d is nil
+func (d *debugRecorder) wrap(client httpclient.Client) httpclient.Client {
+	if d == nil {
+		return client
+	}
+	return &debugClient{client: client, recorder: d}
+}
+
+// debugClient records the requests made through an httpclient.Client
+type debugClient struct {
+	client   httpclient.Client
+	recorder *debugRecorder
+}
+
+func (c *debugClient) Do(ctx context.Context, url string, method string, headers map[string]string, body io.Reader, timeout time.Duration) (*http.Response, string, error) {
+	var requestBody []byte
+	if body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(body); err != nil {
+			return nil, url, err
+		}
+		body = bytes.NewReader(requestBody)
+	}
+
+	exchange := provider.RawExchange{
+		Method:         method,
+		URL:            url,
+		RequestHeaders: redactHeaders(headers),
+		RequestBody:    string(requestBody),
+	}
+
+	resp, finalURL, err := c.client.Do(ctx, url, method, headers, body, timeout)
+	if err != nil {
+		exchange.Error = err.Error()
+	} else {
+		exchange.StatusCode = resp.StatusCode
+		exchange.ResponseBody = readResponseBody(resp)
+	}
+	c.recorder.record(exchange)
+	return resp, finalURL, err
+}
+
+// debugTransport records the requests made through an http.Client, for SDK-based providers
+type debugTransport struct {
+	base     http.RoundTripper
+	recorder *debugRecorder
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for name, values := range req.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	exchange := provider.RawExchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeaders: redactHeaders(headers),
+		RequestBody:    string(requestBody),
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		exchange.Error = err.Error()
+	} else {
+		exchange.StatusCode = resp.StatusCode
+		exchange.ResponseBody = readResponseBody(resp)
+	}
+	t.recorder.record(exchange)
+	return resp, err
+}
+
+// readResponseBody reads the response body and replaces it so the caller can still read it,
+// including any read error
+func readResponseBody(resp *http.Response) string {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+		return string(data)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return string(data)
+}
+
+// errReader returns err from every read
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// redactHeaders copies the headers with credential values replaced
+func redactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if redactedHeaders[strings.ToLower(name)] {
+			value = "[REDACTED]"
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
@@ -0,0 +1,100 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestOpenRouterProvider_DebugCapturesRawExchange(t *testing.T) {
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		sent = string(data)
+		w.Write([]byte(`{"model":"test-model","choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "secret-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+		Debug:      true,
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	recorder := p.(provider.DebugRecorder)
+	if recorder.LastRawExchange() != nil {
+		t.Error("Expected no exchange before the first request")
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "ok" {
+		t.Errorf("Expected the response to still be parsed, got %q", result.Content)
+	}
+
+	exchange := recorder.LastRawExchange()
+	if exchange == nil {
+		t.Fatal("Expected the exchange to be recorded")
+	}
+	if exchange.RequestBody != sent {
+		t.Errorf("Expected the captured request body to match what was sent\n%s\ngot\n%s", sent, exchange.RequestBody)
+	}
+	if exchange.Method != "POST" || exchange.URL != server.URL || exchange.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected exchange: %+v", exchange)
+	}
+	if !strings.Contains(exchange.ResponseBody, `"content":"ok"`) {
+		t.Errorf("Expected the raw response body, got %s", exchange.ResponseBody)
+	}
+	if exchange.RequestHeaders["Authorization"] != "[REDACTED]" {
+		t.Errorf("Expected the Authorization header to be redacted, got %q", exchange.RequestHeaders["Authorization"])
+	}
+}
+
+func TestDebugTransport_RecordsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("echo:"), data...))
+	}))
+	defer server.Close()
+
+	recorder := &debugRecorder{name: "test"}
+	client := &http.Client{Transport: &debugTransport{base: http.DefaultTransport, recorder: recorder}}
+
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(`{"contents":[]}`))
+	req.Header.Set("x-goog-api-key", "secret-key")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != `echo:{"contents":[]}` {
+		t.Errorf("Expected the request body to reach the server and the response to reach the caller, got %s", body)
+	}
+	exchange := recorder.lastExchange()
+	if exchange.RequestBody != `{"contents":[]}` || exchange.ResponseBody != `echo:{"contents":[]}` {
+		t.Errorf("Unexpected exchange: %+v", exchange)
+	}
+	if exchange.RequestHeaders["X-Goog-Api-Key"] != "[REDACTED]" {
+		t.Errorf("Expected the API key header to be redacted, got %v", exchange.RequestHeaders)
+	}
+}
+
+func TestDebugRecorder_Disabled(t *testing.T) {
+	p := newTestFunctionCallingProvider(t, "http://localhost/v1/chat/completions")
+	if p.debug != nil || p.LastRawExchange() != nil {
+		t.Error("Expected debug mode to be off by default")
+	}
+}
@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// embeddingsURL derives an OpenAI-compatible embeddings endpoint from a chat completions endpoint.
+// It returns an empty string if the endpoint doesn't follow the OpenAI layout.
+func embeddingsURL(chatURL string) string {
+	if strings.HasSuffix(chatURL, "/chat/completions") {
+		return strings.TrimSuffix(chatURL, "/chat/completions") + "/embeddings"
+	}
+	return ""
+}
+
+// embedInputMessages wraps embedding inputs as messages so they can be counted by a token estimator
+func embedInputMessages(input []string) []provider.Message {
+	messages := make([]provider.Message, 0, len(input))
+	for _, text := range input {
+		messages = append(messages, provider.Message{Role: "user", Content: text})
+	}
+	return messages
+}
+
+// requestOpenAIEmbeddings calls an OpenAI-compatible /embeddings endpoint
+func requestOpenAIEmbeddings(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration, request provider.EmbeddingRequest) (*provider.EmbeddingResult, error) {
+	if request.Model == "" {
+		return nil, fmt.Errorf("embedding model is required")
+	}
+	if len(request.Input) == 0 {
+		return nil, fmt.Errorf("embedding input is empty")
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model": request.Model,
+		"input": request.Input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, _, err := client.Do(ctx, url, "POST", headers, bytes.NewBuffer(jsonData), timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, provider.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage *provider.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Data) != len(request.Input) {
+		return nil, fmt.Errorf("expected %d embeddings, received %d", len(request.Input), len(result.Data))
+	}
+
+	vectors := make([][]float32, len(request.Input))
+	for i, item := range result.Data {
+		index := item.Index
+		if index < 0 || index >= len(vectors) {
+			index = i
+		}
+		vectors[index] = item.Embedding
+	}
+
+	embeddingResult := &provider.EmbeddingResult{
+		Vectors: vectors,
+		Model:   request.Model,
+	}
+	if result.Model != "" {
+		embeddingResult.Model = result.Model
+	}
+	if result.Usage != nil {
+		embeddingResult.Usage = *result.Usage
+	}
+	return embeddingResult, nil
+}
@@ -1,23 +1,44 @@
 package providers
 
 import (
-	"time"
-
-	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
 	"github.com/FramnkRulez/go-llm-router/provider"
 )
 
-// NewGeminiProvider creates a new Gemini provider
-func NewGeminiProvider(apiKey string, models []string, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank int) (provider.Provider, error) {
-	return newGeminiProvider(apiKey, models, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank)
+// NewGeminiProvider creates a new Gemini provider, using Vertex AI when vertex is set
+func NewGeminiProvider(config provider.Config, vertex *VertexConfig) (provider.Provider, error) {
+	return newGeminiProvider(config, vertex)
 }
 
 // NewOpenRouterProvider creates a new OpenRouter provider
-func NewOpenRouterProvider(apiKey string, url string, timeout time.Duration, models []string, referer string, xTitle string, httpClient httpclient.Client, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank int) (provider.Provider, error) {
-	return newOpenRouterProvider(apiKey, url, timeout, models, referer, xTitle, httpClient, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank)
+func NewOpenRouterProvider(config provider.Config, url string, referer string, xTitle string, routing *OpenRouterRouting) (provider.Provider, error) {
+	return newOpenRouterProvider(config, url, referer, xTitle, routing)
 }
 
 // NewFunctionCallingProvider creates a new function calling provider for LLM APIs that support function calling
-func NewFunctionCallingProvider(apiKey string, url string, timeout time.Duration, models []string, httpClient httpclient.Client, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank int, toolExecutor ToolExecutor) (provider.Provider, error) {
-	return newFunctionCallingProvider(apiKey, url, timeout, models, httpClient, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank, toolExecutor)
+func NewFunctionCallingProvider(config provider.Config, url string, toolExecutor ToolExecutor, toolConfig ToolExecutionConfig) (provider.Provider, error) {
+	return newFunctionCallingProvider(config, url, toolExecutor, toolConfig)
+}
+
+// NewOpenAIProvider creates a function calling provider for the OpenAI API or a compatible proxy at baseURL
+func NewOpenAIProvider(config provider.Config, baseURL string, orgID string, toolExecutor ToolExecutor, toolConfig ToolExecutionConfig) (provider.Provider, error) {
+	return newOpenAIProvider(config, baseURL, orgID, toolExecutor, toolConfig)
+}
+
+// NewMistralProvider creates a function calling provider for the Mistral API or a compatible proxy at baseURL
+func NewMistralProvider(config provider.Config, baseURL string, options MistralOptions, toolExecutor ToolExecutor, toolConfig ToolExecutionConfig) (provider.Provider, error) {
+	return newMistralProvider(config, baseURL, options, toolExecutor, toolConfig)
+}
+
+// NewBedrockProvider creates a new AWS Bedrock provider. Credentials are resolved from the
+// explicit credentials, the environment or the named profile of the shared credentials file.
+func NewBedrockProvider(config provider.Config, region string, endpoint string, credentials AWSCredentials, profile string, credentialsProvider provider.AWSCredentialsProvider) (provider.Provider, error) {
+	return newBedrockProvider(config, region, endpoint, credentials, profile, credentialsProvider)
+}
+
+// tokenEstimatorOrDefault returns the configured estimator or the default heuristic
+func tokenEstimatorOrDefault(estimator provider.TokenEstimator) provider.TokenEstimator {
+	if estimator == nil {
+		return provider.DefaultTokenEstimator
+	}
+	return estimator
 }
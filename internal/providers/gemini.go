@@ -1,12 +1,21 @@
 package providers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/internal/retry"
 	"github.com/FramnkRulez/go-llm-router/provider"
 	"google.golang.org/genai"
 )
@@ -33,98 +42,284 @@ func (r GeminiRole) IsValid() bool {
 
 // GeminiProvider implements the Provider interface for Google's Gemini API
 type GeminiProvider struct {
-	apiKey               string
-	client               *genai.Client
-	models               []string
-	maxDailyRequests     int
-	maxRequestsPerMinute int
-	maxTokensPerMinute   int
-	rank                 int
-	requestsToday        int
-	requestsThisMinute   int
-	tokensThisMinute     int
-	lastReset            time.Time
-	lastMinuteReset      time.Time
+	apiKey         string
+	client         geminiAPI
+	httpClient     httpclient.Client
+	maxInlineBytes int
+	models         []string
+	rank           int
+	weight         int
+	tokenEstimator provider.TokenEstimator
+	contextWindow  int
+	timeout        time.Duration
+	limiter        *rateLimiter
+	modelLimits    modelRateLimiters // Limiters of the models with their own ModelLimits
+	debug          *debugRecorder    // nil unless debug mode is enabled
+	lifecycle      *lifecycle
+	selector       modelSelector
+	toolsDisabled  bool
+	images         *imageResizer       // nil unless AutoResizeImages is set
+	includeRaw     bool                // Sets QueryResult.Raw on responses
+	clampTemp      bool                // Clamps out-of-range temperatures instead of failing
+	concurrency    *concurrencyLimiter // nil unless MaxConcurrent is set
+	roleMap        map[string]string   // Overrides entries of geminiRoleMap, nil if not set
+	retry          retry.Policy
+}
+
+// geminiAPI is the subset of the genai client used by the provider
+type geminiAPI interface {
+	GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
+	UploadFile(ctx context.Context, data []byte, mimeType string, displayName string) (*genai.File, error)
+	GetFile(ctx context.Context, name string) (*genai.File, error)
+	EmbedContent(ctx context.Context, model string, contents []*genai.Content) (*genai.EmbedContentResponse, error)
+	GetModel(ctx context.Context, model string) (*genai.Model, error)
+	GenerateImages(ctx context.Context, model string, prompt string, config *genai.GenerateImagesConfig) (*genai.GenerateImagesResponse, error)
+	Warmup(ctx context.Context) error
+}
+
+// genaiClient adapts *genai.Client to geminiAPI
+type genaiClient struct {
+	client *genai.Client
+}
+
+func (c genaiClient) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	return c.client.Models.GenerateContent(ctx, model, contents, config)
+}
+
+func (c genaiClient) UploadFile(ctx context.Context, data []byte, mimeType string, displayName string) (*genai.File, error) {
+	return c.client.Files.Upload(ctx, bytes.NewReader(data), &genai.UploadFileConfig{
+		MIMEType:    mimeType,
+		DisplayName: displayName,
+	})
+}
+
+func (c genaiClient) GetFile(ctx context.Context, name string) (*genai.File, error) {
+	return c.client.Files.Get(ctx, name, nil)
+}
+
+func (c genaiClient) EmbedContent(ctx context.Context, model string, contents []*genai.Content) (*genai.EmbedContentResponse, error) {
+	return c.client.Models.EmbedContent(ctx, model, contents, nil)
+}
+
+// Warmup sends a HEAD request to the API's base URL through the SDK's own HTTP client, so its
+// connection pool (and, for Vertex AI, its access token) is ready for the first request
+func (c genaiClient) Warmup(ctx context.Context) error {
+	config := c.client.ClientConfig()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, config.HTTPOptions.BaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create warmup request: %w", err)
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("warmup request failed: %w", err)
+	}
+	return drainResponse(resp)
+}
+
+func (c genaiClient) GetModel(ctx context.Context, model string) (*genai.Model, error) {
+	return c.client.Models.Get(ctx, model, nil)
+}
+
+func (c genaiClient) GenerateImages(ctx context.Context, model string, prompt string, config *genai.GenerateImagesConfig) (*genai.GenerateImagesResponse, error) {
+	return c.client.Models.GenerateImages(ctx, model, prompt, config)
+}
+
+// geminiMaxInlineBytes is the largest file sent inline; larger files are uploaded through the Files API.
+// Gemini rejects requests over 20MB, so this leaves room for the rest of the request.
+const geminiMaxInlineBytes = 15 * 1024 * 1024
+
+// geminiMaxFetchBytes is the largest remote file attachment that will be downloaded
+const geminiMaxFetchBytes = 100 * 1024 * 1024
+
+// geminiFilePollInterval is how often an uploaded file is checked until Gemini has processed it
+var geminiFilePollInterval = time.Second
+
+// geminiDocumentMIMETypes are the document types Gemini can read
+var geminiDocumentMIMETypes = map[string]bool{
+	"application/pdf":          true,
+	"application/x-javascript": true,
+	"text/javascript":          true,
+	"application/x-python":     true,
+	"text/x-python":            true,
+	"text/plain":               true,
+	"text/html":                true,
+	"text/css":                 true,
+	"text/md":                  true,
+	"text/markdown":            true,
+	"text/csv":                 true,
+	"text/xml":                 true,
+	"text/rtf":                 true,
+	"application/rtf":          true,
 }
 
 // geminiDebugEnabled enables verbose logging when GEMINI_DEBUG=1 is set in env.
 var geminiDebugEnabled = os.Getenv("GEMINI_DEBUG") == "1"
 
 var _ provider.Provider = (*GeminiProvider)(nil)
+var _ provider.TokenEstimator = (*GeminiProvider)(nil)
+var _ provider.Weighted = (*GeminiProvider)(nil)
+var _ provider.ToolCapable = (*GeminiProvider)(nil)
+var _ provider.Embedder = (*GeminiProvider)(nil)
+var _ provider.ImageGenerator = (*GeminiProvider)(nil)
+var _ provider.Transcriber = (*GeminiProvider)(nil)
+var _ provider.HealthChecker = (*GeminiProvider)(nil)
+var _ provider.Warmer = (*GeminiProvider)(nil)
+var _ provider.TimeoutAware = (*GeminiProvider)(nil)
+var _ provider.DebugRecorder = (*GeminiProvider)(nil)
+
+// geminiDefaultEmbeddingModel is used when an embedding request doesn't name a model
+const geminiDefaultEmbeddingModel = "text-embedding-004"
 
-// convertRoleToGemini converts standard chat roles to Gemini-compatible roles
+// geminiDefaultImageModel is used when an image request doesn't name a model
+const geminiDefaultImageModel = "imagen-3.0-generate-002"
+
+// geminiImageAspectRatios are the aspect ratios Imagen can generate
+var geminiImageAspectRatios = []string{"1:1", "3:4", "4:3", "9:16", "16:9"}
+
+// geminiRoleMap converts standard chat roles to Gemini roles. Gemini has no "system" role in
+// contents; system messages are sent as the system instruction. Tool results are sent as
+// function response parts, which the API expects in a user turn.
+var geminiRoleMap = map[string]string{
+	"system":    string(GeminiRoleUser),
+	"user":      string(GeminiRoleUser),
+	"assistant": string(GeminiRoleModel),
+	"tool":      string(GeminiRoleUser),
+}
+
+// convertRoleToGemini converts standard chat roles to Gemini-compatible roles, using the
+// configured roleMap before the defaults in geminiRoleMap
 // Returns a strongly typed GeminiRole
-func convertRoleToGemini(role string) GeminiRole {
-	switch role {
-	case "system":
-		// Gemini doesn't support "system" role, convert to "user"
-		return GeminiRoleUser
-	case "user":
-		return GeminiRoleUser
-	case "assistant":
-		return GeminiRoleModel
-	default:
-		// Default to user for unknown roles
-		return GeminiRoleUser
+func convertRoleToGemini(roleMap map[string]string, role string) GeminiRole {
+	if mapped, ok := roleMap[role]; ok {
+		return GeminiRole(mapped)
 	}
+	if mapped, ok := geminiRoleMap[role]; ok {
+		return GeminiRole(mapped)
+	}
+	// Default to user for unknown roles
+	return GeminiRoleUser
 }
 
-// validateGeminiRole validates that a role is acceptable for Gemini
-func validateGeminiRole(role string) error {
-	geminiRole := convertRoleToGemini(role)
+// validateGeminiRole validates that a role converts to one Gemini accepts
+func validateGeminiRole(roleMap map[string]string, role string) error {
+	geminiRole := convertRoleToGemini(roleMap, role)
 	if !geminiRole.IsValid() {
-		return fmt.Errorf("invalid role for Gemini: %s (only 'user' and 'assistant' are supported)", role)
+		return fmt.Errorf("invalid role for Gemini: %s is mapped to %s (only 'user' and 'model' are supported)", role, geminiRole)
 	}
 	return nil
 }
 
-// newGeminiProvider creates a new Gemini provider
-func newGeminiProvider(apiKey string, models []string, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank int) (provider.Provider, error) {
+// VertexConfig selects the Vertex AI backend for Gemini, which authenticates with Google
+// application default credentials instead of an API key
+type VertexConfig struct {
+	Project  string
+	Location string
+}
+
+// geminiClientConfig builds the genai client configuration for the Gemini API, or for Vertex AI
+// when vertex is set, in which case the API key is ignored
+func geminiClientConfig(config provider.Config, vertex *VertexConfig) (*genai.ClientConfig, error) {
+	if vertex == nil {
+		// The SDK falls back to these environment variables when no key is configured
+		if config.APIKey == "" && os.Getenv("GOOGLE_API_KEY") == "" && os.Getenv("GEMINI_API_KEY") == "" {
+			return nil, fmt.Errorf("%w for Gemini: set APIKey, GOOGLE_API_KEY or GEMINI_API_KEY, or use Vertex AI", provider.ErrMissingAPIKey)
+		}
+		return &genai.ClientConfig{
+			APIKey:  config.APIKey,
+			Backend: genai.BackendGeminiAPI,
+		}, nil
+	}
+
+	if vertex.Project == "" || vertex.Location == "" {
+		return nil, fmt.Errorf("project and location are required for Vertex AI")
+	}
+	return &genai.ClientConfig{
+		Backend:  genai.BackendVertexAI,
+		Project:  vertex.Project,
+		Location: vertex.Location,
+	}, nil
+}
+
+// newGeminiProvider creates a new Gemini provider, using Vertex AI when vertex is set
+func newGeminiProvider(config provider.Config, vertex *VertexConfig) (provider.Provider, error) {
 	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	clientConfig, err := geminiClientConfig(config, vertex)
+	if err != nil {
+		return nil, err
+	}
+
+	// The SDK makes its own HTTP requests, so debug mode records them at the transport.
+	// Vertex AI needs the SDK's authenticated client, so its requests are not recorded.
+	debug := newDebugRecorder(config, "Gemini")
+	if debug != nil && vertex == nil {
+		clientConfig.HTTPClient = &http.Client{Transport: &debugTransport{base: http.DefaultTransport, recorder: debug}}
+	}
+
+	client, err := genai.NewClient(ctx, clientConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	now := time.Now()
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = httpclient.New("go-llm-router/1.0")
+	}
+
+	// Vertex AI quota belongs to the project and location rather than an API key
+	limiterID := ""
+	if vertex != nil {
+		limiterID = "vertex:" + vertex.Project + "/" + vertex.Location
+	}
+	limiter, err := newConfiguredRateLimiter(config, "Gemini", limiterID)
+	if err != nil {
+		return nil, err
+	}
+	modelLimits, err := newModelRateLimiters(config, "Gemini", limiterID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GeminiProvider{
-		apiKey:               apiKey,
-		client:               client,
-		models:               models,
-		maxDailyRequests:     maxDailyRequests,
-		maxRequestsPerMinute: maxRequestsPerMinute,
-		maxTokensPerMinute:   maxTokensPerMinute,
-		rank:                 rank,
-		requestsToday:        0,
-		requestsThisMinute:   0,
-		tokensThisMinute:     0,
-		lastReset:            now.Truncate(24 * time.Hour),
-		lastMinuteReset:      now.Truncate(time.Minute),
+		apiKey:         config.APIKey,
+		client:         genaiClient{client: client},
+		httpClient:     httpClient,
+		maxInlineBytes: geminiMaxInlineBytes,
+		models:         config.Models,
+		rank:           config.Rank,
+		weight:         config.Weight,
+		tokenEstimator: tokenEstimatorOrDefault(config.TokenEstimator),
+		contextWindow:  config.MaxContextTokens,
+		timeout:        config.Timeout,
+		limiter:        limiter,
+		modelLimits:    modelLimits,
+		debug:          debug,
+		lifecycle:      newLifecycle(),
+		selector:       modelSelector{strategy: config.ModelStrategy},
+		toolsDisabled:  config.DisableTools,
+		images:         newImageResizer(config),
+		includeRaw:     config.IncludeRawResponse,
+		clampTemp:      config.ClampTemperature,
+		concurrency:    newConcurrencyLimiter(config),
+		roleMap:        config.RoleMap,
+		retry:          retry.New(config.Retry),
 	}, nil
 }
 
-// estimateTokensForMessages provides a rough estimation of tokens in the messages
-// This is a simple approximation: ~4 characters per token for English text
-func estimateTokensForMessages(messages []provider.Message) int {
-	totalChars := 0
-	for _, msg := range messages {
-		totalChars += len(msg.Content)
-		for _, file := range msg.Files {
-			totalChars += len(file.Data)
-		}
-	}
-	// Rough approximation: 4 characters per token
-	return totalChars / 4
+// EstimateTokens estimates the tokens in the messages using the provider's token estimator
+func (g *GeminiProvider) EstimateTokens(messages []provider.Message) int {
+	return g.tokenEstimator.EstimateTokens(messages)
 }
 
 // Query sends a prompt to Gemini and returns the response (legacy method)
 func (g *GeminiProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
 	options := provider.QueryOptions{
-		Temperature: temperature,
-		ForceModel:  forceModel,
+		Temperature:    temperature,
+		TemperatureSet: true,
+		ForceModel:     forceModel,
 	}
 
 	result, err := g.QueryWithOptions(ctx, messages, options)
@@ -137,71 +332,83 @@ func (g *GeminiProvider) Query(ctx context.Context, messages []provider.Message,
 
 // QueryWithOptions sends a prompt to Gemini with advanced options including function calling
 func (g *GeminiProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
-	if time.Since(g.lastReset) > 24*time.Hour {
-		g.requestsToday = 0
-		g.lastReset = time.Now().Truncate(24 * time.Hour)
+	ctx, done, err := g.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer done()
 
-	modelsToUse := g.models
-	if options.ForceModel != "" {
-		modelsToUse = []string{options.ForceModel}
+	release, err := g.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	var err error
-	for _, model := range modelsToUse {
-		// Convert messages to Gemini format with support for files
-		genaiMessages := make([]*genai.Content, 0, len(messages))
-		for _, message := range messages {
-			// Validate role for Gemini
-			if err := validateGeminiRole(message.Role); err != nil {
-				return nil, fmt.Errorf("message validation failed: %w", err)
-			}
-			parts := make([]*genai.Part, 0)
+	ctx, cancel, timeout := withQueryTimeout(ctx, g.timeout, options)
+	defer cancel()
 
-			// Add text content if present
-			if message.Content != "" {
-				parts = append(parts, &genai.Part{Text: message.Content})
-			}
+	messages = provider.TrimToContextWindowWithEstimator(g.tokenEstimator, messages, g.contextWindow, true)
+	messages, err = g.images.apply(messages)
+	if err != nil {
+		return nil, err
+	}
+	options.Tools = mergeTools(options.Tools)
 
-			// Add file attachments if present
-			for _, file := range message.Files {
-				switch file.Type {
-				case "image":
-					// Handle image files
-					inlineData := &genai.Blob{
-						Data:     file.Data,
-						MIMEType: file.MimeType,
-					}
-					parts = append(parts, &genai.Part{InlineData: inlineData})
-				case "document":
-					// Handle document files (PDF, etc.)
-					// Note: Gemini has limited document support, mainly for images
-					// For now, we'll skip document files that aren't images
-					if file.MimeType == "application/pdf" {
-						// PDFs need special handling - skip for now
-						continue
-					}
-				default:
-					// Skip unsupported file types
-					continue
-				}
-			}
+	modelsToUse := g.selector.order(g.models, options.ForceModel)
+	if len(modelsToUse) == 0 {
+		return nil, noModelsError(g.Name())
+	}
 
-			// Convert role to Gemini format
-			geminiRole := convertRoleToGemini(message.Role)
+	// Convert messages to Gemini format with support for files
+	systemInstruction, genaiMessages, err := g.buildContents(ctx, messages, options.SystemPrompt)
+	if err != nil {
+		return nil, err
+	}
 
-			genaiMessages = append(genaiMessages, &genai.Content{
-				Parts: parts,
-				Role:  geminiRole.String(),
-			})
+	var attempts modelAttempts
+	for _, model := range modelsToUse {
+		attempts.next(model, err)
+
+		// Models with their own limits are skipped once they have used them up
+		if limitErr := g.modelLimits.checkModel(model, g.limiter, g, messages); limitErr != nil {
+			err = limitErr
+			continue
+		}
+
+		// Tools would otherwise be ignored by models without function calling
+		if len(options.Tools) > 0 && !geminiModelSupportsTools(model) {
+			err = fmt.Errorf("%w: model %s can't call functions", provider.ErrToolsNotSupported, model)
+			continue
+		}
+
+		// The range depends on the model, so a later model may still take the temperature
+		modelOptions, rangeErr := geminiTemperatureRange(model).apply(options, g.clampTemp)
+		if rangeErr != nil {
+			err = rangeErr
+			continue
 		}
 
 		// Create generation config
-		config := &genai.GenerateContentConfig{}
-		if options.Temperature > 0 {
-			temp := float32(options.Temperature)
+		config := &genai.GenerateContentConfig{SystemInstruction: systemInstruction}
+		if modelOptions.HasTemperature() {
+			temp := float32(modelOptions.Temperature)
 			config.Temperature = &temp
 		}
+		if options.N > 1 {
+			config.CandidateCount = int32(options.N)
+		}
+		if options.Seed != nil {
+			seed := int32(*options.Seed)
+			config.Seed = &seed
+		}
+		if options.ThinkingBudget != nil {
+			budget := int32(*options.ThinkingBudget)
+			config.ThinkingConfig = &genai.ThinkingConfig{ThinkingBudget: &budget}
+		}
+		// Gemini rejects a JSON response type combined with function calling
+		if options.JSONMode && len(options.Tools) == 0 {
+			config.ResponseMIMEType = "application/json"
+		}
 
 		// Create tools if provided
 		if len(options.Tools) > 0 {
@@ -222,44 +429,55 @@ func (g *GeminiProvider) QueryWithOptions(ctx context.Context, messages []provid
 				tools = append(tools, genaiTool)
 			}
 			config.Tools = tools
+			config.ToolConfig = geminiToolConfig(options.ToolChoice)
 		}
 
-		// Make the request
-		resp, err := g.client.Models.GenerateContent(ctx, model, genaiMessages, config)
-		if err != nil {
+		// Make the request, retrying transient errors
+		var resp *genai.GenerateContentResponse
+		genErr := g.retry.Do(ctx, isTransientGeminiError, func() (callErr error) {
+			resp, callErr = g.generateContent(ctx, timeout, model, genaiMessages, config)
+			return callErr
+		})
+		if genErr != nil {
+			err = genErr
+			// Other models are rejected the same way when the key or project lacks access
+			if isGeminiAuthError(genErr) {
+				break
+			}
 			continue
 		}
 
 		// Update rate limiting counters
-		g.requestsToday++
-		g.requestsThisMinute++
-
-		// Estimate tokens for this request (rough approximation)
-		estimatedTokens := estimateTokensForMessages(messages)
-		g.tokensThisMinute += estimatedTokens
-
-		content := ""
-		finishReason := "stop"
-		var toolCalls []provider.ToolCall
+		limiter := g.modelLimits.forModel(model, g.limiter)
+		limiter.recordRequest()
+		limiter.recordTokens(g.EstimateTokens(messages))
 
+		completions := make([]provider.Completion, 0, len(resp.Candidates))
 		for ci, candidate := range resp.Candidates {
+			completion := provider.Completion{FinishReason: "stop"}
 			if candidate.FinishReason != "" {
-				finishReason = string(candidate.FinishReason)
+				completion.FinishReason = string(candidate.FinishReason)
 			}
 
 			candidateHandled := false
 			// Defensive: candidate.Content may be nil
 			if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
 				if geminiDebugEnabled {
-					log.Printf("[gemini] candidate %d has no parts (finish_reason=%s model=%s)", ci, finishReason, model)
+					log.Printf("[gemini] candidate %d has no parts (finish_reason=%s model=%s)", ci, completion.FinishReason, model)
 				}
+				completions = append(completions, completion)
 				continue
 			}
 
 			for pi, part := range candidate.Content.Parts {
 				partHandled := false
 				if part.Text != "" {
-					content += part.Text
+					// Thought summaries are the model's reasoning, not part of the answer
+					if part.Thought {
+						completion.Reasoning += part.Text
+					} else {
+						completion.Content += part.Text
+					}
 					partHandled = true
 				}
 
@@ -272,7 +490,7 @@ func (g *GeminiProvider) QueryWithOptions(ctx context.Context, messages []provid
 							Arguments: part.FunctionCall.Args,
 						},
 					}
-					toolCalls = append(toolCalls, toolCall)
+					completion.ToolCalls = append(completion.ToolCalls, toolCall)
 					partHandled = true
 					if geminiDebugEnabled {
 						log.Printf("[gemini] tool call model=%s candidate=%d part=%d name=%s args=%v", model, ci, pi, part.FunctionCall.Name, part.FunctionCall.Args)
@@ -295,60 +513,618 @@ func (g *GeminiProvider) QueryWithOptions(ctx context.Context, messages []provid
 			}
 
 			if !candidateHandled && geminiDebugEnabled {
-				log.Printf("[gemini] candidate %d produced no handled parts (finish_reason=%s model=%s)", ci, finishReason, model)
+				log.Printf("[gemini] candidate %d produced no handled parts (finish_reason=%s model=%s)", ci, completion.FinishReason, model)
 			}
+			completions = append(completions, completion)
+		}
+
+		// A blocked answer is an error so the router can fall back to another provider
+		if blockErr := geminiBlockError(resp, model, completions); blockErr != nil {
+			err = blockErr
+			continue
 		}
 
 		// Note: Gemini now supports function calling with the new SDK
 		result := &provider.QueryResult{
-			Content:      content,
 			Model:        model,
-			ToolCalls:    toolCalls,
-			FinishReason: finishReason,
+			FinishReason: "stop",
+		}
+		if len(completions) > 0 {
+			result.Content = completions[0].Content
+			result.Reasoning = completions[0].Reasoning
+			result.ToolCalls = completions[0].ToolCalls
+			result.FinishReason = completions[0].FinishReason
+		}
+		if options.N > 1 {
+			result.Completions = completions
 		}
 
+		if resp.UsageMetadata != nil {
+			result.Usage = &provider.Usage{
+				PromptTokens:     int(resp.UsageMetadata.PromptTokenCount),
+				CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+				TotalTokens:      int(resp.UsageMetadata.TotalTokenCount),
+			}
+		}
+		if g.includeRaw {
+			// The SDK has already decoded the body, so the raw form is the response re-encoded
+			raw, err := json.Marshal(resp)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode raw response: %w", err)
+			}
+			result.Raw = raw
+		}
+		result.ModelAttempts = attempts.succeeded()
+
 		return result, nil
 	}
 
 	return nil, fmt.Errorf("failed to generate content: %w", err)
 }
 
-// Close closes the Gemini client
+// geminiNonToolModelPrefixes are the prefixes of models that don't support function calling
+var geminiNonToolModelPrefixes = []string{
+	"gemini-1.0-pro",
+	"gemini-pro-vision",
+	"gemma-",
+	"text-embedding-",
+	"embedding-",
+	"imagen-",
+	"aqa",
+}
+
+// geminiModelSupportsTools reports whether the model supports function calling
+func geminiModelSupportsTools(model string) bool {
+	model = strings.TrimPrefix(model, "models/")
+	for _, prefix := range geminiNonToolModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTransientGeminiError reports whether a failed GenerateContent call may succeed if it is
+// sent again: rate limits, timeouts and server errors
+func isTransientGeminiError(err error) bool {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return provider.IsRetryableStatus(apiErr.Code)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// isGeminiAuthError reports whether the API rejected the API key or the project's permissions
+func isGeminiAuthError(err error) bool {
+	var apiErr genai.APIError
+	return errors.As(err, &apiErr) && (apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden)
+}
+
+// geminiToolConfig maps QueryOptions.ToolChoice to a function calling mode. A function name
+// forces a call to that function through ANY mode restricted to it.
+func geminiToolConfig(choice string) *genai.ToolConfig {
+	config := &genai.FunctionCallingConfig{}
+	switch choice {
+	case "":
+		return nil
+	case "auto":
+		config.Mode = genai.FunctionCallingConfigModeAuto
+	case "none":
+		config.Mode = genai.FunctionCallingConfigModeNone
+	case "required", "any":
+		config.Mode = genai.FunctionCallingConfigModeAny
+	default:
+		config.Mode = genai.FunctionCallingConfigModeAny
+		config.AllowedFunctionNames = []string{choice}
+	}
+	return &genai.ToolConfig{FunctionCallingConfig: config}
+}
+
+// geminiBlockedFinishReasons are the finish reasons of candidates withheld by Gemini's filters
+var geminiBlockedFinishReasons = map[genai.FinishReason]bool{
+	genai.FinishReasonSafety:            true,
+	genai.FinishReasonRecitation:        true,
+	genai.FinishReasonOther:             true,
+	genai.FinishReasonBlocklist:         true,
+	genai.FinishReasonProhibitedContent: true,
+	genai.FinishReasonSPII:              true,
+	genai.FinishReasonImageSafety:       true,
+}
+
+// geminiBlockError returns a ContentBlockedError if the prompt was blocked or the first candidate
+// is empty because it was withheld, and nil otherwise
+func geminiBlockError(resp *genai.GenerateContentResponse, model string, completions []provider.Completion) error {
+	if len(resp.Candidates) == 0 {
+		if resp.PromptFeedback == nil || resp.PromptFeedback.BlockReason == "" {
+			return nil
+		}
+		return &provider.ContentBlockedError{
+			Model:  model,
+			Reason: string(resp.PromptFeedback.BlockReason),
+			Detail: geminiBlockDetail(resp.PromptFeedback.BlockReasonMessage, resp.PromptFeedback.SafetyRatings),
+		}
+	}
+
+	candidate := resp.Candidates[0]
+	if !geminiBlockedFinishReasons[candidate.FinishReason] || completions[0].Content != "" || len(completions[0].ToolCalls) > 0 {
+		return nil
+	}
+	return &provider.ContentBlockedError{
+		Model:  model,
+		Reason: string(candidate.FinishReason),
+		Detail: geminiBlockDetail(candidate.FinishMessage, candidate.SafetyRatings),
+	}
+}
+
+// geminiBlockDetail combines Gemini's explanation with the categories that likely caused the block
+func geminiBlockDetail(message string, ratings []*genai.SafetyRating) string {
+	var details []string
+	if message != "" {
+		details = append(details, message)
+	}
+
+	var categories []string
+	for _, rating := range ratings {
+		// Only Vertex AI sets Blocked; the Gemini API reports a high probability instead
+		if rating != nil && (rating.Blocked || rating.Probability == genai.HarmProbabilityHigh) {
+			categories = append(categories, string(rating.Category))
+		}
+	}
+	if len(categories) > 0 {
+		details = append(details, "blocked categories: "+strings.Join(categories, ", "))
+	}
+	return strings.Join(details, "; ")
+}
+
+// generateContent calls the API, bounded by timeout when it is positive
+func (g *GeminiProvider) generateContent(ctx context.Context, timeout time.Duration, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return g.client.GenerateContent(ctx, model, contents, config)
+}
+
+// buildContents converts messages, including their file attachments, to Gemini contents.
+// System messages and the system prompt are returned separately as the system instruction.
+func (g *GeminiProvider) buildContents(ctx context.Context, messages []provider.Message, systemPrompt string) (*genai.Content, []*genai.Content, error) {
+	// The system prompt and any system-role messages are merged into the single system
+	// instruction Gemini takes, in order
+	var systemTexts []string
+	if systemPrompt != "" {
+		systemTexts = append(systemTexts, systemPrompt)
+	}
+
+	// Function responses are matched to calls by name, so remember the name of each call id
+	toolNames := make(map[string]string)
+
+	genaiMessages := make([]*genai.Content, 0, len(messages))
+	for _, message := range messages {
+		// Validate role for Gemini
+		if err := validateGeminiRole(g.roleMap, message.Role); err != nil {
+			return nil, nil, fmt.Errorf("message validation failed: %w", err)
+		}
+
+		if message.Role == "tool" && message.ToolCallID == "" {
+			return nil, nil, fmt.Errorf("message validation failed: tool message has no ToolCallID to match it to its function call")
+		}
+
+		if message.Role == "system" {
+			if message.Content != "" {
+				systemTexts = append(systemTexts, message.Content)
+			}
+			continue
+		}
+
+		parts := make([]*genai.Part, 0)
+
+		if message.ToolCallID != "" {
+			// A tool result from the history; the ids of calls made by Gemini are their names
+			name := toolNames[message.ToolCallID]
+			if name == "" {
+				name = message.ToolCallID
+			}
+			parts = append(parts, &genai.Part{FunctionResponse: &genai.FunctionResponse{
+				Name:     name,
+				Response: geminiFunctionResponse(message.Content),
+			}})
+		} else if message.Content != "" {
+			// Add text content if present
+			parts = append(parts, &genai.Part{Text: message.Content})
+		}
+
+		for _, toolCall := range message.ToolCalls {
+			toolNames[toolCall.ID] = toolCall.Function.Name
+			parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+				Name: toolCall.Function.Name,
+				Args: toolCall.Function.Arguments,
+			}})
+		}
+
+		// Add file attachments if present
+		for _, file := range message.Files {
+			part, err := g.filePart(ctx, file)
+			if err != nil {
+				return nil, nil, err
+			}
+			parts = append(parts, part)
+		}
+
+		// Convert role to Gemini format
+		geminiRole := convertRoleToGemini(g.roleMap, message.Role)
+
+		genaiMessages = append(genaiMessages, &genai.Content{
+			Parts: parts,
+			Role:  geminiRole.String(),
+		})
+	}
+
+	var systemInstruction *genai.Content
+	if len(systemTexts) > 0 {
+		systemInstruction = &genai.Content{Parts: []*genai.Part{{Text: strings.Join(systemTexts, "\n\n")}}}
+	}
+	return systemInstruction, genaiMessages, nil
+}
+
+// geminiFunctionResponse wraps a tool result for a function response part. JSON objects are
+// passed through; anything else is sent under the "output" key.
+func geminiFunctionResponse(content string) map[string]any {
+	var response map[string]any
+	if err := json.Unmarshal([]byte(content), &response); err == nil && response != nil {
+		return response
+	}
+	return map[string]any{"output": content}
+}
+
+// filePart converts a file attachment to a Gemini part. Images, audio, video and supported
+// documents are sent inline when small enough and uploaded through the Files API otherwise.
+func (g *GeminiProvider) filePart(ctx context.Context, file provider.File) (*genai.Part, error) {
+	// Gemini needs the bytes of remote files
+	if file.URL != "" && len(file.Data) == 0 {
+		fetched, err := g.fetchFile(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+		file = fetched
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(strings.Split(file.MimeType, ";")[0]))
+
+	supported := strings.HasPrefix(mimeType, "image/") ||
+		strings.HasPrefix(mimeType, "audio/") ||
+		strings.HasPrefix(mimeType, "video/") ||
+		geminiDocumentMIMETypes[mimeType]
+	if !supported {
+		return nil, fmt.Errorf("unsupported file type for Gemini: %s (%s)", file.Name, file.MimeType)
+	}
+
+	if len(file.Data) <= g.maxInlineBytes {
+		return &genai.Part{InlineData: &genai.Blob{Data: file.Data, MIMEType: mimeType}}, nil
+	}
+
+	uploaded, err := g.client.UploadFile(ctx, file.Data, mimeType, file.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s to Gemini: %w", file.Name, err)
+	}
+
+	// Large files may need processing before they can be used in a request
+	for uploaded.State == genai.FileStateProcessing {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(geminiFilePollInterval):
+		}
+		uploaded, err = g.client.GetFile(ctx, uploaded.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check upload of %s: %w", file.Name, err)
+		}
+	}
+	if uploaded.State == genai.FileStateFailed {
+		return nil, fmt.Errorf("gemini failed to process %s", file.Name)
+	}
+
+	return genai.NewPartFromURI(uploaded.URI, mimeType), nil
+}
+
+// fetchFile downloads a remote file attachment, taking the MIME type from the response if it isn't set
+func (g *GeminiProvider) fetchFile(ctx context.Context, file provider.File) (provider.File, error) {
+	resp, _, err := g.httpClient.Do(ctx, file.URL, "GET", nil, nil, 0)
+	if err != nil {
+		return file, fmt.Errorf("failed to fetch %s: %w", file.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return file, fmt.Errorf("failed to fetch %s: status %d", file.URL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, geminiMaxFetchBytes+1))
+	if err != nil {
+		return file, fmt.Errorf("failed to read %s: %w", file.URL, err)
+	}
+	if len(data) > geminiMaxFetchBytes {
+		return file, fmt.Errorf("file at %s exceeds %d bytes", file.URL, geminiMaxFetchBytes)
+	}
+
+	file.Data = data
+	if file.MimeType == "" {
+		file.MimeType = resp.Header.Get("Content-Type")
+	}
+	return file, nil
+}
+
+// SupportsEmbeddings reports that Gemini can create embeddings
+func (g *GeminiProvider) SupportsEmbeddings() bool {
+	return true
+}
+
+// Embeddings creates vector embeddings with Gemini's EmbedContent API.
+// The model defaults to text-embedding-004.
+func (g *GeminiProvider) Embeddings(ctx context.Context, request provider.EmbeddingRequest) (*provider.EmbeddingResult, error) {
+	ctx, done, err := g.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := g.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if len(request.Input) == 0 {
+		return nil, fmt.Errorf("embedding input is empty")
+	}
+
+	model := request.Model
+	if model == "" {
+		model = geminiDefaultEmbeddingModel
+	}
+
+	contents := make([]*genai.Content, 0, len(request.Input))
+	for _, text := range request.Input {
+		contents = append(contents, genai.NewContentFromText(text, genai.RoleUser))
+	}
+
+	resp, err := g.client.EmbedContent(ctx, model, contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed content: %w", err)
+	}
+
+	if len(resp.Embeddings) != len(request.Input) {
+		return nil, fmt.Errorf("expected %d embeddings, received %d", len(request.Input), len(resp.Embeddings))
+	}
+
+	vectors := make([][]float32, 0, len(resp.Embeddings))
+	for _, embedding := range resp.Embeddings {
+		vectors = append(vectors, embedding.Values)
+	}
+
+	// Gemini doesn't report usage for embeddings, so count the estimate
+	tokens := g.EstimateTokens(embedInputMessages(request.Input))
+	g.limiter.recordRequest()
+	g.limiter.recordTokens(tokens)
+
+	return &provider.EmbeddingResult{
+		Vectors: vectors,
+		Model:   model,
+		Usage:   provider.Usage{PromptTokens: tokens, TotalTokens: tokens},
+	}, nil
+}
+
+// SupportsImageGeneration reports that Gemini can generate images
+func (g *GeminiProvider) SupportsImageGeneration() bool {
+	return true
+}
+
+// GenerateImage generates images with Imagen. The model defaults to imagen-3.0-generate-002.
+// Imagen doesn't take pixel sizes, so the size is sent as the closest supported aspect ratio.
+func (g *GeminiProvider) GenerateImage(ctx context.Context, request provider.ImageRequest) (*provider.ImageResult, error) {
+	ctx, done, err := g.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := g.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if request.Prompt == "" {
+		return nil, fmt.Errorf("image prompt is empty")
+	}
+
+	model := request.Model
+	if model == "" {
+		model = geminiDefaultImageModel
+	}
+
+	config := &genai.GenerateImagesConfig{}
+	if request.N > 1 {
+		config.NumberOfImages = int32(request.N)
+	}
+	if request.Size != "" {
+		ratio, err := closestAspectRatio(request.Size, geminiImageAspectRatios)
+		if err != nil {
+			return nil, err
+		}
+		config.AspectRatio = ratio
+	}
+
+	resp, err := g.client.GenerateImages(ctx, model, request.Prompt, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate images: %w", err)
+	}
+	g.limiter.recordRequest()
+
+	images := make([]provider.File, 0, len(resp.GeneratedImages))
+	var filtered string
+	for _, generated := range resp.GeneratedImages {
+		if generated.Image == nil || len(generated.Image.ImageBytes) == 0 {
+			if generated.RAIFilteredReason != "" {
+				filtered = generated.RAIFilteredReason
+			}
+			continue
+		}
+		mimeType := generated.Image.MIMEType
+		if mimeType == "" {
+			mimeType = "image/png"
+		}
+		images = append(images, provider.File{
+			Type:     "image",
+			Data:     generated.Image.ImageBytes,
+			MimeType: mimeType,
+			Name:     fmt.Sprintf("image-%d.%s", len(images)+1, strings.TrimPrefix(mimeType, "image/")),
+		})
+	}
+	if len(images) == 0 {
+		if filtered != "" {
+			return nil, &provider.ContentBlockedError{Model: model, Reason: "SAFETY", Detail: filtered}
+		}
+		return nil, fmt.Errorf("no images received")
+	}
+
+	return &provider.ImageResult{Images: images, Model: model}, nil
+}
+
+// SupportsTranscription reports that Gemini can transcribe audio
+func (g *GeminiProvider) SupportsTranscription() bool {
+	return true
+}
+
+// Transcribe sends the audio to a Gemini model with an instruction to transcribe it.
+// The model defaults to the first configured model.
+func (g *GeminiProvider) Transcribe(ctx context.Context, request provider.TranscriptionRequest) (*provider.TranscriptionResult, error) {
+	ctx, done, err := g.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := g.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	model := request.Model
+	if model == "" {
+		if len(g.models) == 0 {
+			return nil, noModelsError(g.Name())
+		}
+		model = g.models[0]
+	}
+
+	audio, err := g.filePart(ctx, request.Audio)
+	if err != nil {
+		return nil, err
+	}
+
+	instruction := "Transcribe the speech in this audio verbatim. Reply with the transcript only."
+	if request.Language != "" {
+		instruction += " The spoken language is " + request.Language + "."
+	}
+	contents := []*genai.Content{{
+		Role:  genai.RoleUser,
+		Parts: []*genai.Part{{Text: instruction}, audio},
+	}}
+
+	resp, err := g.generateContent(ctx, g.timeout, model, contents, &genai.GenerateContentConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+	g.limiter.recordRequest()
+	if resp.UsageMetadata != nil {
+		g.limiter.recordTokens(int(resp.UsageMetadata.TotalTokenCount))
+	}
+
+	return &provider.TranscriptionResult{Text: strings.TrimSpace(resp.Text()), Model: model}, nil
+}
+
+// HealthCheck looks up the first configured model, which verifies the API key without generating content
+func (g *GeminiProvider) HealthCheck(ctx context.Context) error {
+	ctx, done, err := g.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if len(g.models) == 0 {
+		return noModelsError(g.Name())
+	}
+
+	if _, err := g.client.GetModel(ctx, g.models[0]); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	return nil
+}
+
+// Warmup opens a connection to the Gemini API ahead of the first query. It doesn't count
+// against the rate limits.
+func (g *GeminiProvider) Warmup(ctx context.Context) error {
+	ctx, done, err := g.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+	return g.client.Warmup(ctx)
+}
+
+// Close closes the Gemini client and saves its final rate-limit counters to the RateStore, if any.
+// Later calls do nothing.
 func (g *GeminiProvider) Close() {
-	// The new genai client doesn't have a Close method
-	// Resources are managed automatically
+	if g.lifecycle.close() {
+		g.limiter.flush()
+		g.modelLimits.flush()
+	}
 }
 
 // HasRemainingRequests checks if the provider has remaining requests
 func (g *GeminiProvider) HasRemainingRequests(ctx context.Context) bool {
-	// Reset daily counter if needed
-	if time.Since(g.lastReset) > 24*time.Hour {
-		g.requestsToday = 0
-		g.lastReset = time.Now().Truncate(24 * time.Hour)
-	}
-	return g.maxDailyRequests == 0 || g.requestsToday < g.maxDailyRequests
+	return g.modelLimits.anyRemaining(g.limiter, (*rateLimiter).hasRemainingRequests)
 }
 
 // HasRemainingRequestsPerMinute checks if the provider has remaining requests per minute
 func (g *GeminiProvider) HasRemainingRequestsPerMinute(ctx context.Context) bool {
-	// Reset minute counter if needed
-	if time.Since(g.lastMinuteReset) > time.Minute {
-		g.requestsThisMinute = 0
-		g.tokensThisMinute = 0
-		g.lastMinuteReset = time.Now().Truncate(time.Minute)
-	}
-	return g.maxRequestsPerMinute == 0 || g.requestsThisMinute < g.maxRequestsPerMinute
+	return g.modelLimits.anyRemaining(g.limiter, (*rateLimiter).hasRemainingRequestsPerMinute)
 }
 
 // HasRemainingTokensPerMinute checks if the provider has remaining tokens per minute
 func (g *GeminiProvider) HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool {
-	// Reset minute counter if needed
-	if time.Since(g.lastMinuteReset) > time.Minute {
-		g.requestsThisMinute = 0
-		g.tokensThisMinute = 0
-		g.lastMinuteReset = time.Now().Truncate(time.Minute)
-	}
-	return g.maxTokensPerMinute == 0 || (g.tokensThisMinute+estimatedTokens) <= g.maxTokensPerMinute
+	return g.modelLimits.anyRemaining(g.limiter, func(l *rateLimiter) bool {
+		return l.hasRemainingTokensPerMinute(estimatedTokens)
+	})
+}
+
+// LastRawExchange returns the last raw request and response, or nil unless debug mode is enabled
+func (g *GeminiProvider) LastRawExchange() *provider.RawExchange {
+	return g.debug.lastExchange()
+}
+
+// Models returns the configured models
+func (g *GeminiProvider) Models() []string {
+	return append([]string(nil), g.models...)
+}
+
+// ResetLimits clears the provider's rate-limit counters
+func (g *GeminiProvider) ResetLimits() {
+	g.limiter.reset()
+	g.modelLimits.reset()
 }
 
 // GetRank returns the provider's rank
@@ -356,6 +1132,30 @@ func (g *GeminiProvider) GetRank() int {
 	return g.rank
 }
 
+// GetWeight returns the provider's load-balancing weight
+func (g *GeminiProvider) GetWeight() int {
+	return g.weight
+}
+
+// SupportsTools reports whether tool calls are enabled and at least one of the configured
+// models supports function calling
+func (g *GeminiProvider) SupportsTools() bool {
+	if g.toolsDisabled {
+		return false
+	}
+	for _, model := range g.models {
+		if geminiModelSupportsTools(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTimeout returns the provider's per-request timeout, 0 if none is configured
+func (g *GeminiProvider) GetTimeout() time.Duration {
+	return g.timeout
+}
+
 // Name returns the name of the provider
 func (g *GeminiProvider) Name() string {
 	return "Gemini"
@@ -0,0 +1,931 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/internal/retry"
+	"github.com/FramnkRulez/go-llm-router/provider"
+	"google.golang.org/genai"
+)
+
+// fakeGeminiAPI records requests and uploads instead of calling Gemini
+type fakeGeminiAPI struct {
+	mu        sync.Mutex
+	contents  [][]*genai.Content
+	configs   []*genai.GenerateContentConfig
+	uploads   []string
+	response  *genai.GenerateContentResponse
+	err       error
+	failures  []error // Returned by the first calls, one per call, before err or the response
+	fileState genai.FileState
+	delay     time.Duration
+	warmups   int
+
+	imageConfigs []*genai.GenerateImagesConfig
+	images       *genai.GenerateImagesResponse
+}
+
+func (f *fakeGeminiAPI) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contents = append(f.contents, contents)
+	f.configs = append(f.configs, config)
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		return nil, err
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.response != nil {
+		return f.response, nil
+	}
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "ok"}}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}, nil
+}
+
+func (f *fakeGeminiAPI) UploadFile(ctx context.Context, data []byte, mimeType string, displayName string) (*genai.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads = append(f.uploads, displayName)
+
+	state := f.fileState
+	if state == "" {
+		state = genai.FileStateActive
+	}
+	return &genai.File{Name: "files/" + displayName, URI: "https://files.example/" + displayName, MIMEType: mimeType, State: state}, nil
+}
+
+func (f *fakeGeminiAPI) GetFile(ctx context.Context, name string) (*genai.File, error) {
+	return &genai.File{Name: name, URI: "https://files.example/" + strings.TrimPrefix(name, "files/"), State: genai.FileStateActive}, nil
+}
+
+func (f *fakeGeminiAPI) EmbedContent(ctx context.Context, model string, contents []*genai.Content) (*genai.EmbedContentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contents = append(f.contents, contents)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	resp := &genai.EmbedContentResponse{}
+	for i := range contents {
+		resp.Embeddings = append(resp.Embeddings, &genai.ContentEmbedding{Values: []float32{float32(i), 0.5}})
+	}
+	return resp, nil
+}
+
+func (f *fakeGeminiAPI) Warmup(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.warmups++
+	return nil
+}
+
+func (f *fakeGeminiAPI) GetModel(ctx context.Context, model string) (*genai.Model, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &genai.Model{Name: "models/" + model}, nil
+}
+
+func (f *fakeGeminiAPI) GenerateImages(ctx context.Context, model string, prompt string, config *genai.GenerateImagesConfig) (*genai.GenerateImagesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.imageConfigs = append(f.imageConfigs, config)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.images, nil
+}
+
+// newTestGeminiProvider creates a Gemini provider backed by the fake client
+func newTestGeminiProvider(api *fakeGeminiAPI) *GeminiProvider {
+	return &GeminiProvider{
+		client:         api,
+		httpClient:     httpclient.New("go-llm-router-test"),
+		maxInlineBytes: geminiMaxInlineBytes,
+		models:         []string{"gemini-test"},
+		tokenEstimator: provider.DefaultTokenEstimator,
+		limiter:        newRateLimiter(0, 0, 0),
+		lifecycle:      newLifecycle(),
+	}
+}
+
+func TestGeminiProvider_RetriesTransientErrors(t *testing.T) {
+	api := &fakeGeminiAPI{failures: []error{
+		genai.APIError{Code: http.StatusServiceUnavailable, Status: "UNAVAILABLE"},
+		genai.APIError{Code: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"},
+	}}
+	g := newTestGeminiProvider(api)
+	g.retry = retry.New(provider.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	result, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Expected the request to succeed after retries, got %v", err)
+	}
+	if result.Content != "ok" || len(api.configs) != 3 {
+		t.Errorf("Expected 3 attempts ending in success, got %d attempts and %+v", len(api.configs), result)
+	}
+}
+
+func TestGeminiProvider_StopsOnAuthErrors(t *testing.T) {
+	api := &fakeGeminiAPI{err: genai.APIError{Code: http.StatusForbidden, Status: "PERMISSION_DENIED"}}
+	g := newTestGeminiProvider(api)
+	g.models = []string{"gemini-a", "gemini-b"}
+	g.retry = retry.New(provider.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	_, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		t.Fatalf("Expected the permission error, got %v", err)
+	}
+	if len(api.configs) != 1 {
+		t.Errorf("Expected no retries or other models after a permission error, got %d attempts", len(api.configs))
+	}
+}
+
+func TestGeminiProvider_RetryRespectsCancellation(t *testing.T) {
+	api := &fakeGeminiAPI{err: genai.APIError{Code: http.StatusServiceUnavailable}}
+	g := newTestGeminiProvider(api)
+	g.retry = retry.New(provider.RetryConfig{MaxAttempts: 5, BaseDelay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := g.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || len(api.configs) != 1 {
+		t.Errorf("Expected the backoff to stop when the context is done, got %d attempts in %v", len(api.configs), elapsed)
+	}
+}
+
+func TestGeminiProvider_SupportsTools(t *testing.T) {
+	g := newTestGeminiProvider(&fakeGeminiAPI{})
+	g.models = []string{"gemini-1.0-pro", "gemini-2.0-flash"}
+	if !g.SupportsTools() {
+		t.Error("Expected tool support when one of the models supports function calling")
+	}
+
+	g.models = []string{"gemini-1.0-pro", "models/gemma-3-27b-it"}
+	if g.SupportsTools() {
+		t.Error("Expected no tool support when none of the models supports function calling")
+	}
+
+	g.models = []string{"gemini-2.0-flash"}
+	g.toolsDisabled = true
+	if g.SupportsTools() {
+		t.Error("Expected DisableTools to turn tool support off")
+	}
+}
+
+func TestGeminiProvider_ToolsWithNonToolModel(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+	g.models = []string{"gemini-2.0-flash"}
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+
+	_, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "Weather?"}}, provider.QueryOptions{
+		Tools:      tools,
+		ForceModel: "gemini-1.0-pro",
+	})
+	if !errors.Is(err, provider.ErrToolsNotSupported) || !strings.Contains(err.Error(), "gemini-1.0-pro") {
+		t.Errorf("Expected ErrToolsNotSupported naming the model, got %v", err)
+	}
+	if len(api.contents) != 0 {
+		t.Errorf("Expected no request to a model that can't call functions, got %d", len(api.contents))
+	}
+
+	// Without a forced model, models that can't call functions are passed over
+	g.models = []string{"gemma-3-27b-it", "gemini-2.0-flash"}
+	result, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "Weather?"}}, provider.QueryOptions{Tools: tools})
+	if err != nil || result.Model != "gemini-2.0-flash" {
+		t.Errorf("Expected the tool-capable model to answer, got %+v (%v)", result, err)
+	}
+}
+
+func TestGeminiProvider_Close(t *testing.T) {
+	g := newTestGeminiProvider(&fakeGeminiAPI{})
+	g.Close()
+	g.Close()
+
+	if _, _, err := g.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, ""); !errors.Is(err, provider.ErrProviderClosed) {
+		t.Errorf("Expected ErrProviderClosed after Close, got %v", err)
+	}
+	if _, err := g.Embeddings(context.Background(), provider.EmbeddingRequest{Input: []string{"hi"}}); !errors.Is(err, provider.ErrProviderClosed) {
+		t.Errorf("Expected ErrProviderClosed from Embeddings after Close, got %v", err)
+	}
+}
+
+func TestGeminiProvider_Seed(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: &genai.Content{Parts: []*genai.Part{{Text: "ok"}}},
+	}}}}
+	g := newTestGeminiProvider(api)
+
+	seed := 7
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Seed: &seed}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if api.configs[0].Seed == nil || *api.configs[0].Seed != 7 {
+		t.Errorf("Expected seed 7 in the generation config, got %v", api.configs[0].Seed)
+	}
+	if api.configs[1].Seed != nil {
+		t.Errorf("Expected no seed when unset, got %v", *api.configs[1].Seed)
+	}
+}
+
+func TestGeminiProvider_ThinkingBudget(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for _, budget := range []int{1024, 0} {
+		if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ThinkingBudget: &budget}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ReasoningEffort: "high"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, want := range []int32{1024, 0} {
+		thinking := api.configs[i].ThinkingConfig
+		if thinking == nil || thinking.ThinkingBudget == nil || *thinking.ThinkingBudget != want {
+			t.Errorf("Expected a thinking budget of %d in the generation config, got %+v", want, thinking)
+		}
+	}
+	if api.configs[2].ThinkingConfig != nil {
+		t.Errorf("Expected no thinking config without a budget, got %+v", api.configs[2].ThinkingConfig)
+	}
+}
+
+func TestGeminiProvider_JSONMode(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := []provider.Message{{Role: "user", Content: "Reply in JSON"}}
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+	for _, options := range []provider.QueryOptions{{JSONMode: true}, {JSONMode: true, Tools: tools}, {}} {
+		if _, err := g.QueryWithOptions(context.Background(), messages, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if mime := api.configs[0].ResponseMIMEType; mime != "application/json" {
+		t.Errorf("Expected a JSON response MIME type, got %q", mime)
+	}
+	if mime := api.configs[1].ResponseMIMEType; mime != "" {
+		t.Errorf("Expected no response MIME type with tools, got %q", mime)
+	}
+	if mime := api.configs[2].ResponseMIMEType; mime != "" {
+		t.Errorf("Expected no response MIME type without JSONMode, got %q", mime)
+	}
+}
+
+func TestGeminiProvider_TemperatureRange(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+	g.models = []string{"gemini-1.0-pro", "gemini-2.0-flash"}
+
+	// 1.5 is too high for Gemini 1.0 but fine for later models
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	result, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: 1.5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Model != "gemini-2.0-flash" || len(api.configs) != 1 || *api.configs[0].Temperature != 1.5 {
+		t.Errorf("Expected the query to fall through to gemini-2.0-flash, got %q with %d requests", result.Model, len(api.configs))
+	}
+
+	_, err = g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: 3})
+	if !errors.Is(err, provider.ErrTemperatureOutOfRange) {
+		t.Errorf("Expected ErrTemperatureOutOfRange, got %v", err)
+	}
+	if len(api.configs) != 1 {
+		t.Errorf("Expected the rejected temperature not to be sent, got %d requests", len(api.configs))
+	}
+
+	g.clampTemp = true
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: 3, ForceModel: "gemini-1.0-pro"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if temperature := api.configs[1].Temperature; temperature == nil || *temperature != 1 {
+		t.Errorf("Expected the temperature clamped to 1 for Gemini 1.0, got %v", temperature)
+	}
+}
+
+func TestGeminiProvider_ToolChoice(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: &genai.Content{Parts: []*genai.Part{{Text: "ok"}}},
+	}}}}
+	g := newTestGeminiProvider(api)
+
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+	for _, choice := range []string{"auto", "none", "get_weather"} {
+		options := provider.QueryOptions{Tools: tools, ToolChoice: choice}
+		if _, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	expected := []genai.FunctionCallingConfigMode{genai.FunctionCallingConfigModeAuto, genai.FunctionCallingConfigModeNone, genai.FunctionCallingConfigModeAny}
+	for i, mode := range expected {
+		config := api.configs[i].ToolConfig.FunctionCallingConfig
+		if config.Mode != mode {
+			t.Errorf("Expected mode %s, got %s", mode, config.Mode)
+		}
+	}
+	if names := api.configs[2].ToolConfig.FunctionCallingConfig.AllowedFunctionNames; len(names) != 1 || names[0] != "get_weather" {
+		t.Errorf("Expected calls restricted to get_weather, got %v", names)
+	}
+}
+
+func TestGeminiProvider_NoModels(t *testing.T) {
+	g := newTestGeminiProvider(&fakeGeminiAPI{})
+	g.models = nil
+
+	_, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err == nil || err.Error() != "no models configured for provider Gemini" {
+		t.Errorf("Expected a no models error, got %v", err)
+	}
+}
+
+func TestGeminiClientConfig_Vertex(t *testing.T) {
+	config, err := geminiClientConfig(provider.Config{APIKey: "ignored"}, &VertexConfig{Project: "my-project", Location: "us-central1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Backend != genai.BackendVertexAI || config.Project != "my-project" || config.Location != "us-central1" {
+		t.Errorf("Expected the Vertex AI backend with the project and location, got %+v", config)
+	}
+	if config.APIKey != "" {
+		t.Errorf("Expected the API key to be ignored with Vertex AI, got %q", config.APIKey)
+	}
+
+	if _, err := geminiClientConfig(provider.Config{}, &VertexConfig{Project: "my-project"}); err == nil {
+		t.Error("Expected an error without a location")
+	}
+
+	config, err = geminiClientConfig(provider.Config{APIKey: "test-key"}, nil)
+	if err != nil || config.Backend != genai.BackendGeminiAPI || config.APIKey != "test-key" {
+		t.Errorf("Expected the Gemini API backend by default, got %+v (%v)", config, err)
+	}
+}
+
+func TestGeminiProvider_PerMinuteLimitsAndRank(t *testing.T) {
+	p, err := newGeminiProvider(provider.Config{
+		APIKey:               "test-key",
+		Models:               []string{"gemini-test"},
+		MaxRequestsPerMinute: 2,
+		MaxTokensPerMinute:   1000,
+		Rank:                 7,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	g := p.(*GeminiProvider)
+	g.client = &fakeGeminiAPI{}
+
+	if g.GetRank() != 7 {
+		t.Errorf("Expected configured rank 7, got %d", g.GetRank())
+	}
+
+	ctx := context.Background()
+	messages := []provider.Message{{Role: "user", Content: strings.Repeat("word ", 40)}} // 50 estimated tokens
+	for i := 0; i < 2; i++ {
+		if !g.HasRemainingRequestsPerMinute(ctx) {
+			t.Fatalf("Expected request %d to be within the per-minute limit", i+1)
+		}
+		if _, err := g.QueryWithOptions(ctx, messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if g.HasRemainingRequestsPerMinute(ctx) {
+		t.Error("Expected the per-minute request limit to be reached")
+	}
+	if !g.HasRemainingRequests(ctx) {
+		t.Error("Expected no daily limit when none is configured")
+	}
+	if g.HasRemainingTokensPerMinute(ctx, 901) {
+		t.Error("Expected recorded tokens to count against the per-minute token limit")
+	}
+	if !g.HasRemainingTokensPerMinute(ctx, 900) {
+		t.Error("Expected a small request to fit in the remaining token budget")
+	}
+}
+
+func TestGeminiProvider_Timeout(t *testing.T) {
+	g := newTestGeminiProvider(&fakeGeminiAPI{delay: time.Second})
+	g.timeout = 10 * time.Millisecond
+
+	start := time.Now()
+	_, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the request to be cut short by the timeout, took %v", elapsed)
+	}
+}
+
+func TestGeminiProvider_ThoughtParts(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{Role: "model", Parts: []*genai.Part{
+				{Text: "The user wants a sum. 2 + 2 = 4.", Thought: true},
+				{Text: "4"},
+			}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}}
+	g := newTestGeminiProvider(api)
+
+	result, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What is 2 + 2?"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "4" || result.Reasoning != "The user wants a sum. 2 + 2 = 4." {
+		t.Errorf("Expected thought parts in Reasoning and the answer in Content, got %+v", result)
+	}
+}
+
+func TestGeminiProvider_SafetyBlock(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model"},
+			FinishReason: genai.FinishReasonSafety,
+			SafetyRatings: []*genai.SafetyRating{
+				{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityHigh},
+				{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityNegligible},
+			},
+		}},
+	}}
+	g := newTestGeminiProvider(api)
+
+	_, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	var blockErr *provider.ContentBlockedError
+	if !errors.As(err, &blockErr) {
+		t.Fatalf("Expected a ContentBlockedError, got %v", err)
+	}
+	if blockErr.Reason != "SAFETY" || blockErr.Model != "gemini-test" {
+		t.Errorf("Unexpected block error: %+v", blockErr)
+	}
+	if !strings.Contains(err.Error(), "SAFETY") || !strings.Contains(err.Error(), string(genai.HarmCategoryDangerousContent)) || strings.Contains(err.Error(), string(genai.HarmCategoryHarassment)) {
+		t.Errorf("Expected the reason and the blocked category in the error, got %v", err)
+	}
+}
+
+func TestGeminiProvider_PromptBlocked(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonSafety},
+	}}
+	g := newTestGeminiProvider(api)
+
+	_, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	var blockErr *provider.ContentBlockedError
+	if !errors.As(err, &blockErr) || blockErr.Reason != "SAFETY" {
+		t.Fatalf("Expected a ContentBlockedError for the blocked prompt, got %v", err)
+	}
+}
+
+func TestGeminiProvider_RecitationWithContentIsNotBlocked(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "partial answer"}}},
+			FinishReason: genai.FinishReasonRecitation,
+		}},
+	}}
+	g := newTestGeminiProvider(api)
+
+	result, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "partial answer" || result.FinishReason != "RECITATION" {
+		t.Errorf("Expected the partial answer to be returned, got %+v", result)
+	}
+}
+
+func TestGeminiProvider_MultipleCandidates(t *testing.T) {
+	candidate := func(text string) *genai.Candidate {
+		return &genai.Candidate{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{Text: text}}},
+			FinishReason: genai.FinishReasonStop,
+		}
+	}
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{candidate("first"), candidate("second"), candidate("third")},
+	}}
+	g := newTestGeminiProvider(api)
+
+	result, err := g.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{N: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if api.configs[0].CandidateCount != 3 {
+		t.Errorf("Expected CandidateCount 3, got %d", api.configs[0].CandidateCount)
+	}
+	if len(result.Completions) != 3 || result.Completions[2].Content != "third" {
+		t.Fatalf("Expected three completions, got %+v", result.Completions)
+	}
+	if result.Content != "first" {
+		t.Errorf("Expected the first candidate as the result content, got %q", result.Content)
+	}
+}
+
+func TestGeminiProvider_InlinePDF(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	pdf := []byte("%PDF-1.4 test document")
+	messages := []provider.Message{{
+		Role:    "user",
+		Content: "Summarize this",
+		Files:   []provider.File{{Name: "report.pdf", MimeType: "application/pdf", Type: "document", Data: pdf}},
+	}}
+
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parts := api.contents[0][0].Parts
+	if len(parts) != 2 {
+		t.Fatalf("Expected text and file parts, got %d parts", len(parts))
+	}
+	if parts[0].Text != "Summarize this" {
+		t.Errorf("Expected text part first, got %+v", parts[0])
+	}
+	if parts[1].InlineData == nil || parts[1].InlineData.MIMEType != "application/pdf" || string(parts[1].InlineData.Data) != string(pdf) {
+		t.Errorf("Expected inline PDF blob, got %+v", parts[1])
+	}
+	if len(api.uploads) != 0 {
+		t.Errorf("Expected small PDF not to be uploaded, got uploads %v", api.uploads)
+	}
+}
+
+func TestGeminiProvider_UploadsLargeDocuments(t *testing.T) {
+	api := &fakeGeminiAPI{fileState: genai.FileStateProcessing}
+	g := newTestGeminiProvider(api)
+	g.maxInlineBytes = 8
+
+	pollInterval := geminiFilePollInterval
+	geminiFilePollInterval = 0
+	defer func() { geminiFilePollInterval = pollInterval }()
+
+	messages := []provider.Message{{
+		Role:  "user",
+		Files: []provider.File{{Name: "big.pdf", MimeType: "application/pdf", Type: "document", Data: []byte("%PDF-1.4 larger than the limit")}},
+	}}
+
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(api.uploads) != 1 || api.uploads[0] != "big.pdf" {
+		t.Fatalf("Expected the PDF to be uploaded once, got %v", api.uploads)
+	}
+	part := api.contents[0][0].Parts[0]
+	if part.FileData == nil || part.FileData.FileURI != "https://files.example/big.pdf" || part.FileData.MIMEType != "application/pdf" {
+		t.Errorf("Expected file data part referencing the upload, got %+v", part)
+	}
+}
+
+func TestGeminiProvider_UnsupportedFileType(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := []provider.Message{{
+		Role:  "user",
+		Files: []provider.File{{Name: "archive.zip", MimeType: "application/zip", Type: "document", Data: []byte("PK")}},
+	}}
+
+	_, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if err == nil || !strings.Contains(err.Error(), "archive.zip") {
+		t.Fatalf("Expected unsupported file type error naming the file, got %v", err)
+	}
+	if len(api.contents) != 0 {
+		t.Error("Expected no request to be sent for an unsupported file")
+	}
+}
+
+func TestGeminiProvider_FetchesURLAttachments(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\n fake image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+	}))
+	defer server.Close()
+
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := []provider.Message{{
+		Role:  "user",
+		Files: []provider.File{{Type: "image", Name: "cat.png", URL: server.URL + "/cat.png"}},
+	}}
+
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	part := api.contents[0][0].Parts[0]
+	if part.InlineData == nil || string(part.InlineData.Data) != string(image) || part.InlineData.MIMEType != "image/png" {
+		t.Errorf("Expected fetched image to be sent inline, got %+v", part)
+	}
+}
+
+func TestGeminiProvider_SystemInstruction(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := []provider.Message{
+		{Role: "system", Content: "Legacy system message."},
+		{Role: "user", Content: "hi"},
+	}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{SystemPrompt: "Answer in French."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	instruction := api.configs[0].SystemInstruction
+	if instruction == nil || len(instruction.Parts) != 1 {
+		t.Fatalf("Expected system prompt and system message in the system instruction, got %+v", instruction)
+	}
+	if text := instruction.Parts[0].Text; text != "Answer in French.\n\nLegacy system message." {
+		t.Errorf("Unexpected system instruction: %q", text)
+	}
+
+	contents := api.contents[0]
+	if len(contents) != 1 || contents[0].Role != "user" || contents[0].Parts[0].Text != "hi" {
+		t.Errorf("Expected only the user turn in the conversation, got %+v", contents)
+	}
+}
+
+func TestGeminiProvider_MergesSystemMessages(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := []provider.Message{
+		{Role: "system", Content: "You are a travel agent."},
+		{Role: "system", Content: "Only suggest trains."},
+		{Role: "user", Content: "How do I get to Lyon?"},
+	}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	instruction := api.configs[0].SystemInstruction
+	if instruction == nil || len(instruction.Parts) != 1 {
+		t.Fatalf("Expected a single merged system instruction, got %+v", instruction)
+	}
+	if text := instruction.Parts[0].Text; text != "You are a travel agent.\n\nOnly suggest trains." {
+		t.Errorf("Unexpected system instruction: %q", text)
+	}
+	if contents := api.contents[0]; len(contents) != 1 || contents[0].Role != "user" {
+		t.Errorf("Expected the system messages to be left out of the conversation, got %+v", contents)
+	}
+}
+
+func TestGeminiProvider_RoleMap(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+	g.roleMap = map[string]string{"critic": "model"}
+
+	messages := []provider.Message{
+		{Role: "user", Content: "Draft a title."},
+		{Role: "critic", Content: "Too long."},
+		{Role: "assistant", Content: "Shorter title."},
+	}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	contents := api.contents[0]
+	if contents[0].Role != "user" || contents[1].Role != "model" || contents[2].Role != "model" {
+		t.Errorf("Expected roles user, model, model, got %s, %s, %s", contents[0].Role, contents[1].Role, contents[2].Role)
+	}
+
+	// A mapping to a role Gemini doesn't have is rejected before sending
+	g.roleMap = map[string]string{"user": "developer"}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err == nil {
+		t.Error("Expected an error for a role mapped to one Gemini doesn't support")
+	}
+	if len(api.contents) != 1 {
+		t.Errorf("Expected no request with an invalid role, got %d", len(api.contents))
+	}
+}
+
+func TestGeminiProvider_ToolCallHistory(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := append(toolCallHistory(), provider.Message{Role: "tool", ToolCallID: "get_time", Content: "12:00"})
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	contents := api.contents[0]
+	if len(contents) != 4 {
+		t.Fatalf("Expected 4 contents, got %d", len(contents))
+	}
+
+	call := contents[1].Parts[0].FunctionCall
+	if contents[1].Role != "model" || call == nil || call.Name != "get_weather" || call.Args["city"] != "Paris" {
+		t.Errorf("Expected the assistant's tool call as a function call part, got %+v", contents[1])
+	}
+
+	response := contents[2].Parts[0].FunctionResponse
+	if contents[2].Role != "user" || response == nil || response.Name != "get_weather" || response.Response["temperature"] != float64(21) {
+		t.Errorf("Expected the tool result as a function response named after its call, got %+v", contents[2])
+	}
+
+	// Calls made by Gemini use their name as id, and non-JSON results are wrapped
+	response = contents[3].Parts[0].FunctionResponse
+	if response == nil || response.Name != "get_time" || response.Response["output"] != "12:00" {
+		t.Errorf("Expected a wrapped function response, got %+v", contents[3])
+	}
+}
+
+func TestGeminiProvider_ToolRoleFunctionResponse(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	messages := append(toolCallHistory(), provider.Message{Role: "user", Content: "And tomorrow?"})
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	contents := api.contents[0]
+	if len(contents) != 4 {
+		t.Fatalf("Expected 4 contents, got %d", len(contents))
+	}
+	result := contents[2]
+	if result.Role != "user" || len(result.Parts) != 1 || result.Parts[0].FunctionResponse == nil || result.Parts[0].Text != "" {
+		t.Errorf("Expected the tool message as a lone function response part in a user turn, got %+v", result)
+	}
+	if contents[3].Role != "user" || contents[3].Parts[0].Text != "And tomorrow?" {
+		t.Errorf("Expected the conversation to continue after the function response, got %+v", contents[3])
+	}
+
+	// A tool message that can't be matched to its call is rejected before sending
+	messages = []provider.Message{{Role: "user", Content: "hi"}, {Role: "tool", Content: "12:00"}}
+	if _, err := g.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err == nil || !strings.Contains(err.Error(), "ToolCallID") {
+		t.Errorf("Expected an error for a tool message without a ToolCallID, got %v", err)
+	}
+	if len(api.contents) != 1 {
+		t.Errorf("Expected no request for the invalid tool message, got %d", len(api.contents))
+	}
+}
+
+func TestGeminiProvider_IncludeRawResponse(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		ModelVersion: "gemini-test-001",
+		Candidates: []*genai.Candidate{{
+			Content:           &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "ok"}}},
+			FinishReason:      genai.FinishReasonStop,
+			GroundingMetadata: &genai.GroundingMetadata{WebSearchQueries: []string{"weather paris"}},
+		}},
+	}}
+	p := newTestGeminiProvider(api)
+	p.includeRaw = true
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var raw genai.GenerateContentResponse
+	if err := json.Unmarshal(result.Raw, &raw); err != nil {
+		t.Fatalf("Expected the raw response to be JSON, got %s (%v)", result.Raw, err)
+	}
+	if raw.ModelVersion != "gemini-test-001" || len(raw.Candidates) != 1 || raw.Candidates[0].GroundingMetadata == nil ||
+		raw.Candidates[0].GroundingMetadata.WebSearchQueries[0] != "weather paris" {
+		t.Errorf("Expected the SDK response to round-trip, got %s", result.Raw)
+	}
+}
+
+func TestGeminiProvider_GenerateImage(t *testing.T) {
+	api := &fakeGeminiAPI{images: &genai.GenerateImagesResponse{GeneratedImages: []*genai.GeneratedImage{
+		{Image: &genai.Image{ImageBytes: []byte("png-1"), MIMEType: "image/png"}},
+		{Image: &genai.Image{ImageBytes: []byte("png-2"), MIMEType: "image/png"}},
+	}}}
+	g := newTestGeminiProvider(api)
+
+	result, err := g.GenerateImage(context.Background(), provider.ImageRequest{Prompt: "a lighthouse", Size: "1792x1024", N: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config := api.imageConfigs[0]; config.NumberOfImages != 2 || config.AspectRatio != "16:9" {
+		t.Errorf("Expected 2 images at 16:9, got %+v", config)
+	}
+	if result.Model != geminiDefaultImageModel || len(result.Images) != 2 || string(result.Images[1].Data) != "png-2" || result.Images[0].Type != "image" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if _, err := g.GenerateImage(context.Background(), provider.ImageRequest{Prompt: "a lighthouse", Size: "large"}); err == nil {
+		t.Error("Expected an error for an invalid size")
+	}
+}
+
+func TestGeminiProvider_GenerateImageFiltered(t *testing.T) {
+	api := &fakeGeminiAPI{images: &genai.GenerateImagesResponse{GeneratedImages: []*genai.GeneratedImage{
+		{RAIFilteredReason: "filtered for violence"},
+	}}}
+	g := newTestGeminiProvider(api)
+
+	_, err := g.GenerateImage(context.Background(), provider.ImageRequest{Prompt: "a battle"})
+	var blockErr *provider.ContentBlockedError
+	if !errors.As(err, &blockErr) || blockErr.Detail != "filtered for violence" {
+		t.Errorf("Expected a ContentBlockedError with the filter reason, got %v", err)
+	}
+}
+
+func TestGeminiProvider_Transcribe(t *testing.T) {
+	api := &fakeGeminiAPI{response: &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "Bonjour tout le monde.\n"}}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}}
+	g := newTestGeminiProvider(api)
+
+	result, err := g.Transcribe(context.Background(), provider.TranscriptionRequest{
+		Audio:    provider.File{Type: "audio", Name: "hello.wav", MimeType: "audio/wav", Data: []byte("wav-bytes")},
+		Language: "fr",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Text != "Bonjour tout le monde." || result.Model != "gemini-test" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	parts := api.contents[0][0].Parts
+	if len(parts) != 2 || !strings.Contains(parts[0].Text, "fr") || parts[1].InlineData == nil || parts[1].InlineData.MIMEType != "audio/wav" {
+		t.Errorf("Expected the instruction and the inline audio, got %+v", parts)
+	}
+}
+
+func TestGeminiProvider_HealthCheck(t *testing.T) {
+	g := newTestGeminiProvider(&fakeGeminiAPI{})
+	if err := g.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Expected healthy provider, got %v", err)
+	}
+
+	g = newTestGeminiProvider(&fakeGeminiAPI{err: errors.New("API key not valid")})
+	if err := g.HealthCheck(context.Background()); err == nil || !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("Expected the client error to be reported, got %v", err)
+	}
+}
+
+func TestGeminiProvider_Embeddings(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	g := newTestGeminiProvider(api)
+
+	result, err := g.Embeddings(context.Background(), provider.EmbeddingRequest{Input: []string{"first", "second"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Model != geminiDefaultEmbeddingModel {
+		t.Errorf("Expected default embedding model, got %q", result.Model)
+	}
+	if len(result.Vectors) != 2 || result.Vectors[1][0] != 1 {
+		t.Errorf("Expected one vector per input in order, got %v", result.Vectors)
+	}
+	if len(api.contents[0]) != 2 || api.contents[0][1].Parts[0].Text != "second" {
+		t.Errorf("Expected each input to be sent as its own content, got %+v", api.contents[0])
+	}
+}
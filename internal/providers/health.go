@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+)
+
+// modelsURL derives an OpenAI-compatible models endpoint from a chat completions endpoint.
+// It returns an empty string if the endpoint doesn't follow the OpenAI layout.
+func modelsURL(chatURL string) string {
+	if strings.HasSuffix(chatURL, "/chat/completions") {
+		return strings.TrimSuffix(chatURL, "/chat/completions") + "/models"
+	}
+	return ""
+}
+
+// checkHealthGET makes a GET request and returns an error unless it succeeds with a 2xx status
+func checkHealthGET(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration) error {
+	resp, _, err := client.Do(ctx, url, "GET", headers, nil, timeout)
+	if err != nil {
+		return fmt.Errorf("health check request failed: %w", err)
+	}
+	return checkHealthResponse(resp)
+}
+
+// checkHealthCompletion sends a one-token completion to a chat completions endpoint
+func checkHealthCompletion(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration, model string) error {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model":      model,
+		"messages":   []map[string]interface{}{{"role": "user", "content": "ping"}},
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, _, err := client.Do(ctx, url, "POST", headers, bytes.NewBuffer(jsonData), timeout)
+	if err != nil {
+		return fmt.Errorf("health check request failed: %w", err)
+	}
+	return checkHealthResponse(resp)
+}
+
+// checkHealthResponse closes the response and turns a non-2xx status into an error
+func checkHealthResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("health check failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,196 @@
+package providers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+
+	_ "image/gif" // Register decoders for the formats image.Decode accepts
+	_ "image/png"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+const (
+	defaultMaxImageDimension = 2048
+	defaultMaxImageBytes     = 5 << 20
+
+	// Resized images are encoded at the first quality that fits within the byte limit
+	maxJPEGQuality = 85
+	minJPEGQuality = 40
+)
+
+// imageResizer downscales attached images that exceed its limits and re-encodes them as JPEG.
+// A nil resizer leaves messages unchanged.
+type imageResizer struct {
+	maxDimension int
+	maxBytes     int
+}
+
+// newImageResizer returns the resizer described by the config, or nil unless AutoResizeImages is set
+func newImageResizer(config provider.Config) *imageResizer {
+	if !config.AutoResizeImages {
+		return nil
+	}
+	r := &imageResizer{maxDimension: config.MaxImageDimension, maxBytes: config.MaxImageBytes}
+	if r.maxDimension <= 0 {
+		r.maxDimension = defaultMaxImageDimension
+	}
+	if r.maxBytes <= 0 {
+		r.maxBytes = defaultMaxImageBytes
+	}
+	return r
+}
+
+// apply returns the messages with oversized images replaced by resized copies. The caller's
+// messages are not modified. Files that aren't images, are passed by URL or can't be decoded are
+// sent as they are.
+func (r *imageResizer) apply(messages []provider.Message) ([]provider.Message, error) {
+	if r == nil {
+		return messages, nil
+	}
+
+	resized := messages
+	copied := false
+	for i, message := range messages {
+		var files []provider.File
+		for j, file := range message.Files {
+			replacement, ok, err := r.resize(file)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if files == nil {
+				files = append([]provider.File(nil), message.Files...)
+			}
+			files[j] = replacement
+		}
+		if files == nil {
+			continue
+		}
+		if !copied {
+			resized = append([]provider.Message(nil), messages...)
+			copied = true
+		}
+		resized[i].Files = files
+	}
+	return resized, nil
+}
+
+// resize returns a JPEG copy of the file within the limits, and false if the file doesn't need it
+func (r *imageResizer) resize(file provider.File) (provider.File, bool, error) {
+	if len(file.Data) == 0 || !isImageFile(file) {
+		return file, false, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(file.Data))
+	if err != nil {
+		// Formats without a registered decoder (e.g. WebP) are left for the provider to handle
+		return file, false, nil
+	}
+	if config.Width <= r.maxDimension && config.Height <= r.maxDimension && len(file.Data) <= r.maxBytes {
+		return file, false, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(file.Data))
+	if err != nil {
+		return file, false, nil
+	}
+
+	width, height := fitWithin(config.Width, config.Height, r.maxDimension)
+	for {
+		scaled := downscale(img, width, height)
+		for quality := maxJPEGQuality; quality >= minJPEGQuality; quality -= 15 {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality}); err != nil {
+				return file, false, fmt.Errorf("failed to encode image %s: %w", file.Name, err)
+			}
+			if buf.Len() <= r.maxBytes {
+				return provider.File{
+					Type:     file.Type,
+					Data:     buf.Bytes(),
+					MimeType: "image/jpeg",
+					Name:     jpegName(file.Name),
+				}, true, nil
+			}
+		}
+		if width <= 1 && height <= 1 {
+			return file, false, fmt.Errorf("image %s does not fit in %d bytes", file.Name, r.maxBytes)
+		}
+		// Still too large at the lowest quality; shrink further
+		width, height = max(width*3/4, 1), max(height*3/4, 1)
+	}
+}
+
+// isImageFile reports whether the attachment is an image by its MIME type, or by its type when
+// the MIME type is unset
+func isImageFile(file provider.File) bool {
+	if file.MimeType != "" {
+		return strings.HasPrefix(file.MimeType, "image/")
+	}
+	return file.Type == "image"
+}
+
+// fitWithin scales width and height down, keeping the aspect ratio, so neither exceeds limit
+func fitWithin(width, height, limit int) (int, int) {
+	if width <= limit && height <= limit {
+		return width, height
+	}
+	if width >= height {
+		return limit, max(height*limit/width, 1)
+	}
+	return max(width*limit/height, 1), limit
+}
+
+// downscale resizes img to width x height, at most its own size, by averaging the source pixels
+// that fall in each target pixel. Transparent areas are composited onto white, since JPEG has no
+// alpha channel.
+func downscale(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/height
+		y1 := max(bounds.Min.Y+(y+1)*srcHeight/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcWidth/width
+			x1 := max(bounds.Min.X+(x+1)*srcWidth/width, x0+1)
+
+			var red, green, blue, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// Premultiplied colors over white: c + (1 - alpha)
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					red += uint64(cr + 0xffff - ca)
+					green += uint64(cg + 0xffff - ca)
+					blue += uint64(cb + 0xffff - ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(red / n >> 8),
+				G: uint8(green / n >> 8),
+				B: uint8(blue / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
+
+// jpegName replaces the file name's extension with .jpg
+func jpegName(name string) string {
+	if name == "" {
+		return ""
+	}
+	if dot := strings.LastIndex(name, "."); dot > 0 {
+		name = name[:dot]
+	}
+	return name + ".jpg"
+}
@@ -0,0 +1,79 @@
+package providers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// noisyPNG encodes a random image, which compresses poorly and so stays large in bytes
+func noisyPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageResizer_ShrinksOversizedImages(t *testing.T) {
+	data := noisyPNG(t, 1600, 1000)
+	resizer := newImageResizer(provider.Config{AutoResizeImages: true, MaxImageDimension: 800, MaxImageBytes: 100_000})
+
+	messages := []provider.Message{{Role: "user", Content: "What's this?", Files: []provider.File{
+		{Type: "image", Data: data, MimeType: "image/png", Name: "photo.png"},
+		{Type: "document", Data: []byte("%PDF-1.4"), MimeType: "application/pdf", Name: "notes.pdf"},
+	}}}
+	resized, err := resizer.apply(messages)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	file := resized[0].Files[0]
+	if file.MimeType != "image/jpeg" || file.Name != "photo.jpg" {
+		t.Errorf("Expected a JPEG, got %s %s", file.MimeType, file.Name)
+	}
+	if len(file.Data) > 100_000 {
+		t.Errorf("Expected at most 100000 bytes, got %d", len(file.Data))
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(file.Data))
+	if err != nil || format != "jpeg" {
+		t.Fatalf("Expected a decodable JPEG, got %q (%v)", format, err)
+	}
+	if config.Width > 800 || config.Height > 800 || config.Width < config.Height {
+		t.Errorf("Expected the image to fit in 800 pixels with its aspect ratio, got %dx%d", config.Width, config.Height)
+	}
+
+	if string(resized[0].Files[1].Data) != "%PDF-1.4" {
+		t.Errorf("Expected non-image files to be left unchanged")
+	}
+	if !bytes.Equal(messages[0].Files[0].Data, data) {
+		t.Errorf("Expected the caller's messages not to be modified")
+	}
+}
+
+func TestImageResizer_LeavesSmallImagesAlone(t *testing.T) {
+	data := noisyPNG(t, 64, 64)
+	messages := []provider.Message{{Role: "user", Files: []provider.File{{Type: "image", Data: data, MimeType: "image/png"}}}}
+
+	resized, err := newImageResizer(provider.Config{AutoResizeImages: true}).apply(messages)
+	if err != nil || resized[0].Files[0].MimeType != "image/png" {
+		t.Errorf("Expected an image within the limits to be sent as is, got %+v (%v)", resized[0].Files[0].MimeType, err)
+	}
+
+	if newImageResizer(provider.Config{MaxImageDimension: 16}) != nil {
+		t.Errorf("Expected resizing to be off unless AutoResizeImages is set")
+	}
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// openAIDefaultImageModel is used when an image request doesn't name a model
+const openAIDefaultImageModel = "gpt-image-1"
+
+// imagesURL derives an OpenAI-compatible image generation endpoint from a chat completions endpoint.
+// It returns an empty string if the endpoint doesn't follow the OpenAI layout.
+func imagesURL(chatURL string) string {
+	if strings.HasSuffix(chatURL, "/chat/completions") {
+		return strings.TrimSuffix(chatURL, "/chat/completions") + "/images/generations"
+	}
+	return ""
+}
+
+// requestOpenAIImages calls an OpenAI-compatible /images/generations endpoint
+func requestOpenAIImages(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration, request provider.ImageRequest) (*provider.ImageResult, error) {
+	if request.Prompt == "" {
+		return nil, fmt.Errorf("image prompt is empty")
+	}
+
+	model := request.Model
+	if model == "" {
+		model = openAIDefaultImageModel
+	}
+	body := map[string]interface{}{
+		"model":  model,
+		"prompt": request.Prompt,
+	}
+	if request.N > 1 {
+		body["n"] = request.N
+	}
+	if request.Size != "" {
+		body["size"] = request.Size
+	}
+	// DALL·E returns URLs by default; newer models always return base64 data and reject the field
+	if strings.HasPrefix(model, "dall-e") {
+		body["response_format"] = "b64_json"
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, _, err := client.Do(ctx, url, "POST", headers, bytes.NewBuffer(jsonData), timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, provider.NewAPIError(resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+			URL     string `json:"url"`
+		} `json:"data"`
+		OutputFormat string `json:"output_format"`
+		Usage        *struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("no images received")
+	}
+
+	format := result.OutputFormat
+	if format == "" {
+		format = "png"
+	}
+	images := make([]provider.File, 0, len(result.Data))
+	for i, image := range result.Data {
+		file := provider.File{
+			Type:     "image",
+			MimeType: "image/" + format,
+			Name:     "image-" + strconv.Itoa(i+1) + "." + format,
+			URL:      image.URL,
+		}
+		if image.B64JSON != "" {
+			data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image %d: %w", i+1, err)
+			}
+			file.Data = data
+		}
+		images = append(images, file)
+	}
+
+	imageResult := &provider.ImageResult{Images: images, Model: model}
+	if result.Usage != nil {
+		imageResult.Usage = &provider.Usage{
+			PromptTokens:     result.Usage.InputTokens,
+			CompletionTokens: result.Usage.OutputTokens,
+			TotalTokens:      result.Usage.TotalTokens,
+		}
+	}
+	return imageResult, nil
+}
+
+// closestAspectRatio returns the "W:H" ratio from ratios that is closest to a "WIDTHxHEIGHT" size
+func closestAspectRatio(size string, ratios []string) (string, error) {
+	width, height, ok := strings.Cut(size, "x")
+	w, wErr := strconv.Atoi(width)
+	h, hErr := strconv.Atoi(height)
+	if !ok || wErr != nil || hErr != nil || w <= 0 || h <= 0 {
+		return "", fmt.Errorf("invalid image size %q, expected WIDTHxHEIGHT", size)
+	}
+
+	target := float64(w) / float64(h)
+	closest, closestDiff := "", math.Inf(1)
+	for _, ratio := range ratios {
+		var rw, rh float64
+		fmt.Sscanf(ratio, "%g:%g", &rw, &rh)
+		if diff := math.Abs(rw/rh - target); diff < closestDiff {
+			closest, closestDiff = ratio, diff
+		}
+	}
+	return closest, nil
+}
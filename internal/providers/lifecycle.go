@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// lifecycle tracks a provider's in-flight requests so Close can cancel them and wait for them
+// to return. It is safe for concurrent use.
+type lifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// begin registers a request and returns a context that is also canceled when the provider is
+// closed. done must be called when the request has finished. It returns ErrProviderClosed
+// after close.
+func (l *lifecycle) begin(ctx context.Context) (context.Context, func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, nil, provider.ErrProviderClosed
+	}
+	l.inFlight.Add(1)
+
+	requestCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(l.ctx, cancel)
+	return requestCtx, func() {
+		stop()
+		cancel()
+		l.inFlight.Done()
+	}, nil
+}
+
+// close rejects new requests, cancels the in-flight ones and waits for them to return.
+// Calling it more than once is harmless; only the first call returns true.
+func (l *lifecycle) close() bool {
+	l.mu.Lock()
+	first := !l.closed
+	l.closed = true
+	l.mu.Unlock()
+
+	l.cancel()
+	l.inFlight.Wait()
+	return first
+}
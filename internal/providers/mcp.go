@@ -5,8 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
@@ -16,23 +16,49 @@ import (
 // FunctionCallingProvider implements the Provider interface for LLM APIs that support function calling
 // This provider can work with OpenAI, Anthropic, or any other LLM API that supports the OpenAI function calling format
 type FunctionCallingProvider struct {
-	apiKey               string
-	url                  string
-	timeout              time.Duration
-	client               httpclient.Client
-	models               []string
-	maxDailyRequests     int
-	maxRequestsPerMinute int
-	maxTokensPerMinute   int
-	rank                 int
-	requestsToday        int
-	requestsThisMinute   int
-	tokensThisMinute     int
-	lastReset            time.Time
-	lastMinuteReset      time.Time
-	toolExecutor         ToolExecutor
+	apiKey         string
+	url            string
+	timeout        time.Duration
+	maxResponse    int64 // Response body size limit
+	client         httpclient.Client
+	models         []string
+	catalog        modelCatalog // Models listed by RefreshModels
+	rank           int
+	weight         int
+	tokenEstimator provider.TokenEstimator
+	contextWindow  int
+	limiter        *rateLimiter
+	modelLimits    modelRateLimiters // Limiters of the models with their own ModelLimits
+	toolExecutor   ToolExecutor
+	toolConfig     ToolExecutionConfig
+	name           string                                   // Reported by Name(), "FunctionCalling" unless set by a wrapper
+	headers        map[string]string                        // Extra headers sent with every request
+	adjustRequest  func(requestBody map[string]interface{}) // Rewrites the chat request for APIs that differ from OpenAI's; nil if not needed
+	debug          *debugRecorder                           // nil unless debug mode is enabled
+	lifecycle      *lifecycle
+	selector       modelSelector
+	toolsDisabled  bool
+	images         *imageResizer                     // nil unless AutoResizeImages is set
+	includeRaw     bool                              // Sets QueryResult.Raw on responses
+	clampTemp      bool                              // Clamps out-of-range temperatures instead of failing
+	concurrency    *concurrencyLimiter               // nil unless MaxConcurrent is set
+	roleMap        map[string]string                 // Renames message roles before sending, nil if not set
+	modifyRequest  func(body map[string]interface{}) // Caller's RequestModifier, nil if not set
 }
 
+// ToolExecutionConfig controls how the provider runs the tool calls returned by the model
+type ToolExecutionConfig struct {
+	// MaxConcurrentTools limits how many tool calls from a single response run at once (default 4)
+	MaxConcurrentTools int
+	// ToolTimeout bounds each tool execution; a tool that times out is treated as failed (0 means no timeout)
+	ToolTimeout time.Duration
+	// FailurePolicy decides what happens to a failed tool call (default ToolFailureReturnToModel)
+	FailurePolicy provider.ToolFailurePolicy
+}
+
+// defaultMaxConcurrentTools is used when ToolExecutionConfig.MaxConcurrentTools is not set
+const defaultMaxConcurrentTools = 4
+
 // ToolExecutor interface for executing tool calls
 type ToolExecutor interface {
 	ExecuteTool(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error)
@@ -40,34 +66,79 @@ type ToolExecutor interface {
 }
 
 var _ provider.Provider = (*FunctionCallingProvider)(nil)
+var _ provider.TokenEstimator = (*FunctionCallingProvider)(nil)
+var _ provider.Weighted = (*FunctionCallingProvider)(nil)
+var _ provider.ToolCapable = (*FunctionCallingProvider)(nil)
+var _ provider.TimeoutAware = (*FunctionCallingProvider)(nil)
+var _ provider.HealthChecker = (*FunctionCallingProvider)(nil)
+var _ provider.Warmer = (*FunctionCallingProvider)(nil)
+var _ provider.ModelRefresher = (*FunctionCallingProvider)(nil)
+var _ provider.DebugRecorder = (*FunctionCallingProvider)(nil)
+var _ provider.Embedder = (*FunctionCallingProvider)(nil)
+var _ provider.ImageGenerator = (*FunctionCallingProvider)(nil)
+var _ provider.Transcriber = (*FunctionCallingProvider)(nil)
+var _ provider.RawRequester = (*FunctionCallingProvider)(nil)
 
 // newFunctionCallingProvider creates a new function calling provider
-func newFunctionCallingProvider(apiKey string, url string, timeout time.Duration, models []string, httpClient httpclient.Client, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank int, toolExecutor ToolExecutor) (provider.Provider, error) {
-	now := time.Now()
+func newFunctionCallingProvider(config provider.Config, url string, toolExecutor ToolExecutor, toolConfig ToolExecutionConfig) (provider.Provider, error) {
+	// The API key is optional, since local servers such as Ollama or vLLM don't need one
+	if url == "" {
+		return nil, fmt.Errorf("URL is required for the function calling provider")
+	}
+	if toolConfig.MaxConcurrentTools <= 0 {
+		toolConfig.MaxConcurrentTools = defaultMaxConcurrentTools
+	}
+
+	limiter, err := newConfiguredRateLimiter(config, "FunctionCalling", url)
+	if err != nil {
+		return nil, err
+	}
+	modelLimits, err := newModelRateLimiters(config, "FunctionCalling", url)
+	if err != nil {
+		return nil, err
+	}
+
+	debug := newDebugRecorder(config, "FunctionCalling")
+	client, url := withQueryParams(debug.wrap(config.HTTPClient), url, config.QueryParams)
 	return &FunctionCallingProvider{
-		url:                  url,
-		apiKey:               apiKey,
-		timeout:              timeout,
-		models:               models,
-		client:               httpClient,
-		maxDailyRequests:     maxDailyRequests,
-		maxRequestsPerMinute: maxRequestsPerMinute,
-		maxTokensPerMinute:   maxTokensPerMinute,
-		rank:                 rank,
-		requestsToday:        0,
-		requestsThisMinute:   0,
-		tokensThisMinute:     0,
-		lastReset:            now.Truncate(24 * time.Hour),
-		lastMinuteReset:      now.Truncate(time.Minute),
-		toolExecutor:         toolExecutor,
+		url:            url,
+		apiKey:         config.APIKey,
+		timeout:        config.Timeout,
+		maxResponse:    maxResponseBytesOrDefault(config.MaxResponseBytes),
+		models:         config.Models,
+		client:         client,
+		rank:           config.Rank,
+		weight:         config.Weight,
+		tokenEstimator: tokenEstimatorOrDefault(config.TokenEstimator),
+		contextWindow:  config.MaxContextTokens,
+		limiter:        limiter,
+		modelLimits:    modelLimits,
+		toolExecutor:   toolExecutor,
+		toolConfig:     toolConfig,
+		debug:          debug,
+		lifecycle:      newLifecycle(),
+		selector:       modelSelector{strategy: config.ModelStrategy},
+		toolsDisabled:  config.DisableTools,
+		images:         newImageResizer(config),
+		includeRaw:     config.IncludeRawResponse,
+		clampTemp:      config.ClampTemperature,
+		concurrency:    newConcurrencyLimiter(config),
+		roleMap:        config.RoleMap,
+		modifyRequest:  config.RequestModifier,
 	}, nil
 }
 
+// EstimateTokens estimates the tokens in the messages using the provider's token estimator
+func (f *FunctionCallingProvider) EstimateTokens(messages []provider.Message) int {
+	return f.tokenEstimator.EstimateTokens(messages)
+}
+
 // Query sends a prompt to the LLM API and returns the response (legacy method)
 func (f *FunctionCallingProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
 	options := provider.QueryOptions{
-		Temperature: temperature,
-		ForceModel:  forceModel,
+		Temperature:    temperature,
+		TemperatureSet: true,
+		ForceModel:     forceModel,
 	}
 
 	result, err := f.QueryWithOptions(ctx, messages, options)
@@ -80,30 +151,63 @@ func (f *FunctionCallingProvider) Query(ctx context.Context, messages []provider
 
 // QueryWithOptions sends a prompt to the LLM API with advanced options including function calling
 func (f *FunctionCallingProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
-	if time.Since(f.lastReset) > 24*time.Hour {
-		f.requestsToday = 0
-		f.lastReset = time.Now().Truncate(24 * time.Hour)
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer done()
+	ctx, cancel, timeout := withQueryTimeout(ctx, f.timeout, options)
+	defer cancel()
 
-	modelsToUse := f.models
-	if options.ForceModel != "" {
-		modelsToUse = []string{options.ForceModel}
+	messages = withSystemPrompt(messages, options.SystemPrompt)
+	messages = provider.TrimToContextWindowWithEstimator(f.tokenEstimator, messages, f.contextWindow, true)
+	messages, err = f.images.apply(messages)
+	if err != nil {
+		return nil, err
+	}
+	options, err = openAITemperatureRange.apply(options, f.clampTemp)
+	if err != nil {
+		return nil, err
 	}
 
-	// If no tools are provided but we have a tool executor, get available tools
-	if len(options.Tools) == 0 && f.toolExecutor != nil {
-		options.Tools = f.toolExecutor.GetAvailableTools()
+	modelsToUse := f.selector.order(f.models, options.ForceModel)
+	if len(modelsToUse) == 0 {
+		return nil, noModelsError(f.Name())
 	}
 
+	// The executor's tools are offered along with the query's; a query tool replaces an
+	// executor tool of the same name
+	var executorTools []provider.Tool
+	if f.toolExecutor != nil {
+		executorTools = f.toolExecutor.GetAvailableTools()
+	}
+	options.Tools = mergeTools(options.Tools, executorTools)
+
 	var outerErr error
+	var attempts modelAttempts
 	for _, model := range modelsToUse {
+		// A canceled query is not retried with the next model
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		attempts.next(model, outerErr)
+
+		// Models with their own limits are skipped once they have used them up
+		if err := f.modelLimits.checkModel(model, f.limiter, f, messages); err != nil {
+			outerErr = err
+			continue
+		}
+
 		// Convert messages to API format
 		apiMessages := make([]map[string]interface{}, 0, len(messages))
 		for _, message := range messages {
 			msg := map[string]interface{}{
-				"role":    message.Role,
+				"role":    mapRole(f.roleMap, message.Role),
 				"content": message.Content,
 			}
+			if message.Name != "" {
+				msg["name"] = message.Name
+			}
 
 			// Add file attachments if present
 			if len(message.Files) > 0 {
@@ -115,18 +219,27 @@ func (f *FunctionCallingProvider) QueryWithOptions(ctx context.Context, messages
 						"name":      file.Name,
 						"data":      file.Data,
 					}
+					if file.URL != "" {
+						fileData["url"] = file.URL
+					}
 					files = append(files, fileData)
 				}
 				msg["files"] = files
 			}
 
+			if err := addOpenAIToolFields(msg, message); err != nil {
+				return nil, err
+			}
+
 			apiMessages = append(apiMessages, msg)
 		}
 
 		requestBody := map[string]interface{}{
-			"model":       model,
-			"messages":    apiMessages,
-			"temperature": options.Temperature,
+			"model":    model,
+			"messages": apiMessages,
+		}
+		if options.HasTemperature() {
+			requestBody["temperature"] = options.Temperature
 		}
 
 		// Add tools if provided
@@ -136,72 +249,210 @@ func (f *FunctionCallingProvider) QueryWithOptions(ctx context.Context, messages
 
 		// Add tool_choice if provided
 		if options.ToolChoice != "" {
-			requestBody["tool_choice"] = options.ToolChoice
+			requestBody["tool_choice"] = openAIToolChoice(options.ToolChoice)
+		}
+		if options.ParallelToolCalls != nil {
+			requestBody["parallel_tool_calls"] = *options.ParallelToolCalls
+		}
+
+		if options.N > 1 {
+			requestBody["n"] = options.N
+		}
+		if options.Seed != nil {
+			requestBody["seed"] = *options.Seed
+		}
+		if options.ReasoningEffort != "" {
+			requestBody["reasoning_effort"] = options.ReasoningEffort
+		}
+		if options.JSONMode {
+			requestBody["response_format"] = map[string]interface{}{"type": "json_object"}
+		}
+		if options.LogProbs {
+			requestBody["logprobs"] = true
+			if options.TopLogProbs > 0 {
+				requestBody["top_logprobs"] = options.TopLogProbs
+			}
+		}
+		if f.adjustRequest != nil {
+			f.adjustRequest(requestBody)
+		}
+		// The caller's modifier runs last so it can override any field set above
+		if f.modifyRequest != nil {
+			f.modifyRequest(requestBody)
 		}
 
 		// Make the initial request
-		result, err := f.makeRequest(ctx, requestBody)
+		result, err := f.makeRequest(ctx, requestBody, timeout)
 		if err != nil {
 			outerErr = err
 			continue
 		}
 
+		// Count the estimated tokens for this request
+		f.modelLimits.forModel(model, f.limiter).recordTokens(f.EstimateTokens(messages))
+
 		// Handle tool calls if present
 		if len(result.ToolCalls) > 0 && f.toolExecutor != nil {
-			// Execute tool calls
-			toolResults := make([]provider.ToolCallResult, 0, len(result.ToolCalls))
-			for _, toolCall := range result.ToolCalls {
-				toolResult, err := f.toolExecutor.ExecuteTool(ctx, toolCall)
-				if err != nil {
-					// Log error but continue with other tool calls
-					fmt.Printf("Tool execution failed for %s: %v\n", toolCall.Function.Name, err)
-					continue
-				}
-				toolResults = append(toolResults, *toolResult)
+			toolResults, err := f.executeToolCalls(ctx, result.ToolCalls, f.toolConcurrency(options))
+			if err != nil {
+				return nil, err
 			}
 
 			// Add tool results to messages and make another request
 			if len(toolResults) > 0 {
-				// Create a new message with tool results
-				toolMessage := map[string]interface{}{
-					"role":         "tool",
-					"tool_results": toolResults,
+				// The assistant turn with the tool calls comes first, then one message per result
+				toolMessages, err := openAIToolMessages(result.Content, result.ToolCalls, toolResults)
+				if err != nil {
+					return nil, err
 				}
 
-				// Add tool results to the conversation
-				updatedMessages := make([]map[string]interface{}, len(apiMessages)+1)
-				copy(updatedMessages, apiMessages)
-				updatedMessages[len(apiMessages)] = toolMessage
+				updatedMessages := make([]map[string]interface{}, 0, len(apiMessages)+len(toolMessages))
+				updatedMessages = append(updatedMessages, apiMessages...)
+				updatedMessages = append(updatedMessages, toolMessages...)
 
-				// Make another request with tool results
+				// Make another request with tool results, unless the caller has given up on the
+				// query while the tools ran
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
 				requestBody["messages"] = updatedMessages
-				finalResult, err := f.makeRequest(ctx, requestBody)
+				finalResult, err := f.makeRequest(ctx, requestBody, timeout)
 				if err != nil {
 					outerErr = err
 					continue
 				}
+				finalResult.Usage = provider.AddUsage(result.Usage, finalResult.Usage)
+				finalResult.ModelAttempts = attempts.succeeded()
 
 				return finalResult, nil
 			}
 		}
 
+		result.ModelAttempts = attempts.succeeded()
 		return result, nil
 	}
 
 	return nil, outerErr
 }
 
+// toolConcurrency returns how many tool calls of one response may run at once: MaxConcurrentTools,
+// or one at a time when the query disables parallel tool calls, in case the model sends several
+// anyway
+func (f *FunctionCallingProvider) toolConcurrency(options provider.QueryOptions) int {
+	if options.ParallelToolCalls != nil && !*options.ParallelToolCalls {
+		return 1
+	}
+	return f.toolConfig.MaxConcurrentTools
+}
+
+// executeToolCalls runs the tool calls concurrently, at most maxConcurrent at a time.
+// Results keep the order of the tool calls; failed tools are handled according to the FailurePolicy.
+// If ctx is canceled, tool calls that have not started yet are not run and ctx's error is returned.
+func (f *FunctionCallingProvider) executeToolCalls(ctx context.Context, toolCalls []provider.ToolCall, maxConcurrent int) ([]provider.ToolCallResult, error) {
+	results := make([]*provider.ToolCallResult, len(toolCalls))
+	slots := make(chan struct{}, maxConcurrent)
+	var wg sync.WaitGroup
+
+	// With ToolFailureAbortQuery, the first failure cancels the tools that are still running
+	runCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	var abortOnce sync.Once
+	var abortErr error
+
+	for i, toolCall := range toolCalls {
+		select {
+		case slots <- struct{}{}:
+		case <-runCtx.Done():
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, toolCall provider.ToolCall) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			// Executors may ignore the context, so don't start a tool for a canceled query
+			if runCtx.Err() != nil {
+				return
+			}
+
+			toolCtx, span := provider.StartSpan(runCtx, "tool.execute")
+			defer span.End()
+			span.SetAttributes(provider.Attr("tool.name", toolCall.Function.Name), provider.Attr("tool.call_id", toolCall.ID))
+
+			if f.toolConfig.ToolTimeout > 0 {
+				var cancel context.CancelFunc
+				toolCtx, cancel = context.WithTimeout(toolCtx, f.toolConfig.ToolTimeout)
+				defer cancel()
+			}
+
+			toolResult, err := f.toolExecutor.ExecuteTool(toolCtx, toolCall)
+			if err != nil {
+				if toolCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+					err = fmt.Errorf("timed out after %v: %w", f.toolConfig.ToolTimeout, err)
+				}
+				span.RecordError(err)
+				switch f.toolConfig.FailurePolicy {
+				case provider.ToolFailureAbortQuery:
+					abortOnce.Do(func() {
+						abortErr = &provider.ToolExecutionError{ToolName: toolCall.Function.Name, ToolCallID: toolCall.ID, Err: err}
+						abort()
+					})
+				case provider.ToolFailureContinue:
+					// The failure is on the span; leave the result out and continue with the other tool calls
+					f.debug.logf("tool %s failed, continuing without its result: %v", toolCall.Function.Name, err)
+				default:
+					results[i] = &provider.ToolCallResult{
+						ID:      toolCall.ID,
+						Type:    "function",
+						Content: map[string]interface{}{"error": err.Error()},
+					}
+				}
+				return
+			}
+			results[i] = toolResult
+		}(i, toolCall)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if abortErr != nil {
+		return nil, abortErr
+	}
+
+	toolResults := make([]provider.ToolCallResult, 0, len(toolCalls))
+	for _, toolResult := range results {
+		if toolResult != nil {
+			toolResults = append(toolResults, *toolResult)
+		}
+	}
+	return toolResults, nil
+}
+
 // makeRequest makes a single request to the LLM API
-func (f *FunctionCallingProvider) makeRequest(ctx context.Context, requestBody map[string]interface{}) (*provider.QueryResult, error) {
+func (f *FunctionCallingProvider) makeRequest(ctx context.Context, requestBody map[string]interface{}, timeout time.Duration) (*provider.QueryResult, error) {
+	requestedModel, ok := requestBody["model"].(string)
+	if !ok || requestedModel == "" {
+		return nil, fmt.Errorf("invalid request: model must be a non-empty string, got %T %v", requestBody["model"], requestBody["model"])
+	}
+
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, _, err := f.client.Do(ctx, f.url, "POST", map[string]string{
-		"Authorization": "Bearer " + f.apiKey,
-		"Content-Type":  "application/json",
-	}, bytes.NewBuffer(jsonData), f.timeout)
+	// Each request of the tool loop takes a slot, so running tools don't hold one
+	release, err := f.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, _, err := f.client.Do(ctx, f.url, "POST", f.requestHeaders(), bytes.NewBuffer(jsonData), timeout)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -209,27 +460,23 @@ func (f *FunctionCallingProvider) makeRequest(ctx context.Context, requestBody m
 
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp.Body, f.maxResponse)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, provider.NewAPIError(resp.StatusCode, string(body))
 	}
 
 	// Update rate limiting counters
-	f.requestsToday++
-	f.requestsThisMinute++
+	f.modelLimits.forModel(requestedModel, f.limiter).recordRequest()
 
 	var result struct {
-		Choices []struct {
-			Message struct {
-				Content   string              `json:"content"`
-				ToolCalls []provider.ToolCall `json:"tool_calls,omitempty"`
-			} `json:"message"`
-			FinishReason string `json:"finish_reason"`
-		} `json:"choices"`
+		Model             string          `json:"model"`
+		Choices           []openAIChoice  `json:"choices"`
+		SystemFingerprint string          `json:"system_fingerprint"`
+		Usage             *provider.Usage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -239,53 +486,280 @@ func (f *FunctionCallingProvider) makeRequest(ctx context.Context, requestBody m
 	if len(result.Choices) == 0 {
 		return nil, fmt.Errorf("no response choices received")
 	}
+	normalizeOpenAIChoices(result.Choices, f.debug)
+
+	// Gateways may substitute a different model and report it in the response
+	model := result.Model
+	if model == "" {
+		model = requestedModel
+	}
 
 	choice := result.Choices[0]
 	queryResult := &provider.QueryResult{
-		Content:      choice.Message.Content,
-		Model:        requestBody["model"].(string),
-		ToolCalls:    choice.Message.ToolCalls,
-		FinishReason: choice.FinishReason,
+		Content:           choice.Message.Content,
+		Reasoning:         openAIReasoning(choice.Message.Reasoning, choice.Message.ReasoningContent),
+		Model:             model,
+		ToolCalls:         choice.Message.ToolCalls,
+		FinishReason:      choice.FinishReason,
+		Usage:             result.Usage,
+		SystemFingerprint: result.SystemFingerprint,
+		LogProbs:          choice.LogProbs.tokens(),
+	}
+	if n, _ := requestBody["n"].(int); n > 1 {
+		queryResult.Completions = openAICompletions(result.Choices)
+	}
+	if f.includeRaw {
+		queryResult.Raw = body
 	}
 
 	return queryResult, nil
 }
 
-// Close closes the function calling provider
+// SupportsEmbeddings reports whether the provider's endpoint has an embeddings counterpart
+func (f *FunctionCallingProvider) SupportsEmbeddings() bool {
+	return embeddingsURL(f.url) != ""
+}
+
+// Embeddings creates vector embeddings through the API's OpenAI-compatible embeddings endpoint
+func (f *FunctionCallingProvider) Embeddings(ctx context.Context, request provider.EmbeddingRequest) (*provider.EmbeddingResult, error) {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := f.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	url := embeddingsURL(f.url)
+	if url == "" {
+		return nil, fmt.Errorf("embeddings are not supported for endpoint %s", f.url)
+	}
+
+	result, err := requestOpenAIEmbeddings(ctx, f.client, url, f.requestHeaders(), f.timeout, request)
+	if err != nil {
+		return nil, err
+	}
+
+	f.limiter.recordRequest()
+	f.limiter.recordTokens(f.EstimateTokens(embedInputMessages(request.Input)))
+	return result, nil
+}
+
+// SupportsRawRequests reports whether the provider's endpoint follows the OpenAI layout, so
+// other paths can be resolved against its base URL
+func (f *FunctionCallingProvider) SupportsRawRequests() bool {
+	return rawURL(f.url, "") != ""
+}
+
+// DoRaw sends a request to another endpoint of the API with the provider's credentials. It
+// counts against the daily and per-minute request limits.
+func (f *FunctionCallingProvider) DoRaw(ctx context.Context, path string, body interface{}) ([]byte, error) {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := f.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	url := rawURL(f.url, path)
+	if url == "" {
+		return nil, fmt.Errorf("raw requests are not supported for endpoint %s", f.url)
+	}
+
+	data, err := requestRaw(ctx, f.client, url, f.requestHeaders(), f.timeout, f.maxResponse, body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.limiter.recordRequest()
+	return data, nil
+}
+
+// SupportsImageGeneration reports whether the provider's endpoint has an image generation counterpart
+func (f *FunctionCallingProvider) SupportsImageGeneration() bool {
+	return imagesURL(f.url) != ""
+}
+
+// GenerateImage generates images through the API's OpenAI-compatible image generation endpoint.
+// The model defaults to gpt-image-1.
+func (f *FunctionCallingProvider) GenerateImage(ctx context.Context, request provider.ImageRequest) (*provider.ImageResult, error) {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := f.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	url := imagesURL(f.url)
+	if url == "" {
+		return nil, fmt.Errorf("image generation is not supported for endpoint %s", f.url)
+	}
+
+	result, err := requestOpenAIImages(ctx, f.client, url, f.requestHeaders(), f.timeout, request)
+	if err != nil {
+		return nil, err
+	}
+
+	f.limiter.recordRequest()
+	if result.Usage != nil {
+		f.limiter.recordTokens(result.Usage.TotalTokens)
+	}
+	return result, nil
+}
+
+// SupportsTranscription reports whether the provider's endpoint has a transcription counterpart
+func (f *FunctionCallingProvider) SupportsTranscription() bool {
+	return transcriptionsURL(f.url) != ""
+}
+
+// Transcribe uploads the audio to the API's OpenAI-compatible transcription endpoint.
+// The model defaults to whisper-1.
+func (f *FunctionCallingProvider) Transcribe(ctx context.Context, request provider.TranscriptionRequest) (*provider.TranscriptionResult, error) {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := f.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	url := transcriptionsURL(f.url)
+	if url == "" {
+		return nil, fmt.Errorf("transcription is not supported for endpoint %s", f.url)
+	}
+
+	result, err := requestOpenAITranscription(ctx, f.client, url, f.requestHeaders(), f.timeout, request)
+	if err != nil {
+		return nil, err
+	}
+
+	f.limiter.recordRequest()
+	return result, nil
+}
+
+// HealthCheck lists the API's models to verify the API key and connectivity.
+// Endpoints that don't follow the OpenAI layout are checked with a one-token completion.
+func (f *FunctionCallingProvider) HealthCheck(ctx context.Context) error {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	headers := f.requestHeaders()
+
+	if url := modelsURL(f.url); url != "" {
+		return checkHealthGET(ctx, f.client, url, headers, f.timeout)
+	}
+	if len(f.models) == 0 {
+		return noModelsError(f.Name())
+	}
+	return checkHealthCompletion(ctx, f.client, f.url, headers, f.timeout, f.models[0])
+}
+
+// Warmup opens a connection to the API host ahead of the first query. It doesn't count against
+// the rate limits.
+func (f *FunctionCallingProvider) Warmup(ctx context.Context) error {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return warmConnection(ctx, f.client, f.url, f.timeout)
+}
+
+// requestHeaders returns the headers sent with every request to the API
+func (f *FunctionCallingProvider) requestHeaders() map[string]string {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if f.apiKey != "" {
+		headers["Authorization"] = "Bearer " + f.apiKey
+	}
+	for name, value := range f.headers {
+		headers[name] = value
+	}
+	return headers
+}
+
+// Models returns the configured models, or the models listed by the last RefreshModels
+func (f *FunctionCallingProvider) Models() []string {
+	return f.catalog.models(f.models)
+}
+
+// RefreshModels lists the models served by the API's OpenAI-compatible models endpoint
+func (f *FunctionCallingProvider) RefreshModels(ctx context.Context) error {
+	ctx, done, err := f.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	url := modelsURL(f.url)
+	if url == "" {
+		return fmt.Errorf("listing models is not supported for endpoint %s", f.url)
+	}
+
+	models, err := fetchOpenAIModels(ctx, f.client, url, f.requestHeaders(), f.timeout)
+	if err != nil {
+		return err
+	}
+	f.catalog.set(models)
+	return nil
+}
+
+// LastRawExchange returns the last raw request and response, or nil unless debug mode is enabled
+func (f *FunctionCallingProvider) LastRawExchange() *provider.RawExchange {
+	return f.debug.lastExchange()
+}
+
+// Close closes the function calling provider and saves its final rate-limit counters to the RateStore, if any.
+// Later calls do nothing.
 func (f *FunctionCallingProvider) Close() {
-	// No cleanup needed for HTTP client
+	if f.lifecycle.close() {
+		f.limiter.flush()
+		f.modelLimits.flush()
+	}
 }
 
 // HasRemainingRequests checks if the provider has remaining requests
 func (f *FunctionCallingProvider) HasRemainingRequests(ctx context.Context) bool {
-	// Reset daily counter if needed
-	if time.Since(f.lastReset) > 24*time.Hour {
-		f.requestsToday = 0
-		f.lastReset = time.Now().Truncate(24 * time.Hour)
-	}
-	return f.maxDailyRequests == 0 || f.requestsToday < f.maxDailyRequests
+	return f.modelLimits.anyRemaining(f.limiter, (*rateLimiter).hasRemainingRequests)
 }
 
 // HasRemainingRequestsPerMinute checks if the provider has remaining requests per minute
 func (f *FunctionCallingProvider) HasRemainingRequestsPerMinute(ctx context.Context) bool {
-	// Reset minute counter if needed
-	if time.Since(f.lastMinuteReset) > time.Minute {
-		f.requestsThisMinute = 0
-		f.tokensThisMinute = 0
-		f.lastMinuteReset = time.Now().Truncate(time.Minute)
-	}
-	return f.maxRequestsPerMinute == 0 || f.requestsThisMinute < f.maxRequestsPerMinute
+	return f.modelLimits.anyRemaining(f.limiter, (*rateLimiter).hasRemainingRequestsPerMinute)
 }
 
 // HasRemainingTokensPerMinute checks if the provider has remaining tokens per minute
 func (f *FunctionCallingProvider) HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool {
-	// Reset minute counter if needed
-	if time.Since(f.lastMinuteReset) > time.Minute {
-		f.requestsThisMinute = 0
-		f.tokensThisMinute = 0
-		f.lastMinuteReset = time.Now().Truncate(time.Minute)
-	}
-	return f.maxTokensPerMinute == 0 || (f.tokensThisMinute+estimatedTokens) <= f.maxTokensPerMinute
+	return f.modelLimits.anyRemaining(f.limiter, func(l *rateLimiter) bool {
+		return l.hasRemainingTokensPerMinute(estimatedTokens)
+	})
+}
+
+// ResetLimits clears the provider's rate-limit counters
+func (f *FunctionCallingProvider) ResetLimits() {
+	f.limiter.reset()
+	f.modelLimits.reset()
 }
 
 // GetRank returns the provider's rank
@@ -293,7 +767,25 @@ func (f *FunctionCallingProvider) GetRank() int {
 	return f.rank
 }
 
+// GetWeight returns the provider's load-balancing weight
+func (f *FunctionCallingProvider) GetWeight() int {
+	return f.weight
+}
+
+// SupportsTools reports whether tool calls are enabled for the provider
+func (f *FunctionCallingProvider) SupportsTools() bool {
+	return !f.toolsDisabled
+}
+
+// GetTimeout returns the provider's per-request timeout, 0 if none is configured
+func (f *FunctionCallingProvider) GetTimeout() time.Duration {
+	return f.timeout
+}
+
 // Name returns the name of the provider
 func (f *FunctionCallingProvider) Name() string {
+	if f.name != "" {
+		return f.name
+	}
 	return "FunctionCalling"
 }
@@ -0,0 +1,426 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// newTestFunctionCallingProvider creates a function calling provider without a tool executor
+func newTestFunctionCallingProvider(t *testing.T, url string) *FunctionCallingProvider {
+	t.Helper()
+
+	p, err := newFunctionCallingProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"requested-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, url, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return p.(*FunctionCallingProvider)
+}
+
+// toolCallHistory is a conversation in which the assistant called a tool and got its result
+func toolCallHistory() []provider.Message {
+	return []provider.Message{
+		{Role: "user", Content: "What's the weather in Paris?"},
+		{Role: "assistant", ToolCalls: []provider.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: provider.ToolCallFunction{Name: "get_weather", Arguments: map[string]interface{}{"city": "Paris"}},
+		}}},
+		{Role: "tool", ToolCallID: "call_1", Content: `{"temperature":21}`},
+	}
+}
+
+func TestFunctionCallingProvider_ToolCallHistory(t *testing.T) {
+	var messages json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body struct {
+			Messages json.RawMessage `json:"messages"`
+		}
+		json.Unmarshal(data, &body)
+		messages = body.Messages
+		w.Write([]byte(`{"choices":[{"message":{"content":"It's 21 degrees."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	if _, err := f.QueryWithOptions(context.Background(), toolCallHistory(), provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `[` +
+		`{"content":"What's the weather in Paris?","role":"user"},` +
+		`{"content":"","role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_1","type":"function"}]},` +
+		`{"content":"{\"temperature\":21}","role":"tool","tool_call_id":"call_1"}]`
+	if string(messages) != expected {
+		t.Errorf("Unexpected messages\n%s\nexpected\n%s", messages, expected)
+	}
+}
+
+func TestFunctionCallingProvider_ToolChoice(t *testing.T) {
+	var toolChoice json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body struct {
+			ToolChoice json.RawMessage `json:"tool_choice"`
+		}
+		json.Unmarshal(data, &body)
+		toolChoice = body.ToolChoice
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+	for choice, expected := range map[string]string{
+		"auto":        `"auto"`,
+		"none":        `"none"`,
+		"get_weather": `{"function":{"name":"get_weather"},"type":"function"}`,
+	} {
+		options := provider.QueryOptions{Tools: tools, ToolChoice: choice}
+		if _, err := f.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(toolChoice) != expected {
+			t.Errorf("Expected tool_choice %s for %q, got %s", expected, choice, toolChoice)
+		}
+	}
+}
+
+func TestFunctionCallingProvider_Close(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	f.Close()
+
+	if _, _, err := f.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, ""); !errors.Is(err, provider.ErrProviderClosed) {
+		t.Errorf("Expected ErrProviderClosed after Close, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request to be sent after Close, got %d", requests.Load())
+	}
+}
+
+func TestFunctionCallingProvider_SeedAndSystemFingerprint(t *testing.T) {
+	var seed json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body struct {
+			Seed json.RawMessage `json:"seed"`
+		}
+		json.Unmarshal(data, &body)
+		seed = body.Seed
+		w.Write([]byte(`{"system_fingerprint":"fp_44709d6fcb","choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	value := 42
+	result, err := f.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{Seed: &value})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(seed) != "42" {
+		t.Errorf("Expected seed 42 in the request, got %s", seed)
+	}
+	if result.SystemFingerprint != "fp_44709d6fcb" {
+		t.Errorf("Expected the system fingerprint from the response, got %q", result.SystemFingerprint)
+	}
+
+	if _, err := f.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seed != nil {
+		t.Errorf("Expected no seed when unset, got %s", seed)
+	}
+}
+
+func TestFunctionCallingProvider_ReasoningEffort(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	budget := 2048
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := f.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ReasoningEffort: "low"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := f.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ThinkingBudget: &budget}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if requests[0]["reasoning_effort"] != "low" {
+		t.Errorf("Expected reasoning_effort in the request, got %v", requests[0]["reasoning_effort"])
+	}
+	if _, ok := requests[1]["reasoning_effort"]; ok {
+		t.Errorf("Expected no reasoning_effort when unset, got %v", requests[1]["reasoning_effort"])
+	}
+}
+
+func TestFunctionCallingProvider_JSONMode(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		w.Write([]byte(`{"choices":[{"message":{"content":"{}"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	messages := []provider.Message{{Role: "user", Content: "Reply in JSON"}}
+	for _, jsonMode := range []bool{true, false} {
+		if _, err := f.QueryWithOptions(context.Background(), messages, provider.QueryOptions{JSONMode: jsonMode}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	format, _ := requests[0]["response_format"].(map[string]interface{})
+	if format["type"] != "json_object" {
+		t.Errorf("Expected response_format json_object, got %v", requests[0]["response_format"])
+	}
+	if _, ok := requests[1]["response_format"]; ok {
+		t.Errorf("Expected no response_format without JSONMode, got %v", requests[1]["response_format"])
+	}
+}
+
+func TestFunctionCallingProvider_ParallelToolCallsOnlyWhenSet(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	enabled, disabled := true, false
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for _, parallel := range []*bool{nil, &enabled, &disabled} {
+		if _, err := f.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ParallelToolCalls: parallel}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if _, ok := requests[0]["parallel_tool_calls"]; ok {
+		t.Errorf("Expected no parallel_tool_calls when unset, got %v", requests[0]["parallel_tool_calls"])
+	}
+	if requests[1]["parallel_tool_calls"] != true || requests[2]["parallel_tool_calls"] != false {
+		t.Errorf("Expected parallel_tool_calls true then false, got %v and %v", requests[1]["parallel_tool_calls"], requests[2]["parallel_tool_calls"])
+	}
+}
+
+func TestFunctionCallingProvider_TemperatureRange(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for _, temperature := range []float64{5, -1} {
+		_, err := f.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: temperature})
+		if !errors.Is(err, provider.ErrTemperatureOutOfRange) {
+			t.Errorf("Expected ErrTemperatureOutOfRange for %g, got %v", temperature, err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Fatalf("Expected rejected temperatures not to be sent, got %d requests", len(requests))
+	}
+
+	f.clampTemp = true
+	for _, temperature := range []float64{5, -1, 1.5} {
+		if _, err := f.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Temperature: temperature}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for i, want := range []float64{2, 0, 1.5} {
+		if requests[i]["temperature"] != want {
+			t.Errorf("Expected temperature %g to be sent, got %v", want, requests[i]["temperature"])
+		}
+	}
+}
+
+func TestFunctionCallingProvider_ResolvedModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"substituted-model","choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	result, err := f.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Model != "substituted-model" {
+		t.Errorf("Expected the model reported in the response, got %q", result.Model)
+	}
+}
+
+func TestFunctionCallingProvider_ModelAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Model == "primary-model" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"overloaded"}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newFunctionCallingProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"primary-model", "secondary-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	attempts := result.ModelAttempts
+	if len(attempts) != 2 {
+		t.Fatalf("Expected both models to be recorded, got %+v", attempts)
+	}
+	if attempts[0].Model != "primary-model" || !strings.Contains(attempts[0].Error, "503") {
+		t.Errorf("Expected the primary model's failure first, got %+v", attempts[0])
+	}
+	if attempts[1].Model != "secondary-model" || attempts[1].Error != "" || result.Model != "secondary-model" {
+		t.Errorf("Expected the secondary model to succeed, got %+v", attempts[1])
+	}
+}
+
+func TestFunctionCallingProvider_ReasoningContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"4","reasoning_content":"2 + 2 is 4."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	result, err := f.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What is 2 + 2?"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "4" || result.Reasoning != "2 + 2 is 4." {
+		t.Errorf("Expected reasoning_content in Reasoning, got %+v", result)
+	}
+
+	// Providers that don't separate reasoning leave it empty
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"4"},"finish_reason":"stop"}]}`))
+	}))
+	defer plain.Close()
+
+	result, err = newTestFunctionCallingProvider(t, plain.URL).QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Reasoning != "" {
+		t.Errorf("Expected no reasoning, got %q", result.Reasoning)
+	}
+}
+
+func TestFunctionCallingProvider_RequestedModelWhenNotReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	result, err := f.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Model != "requested-model" {
+		t.Errorf("Expected the requested model as a fallback, got %q", result.Model)
+	}
+}
+
+func TestFunctionCallingProvider_MakeRequestInvalidModel(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL)
+	for name, body := range map[string]map[string]interface{}{
+		"missing":    {"messages": []interface{}{}},
+		"not string": {"model": 42, "messages": []interface{}{}},
+		"empty":      {"model": "", "messages": []interface{}{}},
+	} {
+		result, err := f.makeRequest(context.Background(), body, f.timeout)
+		if err == nil || !strings.Contains(err.Error(), "model") {
+			t.Errorf("%s: expected a model error, got result %v, err %v", name, result, err)
+		}
+	}
+
+	if requests.Load() != 0 {
+		t.Errorf("Expected malformed requests not to be sent, got %d requests", requests.Load())
+	}
+}
+
+func TestFunctionCallingProvider_HealthCheck(t *testing.T) {
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		if auth != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid api key"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	f := newTestFunctionCallingProvider(t, server.URL+"/v1/chat/completions")
+	if err := f.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("Expected healthy provider, got %v", err)
+	}
+	if path != "/v1/models" {
+		t.Errorf("Expected the models endpoint to be used, got %s", path)
+	}
+
+	f.apiKey = "wrong-key"
+	err := f.HealthCheck(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected a 401 health check error, got %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// openAIChoice is a choice in an OpenAI-compatible chat completion response
+type openAIChoice struct {
+	Message struct {
+		Content          string                  `json:"content"`
+		Reasoning        string                  `json:"reasoning,omitempty"`
+		ReasoningContent string                  `json:"reasoning_content,omitempty"`
+		ToolCalls        openAIResponseToolCalls `json:"tool_calls,omitempty"`
+	} `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+	LogProbs     *openAILogProbs `json:"logprobs,omitempty"`
+
+	// Some gateways answer in the legacy completions format or with a streaming chunk's delta
+	// even for non-streamed requests; see normalizeOpenAIChoices
+	Text  string `json:"text,omitempty"`
+	Delta struct {
+		Content   string                  `json:"content"`
+		ToolCalls openAIResponseToolCalls `json:"tool_calls,omitempty"`
+	} `json:"delta"`
+}
+
+// normalizeOpenAIChoices fills in the message of choices whose message is empty from their
+// "text" or "delta" field, so responses in those envelopes don't parse to empty content
+func normalizeOpenAIChoices(choices []openAIChoice, debug *debugRecorder) {
+	for i := range choices {
+		choice := &choices[i]
+		if choice.Message.Content != "" || len(choice.Message.ToolCalls) > 0 {
+			continue
+		}
+		switch {
+		case choice.Text != "":
+			choice.Message.Content = choice.Text
+			debug.logf("choice %d has no message content, using its text field", i)
+		case choice.Delta.Content != "" || len(choice.Delta.ToolCalls) > 0:
+			choice.Message.Content = choice.Delta.Content
+			choice.Message.ToolCalls = choice.Delta.ToolCalls
+			debug.logf("choice %d has no message content, using its delta", i)
+		}
+	}
+}
+
+// openAIResponseToolCalls are the tool calls of a response message. The OpenAI API and most
+// compatible ones send the arguments as a JSON-encoded string, some gateways as an object;
+// both are decoded into the arguments map.
+type openAIResponseToolCalls []provider.ToolCall
+
+func (c *openAIResponseToolCalls) UnmarshalJSON(data []byte) error {
+	var raw []struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Function struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	toolCalls := make([]provider.ToolCall, 0, len(raw))
+	for _, call := range raw {
+		arguments := call.Function.Arguments
+		var encoded string
+		if json.Unmarshal(arguments, &encoded) == nil {
+			arguments = json.RawMessage(encoded)
+		}
+
+		var decoded map[string]interface{}
+		if len(arguments) > 0 {
+			if err := json.Unmarshal(arguments, &decoded); err != nil {
+				return fmt.Errorf("failed to parse arguments of tool %s: %w", call.Function.Name, err)
+			}
+		}
+		toolCalls = append(toolCalls, provider.ToolCall{
+			ID:       call.ID,
+			Type:     call.Type,
+			Function: provider.ToolCallFunction{Name: call.Function.Name, Arguments: decoded},
+		})
+	}
+	*c = toolCalls
+	return nil
+}
+
+// openAILogProbs is the logprobs block of a choice
+type openAILogProbs struct {
+	Content []struct {
+		Token       string  `json:"token"`
+		LogProb     float64 `json:"logprob"`
+		TopLogProbs []struct {
+			Token   string  `json:"token"`
+			LogProb float64 `json:"logprob"`
+		} `json:"top_logprobs"`
+	} `json:"content"`
+}
+
+// tokens converts the logprobs block, which is absent when logprobs weren't requested
+func (l *openAILogProbs) tokens() []provider.TokenLogProb {
+	if l == nil || len(l.Content) == 0 {
+		return nil
+	}
+
+	tokens := make([]provider.TokenLogProb, 0, len(l.Content))
+	for _, content := range l.Content {
+		token := provider.TokenLogProb{Token: content.Token, LogProb: content.LogProb}
+		for _, alternative := range content.TopLogProbs {
+			token.TopAlternatives = append(token.TopAlternatives, provider.TokenAlternative{Token: alternative.Token, LogProb: alternative.LogProb})
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// openAICompletions converts the choices of a response requested with n > 1
+func openAICompletions(choices []openAIChoice) []provider.Completion {
+	completions := make([]provider.Completion, 0, len(choices))
+	for _, choice := range choices {
+		completions = append(completions, provider.Completion{
+			Content:      choice.Message.Content,
+			Reasoning:    openAIReasoning(choice.Message.Reasoning, choice.Message.ReasoningContent),
+			ToolCalls:    choice.Message.ToolCalls,
+			FinishReason: choice.FinishReason,
+			LogProbs:     choice.LogProbs.tokens(),
+		})
+	}
+	return completions
+}
+
+// openAIReasoning returns the reasoning trace of an OpenAI-compatible response message.
+// OpenRouter names the field "reasoning" while DeepSeek and vLLM use "reasoning_content".
+func openAIReasoning(reasoning, reasoningContent string) string {
+	if reasoning != "" {
+		return reasoning
+	}
+	return reasoningContent
+}
+
+// withSystemPrompt prepends the system prompt as a system message when one is set
+func withSystemPrompt(messages []provider.Message, systemPrompt string) []provider.Message {
+	if systemPrompt == "" {
+		return messages
+	}
+
+	withPrompt := make([]provider.Message, 0, len(messages)+1)
+	withPrompt = append(withPrompt, provider.Message{Role: "system", Content: systemPrompt})
+	return append(withPrompt, messages...)
+}
+
+// mapRole returns the role that roleMap renames role to, or role itself when it has no entry
+func mapRole(roleMap map[string]string, role string) string {
+	if mapped, ok := roleMap[role]; ok {
+		return mapped
+	}
+	return role
+}
+
+// openAIToolChoice converts QueryOptions.ToolChoice to the tool_choice field. "auto", "none"
+// and "required" are sent as is, "any" is an alias for "required", and anything else names the
+// function that must be called.
+func openAIToolChoice(choice string) interface{} {
+	switch choice {
+	case "auto", "none", "required":
+		return choice
+	case "any":
+		return "required"
+	default:
+		return map[string]interface{}{
+			"type":     "function",
+			"function": map[string]interface{}{"name": choice},
+		}
+	}
+}
+
+// mergeTools combines tool sets, keeping only the first tool declared with each function name
+// since APIs reject duplicate functions, and sorts the result by name so requests are
+// reproducible. Earlier sets take precedence.
+func mergeTools(toolSets ...[]provider.Tool) []provider.Tool {
+	var merged []provider.Tool
+	seen := make(map[string]bool)
+	for _, tools := range toolSets {
+		for _, tool := range tools {
+			if seen[tool.Function.Name] {
+				continue
+			}
+			seen[tool.Function.Name] = true
+			merged = append(merged, tool)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Function.Name < merged[j].Function.Name
+	})
+	return merged
+}
+
+// openAIToolMessages builds the messages that continue a conversation after tool calls: the
+// assistant message carrying the tool calls, then one "tool" message per result. Calls without a
+// result, such as those left out under ToolFailureContinue, are dropped from the assistant
+// message, since the API rejects a tool call that isn't answered. Arguments and non-string
+// results are sent as JSON strings, as the OpenAI API expects.
+func openAIToolMessages(content string, toolCalls []provider.ToolCall, results []provider.ToolCallResult) ([]map[string]interface{}, error) {
+	answered := make(map[string]bool, len(results))
+	for _, result := range results {
+		answered[result.ID] = true
+	}
+	replayed := make([]provider.ToolCall, 0, len(results))
+	for _, toolCall := range toolCalls {
+		if answered[toolCall.ID] {
+			replayed = append(replayed, toolCall)
+		}
+	}
+
+	calls, err := openAIToolCalls(replayed)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]map[string]interface{}, 0, len(results)+1)
+	messages = append(messages, map[string]interface{}{
+		"role":       "assistant",
+		"content":    content,
+		"tool_calls": calls,
+	})
+	for _, result := range results {
+		resultContent, err := toolResultContent(result.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result of tool call %s: %w", result.ID, err)
+		}
+		messages = append(messages, map[string]interface{}{
+			"role":         "tool",
+			"tool_call_id": result.ID,
+			"content":      resultContent,
+		})
+	}
+	return messages, nil
+}
+
+// openAIToolCalls converts tool calls to the OpenAI request format, with the arguments as a JSON string
+func openAIToolCalls(toolCalls []provider.ToolCall) ([]map[string]interface{}, error) {
+	calls := make([]map[string]interface{}, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		arguments, err := json.Marshal(toolCall.Function.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal arguments of tool %s: %w", toolCall.Function.Name, err)
+		}
+		callType := toolCall.Type
+		if callType == "" {
+			callType = "function"
+		}
+		calls = append(calls, map[string]interface{}{
+			"id":   toolCall.ID,
+			"type": callType,
+			"function": map[string]interface{}{
+				"name":      toolCall.Function.Name,
+				"arguments": string(arguments),
+			},
+		})
+	}
+	return calls, nil
+}
+
+// addOpenAIToolFields adds the tool calls of an assistant message and the tool call id of a
+// tool message from the history to an OpenAI-format message
+func addOpenAIToolFields(msg map[string]interface{}, message provider.Message) error {
+	if len(message.ToolCalls) > 0 {
+		calls, err := openAIToolCalls(message.ToolCalls)
+		if err != nil {
+			return err
+		}
+		msg["tool_calls"] = calls
+	}
+	if message.ToolCallID != "" {
+		msg["tool_call_id"] = message.ToolCallID
+	}
+	return nil
+}
+
+// toolResultContent returns a tool result as a string, encoding anything but strings as JSON
+func toolResultContent(content interface{}) (string, error) {
+	if s, ok := content.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"fmt"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// mistralDefaultBaseURL is the base URL of the Mistral API
+const mistralDefaultBaseURL = "https://api.mistral.ai/v1"
+
+// MistralOptions holds the request fields that only the Mistral API understands
+type MistralOptions struct {
+	SafePrompt bool // Sent as safe_prompt, which makes Mistral prepend its safety prompt
+}
+
+// newMistralProvider creates a function calling provider for the Mistral chat completions API.
+// Responses use the OpenAI format; requests differ in the Mistral-specific fields, in sending
+// the seed as random_seed, and in leaving out reasoning_effort, which Mistral rejects.
+func newMistralProvider(config provider.Config, baseURL string, options MistralOptions, toolExecutor ToolExecutor, toolConfig ToolExecutionConfig) (provider.Provider, error) {
+	if baseURL == "" {
+		baseURL = mistralDefaultBaseURL
+	}
+
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("%w for Mistral", provider.ErrMissingAPIKey)
+	}
+
+	p, err := newFunctionCallingProvider(config, chatCompletionsURL(baseURL), toolExecutor, toolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	f := p.(*FunctionCallingProvider)
+	f.name = "Mistral"
+	f.adjustRequest = func(requestBody map[string]interface{}) {
+		if seed, ok := requestBody["seed"]; ok {
+			delete(requestBody, "seed")
+			requestBody["random_seed"] = seed
+		}
+		delete(requestBody, "reasoning_effort")
+		if options.SafePrompt {
+			requestBody["safe_prompt"] = true
+		}
+	}
+	return f, nil
+}
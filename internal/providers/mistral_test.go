@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestMistralProvider_Request(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newMistralProvider(provider.Config{
+		APIKey:     "mistral-key",
+		Models:     []string{"mistral-small-latest"},
+		HTTPClient: client,
+	}, "", MistralOptions{SafePrompt: true}, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if p.Name() != "Mistral" {
+		t.Errorf("Expected name Mistral, got %s", p.Name())
+	}
+
+	seed := 7
+	_, err = p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What's the weather in Paris?"}}, provider.QueryOptions{
+		Tools: []provider.Tool{{Type: "function", Function: provider.ToolFunction{
+			Name:       "get_weather",
+			Parameters: map[string]interface{}{"type": "object"},
+		}}},
+		ToolChoice:      "get_weather",
+		Seed:            &seed,
+		ReasoningEffort: "high",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if client.url != "https://api.mistral.ai/v1/chat/completions" {
+		t.Errorf("Expected the default Mistral URL, got %s", client.url)
+	}
+	if client.headers["Authorization"] != "Bearer mistral-key" {
+		t.Errorf("Expected the API key as a bearer token, got %q", client.headers["Authorization"])
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(client.body, &body); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	if body["safe_prompt"] != true {
+		t.Errorf("Expected safe_prompt to be sent, got %v", body["safe_prompt"])
+	}
+	if _, ok := body["seed"]; ok || body["random_seed"] != float64(7) {
+		t.Errorf("Expected the seed to be sent as random_seed, got seed=%v random_seed=%v", body["seed"], body["random_seed"])
+	}
+	if _, ok := body["reasoning_effort"]; ok {
+		t.Errorf("Expected reasoning_effort to be left out, got %v", body["reasoning_effort"])
+	}
+	if tools, ok := body["tools"].([]interface{}); !ok || len(tools) != 1 {
+		t.Errorf("Expected the tool to be sent, got %v", body["tools"])
+	}
+	choice, ok := body["tool_choice"].(map[string]interface{})
+	if !ok || choice["function"].(map[string]interface{})["name"] != "get_weather" {
+		t.Errorf("Expected the named tool choice, got %v", body["tool_choice"])
+	}
+}
+
+func TestMistralProvider_OmitsUnsetFields(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newMistralProvider(provider.Config{
+		APIKey:     "mistral-key",
+		Models:     []string{"mistral-small-latest"},
+		HTTPClient: client,
+	}, "https://proxy.example.com/v1/", MistralOptions{}, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "Hi"}}, 0.7, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.url != "https://proxy.example.com/v1/chat/completions" {
+		t.Errorf("Expected the base URL to be used, got %s", client.url)
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal(client.body, &body)
+	for _, field := range []string{"safe_prompt", "random_seed", "seed"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("Expected %s to be left out, got %v", field, body[field])
+		}
+	}
+}
+
+func TestMistralProvider_ToolCalls(t *testing.T) {
+	client := &recordingHTTPClient{response: `{
+		"model": "mistral-small-latest",
+		"choices": [{
+			"message": {
+				"role": "assistant",
+				"content": "",
+				"tool_calls": [{"id": "D681PevKs", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\": \"Paris\"}"}}]
+			},
+			"finish_reason": "tool_calls"
+		}],
+		"usage": {"prompt_tokens": 20, "completion_tokens": 10, "total_tokens": 30}
+	}`}
+	p, err := newMistralProvider(provider.Config{
+		APIKey:     "mistral-key",
+		Models:     []string{"mistral-small-latest"},
+		HTTPClient: client,
+	}, "", MistralOptions{}, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What's the weather in Paris?"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].ID != "D681PevKs" || result.ToolCalls[0].Function.Name != "get_weather" || result.ToolCalls[0].Function.Arguments["city"] != "Paris" {
+		t.Errorf("Expected the tool call to be parsed, got %+v", result.ToolCalls)
+	}
+	if result.FinishReason != "tool_calls" || result.Usage == nil || result.Usage.TotalTokens != 30 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// modelCatalog holds the models listed by a provider's API. Until the first refresh, the
+// configured models are reported instead. Queries always use the configured models.
+type modelCatalog struct {
+	mu     sync.RWMutex
+	listed []string
+}
+
+// models returns a copy of the listed models, or of configured if none were listed yet
+func (c *modelCatalog) models(configured []string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.listed != nil {
+		return append([]string(nil), c.listed...)
+	}
+	return append([]string(nil), configured...)
+}
+
+func (c *modelCatalog) set(listed []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listed = listed
+}
+
+// noModelsError is returned by a provider asked to query without any configured or forced model
+func noModelsError(providerName string) error {
+	return fmt.Errorf("no models configured for provider %s", providerName)
+}
+
+// modelSelector orders a provider's models for a request according to its ModelStrategy.
+// The zero value tries them in declared order.
+type modelSelector struct {
+	strategy provider.ModelStrategy
+	next     atomic.Uint64
+}
+
+// order returns the models to try, starting with the one picked by the strategy and falling
+// back to the others in declared order. A forced model is the only one tried.
+func (s *modelSelector) order(models []string, forceModel string) []string {
+	if forceModel != "" {
+		return []string{forceModel}
+	}
+	if len(models) < 2 {
+		return models
+	}
+
+	var first int
+	switch s.strategy {
+	case provider.ModelStrategyRoundRobin:
+		first = int((s.next.Add(1) - 1) % uint64(len(models)))
+	case provider.ModelStrategyRandom:
+		first = rand.Intn(len(models))
+	default:
+		return models
+	}
+
+	ordered := make([]string, 0, len(models))
+	ordered = append(ordered, models[first])
+	ordered = append(ordered, models[:first]...)
+	return append(ordered, models[first+1:]...)
+}
+
+// fetchOpenAIModels lists the model ids served by an OpenAI-compatible /models endpoint
+func fetchOpenAIModels(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration) ([]string, error) {
+	resp, _, err := client.Do(ctx, url, "GET", headers, nil, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, provider.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	models := make([]string, 0, len(result.Data))
+	for _, model := range result.Data {
+		if model.ID != "" {
+			models = append(models, model.ID)
+		}
+	}
+	return models, nil
+}
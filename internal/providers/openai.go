@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"fmt"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// openAIDefaultBaseURL is the base URL of the OpenAI API
+const openAIDefaultBaseURL = "https://api.openai.com/v1"
+
+// newOpenAIProvider creates a function calling provider for the OpenAI chat completions API.
+// baseURL defaults to the OpenAI API; orgID, when set, is sent as the OpenAI-Organization header.
+func newOpenAIProvider(config provider.Config, baseURL string, orgID string, toolExecutor ToolExecutor, toolConfig ToolExecutionConfig) (provider.Provider, error) {
+	if baseURL == "" {
+		baseURL = openAIDefaultBaseURL
+	}
+
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("%w for OpenAI", provider.ErrMissingAPIKey)
+	}
+
+	p, err := newFunctionCallingProvider(config, chatCompletionsURL(baseURL), toolExecutor, toolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	f := p.(*FunctionCallingProvider)
+	f.name = "OpenAI"
+	if orgID != "" {
+		f.headers = map[string]string{"OpenAI-Organization": orgID}
+	}
+	return f, nil
+}
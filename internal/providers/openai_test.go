@@ -0,0 +1,443 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// recordingHTTPClient captures the request instead of sending it and answers with response,
+// or with a chat completion if response is empty
+type recordingHTTPClient struct {
+	url      string
+	headers  map[string]string
+	body     []byte
+	response string
+}
+
+func (c *recordingHTTPClient) Do(ctx context.Context, url string, method string, headers map[string]string, body io.Reader, timeout time.Duration) (*http.Response, string, error) {
+	c.url = url
+	c.headers = headers
+	if body != nil {
+		c.body, _ = io.ReadAll(body)
+	}
+
+	response := c.response
+	if response == "" {
+		response = `{"model":"gpt-4o-mini","choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(response)),
+	}, url, nil
+}
+
+func TestOpenAIProvider_DefaultURLAndOrgHeader(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"gpt-4o-mini"},
+		HTTPClient: client,
+	}, "", "org-123", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if p.Name() != "OpenAI" {
+		t.Errorf("Expected name OpenAI, got %s", p.Name())
+	}
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "Hi"}}, 0.7, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if client.url != "https://api.openai.com/v1/chat/completions" {
+		t.Errorf("Expected the default OpenAI URL, got %s", client.url)
+	}
+	if client.headers["OpenAI-Organization"] != "org-123" {
+		t.Errorf("Expected the organization header, got %v", client.headers)
+	}
+	if client.headers["Authorization"] != "Bearer sk-test" {
+		t.Errorf("Expected the API key as a bearer token, got %q", client.headers["Authorization"])
+	}
+}
+
+func TestOpenAIProvider_BaseURL(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"gpt-4o-mini"},
+		HTTPClient: client,
+	}, "https://proxy.example.com/v1/", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "Hi"}}, 0.7, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if client.url != "https://proxy.example.com/v1/chat/completions" {
+		t.Errorf("Expected the overridden base URL, got %s", client.url)
+	}
+	if _, ok := client.headers["OpenAI-Organization"]; ok {
+		t.Errorf("Expected no organization header without an OrgID")
+	}
+}
+
+func TestOpenAIProvider_QueryParams(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:      "sk-test",
+		Models:      []string{"gpt-4o-mini"},
+		HTTPClient:  client,
+		QueryParams: map[string]string{"api-version": "2024-10-21", "route": "eu west/1"},
+	}, "https://gateway.example.com/openai/v1/?api-version=2024-02-01&team=a%26b", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "Hi"}}, 0.7, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Parameters are sorted and escaped, and the configured ones replace those in the URL
+	want := "https://gateway.example.com/openai/v1/chat/completions?api-version=2024-10-21&route=eu+west%2F1&team=a%26b"
+	if client.url != want {
+		t.Errorf("Expected the merged query parameters\n got: %s\nwant: %s", client.url, want)
+	}
+
+	// URLs derived from the chat endpoint get the same parameters
+	client.response = `{"data":[{"index":0,"embedding":[0.1]}]}`
+	embedder := p.(provider.Embedder)
+	if _, err := embedder.Embeddings(context.Background(), provider.EmbeddingRequest{Model: "text-embedding-3-small", Input: []string{"hi"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "https://gateway.example.com/openai/v1/embeddings?api-version=2024-10-21&route=eu+west%2F1&team=a%26b"; client.url != want {
+		t.Errorf("Expected the query parameters on the embeddings URL\n got: %s\nwant: %s", client.url, want)
+	}
+}
+
+func TestOpenAIProvider_GenerateImage(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &request)
+		w.Write([]byte(`{"created":1,"data":[{"b64_json":"` + base64.StdEncoding.EncodeToString([]byte("png-bytes")) + `"}],"usage":{"input_tokens":10,"output_tokens":20,"total_tokens":30}}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"gpt-4o-mini"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL+"/v1", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	generator := p.(provider.ImageGenerator)
+	if !generator.SupportsImageGeneration() {
+		t.Fatal("Expected the OpenAI provider to support image generation")
+	}
+
+	result, err := generator.GenerateImage(context.Background(), provider.ImageRequest{Prompt: "a lighthouse", Size: "1024x1024"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != "/v1/images/generations" {
+		t.Errorf("Unexpected request path %s", path)
+	}
+	if request["model"] != openAIDefaultImageModel || request["prompt"] != "a lighthouse" || request["size"] != "1024x1024" {
+		t.Errorf("Unexpected request: %v", request)
+	}
+	if _, ok := request["response_format"]; ok {
+		t.Error("Expected no response_format for gpt-image-1")
+	}
+	if len(result.Images) != 1 || string(result.Images[0].Data) != "png-bytes" || result.Images[0].MimeType != "image/png" {
+		t.Errorf("Expected the decoded image, got %+v", result.Images)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 30 {
+		t.Errorf("Expected usage to be parsed, got %+v", result.Usage)
+	}
+}
+
+func TestOpenAIProvider_TranscribeMultipartBody(t *testing.T) {
+	client := &recordingHTTPClient{response: `{"text":"Hello there."}`}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"gpt-4o-mini"},
+		HTTPClient: client,
+	}, "", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.(provider.Transcriber).Transcribe(context.Background(), provider.TranscriptionRequest{
+		Audio:    provider.File{Type: "audio", Name: "greeting.mp3", MimeType: "audio/mpeg", Data: []byte("mp3-bytes")},
+		Language: "en",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Text != "Hello there." || result.Model != "whisper-1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if client.url != "https://api.openai.com/v1/audio/transcriptions" {
+		t.Errorf("Unexpected URL %s", client.url)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(client.headers["Content-Type"])
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Expected a multipart content type, got %q", client.headers["Content-Type"])
+	}
+	form, err := multipart.NewReader(bytes.NewReader(client.body), params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("Failed to parse the multipart body: %v", err)
+	}
+
+	files := form.File["file"]
+	if len(files) != 1 || files[0].Filename != "greeting.mp3" || files[0].Header.Get("Content-Type") != "audio/mpeg" {
+		t.Fatalf("Expected the audio in the file field, got %+v", files)
+	}
+	file, _ := files[0].Open()
+	data, _ := io.ReadAll(file)
+	if string(data) != "mp3-bytes" {
+		t.Errorf("Expected the audio bytes, got %q", data)
+	}
+	if form.Value["model"][0] != "whisper-1" || form.Value["language"][0] != "en" {
+		t.Errorf("Expected the model and language fields, got %v", form.Value)
+	}
+}
+
+func TestOpenAIProvider_RequestModifier(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"gpt-4o-mini"},
+		HTTPClient: client,
+		RequestModifier: func(body map[string]interface{}) {
+			body["service_tier"] = "flex"
+		},
+	}, "", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "Hi"}}, 0.7, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(client.body, &body); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	if body["service_tier"] != "flex" || body["model"] != "gpt-4o-mini" {
+		t.Errorf("Expected the injected field alongside the library's fields, got %v", body)
+	}
+}
+
+func TestOpenAIProvider_MessageName(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"gpt-4o-mini"},
+		HTTPClient: client,
+	}, "", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "assistant", Name: "researcher", Content: "Here are the sources."},
+		{Role: "user", Content: "Summarize them."},
+	}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var body struct {
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	if err := json.Unmarshal(client.body, &body); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	if body.Messages[0]["name"] != "researcher" {
+		t.Errorf("Expected the message name to be sent, got %v", body.Messages[0])
+	}
+	if _, ok := body.Messages[1]["name"]; ok {
+		t.Errorf("Expected no name on a message without one, got %v", body.Messages[1])
+	}
+}
+
+func TestOpenAIProvider_RoleMap(t *testing.T) {
+	client := &recordingHTTPClient{}
+	p, err := newOpenAIProvider(provider.Config{
+		APIKey:     "sk-test",
+		Models:     []string{"o3-mini"},
+		HTTPClient: client,
+		RoleMap:    map[string]string{"system": "developer"},
+	}, "", "", nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "system", Content: "Answer in French."},
+		{Role: "user", Content: "Hello"},
+	}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var body struct {
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	if err := json.Unmarshal(client.body, &body); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	if body.Messages[0]["role"] != "developer" {
+		t.Errorf("Expected the system message to be sent as a developer message, got %v", body.Messages[0])
+	}
+	if body.Messages[1]["role"] != "user" {
+		t.Errorf("Expected roles without an entry to be unchanged, got %v", body.Messages[1])
+	}
+}
+
+func TestOpenAIProvider_ResponseEnvelopeVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		content  string
+		tool     string
+	}{
+		{
+			name:     "legacy completions text",
+			response: `{"choices":[{"text":"Hello from text","index":0,"finish_reason":"stop"}]}`,
+			content:  "Hello from text",
+		},
+		{
+			name:     "delta instead of message",
+			response: `{"choices":[{"delta":{"role":"assistant","content":"Hello from delta"},"finish_reason":"stop"}]}`,
+			content:  "Hello from delta",
+		},
+		{
+			name:     "empty message next to text",
+			response: `{"choices":[{"message":{"role":"assistant","content":null},"text":"Hello anyway","finish_reason":"stop"}],"extra":{"gateway":"x"}}`,
+			content:  "Hello anyway",
+		},
+		{
+			name:     "tool calls in delta",
+			response: `{"choices":[{"delta":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`,
+			tool:     "get_weather",
+		},
+		{
+			name:     "message content wins",
+			response: `{"choices":[{"message":{"content":"From message"},"text":"From text","finish_reason":"stop"}]}`,
+			content:  "From message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newOpenAIProvider(provider.Config{
+				APIKey:     "sk-test",
+				Models:     []string{"gpt-4o-mini"},
+				HTTPClient: &recordingHTTPClient{response: tt.response},
+			}, "", "", nil, ToolExecutionConfig{})
+			if err != nil {
+				t.Fatalf("Failed to create provider: %v", err)
+			}
+
+			result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Content != tt.content {
+				t.Errorf("Expected content %q, got %q", tt.content, result.Content)
+			}
+			if tt.tool != "" && (len(result.ToolCalls) != 1 || result.ToolCalls[0].Function.Name != tt.tool || result.ToolCalls[0].Function.Arguments["city"] != "Paris") {
+				t.Errorf("Expected the %s tool call, got %+v", tt.tool, result.ToolCalls)
+			}
+		})
+	}
+}
+
+func TestOpenAIProvider_IncludeRawResponse(t *testing.T) {
+	response := `{"model":"gpt-4o-mini","choices":[{"message":{"content":"ok","annotations":[{"type":"url_citation","url":"https://example.com"}]},"finish_reason":"stop"}],"vendor":{"region":"eu"}}`
+	newProvider := func(includeRaw bool) provider.Provider {
+		p, err := newOpenAIProvider(provider.Config{
+			APIKey:             "sk-test",
+			Models:             []string{"gpt-4o-mini"},
+			HTTPClient:         &recordingHTTPClient{response: response},
+			IncludeRawResponse: includeRaw,
+		}, "", "", nil, ToolExecutionConfig{})
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		return p
+	}
+
+	result, err := newProvider(true).QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(result.Raw) != response {
+		t.Errorf("Expected the raw body to round-trip, got %s", result.Raw)
+	}
+	var raw struct {
+		Vendor struct {
+			Region string `json:"region"`
+		} `json:"vendor"`
+	}
+	if err := json.Unmarshal(result.Raw, &raw); err != nil || raw.Vendor.Region != "eu" {
+		t.Errorf("Expected vendor fields to be decodable from Raw, got %+v (%v)", raw, err)
+	}
+
+	result, err = newProvider(false).QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Raw != nil {
+		t.Errorf("Expected no raw response unless enabled, got %s", result.Raw)
+	}
+}
+
+func TestOpenAIProvider_MaxResponseBytes(t *testing.T) {
+	large := `{"choices":[{"message":{"content":"` + strings.Repeat("a", 4096) + `"},"finish_reason":"stop"}]}`
+	newProvider := func(limit int64) provider.Provider {
+		p, err := newOpenAIProvider(provider.Config{
+			APIKey:           "sk-test",
+			Models:           []string{"gpt-4o-mini"},
+			HTTPClient:       &recordingHTTPClient{response: large},
+			MaxResponseBytes: limit,
+		}, "", "", nil, ToolExecutionConfig{})
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		return p
+	}
+
+	_, err := newProvider(1024).QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if !errors.Is(err, provider.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+
+	result, err := newProvider(0).QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil || len(result.Content) != 4096 {
+		t.Errorf("Expected the default limit to allow the response, got %v", err)
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
@@ -16,54 +16,112 @@ import (
 
 // OpenRouterProvider implements the Provider interface for OpenRouter API
 type OpenRouterProvider struct {
-	apiKey               string
-	url                  string
-	timeout              time.Duration
-	client               httpclient.Client
-	models               []string
-	referer              string
-	xTitle               string
-	maxDailyRequests     int
-	maxRequestsPerMinute int
-	maxTokensPerMinute   int
-	rank                 int
-	requestsToday        int
-	requestsThisMinute   int
-	tokensThisMinute     int
-	lastReset            time.Time
-	lastMinuteReset      time.Time
+	apiKey         string
+	url            string
+	timeout        time.Duration
+	maxResponse    int64 // Response body size limit
+	client         httpclient.Client
+	models         []string
+	catalog        modelCatalog // Models listed by RefreshModels
+	referer        string
+	xTitle         string
+	routing        OpenRouterRouting
+	rank           int
+	weight         int
+	tokenEstimator provider.TokenEstimator
+	contextWindow  int
+	limiter        *rateLimiter
+	modelLimits    modelRateLimiters // Limiters of the models with their own ModelLimits
+	debug          *debugRecorder    // nil unless debug mode is enabled
+	lifecycle      *lifecycle
+	selector       modelSelector
+	toolsDisabled  bool
+	images         *imageResizer                     // nil unless AutoResizeImages is set
+	includeRaw     bool                              // Sets QueryResult.Raw on responses
+	clampTemp      bool                              // Clamps out-of-range temperatures instead of failing
+	concurrency    *concurrencyLimiter               // nil unless MaxConcurrent is set
+	roleMap        map[string]string                 // Renames message roles before sending, nil if not set
+	modifyRequest  func(body map[string]interface{}) // Caller's RequestModifier, nil if not set
 }
 
 var _ provider.Provider = (*OpenRouterProvider)(nil)
+var _ provider.TokenEstimator = (*OpenRouterProvider)(nil)
+var _ provider.Weighted = (*OpenRouterProvider)(nil)
+var _ provider.ToolCapable = (*OpenRouterProvider)(nil)
+var _ provider.TimeoutAware = (*OpenRouterProvider)(nil)
+var _ provider.HealthChecker = (*OpenRouterProvider)(nil)
+var _ provider.Warmer = (*OpenRouterProvider)(nil)
+var _ provider.Embedder = (*OpenRouterProvider)(nil)
+var _ provider.ModelRefresher = (*OpenRouterProvider)(nil)
+var _ provider.DebugRecorder = (*OpenRouterProvider)(nil)
+var _ provider.RawRequester = (*OpenRouterProvider)(nil)
+
+// OpenRouterRouting configures OpenRouter's server-side routing of a request
+type OpenRouterRouting struct {
+	ProviderPreferences map[string]interface{} // Sent as the "provider" preferences object
+	FallbackModels      []string               // Sent as "models", tried by OpenRouter after the requested model
+}
+
+// newOpenRouterProvider creates a new OpenRouter provider. routing may be nil.
+func newOpenRouterProvider(config provider.Config, url string, referer string, xTitle string, routing *OpenRouterRouting) (provider.Provider, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("%w for OpenRouter", provider.ErrMissingAPIKey)
+	}
 
-// newOpenRouterProvider creates a new OpenRouter provider
-func newOpenRouterProvider(apiKey string, url string, timeout time.Duration, models []string, referer string, xTitle string, httpClient httpclient.Client, maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute, rank int) (provider.Provider, error) {
-	now := time.Now()
-	return &OpenRouterProvider{
-		url:                  url,
-		apiKey:               apiKey,
-		timeout:              timeout,
-		models:               models,
-		client:               httpClient,
-		referer:              referer,
-		xTitle:               xTitle,
-		maxDailyRequests:     maxDailyRequests,
-		maxRequestsPerMinute: maxRequestsPerMinute,
-		maxTokensPerMinute:   maxTokensPerMinute,
-		rank:                 rank,
-		requestsToday:        0,
-		requestsThisMinute:   0,
-		tokensThisMinute:     0,
-		lastReset:            now.Truncate(24 * time.Hour),
-		lastMinuteReset:      now.Truncate(time.Minute),
-	}, nil
+	limiter, err := newConfiguredRateLimiter(config, "OpenRouter", url)
+	if err != nil {
+		return nil, err
+	}
+	modelLimits, err := newModelRateLimiters(config, "OpenRouter", url)
+	if err != nil {
+		return nil, err
+	}
+
+	debug := newDebugRecorder(config, "OpenRouter")
+	client, url := withQueryParams(debug.wrap(config.HTTPClient), url, config.QueryParams)
+	p := &OpenRouterProvider{
+		url:            url,
+		apiKey:         config.APIKey,
+		timeout:        config.Timeout,
+		maxResponse:    maxResponseBytesOrDefault(config.MaxResponseBytes),
+		models:         config.Models,
+		client:         client,
+		referer:        referer,
+		xTitle:         xTitle,
+		rank:           config.Rank,
+		weight:         config.Weight,
+		tokenEstimator: tokenEstimatorOrDefault(config.TokenEstimator),
+		contextWindow:  config.MaxContextTokens,
+		limiter:        limiter,
+		modelLimits:    modelLimits,
+		debug:          debug,
+		lifecycle:      newLifecycle(),
+		selector:       modelSelector{strategy: config.ModelStrategy},
+		toolsDisabled:  config.DisableTools,
+		images:         newImageResizer(config),
+		includeRaw:     config.IncludeRawResponse,
+		clampTemp:      config.ClampTemperature,
+		concurrency:    newConcurrencyLimiter(config),
+		roleMap:        config.RoleMap,
+		modifyRequest:  config.RequestModifier,
+	}
+	if routing != nil {
+		p.routing = *routing
+	}
+	return p, nil
+}
+
+// EstimateTokens estimates the tokens in the messages using the provider's token estimator
+func (o *OpenRouterProvider) EstimateTokens(messages []provider.Message) int {
+	return o.tokenEstimator.EstimateTokens(messages)
 }
 
 // Query sends a prompt to OpenRouter and returns the response (legacy method)
 func (o *OpenRouterProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
 	options := provider.QueryOptions{
-		Temperature: temperature,
-		ForceModel:  forceModel,
+		Temperature:    temperature,
+		TemperatureSet: true,
+		ForceModel:     forceModel,
 	}
 
 	result, err := o.QueryWithOptions(ctx, messages, options)
@@ -76,24 +134,58 @@ func (o *OpenRouterProvider) Query(ctx context.Context, messages []provider.Mess
 
 // QueryWithOptions sends a prompt to OpenRouter with advanced options including tool calls
 func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
-	var outerErr error
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 
-	if time.Since(o.lastReset) > 24*time.Hour {
-		o.requestsToday = 0
-		o.lastReset = time.Now().Truncate(24 * time.Hour)
+	release, err := o.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
+
+	ctx, cancel, timeout := withQueryTimeout(ctx, o.timeout, options)
+	defer cancel()
 
-	modelsToUse := o.models
-	if options.ForceModel != "" {
-		modelsToUse = []string{options.ForceModel}
+	messages = withSystemPrompt(messages, options.SystemPrompt)
+	messages = provider.TrimToContextWindowWithEstimator(o.tokenEstimator, messages, o.contextWindow, true)
+	messages, err = o.images.apply(messages)
+	if err != nil {
+		return nil, err
+	}
+	options, err = openAITemperatureRange.apply(options, o.clampTemp)
+	if err != nil {
+		return nil, err
+	}
+	options.Tools = mergeTools(options.Tools)
+
+	var outerErr error
+
+	modelsToUse := o.selector.order(o.models, options.ForceModel)
+	if len(modelsToUse) == 0 {
+		return nil, noModelsError(o.Name())
 	}
 
+	var attempts modelAttempts
 	for _, model := range modelsToUse {
+		attempts.next(model, outerErr)
+
+		// Models with their own limits are skipped once they have used them up
+		if err := o.modelLimits.checkModel(model, o.limiter, o, messages); err != nil {
+			outerErr = err
+			continue
+		}
+
 		// Convert messages to OpenRouter format with file support
 		openRouterMessages := make([]map[string]interface{}, 0, len(messages))
 		for _, message := range messages {
 			msg := map[string]interface{}{
-				"role": message.Role,
+				"role": mapRole(o.roleMap, message.Role),
+			}
+			if message.Name != "" {
+				msg["name"] = message.Name
 			}
 
 			// Handle content and files
@@ -103,36 +195,47 @@ func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []pr
 
 				// Add text content if present
 				if message.Content != "" {
-					content = append(content, map[string]interface{}{
-						"type": "text",
-						"text": message.Content,
-					})
+					content = append(content, openRouterTextPart(message))
 				}
 
 				// Add file attachments
 				for _, file := range message.Files {
+					// Remote files are passed by URL; local data is sent as a data URL
+					url := file.URL
+					if url == "" {
+						url = fmt.Sprintf("data:%s;base64,%s", file.MimeType, base64.StdEncoding.EncodeToString(file.Data))
+					}
 					fileContent := map[string]interface{}{
 						"type": "image_url",
 						"image_url": map[string]interface{}{
-							"url": fmt.Sprintf("data:%s;base64,%s", file.MimeType, base64.StdEncoding.EncodeToString(file.Data)),
+							"url": url,
 						},
 					}
 					content = append(content, fileContent)
 				}
 
 				msg["content"] = content
+			} else if message.Cacheable && message.Content != "" {
+				// The cache marker can only be set on a content part
+				msg["content"] = []map[string]interface{}{openRouterTextPart(message)}
 			} else {
 				// Simple text message
 				msg["content"] = message.Content
 			}
 
+			if err := addOpenAIToolFields(msg, message); err != nil {
+				return nil, err
+			}
+
 			openRouterMessages = append(openRouterMessages, msg)
 		}
 
 		requestBody := map[string]interface{}{
-			"model":       model,
-			"messages":    openRouterMessages,
-			"temperature": options.Temperature,
+			"model":    model,
+			"messages": openRouterMessages,
+		}
+		if options.HasTemperature() {
+			requestBody["temperature"] = options.Temperature
 		}
 
 		// Add tools if provided
@@ -142,7 +245,39 @@ func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []pr
 
 		// Add tool_choice if provided
 		if options.ToolChoice != "" {
-			requestBody["tool_choice"] = options.ToolChoice
+			requestBody["tool_choice"] = openAIToolChoice(options.ToolChoice)
+		}
+		if options.ParallelToolCalls != nil {
+			requestBody["parallel_tool_calls"] = *options.ParallelToolCalls
+		}
+
+		if options.N > 1 {
+			requestBody["n"] = options.N
+		}
+		if options.Seed != nil {
+			requestBody["seed"] = *options.Seed
+		}
+		if reasoning := openRouterReasoning(options); reasoning != nil {
+			requestBody["reasoning"] = reasoning
+		}
+		if options.JSONMode {
+			requestBody["response_format"] = map[string]interface{}{"type": "json_object"}
+		}
+		if len(o.routing.ProviderPreferences) > 0 {
+			requestBody["provider"] = o.routing.ProviderPreferences
+		}
+		if len(o.routing.FallbackModels) > 0 {
+			requestBody["models"] = o.routing.FallbackModels
+		}
+		if options.LogProbs {
+			requestBody["logprobs"] = true
+			if options.TopLogProbs > 0 {
+				requestBody["top_logprobs"] = options.TopLogProbs
+			}
+		}
+		// The caller's modifier runs last so it can override any field set above
+		if o.modifyRequest != nil {
+			o.modifyRequest(requestBody)
 		}
 
 		jsonData, err := json.Marshal(requestBody)
@@ -151,12 +286,7 @@ func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []pr
 			continue
 		}
 
-		resp, _, err := o.client.Do(ctx, o.url, "POST", map[string]string{
-			"Authorization": "Bearer " + o.apiKey,
-			"Content-Type":  "application/json",
-			"HTTP-Referer":  o.referer,
-			"X-Title":       o.xTitle,
-		}, bytes.NewBuffer(jsonData), o.timeout)
+		resp, _, err := o.client.Do(ctx, o.url, "POST", o.headers("application/json"), bytes.NewBuffer(jsonData), timeout)
 
 		if err != nil {
 			outerErr = fmt.Errorf("failed to create request: %w", err)
@@ -165,33 +295,27 @@ func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []pr
 
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
+		body, err := readResponse(resp.Body, o.maxResponse)
 		if err != nil {
-			outerErr = fmt.Errorf("failed to read response body: %w", err)
+			outerErr = err
 			continue
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			outerErr = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			outerErr = provider.NewAPIError(resp.StatusCode, string(body))
 			continue
 		}
 
 		// Update rate limiting counters
-		o.requestsToday++
-		o.requestsThisMinute++
-
-		// Estimate tokens for this request (rough approximation)
-		estimatedTokens := estimateTokensForMessages(messages)
-		o.tokensThisMinute += estimatedTokens
+		limiter := o.modelLimits.forModel(model, o.limiter)
+		limiter.recordRequest()
+		limiter.recordTokens(o.EstimateTokens(messages))
 
 		var result struct {
-			Choices []struct {
-				Message struct {
-					Content   string              `json:"content"`
-					ToolCalls []provider.ToolCall `json:"tool_calls,omitempty"`
-				} `json:"message"`
-				FinishReason string `json:"finish_reason"`
-			} `json:"choices"`
+			Model             string          `json:"model"`
+			Choices           []openAIChoice  `json:"choices"`
+			SystemFingerprint string          `json:"system_fingerprint"`
+			Usage             *provider.Usage `json:"usage"`
 		}
 
 		if err := json.Unmarshal(body, &result); err != nil {
@@ -203,14 +327,32 @@ func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []pr
 			outerErr = fmt.Errorf("no response choices received")
 			continue
 		}
+		normalizeOpenAIChoices(result.Choices, o.debug)
+
+		// OpenRouter reports the model that actually served the request, which may differ from the requested id
+		resolvedModel := result.Model
+		if resolvedModel == "" {
+			resolvedModel = model
+		}
 
 		choice := result.Choices[0]
 		queryResult := &provider.QueryResult{
-			Content:      choice.Message.Content,
-			Model:        model,
-			ToolCalls:    choice.Message.ToolCalls,
-			FinishReason: choice.FinishReason,
+			Content:           choice.Message.Content,
+			Reasoning:         openAIReasoning(choice.Message.Reasoning, choice.Message.ReasoningContent),
+			Model:             resolvedModel,
+			ToolCalls:         choice.Message.ToolCalls,
+			FinishReason:      choice.FinishReason,
+			Usage:             result.Usage,
+			SystemFingerprint: result.SystemFingerprint,
+			LogProbs:          choice.LogProbs.tokens(),
+		}
+		if options.N > 1 {
+			queryResult.Completions = openAICompletions(result.Choices)
+		}
+		if o.includeRaw {
+			queryResult.Raw = body
 		}
+		queryResult.ModelAttempts = attempts.succeeded()
 
 		return queryResult, nil
 	}
@@ -218,41 +360,198 @@ func (o *OpenRouterProvider) QueryWithOptions(ctx context.Context, messages []pr
 	return nil, outerErr
 }
 
-// Close closes the OpenRouter provider
+// openRouterTextPart returns the message's text as a content part, with a cache_control marker
+// when the message is cacheable. OpenRouter passes the marker on to the providers that support
+// prompt caching, such as Anthropic, and drops it for the others.
+func openRouterTextPart(message provider.Message) map[string]interface{} {
+	part := map[string]interface{}{
+		"type": "text",
+		"text": message.Content,
+	}
+	if message.Cacheable {
+		part["cache_control"] = map[string]interface{}{"type": "ephemeral"}
+	}
+	return part
+}
+
+// SupportsEmbeddings reports whether the provider's endpoint has an embeddings counterpart
+func (o *OpenRouterProvider) SupportsEmbeddings() bool {
+	return embeddingsURL(o.url) != ""
+}
+
+// Embeddings creates vector embeddings through OpenRouter's embeddings endpoint
+func (o *OpenRouterProvider) Embeddings(ctx context.Context, request provider.EmbeddingRequest) (*provider.EmbeddingResult, error) {
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := o.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	url := embeddingsURL(o.url)
+	if url == "" {
+		return nil, fmt.Errorf("embeddings are not supported for endpoint %s", o.url)
+	}
+
+	result, err := requestOpenAIEmbeddings(ctx, o.client, url, o.headers("application/json"), o.timeout, request)
+	if err != nil {
+		return nil, err
+	}
+
+	o.limiter.recordRequest()
+	o.limiter.recordTokens(o.EstimateTokens(embedInputMessages(request.Input)))
+	return result, nil
+}
+
+// SupportsRawRequests reports whether the provider's endpoint follows the OpenAI layout, so
+// other paths can be resolved against its base URL
+func (o *OpenRouterProvider) SupportsRawRequests() bool {
+	return rawURL(o.url, "") != ""
+}
+
+// DoRaw sends a request to another OpenRouter endpoint with the provider's credentials. It
+// counts against the daily and per-minute request limits.
+func (o *OpenRouterProvider) DoRaw(ctx context.Context, path string, body interface{}) ([]byte, error) {
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := o.concurrency.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	url := rawURL(o.url, path)
+	if url == "" {
+		return nil, fmt.Errorf("raw requests are not supported for endpoint %s", o.url)
+	}
+
+	data, err := requestRaw(ctx, o.client, url, o.headers("application/json"), o.timeout, o.maxResponse, body)
+	if err != nil {
+		return nil, err
+	}
+
+	o.limiter.recordRequest()
+	return data, nil
+}
+
+// HealthCheck verifies the API key with OpenRouter's key endpoint, which doesn't use any quota.
+// Endpoints that don't follow the OpenRouter layout are checked with a one-token completion.
+func (o *OpenRouterProvider) HealthCheck(ctx context.Context) error {
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	headers := o.headers("application/json")
+
+	if strings.HasSuffix(o.url, "/chat/completions") {
+		return checkHealthGET(ctx, o.client, strings.TrimSuffix(o.url, "/chat/completions")+"/auth/key", headers, o.timeout)
+	}
+	if len(o.models) == 0 {
+		return noModelsError(o.Name())
+	}
+	return checkHealthCompletion(ctx, o.client, o.url, headers, o.timeout, o.models[0])
+}
+
+// Warmup opens a connection to the API host ahead of the first query. It doesn't count against
+// the rate limits.
+func (o *OpenRouterProvider) Warmup(ctx context.Context) error {
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return warmConnection(ctx, o.client, o.url, o.timeout)
+}
+
+// headers returns the headers sent with every request. The optional HTTP-Referer and X-Title
+// attribution headers are left out when they aren't configured.
+func (o *OpenRouterProvider) headers(contentType string) map[string]string {
+	headers := map[string]string{"Authorization": "Bearer " + o.apiKey}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	if o.referer != "" {
+		headers["HTTP-Referer"] = o.referer
+	}
+	if o.xTitle != "" {
+		headers["X-Title"] = o.xTitle
+	}
+	return headers
+}
+
+// Models returns the configured models, or the models listed by the last RefreshModels
+func (o *OpenRouterProvider) Models() []string {
+	return o.catalog.models(o.models)
+}
+
+// RefreshModels lists the models available on OpenRouter
+func (o *OpenRouterProvider) RefreshModels(ctx context.Context) error {
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	url := modelsURL(o.url)
+	if url == "" {
+		return fmt.Errorf("listing models is not supported for endpoint %s", o.url)
+	}
+
+	models, err := fetchOpenAIModels(ctx, o.client, url, o.headers(""), o.timeout)
+	if err != nil {
+		return err
+	}
+	o.catalog.set(models)
+	return nil
+}
+
+// LastRawExchange returns the last raw request and response, or nil unless debug mode is enabled
+func (o *OpenRouterProvider) LastRawExchange() *provider.RawExchange {
+	return o.debug.lastExchange()
+}
+
+// Close closes the OpenRouter provider and saves its final rate-limit counters to the RateStore, if any.
+// Later calls do nothing.
 func (o *OpenRouterProvider) Close() {
-	// No cleanup needed for HTTP client
+	if o.lifecycle.close() {
+		o.limiter.flush()
+		o.modelLimits.flush()
+	}
 }
 
 // HasRemainingRequests checks if the provider has remaining requests
 func (o *OpenRouterProvider) HasRemainingRequests(ctx context.Context) bool {
-	// Reset daily counter if needed
-	if time.Since(o.lastReset) > 24*time.Hour {
-		o.requestsToday = 0
-		o.lastReset = time.Now().Truncate(24 * time.Hour)
-	}
-	return o.maxDailyRequests == 0 || o.requestsToday < o.maxDailyRequests
+	return o.modelLimits.anyRemaining(o.limiter, (*rateLimiter).hasRemainingRequests)
 }
 
 // HasRemainingRequestsPerMinute checks if the provider has remaining requests per minute
 func (o *OpenRouterProvider) HasRemainingRequestsPerMinute(ctx context.Context) bool {
-	// Reset minute counter if needed
-	if time.Since(o.lastMinuteReset) > time.Minute {
-		o.requestsThisMinute = 0
-		o.tokensThisMinute = 0
-		o.lastMinuteReset = time.Now().Truncate(time.Minute)
-	}
-	return o.maxRequestsPerMinute == 0 || o.requestsThisMinute < o.maxRequestsPerMinute
+	return o.modelLimits.anyRemaining(o.limiter, (*rateLimiter).hasRemainingRequestsPerMinute)
 }
 
 // HasRemainingTokensPerMinute checks if the provider has remaining tokens per minute
 func (o *OpenRouterProvider) HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool {
-	// Reset minute counter if needed
-	if time.Since(o.lastMinuteReset) > time.Minute {
-		o.requestsThisMinute = 0
-		o.tokensThisMinute = 0
-		o.lastMinuteReset = time.Now().Truncate(time.Minute)
-	}
-	return o.maxTokensPerMinute == 0 || (o.tokensThisMinute+estimatedTokens) <= o.maxTokensPerMinute
+	return o.modelLimits.anyRemaining(o.limiter, func(l *rateLimiter) bool {
+		return l.hasRemainingTokensPerMinute(estimatedTokens)
+	})
+}
+
+// ResetLimits clears the provider's rate-limit counters
+func (o *OpenRouterProvider) ResetLimits() {
+	o.limiter.reset()
+	o.modelLimits.reset()
 }
 
 // GetRank returns the provider's rank
@@ -260,7 +559,36 @@ func (o *OpenRouterProvider) GetRank() int {
 	return o.rank
 }
 
+// GetWeight returns the provider's load-balancing weight
+func (o *OpenRouterProvider) GetWeight() int {
+	return o.weight
+}
+
+// SupportsTools reports whether tool calls are enabled for the provider
+func (o *OpenRouterProvider) SupportsTools() bool {
+	return !o.toolsDisabled
+}
+
+// GetTimeout returns the provider's per-request timeout, 0 if none is configured
+func (o *OpenRouterProvider) GetTimeout() time.Duration {
+	return o.timeout
+}
+
 // Name returns the name of the provider
 func (o *OpenRouterProvider) Name() string {
 	return "OpenRouter"
 }
+
+// openRouterReasoning builds OpenRouter's unified "reasoning" object, which it translates for
+// each upstream model. OpenRouter accepts an effort or a token budget, so the effort wins when
+// both are set. It returns nil when neither is.
+func openRouterReasoning(options provider.QueryOptions) map[string]interface{} {
+	switch {
+	case options.ReasoningEffort != "":
+		return map[string]interface{}{"effort": options.ReasoningEffort}
+	case options.ThinkingBudget != nil:
+		return map[string]interface{}{"max_tokens": *options.ThinkingBudget}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,1100 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// newTestOpenRouterServer answers every request with "ok" and passes the decoded request body to record
+func newTestOpenRouterServer(t *testing.T, record func(body map[string]interface{})) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		json.Unmarshal(data, &body)
+		record(body)
+
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenRouterProvider_CloseCancelsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, "")
+		errs <- err
+	}()
+
+	<-started
+	p.Close()
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("Expected the in-flight request to fail when the provider is closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Close to cancel the in-flight request")
+	}
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, ""); !errors.Is(err, provider.ErrProviderClosed) {
+		t.Errorf("Expected ErrProviderClosed after Close, got %v", err)
+	}
+}
+
+func TestOpenRouterProvider_PerRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		select {
+		case <-time.After(100 * time.Millisecond):
+			w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+		Timeout:    5 * time.Second,
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{PerRequestTimeout: 10 * time.Millisecond}); err == nil {
+		t.Error("Expected the call to time out")
+	}
+	if result, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil || result.Content != "ok" {
+		t.Errorf("Expected a call without a per-request timeout to use the configured timeout, got %v (%v)", result, err)
+	}
+}
+
+func TestOpenRouterProvider_RequestModifier(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+		RequestModifier: func(body map[string]interface{}) {
+			body["logit_bias"] = map[string]int{"50256": -100}
+			body["temperature"] = 0.1
+		},
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{Temperature: 0.9}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bias, ok := request["logit_bias"].(map[string]interface{}); !ok || bias["50256"] != float64(-100) {
+		t.Errorf("Expected the injected logit_bias, got %v", request["logit_bias"])
+	}
+	if request["temperature"] != 0.1 {
+		t.Errorf("Expected the modifier to override the temperature, got %v", request["temperature"])
+	}
+	if request["model"] != "test-model" {
+		t.Errorf("Expected the library's fields to be kept, got %v", request)
+	}
+}
+
+func TestOpenRouterProvider_QueryParams(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:      "test-key",
+		Models:      []string{"test-model"},
+		HTTPClient:  httpclient.New("go-llm-router-test"),
+		QueryParams: map[string]string{"key": "a=b&c"},
+	}, server.URL+"/api/v1/chat/completions?region=eu", "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query.Get("region") != "eu" || query.Get("key") != "a=b&c" {
+		t.Errorf("Expected the URL's and the configured parameters, got %v", query)
+	}
+}
+
+func TestOpenRouterProvider_MessageName(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "user", Name: "planner", Content: "Split the task."},
+		{Role: "user", Content: "Go ahead."},
+	}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := request["messages"].([]interface{})
+	if name := sent[0].(map[string]interface{})["name"]; name != "planner" {
+		t.Errorf("Expected the message name to be sent, got %v", name)
+	}
+	if _, ok := sent[1].(map[string]interface{})["name"]; ok {
+		t.Errorf("Expected no name on a message without one, got %v", sent[1])
+	}
+}
+
+func TestOpenRouterProvider_RoleMap(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+		RoleMap:    map[string]string{"system": "developer"},
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "Hello"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{SystemPrompt: "Be brief."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := request["messages"].([]interface{})
+	if role := sent[0].(map[string]interface{})["role"]; role != "developer" {
+		t.Errorf("Expected the system prompt to be sent as a developer message, got %v", role)
+	}
+	if role := sent[1].(map[string]interface{})["role"]; role != "user" {
+		t.Errorf("Expected roles without an entry to be unchanged, got %v", role)
+	}
+}
+
+func TestOpenRouterProvider_KeepsSeparateSystemMessages(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "system", Content: "You are a travel agent."},
+		{Role: "system", Content: "Only suggest trains."},
+		{Role: "user", Content: "How do I get to Lyon?"},
+	}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := request["messages"].([]interface{})
+	if len(sent) != 3 {
+		t.Fatalf("Expected both system messages and the user message, got %v", sent)
+	}
+	for i, want := range []string{"You are a travel agent.", "Only suggest trains."} {
+		msg := sent[i].(map[string]interface{})
+		if msg["role"] != "system" || msg["content"] != want {
+			t.Errorf("Expected system message %q, got %v", want, msg)
+		}
+	}
+}
+
+func TestOpenRouterProvider_ReasoningOptions(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	budget := 4096
+	tests := []struct {
+		options provider.QueryOptions
+		want    map[string]interface{}
+	}{
+		{provider.QueryOptions{ReasoningEffort: "high"}, map[string]interface{}{"effort": "high"}},
+		{provider.QueryOptions{ThinkingBudget: &budget}, map[string]interface{}{"max_tokens": float64(4096)}},
+		{provider.QueryOptions{ReasoningEffort: "low", ThinkingBudget: &budget}, map[string]interface{}{"effort": "low"}},
+		{provider.QueryOptions{}, nil},
+	}
+	for _, test := range tests {
+		messages := []provider.Message{{Role: "user", Content: "hi"}}
+		if _, err := p.QueryWithOptions(context.Background(), messages, test.options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		reasoning, ok := request["reasoning"]
+		if test.want == nil {
+			if ok {
+				t.Errorf("Expected no reasoning object when unset, got %v", reasoning)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(reasoning, test.want) {
+			t.Errorf("Expected reasoning %v, got %v", test.want, reasoning)
+		}
+	}
+}
+
+func TestOpenRouterProvider_ParallelToolCalls(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := request["parallel_tool_calls"]; ok {
+		t.Errorf("Expected no parallel_tool_calls when unset, got %v", request["parallel_tool_calls"])
+	}
+
+	parallel := false
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ParallelToolCalls: &parallel}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if request["parallel_tool_calls"] != false {
+		t.Errorf("Expected parallel_tool_calls=false, got %v", request["parallel_tool_calls"])
+	}
+}
+
+func TestOpenRouterProvider_CacheControl(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"anthropic/claude-3.5-sonnet"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "system", Content: "A long, reusable system prompt.", Cacheable: true},
+		{Role: "user", Content: "Summarize it."},
+	}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := request["messages"].([]interface{})
+	system, _ := json.Marshal(sent[0].(map[string]interface{})["content"])
+	if string(system) != `[{"cache_control":{"type":"ephemeral"},"text":"A long, reusable system prompt.","type":"text"}]` {
+		t.Errorf("Expected the system prompt with a cache marker, got %s", system)
+	}
+	if content := sent[1].(map[string]interface{})["content"]; content != "Summarize it." {
+		t.Errorf("Expected other messages to be sent as plain text, got %v", content)
+	}
+}
+
+func TestOpenRouterProvider_Seed(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := request["seed"]; ok {
+		t.Errorf("Expected no seed when unset, got %v", request["seed"])
+	}
+
+	seed := 0
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Seed: &seed}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, ok := request["seed"]; !ok || value != float64(0) {
+		t.Errorf("Expected seed 0 to be sent, got %v", request["seed"])
+	}
+}
+
+func TestOpenRouterProvider_Temperature(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := request["temperature"]; ok {
+		t.Errorf("Expected no temperature when unset, got %v", request["temperature"])
+	}
+
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{TemperatureSet: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, ok := request["temperature"]; !ok || value != float64(0) {
+		t.Errorf("Expected an explicit temperature of 0 to be sent, got %v", request["temperature"])
+	}
+}
+
+func TestOpenRouterProvider_LogProbs(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &request)
+		w.Write([]byte(`{"choices":[{"message":{"content":"Yes."},"finish_reason":"stop","logprobs":{"content":[
+			{"token":"Yes","logprob":-0.01,"bytes":[89,101,115],"top_logprobs":[{"token":"Yes","logprob":-0.01},{"token":"No","logprob":-4.6}]},
+			{"token":".","logprob":-0.2,"bytes":[46],"top_logprobs":[{"token":".","logprob":-0.2},{"token":"!","logprob":-1.7}]}
+		]}}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "Is it raining?"}}, provider.QueryOptions{LogProbs: true, TopLogProbs: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if request["logprobs"] != true || request["top_logprobs"] != float64(2) {
+		t.Errorf("Expected logprobs and top_logprobs in the request, got %v and %v", request["logprobs"], request["top_logprobs"])
+	}
+	if len(result.LogProbs) != 2 {
+		t.Fatalf("Expected logprobs for two tokens, got %+v", result.LogProbs)
+	}
+	first := result.LogProbs[0]
+	if first.Token != "Yes" || first.LogProb != -0.01 {
+		t.Errorf("Unexpected first token %+v", first)
+	}
+	if len(first.TopAlternatives) != 2 || first.TopAlternatives[1].Token != "No" || first.TopAlternatives[1].LogProb != -4.6 {
+		t.Errorf("Expected the top alternatives to be parsed, got %+v", first.TopAlternatives)
+	}
+}
+
+func TestOpenRouterProvider_NoLogProbsByDefault(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := request["logprobs"]; ok {
+		t.Errorf("Expected no logprobs in the request, got %v", request["logprobs"])
+	}
+	if result.LogProbs != nil {
+		t.Errorf("Expected no logprobs in the result, got %+v", result.LogProbs)
+	}
+}
+
+func TestOpenRouterProvider_ModelStrategyRoundRobin(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body struct {
+			Model string `json:"model"`
+		}
+		json.Unmarshal(data, &body)
+		mu.Lock()
+		requested = append(requested, body.Model)
+		mu.Unlock()
+
+		if body.Model == "model-c" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:        "test-key",
+		Models:        []string{"model-a", "model-b", "model-c"},
+		HTTPClient:    httpclient.New("go-llm-router-test"),
+		ModelStrategy: provider.ModelStrategyRoundRobin,
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	served := map[string]int{}
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for i := 0; i < 30; i++ {
+		result, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		served[result.Model]++
+	}
+
+	// model-c always fails, so its turns fall back to model-a
+	if served["model-a"] != 20 || served["model-b"] != 10 || served["model-c"] != 0 {
+		t.Errorf("Expected model-b to serve a third of the requests and model-a the rest, got %v", served)
+	}
+	if len(requested) != 40 || requested[2] != "model-c" || requested[3] != "model-a" {
+		t.Errorf("Expected model-c to be tried first every third request and fall back to model-a, got %v", requested[:6])
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ForceModel: "model-b"})
+	if err != nil || result.Model != "model-b" {
+		t.Errorf("Expected ForceModel to override the strategy, got %+v (%v)", result, err)
+	}
+}
+
+func TestOpenRouterProvider_AttributionHeaders(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	for _, attribution := range [][2]string{{"", ""}, {"https://example.com", "My App"}} {
+		p, err := newOpenRouterProvider(provider.Config{
+			APIKey:     "test-key",
+			Models:     []string{"test-model"},
+			HTTPClient: httpclient.New("my-app/2.0"),
+		}, server.URL, attribution[0], attribution[1], nil)
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if _, ok := headers[0]["Http-Referer"]; ok {
+		t.Errorf("Expected no HTTP-Referer header when unset, got %q", headers[0].Get("HTTP-Referer"))
+	}
+	if _, ok := headers[0]["X-Title"]; ok {
+		t.Errorf("Expected no X-Title header when unset, got %q", headers[0].Get("X-Title"))
+	}
+	if headers[1].Get("HTTP-Referer") != "https://example.com" || headers[1].Get("X-Title") != "My App" {
+		t.Errorf("Expected the configured attribution headers, got %v", headers[1])
+	}
+	if headers[0].Get("User-Agent") != "my-app/2.0" {
+		t.Errorf("Expected the client's User-Agent, got %q", headers[0].Get("User-Agent"))
+	}
+}
+
+func TestOpenRouterProvider_Routing(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	config := provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}
+	p, err := newOpenRouterProvider(config, server.URL, "", "", &OpenRouterRouting{
+		ProviderPreferences: map[string]interface{}{"order": []string{"Together"}, "allow_fallbacks": false},
+		FallbackModels:      []string{"fallback-a", "fallback-b"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	preferences, _ := json.Marshal(request["provider"])
+	if string(preferences) != `{"allow_fallbacks":false,"order":["Together"]}` {
+		t.Errorf("Expected the provider preferences in the request, got %s", preferences)
+	}
+	models, _ := json.Marshal(request["models"])
+	if string(models) != `["fallback-a","fallback-b"]` {
+		t.Errorf("Expected the fallback models in the request, got %s", models)
+	}
+
+	p, err = newOpenRouterProvider(config, server.URL, "", "", &OpenRouterRouting{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := request["provider"]; ok {
+		t.Errorf("Expected no provider preferences when empty, got %v", request["provider"])
+	}
+	if _, ok := request["models"]; ok {
+		t.Errorf("Expected no fallback models when empty, got %v", request["models"])
+	}
+}
+
+func TestOpenRouterProvider_NoModels(t *testing.T) {
+	var requested []interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { requested = append(requested, body["model"]) })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err == nil || err.Error() != "no models configured for provider OpenRouter" {
+		t.Errorf("Expected a no models error, got %v", err)
+	}
+	if len(requested) != 0 {
+		t.Errorf("Expected no request without models, got %v", requested)
+	}
+
+	// A forced model doesn't need configured models
+	if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{ForceModel: "forced"}); err != nil {
+		t.Errorf("Unexpected error with a forced model: %v", err)
+	}
+	if len(requested) != 1 || requested[0] != "forced" {
+		t.Errorf("Expected the forced model to be requested, got %v", requested)
+	}
+}
+
+func TestOpenRouterProvider_ToolChoice(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+	for _, choice := range []string{"auto", "none", "get_weather"} {
+		options := provider.QueryOptions{Tools: tools, ToolChoice: choice}
+		if _, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		serialized, _ := json.Marshal(request["tool_choice"])
+		expected := `"` + choice + `"`
+		if choice == "get_weather" {
+			expected = `{"function":{"name":"get_weather"},"type":"function"}`
+		}
+		if string(serialized) != expected {
+			t.Errorf("Expected tool_choice %s, got %s", expected, serialized)
+		}
+	}
+}
+
+func TestOpenRouterProvider_FileURLs(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{
+		Role:    "user",
+		Content: "Compare these",
+		Files: []provider.File{
+			{Type: "image", MimeType: "image/png", Name: "remote.png", URL: "https://example.com/remote.png"},
+			{Type: "image", MimeType: "image/png", Name: "local.png", Data: []byte("png")},
+		},
+	}}
+
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content := request["messages"].([]interface{})[0].(map[string]interface{})["content"].([]interface{})
+	if len(content) != 3 {
+		t.Fatalf("Expected text and two images, got %d parts", len(content))
+	}
+
+	remote := content[1].(map[string]interface{})["image_url"].(map[string]interface{})["url"]
+	if remote != "https://example.com/remote.png" {
+		t.Errorf("Expected remote URL to be passed through, got %v", remote)
+	}
+
+	local := content[2].(map[string]interface{})["image_url"].(map[string]interface{})["url"].(string)
+	if !strings.HasPrefix(local, "data:image/png;base64,") {
+		t.Errorf("Expected local data to be sent as a data URL, got %v", local)
+	}
+}
+
+func TestOpenRouterProvider_MaxContextTokens(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:           "test-key",
+		Models:           []string{"test-model"},
+		HTTPClient:       httpclient.New("go-llm-router-test"),
+		MaxContextTokens: 50,
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: strings.Repeat("old ", 100)},
+		{Role: "assistant", Content: strings.Repeat("reply ", 100)},
+		{Role: "user", Content: "latest question"},
+	}
+
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := request["messages"].([]interface{})
+	if len(sent) != 2 {
+		t.Fatalf("Expected old turns to be trimmed, got %d messages", len(sent))
+	}
+	if sent[0].(map[string]interface{})["role"] != "system" || sent[1].(map[string]interface{})["content"] != "latest question" {
+		t.Errorf("Expected system prompt and latest question to be sent, got %v", sent)
+	}
+}
+
+func TestOpenRouterProvider_SystemPrompt(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{SystemPrompt: "Answer in French."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := request["messages"].([]interface{})
+	if len(sent) != 2 {
+		t.Fatalf("Expected system and user messages, got %d messages", len(sent))
+	}
+	first := sent[0].(map[string]interface{})
+	if first["role"] != "system" || first["content"] != "Answer in French." {
+		t.Errorf("Expected system prompt as the first message, got %v", first)
+	}
+	if sent[1].(map[string]interface{})["content"] != "hi" {
+		t.Errorf("Expected user message after the system prompt, got %v", sent[1])
+	}
+}
+
+func TestOpenRouterProvider_ResolvedModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"openai/gpt-4o-2024-08-06","choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"openai/gpt-4o"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Model != "openai/gpt-4o-2024-08-06" {
+		t.Errorf("Expected the model reported in the response, got %q", result.Model)
+	}
+}
+
+func TestOpenRouterProvider_Reasoning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"4","reasoning":"2 + 2 is 4."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"deepseek/deepseek-r1"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "What is 2 + 2?"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "4" || result.Reasoning != "2 + 2 is 4." {
+		t.Errorf("Expected the reasoning separately from the answer, got %+v", result)
+	}
+}
+
+func TestOpenRouterProvider_MultipleCompletions(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &request)
+		w.Write([]byte(`{"choices":[
+			{"message":{"content":"first"},"finish_reason":"stop"},
+			{"message":{"content":"second"},"finish_reason":"stop"},
+			{"message":{"content":"third"},"finish_reason":"length"}
+		]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{N: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if request["n"] != float64(3) {
+		t.Errorf("Expected n=3 in the request, got %v", request["n"])
+	}
+	if len(result.Completions) != 3 || result.Completions[1].Content != "second" || result.Completions[2].FinishReason != "length" {
+		t.Fatalf("Expected three completions, got %+v", result.Completions)
+	}
+	if result.Content != "first" {
+		t.Errorf("Expected the first choice as the result content, got %q", result.Content)
+	}
+}
+
+func TestOpenRouterProvider_RequestedModelWhenNotReported(t *testing.T) {
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) {})
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"openai/gpt-4o"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Model != "openai/gpt-4o" {
+		t.Errorf("Expected the requested model as a fallback, got %q", result.Model)
+	}
+}
+
+func TestOpenRouterProvider_HealthCheck(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"data":{"label":"test"}}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL+"/api/v1/chat/completions", "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if err := p.(provider.HealthChecker).HealthCheck(context.Background()); err != nil {
+		t.Fatalf("Expected healthy provider, got %v", err)
+	}
+	if path != "/api/v1/auth/key" {
+		t.Errorf("Expected the key endpoint to be used, got %s", path)
+	}
+}
+
+func TestOpenRouterProvider_Embeddings(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &request)
+
+		// Return the vectors out of order to check they are placed by index
+		w.Write([]byte(`{"model":"text-embedding-3-small","data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}],"usage":{"prompt_tokens":4,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL+"/api/v1/chat/completions", "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	embedder := p.(provider.Embedder)
+	if !embedder.SupportsEmbeddings() {
+		t.Fatal("Expected OpenRouter to support embeddings")
+	}
+
+	result, err := embedder.Embeddings(context.Background(), provider.EmbeddingRequest{Model: "openai/text-embedding-3-small", Input: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != "/api/v1/embeddings" {
+		t.Errorf("Expected embeddings endpoint, got %s", path)
+	}
+	if request["model"] != "openai/text-embedding-3-small" {
+		t.Errorf("Expected requested model to be sent, got %v", request["model"])
+	}
+	if result.Vectors[0][0] != 0.1 || result.Vectors[1][0] != 0.3 {
+		t.Errorf("Expected vectors ordered by index, got %v", result.Vectors)
+	}
+	if result.Usage.TotalTokens != 4 {
+		t.Errorf("Expected usage to be reported, got %+v", result.Usage)
+	}
+}
+
+func TestOpenRouterProvider_ToolCallHistory(t *testing.T) {
+	var request map[string]interface{}
+	server := newTestOpenRouterServer(t, func(body map[string]interface{}) { request = body })
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"test-model"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, err := p.QueryWithOptions(context.Background(), toolCallHistory(), provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	messages := request["messages"].([]interface{})
+	assistant := messages[1].(map[string]interface{})
+	toolCall := assistant["tool_calls"].([]interface{})[0].(map[string]interface{})
+	function := toolCall["function"].(map[string]interface{})
+	if toolCall["id"] != "call_1" || function["name"] != "get_weather" || function["arguments"] != `{"city":"Paris"}` {
+		t.Errorf("Expected the assistant's tool call with JSON string arguments, got %v", assistant)
+	}
+	tool := messages[2].(map[string]interface{})
+	if tool["role"] != "tool" || tool["tool_call_id"] != "call_1" || tool["content"] != `{"temperature":21}` {
+		t.Errorf("Expected the tool result with its call id, got %v", tool)
+	}
+}
+
+func TestOpenRouterProvider_RefreshModels(t *testing.T) {
+	var referer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/models" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		referer = r.Header.Get("HTTP-Referer")
+		w.Write([]byte(`{"data":[{"id":"openai/gpt-4o-mini"},{"id":"anthropic/claude-3.5-haiku"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"openai/gpt-4o-mini"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+	}, server.URL+"/api/v1/chat/completions", "https://example.com", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	o := p.(*OpenRouterProvider)
+
+	if models := o.Models(); len(models) != 1 || models[0] != "openai/gpt-4o-mini" {
+		t.Errorf("Expected the configured models before a refresh, got %v", models)
+	}
+	if err := o.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if models := o.Models(); len(models) != 2 || models[1] != "anthropic/claude-3.5-haiku" {
+		t.Errorf("Expected the listed models after a refresh, got %v", models)
+	}
+	if referer != "https://example.com" {
+		t.Errorf("Expected the referer header, got %q", referer)
+	}
+}
+
+func TestOpenRouterProvider_MaxResponseBytes(t *testing.T) {
+	// A misbehaving endpoint that keeps sending data until the client goes away
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		chunk := []byte(strings.Repeat("x", 64<<10))
+		w.Write([]byte(`{"choices":[{"message":{"content":"`))
+		for {
+			if _, err := w.Write(chunk); err != nil || r.Context().Err() != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:           "test-key",
+		Models:           []string{"test-model"},
+		HTTPClient:       httpclient.New("go-llm-router-test"),
+		MaxResponseBytes: 1 << 20,
+	}, server.URL, "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if !errors.Is(err, provider.ErrResponseTooLarge) || !strings.Contains(err.Error(), "response exceeded max size") {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestOpenRouterProvider_DoRaw(t *testing.T) {
+	var path, referer, authorization string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, referer, authorization = r.URL.Path, r.Header.Get("HTTP-Referer"), r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &request)
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer server.Close()
+
+	p, err := newOpenRouterProvider(provider.Config{
+		APIKey:           "test-key",
+		Models:           []string{"test-model"},
+		HTTPClient:       httpclient.New("go-llm-router-test"),
+		MaxDailyRequests: 1,
+	}, server.URL+"/api/v1/chat/completions", "https://example.com", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	o := p.(*OpenRouterProvider)
+	defer o.Close()
+
+	data, err := o.DoRaw(context.Background(), "/rerank", map[string]interface{}{"query": "q"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != `{"results":[]}` {
+		t.Errorf("Expected the raw response body, got %s", data)
+	}
+	if path != "/api/v1/rerank" || authorization != "Bearer test-key" || referer != "https://example.com" {
+		t.Errorf("Expected /api/v1/rerank with the provider's headers, got %s %q %q", path, authorization, referer)
+	}
+	if request["query"] != "q" {
+		t.Errorf("Expected the body to be sent as JSON, got %v", request)
+	}
+	if o.HasRemainingRequests(context.Background()) {
+		t.Errorf("Expected the request to count against the daily limit")
+	}
+}
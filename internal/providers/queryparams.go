@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+)
+
+// chatCompletionsURL appends the chat completions path to an API base URL, keeping any query
+// string, e.g. an api-version, at the end
+func chatCompletionsURL(baseURL string) string {
+	path, query, found := strings.Cut(baseURL, "?")
+	endpoint := strings.TrimSuffix(path, "/") + "/chat/completions"
+	if found {
+		endpoint += "?" + query
+	}
+	return endpoint
+}
+
+// withQueryParams separates the query string from a configured endpoint and returns a client
+// that adds it, merged with params, to every request. The embeddings, models and other URLs are
+// derived from the endpoint's path, so the query is kept out of it. Configured params override
+// ones of the same name in the endpoint. The client and endpoint are returned unchanged when
+// there is nothing to add.
+func withQueryParams(client httpclient.Client, endpoint string, params map[string]string) (httpclient.Client, string) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.RawQuery == "" && len(params) == 0) {
+		return client, endpoint
+	}
+
+	values := u.Query()
+	for key, value := range params {
+		values.Set(key, value)
+	}
+	u.RawQuery = ""
+	return &queryParamsClient{client: client, params: values}, u.String()
+}
+
+// queryParamsClient adds query parameters to the URL of each request made through an
+// httpclient.Client
+type queryParamsClient struct {
+	client httpclient.Client
+	params url.Values
+}
+
+func (c *queryParamsClient) Do(ctx context.Context, rawURL string, method string, headers map[string]string, body io.Reader, timeout time.Duration) (*http.Response, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.client.Do(ctx, rawURL, method, headers, body, timeout)
+	}
+
+	values := u.Query()
+	for key, value := range c.params {
+		values[key] = value
+	}
+	u.RawQuery = values.Encode()
+	return c.client.Do(ctx, u.String(), method, headers, body, timeout)
+}
@@ -0,0 +1,344 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// rateLimiter tracks daily, per-minute and per-minute token usage for a provider.
+// It is safe for concurrent use.
+type rateLimiter struct {
+	mu                   sync.Mutex
+	maxDailyRequests     int
+	maxRequestsPerMinute int
+	maxTokensPerMinute   int
+	requestsToday        int
+	requestsThisMinute   int
+	tokensThisMinute     int
+	lastReset            time.Time
+	lastMinuteReset      time.Time
+	store                provider.RateStore
+	storeKey             string
+}
+
+// newRateLimiter creates a rate limiter. A limit of 0 disables that limit.
+func newRateLimiter(maxDailyRequests, maxRequestsPerMinute, maxTokensPerMinute int) *rateLimiter {
+	now := time.Now()
+	return &rateLimiter{
+		maxDailyRequests:     maxDailyRequests,
+		maxRequestsPerMinute: maxRequestsPerMinute,
+		maxTokensPerMinute:   maxTokensPerMinute,
+		lastReset:            now.Truncate(24 * time.Hour),
+		lastMinuteReset:      now.Truncate(time.Minute),
+	}
+}
+
+// newConfiguredRateLimiter creates the rate limiter described by the config. When the config has a
+// RateStore, the counters saved under the provider's key are restored and every change is saved.
+func newConfiguredRateLimiter(config provider.Config, name, url string) (*rateLimiter, error) {
+	limiter := newRateLimiter(config.MaxDailyRequests, config.MaxRequestsPerMinute, config.MaxTokensPerMinute)
+	if config.RateStore == nil {
+		return limiter, nil
+	}
+
+	key := rateStoreKey(name, url, config.APIKey)
+	counters, err := config.RateStore.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate-limit counters: %w", err)
+	}
+
+	limiter.store = config.RateStore
+	limiter.storeKey = key
+	limiter.restore(counters)
+	return limiter, nil
+}
+
+// rateStoreKey identifies a provider instance across restarts without storing its API key
+func rateStoreKey(name, url, apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return name + "|" + url + "|" + hex.EncodeToString(sum[:8])
+}
+
+// modelRateLimiters holds the limiters of the models configured with their own ModelLimits.
+// Other models share the provider-wide limiter. The zero value has no model limits.
+type modelRateLimiters struct {
+	limiters map[string]*rateLimiter
+	shared   bool // Some of the provider's models have no limits of their own
+}
+
+// newModelRateLimiters creates a limiter for each model in config.ModelLimits, filling unset
+// limits from the provider-wide ones. With a RateStore, each model's counters are saved under
+// its own key.
+func newModelRateLimiters(config provider.Config, name, url string) (modelRateLimiters, error) {
+	if len(config.ModelLimits) == 0 {
+		return modelRateLimiters{}, nil
+	}
+
+	models := modelRateLimiters{limiters: make(map[string]*rateLimiter, len(config.ModelLimits))}
+	for model, limits := range config.ModelLimits {
+		if limits.MaxDailyRequests == 0 {
+			limits.MaxDailyRequests = config.MaxDailyRequests
+		}
+		if limits.MaxRequestsPerMinute == 0 {
+			limits.MaxRequestsPerMinute = config.MaxRequestsPerMinute
+		}
+		if limits.MaxTokensPerMinute == 0 {
+			limits.MaxTokensPerMinute = config.MaxTokensPerMinute
+		}
+
+		limiter := newRateLimiter(limits.MaxDailyRequests, limits.MaxRequestsPerMinute, limits.MaxTokensPerMinute)
+		if config.RateStore != nil {
+			key := rateStoreKey(name, url, config.APIKey) + "|" + model
+			counters, err := config.RateStore.Load(key)
+			if err != nil {
+				return modelRateLimiters{}, fmt.Errorf("failed to load rate-limit counters of %s: %w", model, err)
+			}
+			limiter.store = config.RateStore
+			limiter.storeKey = key
+			limiter.restore(counters)
+		}
+		models.limiters[model] = limiter
+	}
+
+	for _, model := range config.Models {
+		if _, ok := models.limiters[model]; !ok {
+			models.shared = true
+		}
+	}
+	return models, nil
+}
+
+// forModel returns the model's own limiter, or shared when it has none
+func (m modelRateLimiters) forModel(model string, shared *rateLimiter) *rateLimiter {
+	if limiter, ok := m.limiters[model]; ok {
+		return limiter
+	}
+	return shared
+}
+
+// anyRemaining reports whether some model passes check: one with its own limits, or any other
+// model through the shared limiter
+func (m modelRateLimiters) anyRemaining(shared *rateLimiter, check func(*rateLimiter) bool) bool {
+	if len(m.limiters) == 0 || m.shared {
+		if check(shared) {
+			return true
+		}
+	}
+	for _, limiter := range m.limiters {
+		if check(limiter) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkModel returns an error when the model's limits leave no room for the messages. Without
+// model limits, the router has already checked the provider-wide ones.
+func (m modelRateLimiters) checkModel(model string, shared *rateLimiter, estimator provider.TokenEstimator, messages []provider.Message) error {
+	if len(m.limiters) == 0 {
+		return nil
+	}
+
+	limiter := m.forModel(model, shared)
+	estimatedTokens := estimator.EstimateTokens(messages)
+	switch {
+	case !limiter.hasRemainingRequests():
+		return fmt.Errorf("%w: model %s has no daily requests left", provider.ErrRateLimitExceeded, model)
+	case !limiter.hasRemainingRequestsPerMinute():
+		return fmt.Errorf("%w: model %s has no requests left this minute", provider.ErrRateLimitExceeded, model)
+	case !limiter.hasRemainingTokensPerMinute(estimatedTokens):
+		return fmt.Errorf("%w: about %d tokens don't fit the remaining tokens per minute of model %s", provider.ErrTokenBudgetExceeded, estimatedTokens, model)
+	}
+	return nil
+}
+
+// flush writes the counters of every model limiter to the store
+func (m modelRateLimiters) flush() {
+	for _, limiter := range m.limiters {
+		limiter.flush()
+	}
+}
+
+// reset clears the counters of every model limiter
+func (m modelRateLimiters) reset() {
+	for _, limiter := range m.limiters {
+		limiter.reset()
+	}
+}
+
+// restore applies saved counters; counters from windows that have since passed are dropped
+func (l *rateLimiter) restore(counters provider.RateCounters) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.restoreLocked(counters)
+}
+
+// restoreLocked applies saved counters. The caller must hold l.mu.
+func (l *rateLimiter) restoreLocked(counters provider.RateCounters) {
+	if !counters.DayStart.IsZero() {
+		l.requestsToday = counters.RequestsToday
+		l.lastReset = counters.DayStart
+	}
+	if !counters.MinuteStart.IsZero() {
+		l.requestsThisMinute = counters.RequestsThisMinute
+		l.tokensThisMinute = counters.TokensThisMinute
+		l.lastMinuteReset = counters.MinuteStart
+	}
+	l.resetExpiredWindowsLocked()
+}
+
+// refreshLocked loads the combined counters from a shared store so that usage by other
+// processes counts against the limits. The caller must hold l.mu.
+func (l *rateLimiter) refreshLocked() {
+	if _, shared := l.store.(provider.SharedRateStore); !shared {
+		l.resetExpiredWindowsLocked()
+		return
+	}
+
+	counters, err := l.store.Load(l.storeKey)
+	if err != nil {
+		log.Printf("[ratelimit] failed to load shared counters for %s: %v", l.storeKey, err)
+		l.resetExpiredWindowsLocked()
+		return
+	}
+	l.restoreLocked(counters)
+}
+
+// addLocked counts requests and tokens, atomically in the store when it is shared.
+// The caller must hold l.mu.
+func (l *rateLimiter) addLocked(requests, tokens int) {
+	if shared, ok := l.store.(provider.SharedRateStore); ok {
+		counters, err := shared.Increment(l.storeKey, requests, tokens)
+		if err == nil {
+			l.restoreLocked(counters)
+			return
+		}
+		// Keep counting locally so this process still respects the limits
+		log.Printf("[ratelimit] failed to update shared counters for %s: %v", l.storeKey, err)
+	}
+
+	l.resetExpiredWindowsLocked()
+	l.requestsToday += requests
+	l.requestsThisMinute += requests
+	l.tokensThisMinute += tokens
+	l.saveLocked()
+}
+
+// saveLocked persists the counters to the store, if any. The caller must hold l.mu.
+// Failures are logged rather than failing the request that was already made.
+// Shared stores are updated through Increment instead.
+func (l *rateLimiter) saveLocked() {
+	if l.store == nil {
+		return
+	}
+	if _, shared := l.store.(provider.SharedRateStore); shared {
+		return
+	}
+
+	if err := l.store.Save(l.storeKey, l.countersLocked()); err != nil {
+		log.Printf("[ratelimit] failed to save counters for %s: %v", l.storeKey, err)
+	}
+}
+
+// flush writes the current counters to the store, if any, e.g. to retry a save that failed.
+// Shared stores are updated on every change and are not written.
+func (l *rateLimiter) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.saveLocked()
+}
+
+// countersLocked returns the current counters. The caller must hold l.mu.
+func (l *rateLimiter) countersLocked() provider.RateCounters {
+	return provider.RateCounters{
+		RequestsToday:      l.requestsToday,
+		RequestsThisMinute: l.requestsThisMinute,
+		TokensThisMinute:   l.tokensThisMinute,
+		DayStart:           l.lastReset,
+		MinuteStart:        l.lastMinuteReset,
+	}
+}
+
+// reset clears all counters and starts new windows. The cleared counters are written to the
+// store even when it is shared, so that other processes see the reset too.
+func (l *rateLimiter) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.requestsToday = 0
+	l.requestsThisMinute = 0
+	l.tokensThisMinute = 0
+	l.lastReset = now.Truncate(24 * time.Hour)
+	l.lastMinuteReset = now.Truncate(time.Minute)
+
+	if l.store == nil {
+		return
+	}
+	if err := l.store.Save(l.storeKey, l.countersLocked()); err != nil {
+		log.Printf("[ratelimit] failed to save reset counters for %s: %v", l.storeKey, err)
+	}
+}
+
+// resetExpiredWindowsLocked resets the daily and minute counters once their window has passed.
+// The caller must hold l.mu.
+func (l *rateLimiter) resetExpiredWindowsLocked() {
+	if time.Since(l.lastReset) > 24*time.Hour {
+		l.requestsToday = 0
+		l.lastReset = time.Now().Truncate(24 * time.Hour)
+	}
+	if time.Since(l.lastMinuteReset) > time.Minute {
+		l.requestsThisMinute = 0
+		l.tokensThisMinute = 0
+		l.lastMinuteReset = time.Now().Truncate(time.Minute)
+	}
+}
+
+// hasRemainingRequests checks if the daily request limit has not been reached
+func (l *rateLimiter) hasRemainingRequests() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refreshLocked()
+	return l.maxDailyRequests == 0 || l.requestsToday < l.maxDailyRequests
+}
+
+// hasRemainingRequestsPerMinute checks if the per-minute request limit has not been reached
+func (l *rateLimiter) hasRemainingRequestsPerMinute() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refreshLocked()
+	return l.maxRequestsPerMinute == 0 || l.requestsThisMinute < l.maxRequestsPerMinute
+}
+
+// hasRemainingTokensPerMinute checks if estimatedTokens fit in the per-minute token limit
+func (l *rateLimiter) hasRemainingTokensPerMinute(estimatedTokens int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refreshLocked()
+	return l.maxTokensPerMinute == 0 || (l.tokensThisMinute+estimatedTokens) <= l.maxTokensPerMinute
+}
+
+// recordRequest counts a completed request against the daily and per-minute limits
+func (l *rateLimiter) recordRequest() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.addLocked(1, 0)
+}
+
+// recordTokens counts tokens against the per-minute token limit
+func (l *rateLimiter) recordTokens(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.addLocked(0, tokens)
+}
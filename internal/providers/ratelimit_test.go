@@ -0,0 +1,294 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRateLimiterConcurrentRecording(t *testing.T) {
+	limiter := newRateLimiter(100, 100, 1000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.recordRequest()
+			limiter.recordTokens(10)
+		}()
+	}
+	wg.Wait()
+
+	if limiter.hasRemainingRequests() {
+		t.Error("Expected daily limit to be reached after 100 concurrent requests")
+	}
+	if limiter.hasRemainingRequestsPerMinute() {
+		t.Error("Expected per-minute limit to be reached after 100 concurrent requests")
+	}
+	if limiter.hasRemainingTokensPerMinute(1) {
+		t.Error("Expected token limit to be reached after 1000 tokens")
+	}
+}
+
+func TestRateLimiterZeroMeansUnlimited(t *testing.T) {
+	limiter := newRateLimiter(0, 0, 0)
+	for i := 0; i < 1000; i++ {
+		limiter.recordRequest()
+		limiter.recordTokens(1000)
+	}
+
+	if !limiter.hasRemainingRequests() || !limiter.hasRemainingRequestsPerMinute() || !limiter.hasRemainingTokensPerMinute(1000) {
+		t.Error("Expected zero limits to be unlimited")
+	}
+}
+
+// memoryRateStore is an in-memory RateStore
+type memoryRateStore struct {
+	mu        sync.Mutex
+	counters  map[string]provider.RateCounters
+	failSaves bool // Save returns an error while set
+	saves     int
+}
+
+func (s *memoryRateStore) Load(providerKey string) (provider.RateCounters, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counters[providerKey], nil
+}
+
+func (s *memoryRateStore) Save(providerKey string, counters provider.RateCounters) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failSaves {
+		return errors.New("store unavailable")
+	}
+	s.saves++
+	if s.counters == nil {
+		s.counters = make(map[string]provider.RateCounters)
+	}
+	s.counters[providerKey] = counters
+	return nil
+}
+
+func TestRateLimiterRestoresFromStore(t *testing.T) {
+	store := &memoryRateStore{}
+	config := provider.Config{APIKey: "key", MaxDailyRequests: 2, MaxRequestsPerMinute: 10, RateStore: store}
+
+	limiter, err := newConfiguredRateLimiter(config, "Test", "https://example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	limiter.recordRequest()
+	limiter.recordRequest()
+	limiter.recordTokens(40)
+
+	restored, err := newConfiguredRateLimiter(config, "Test", "https://example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restored.hasRemainingRequests() {
+		t.Error("Expected the restored daily count to exhaust the limit")
+	}
+	if restored.requestsThisMinute != 2 || restored.tokensThisMinute != 40 {
+		t.Errorf("Expected minute counters to be restored, got %d requests and %d tokens", restored.requestsThisMinute, restored.tokensThisMinute)
+	}
+
+	// A different API key is a different quota
+	otherKey := config
+	otherKey.APIKey = "other"
+	other, _ := newConfiguredRateLimiter(otherKey, "Test", "https://example.com")
+	if !other.hasRemainingRequests() {
+		t.Error("Expected counters to be kept per API key")
+	}
+}
+
+func TestRateLimiterDropsExpiredSavedWindows(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	store := &memoryRateStore{}
+	config := provider.Config{APIKey: "key", MaxDailyRequests: 1, RateStore: store}
+	store.Save(rateStoreKey("Test", "", "key"), provider.RateCounters{
+		RequestsToday: 1, RequestsThisMinute: 1, TokensThisMinute: 99,
+		DayStart: old.Truncate(24 * time.Hour), MinuteStart: old.Truncate(time.Minute),
+	})
+
+	limiter, err := newConfiguredRateLimiter(config, "Test", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !limiter.hasRemainingRequests() || limiter.tokensThisMinute != 0 {
+		t.Error("Expected counters from past windows to be dropped")
+	}
+}
+
+func TestResetLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	store := &memoryRateStore{}
+	p, err := newFunctionCallingProvider(provider.Config{
+		APIKey:             "test-key",
+		Models:             []string{"test-model"},
+		HTTPClient:         httpclient.New("go-llm-router-test"),
+		MaxDailyRequests:   1,
+		MaxTokensPerMinute: 1000,
+		RateStore:          store,
+	}, server.URL, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if _, _, err := p.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.HasRemainingRequests(context.Background()) {
+		t.Fatal("Expected the daily limit to be exhausted")
+	}
+
+	p.ResetLimits()
+	if !p.HasRemainingRequests(context.Background()) {
+		t.Error("Expected requests to be available again after ResetLimits")
+	}
+	if !p.HasRemainingTokensPerMinute(context.Background(), 1000) {
+		t.Error("Expected the token counter to be cleared")
+	}
+
+	counters, _ := store.Load(rateStoreKey("FunctionCalling", server.URL, "test-key"))
+	if counters.RequestsToday != 0 || counters.TokensThisMinute != 0 || counters.DayStart.IsZero() {
+		t.Errorf("Expected the cleared counters to be saved, got %+v", counters)
+	}
+}
+
+func TestCloseFlushesCountersToStore(t *testing.T) {
+	store := &memoryRateStore{failSaves: true}
+	limiter, err := newConfiguredRateLimiter(provider.Config{APIKey: "key", RateStore: store}, "Gemini", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g := newTestGeminiProvider(&fakeGeminiAPI{})
+	g.limiter = limiter
+
+	// The saves made while recording fail, so only Close can persist the final counts
+	for i := 0; i < 3; i++ {
+		if _, _, err := g.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0.7, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	store.mu.Lock()
+	store.failSaves = false
+	store.mu.Unlock()
+
+	g.Close()
+	counters, _ := store.Load(limiter.storeKey)
+	if counters.RequestsToday != 3 || counters.RequestsThisMinute != 3 {
+		t.Errorf("Expected Close to save the final counters, got %+v", counters)
+	}
+
+	g.Close()
+	if store.saves != 1 {
+		t.Errorf("Expected a second Close not to save again, got %d saves", store.saves)
+	}
+}
+
+func TestFunctionCallingProvider_ModelLimits(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		models = append(models, body["model"].(string))
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p, err := newFunctionCallingProvider(provider.Config{
+		APIKey:     "test-key",
+		Models:     []string{"small-quota", "large-quota"},
+		HTTPClient: httpclient.New("go-llm-router-test"),
+		ModelLimits: map[string]provider.Limits{
+			"small-quota": {MaxRequestsPerMinute: 1},
+			"large-quota": {MaxRequestsPerMinute: 3},
+		},
+	}, server.URL, nil, ToolExecutionConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	// The first model's cap is used up by the first query, then the second model serves the rest
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for i := 0; i < 4; i++ {
+		if !p.HasRemainingRequestsPerMinute(context.Background()) {
+			t.Fatalf("Expected requests left before query %d", i+1)
+		}
+		if _, err := p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Query %d: unexpected error: %v", i+1, err)
+		}
+	}
+	expected := []string{"small-quota", "large-quota", "large-quota", "large-quota"}
+	if len(models) != len(expected) {
+		t.Fatalf("Expected %v to be requested, got %v", expected, models)
+	}
+	for i, model := range expected {
+		if models[i] != model {
+			t.Errorf("Expected query %d to use %s, got %s", i+1, model, models[i])
+		}
+	}
+
+	if p.HasRemainingRequestsPerMinute(context.Background()) {
+		t.Error("Expected no requests left once both models are exhausted")
+	}
+	_, err = p.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if !errors.Is(err, provider.ErrRateLimitExceeded) {
+		t.Errorf("Expected ErrRateLimitExceeded once both models are exhausted, got %v", err)
+	}
+
+	p.ResetLimits()
+	if !p.HasRemainingRequestsPerMinute(context.Background()) {
+		t.Error("Expected ResetLimits to clear the model limits")
+	}
+}
+
+func TestModelRateLimiters_SharedLimits(t *testing.T) {
+	config := provider.Config{
+		Models:             []string{"own-limits", "shared"},
+		MaxTokensPerMinute: 100,
+		ModelLimits:        map[string]provider.Limits{"own-limits": {MaxDailyRequests: 5}},
+	}
+	models, err := newModelRateLimiters(config, "Test", "")
+	if err != nil {
+		t.Fatalf("Failed to create model limiters: %v", err)
+	}
+	shared := newRateLimiter(0, 0, config.MaxTokensPerMinute)
+
+	own := models.forModel("own-limits", shared)
+	if own == shared || own.maxDailyRequests != 5 || own.maxTokensPerMinute != 100 {
+		t.Errorf("Expected the model's own limiter with unset limits taken from the provider, got %+v", own)
+	}
+	if models.forModel("shared", shared) != shared {
+		t.Error("Expected a model without limits to use the provider-wide limiter")
+	}
+
+	// Using up the provider-wide tokens leaves the model with its own limits available
+	shared.recordTokens(100)
+	tokens := func(l *rateLimiter) bool { return l.hasRemainingTokensPerMinute(10) }
+	if !models.anyRemaining(shared, tokens) {
+		t.Error("Expected the model with its own limits to still have tokens")
+	}
+	if err := models.checkModel("shared", shared, provider.DefaultTokenEstimator, []provider.Message{{Role: "user", Content: "hello"}}); !errors.Is(err, provider.ErrTokenBudgetExceeded) {
+		t.Errorf("Expected ErrTokenBudgetExceeded for the exhausted shared model, got %v", err)
+	}
+
+	own.recordTokens(100)
+	if models.anyRemaining(shared, tokens) {
+		t.Error("Expected no tokens left once every limiter is used up")
+	}
+}
@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// rawURL resolves path against the API base URL of an OpenAI-compatible chat completions endpoint.
+// It returns an empty string if the endpoint doesn't follow the OpenAI layout.
+func rawURL(chatURL string, path string) string {
+	if !strings.HasSuffix(chatURL, "/chat/completions") {
+		return ""
+	}
+	return strings.TrimSuffix(chatURL, "/chat/completions") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// requestRaw sends body as JSON to url, or a GET when body is nil, and returns the response body
+func requestRaw(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration, maxResponse int64, body interface{}) ([]byte, error) {
+	method := "GET"
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		method = "POST"
+		reader = bytes.NewReader(jsonData)
+	} else {
+		delete(headers, "Content-Type")
+	}
+
+	resp, _, err := client.Do(ctx, url, method, headers, reader, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := readResponse(resp.Body, maxResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, provider.NewAPIError(resp.StatusCode, string(data))
+	}
+	return data, nil
+}
@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// defaultMaxResponseBytes is used when Config.MaxResponseBytes is not set
+const defaultMaxResponseBytes = 32 << 20
+
+// maxResponseBytesOrDefault returns the configured response size limit, or the default when it is not set
+func maxResponseBytesOrDefault(configured int64) int64 {
+	if configured > 0 {
+		return configured
+	}
+	return defaultMaxResponseBytes
+}
+
+// readResponse reads a response body of at most limit bytes. Larger bodies are not read to the
+// end and return an error wrapping provider.ErrResponseTooLarge.
+func readResponse(body io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w of %d bytes", provider.ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
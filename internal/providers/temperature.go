@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// temperatureRange is the range of sampling temperatures an API accepts
+type temperatureRange struct {
+	min, max float64
+}
+
+var (
+	// openAITemperatureRange is accepted by OpenAI and the OpenAI-compatible APIs
+	openAITemperatureRange = temperatureRange{min: 0, max: 2}
+
+	// converseTemperatureRange is accepted by the Converse API's models
+	converseTemperatureRange = temperatureRange{min: 0, max: 1}
+)
+
+// geminiTemperatureRange returns the temperatures a Gemini model accepts: 0-1 for the 1.0
+// models, 0-2 for later ones
+func geminiTemperatureRange(model string) temperatureRange {
+	model = strings.TrimPrefix(model, "models/")
+	if strings.HasPrefix(model, "gemini-1.0") || model == "gemini-pro" || strings.HasPrefix(model, "gemini-pro-vision") {
+		return temperatureRange{min: 0, max: 1}
+	}
+	return temperatureRange{min: 0, max: 2}
+}
+
+// apply checks the query's temperature against the range. An out-of-range temperature is
+// clamped to the nearest bound when clamp is set and fails with ErrTemperatureOutOfRange
+// otherwise. Queries without a temperature are returned unchanged.
+func (r temperatureRange) apply(options provider.QueryOptions, clamp bool) (provider.QueryOptions, error) {
+	if !options.HasTemperature() || (options.Temperature >= r.min && options.Temperature <= r.max) {
+		return options, nil
+	}
+	if !clamp {
+		return options, fmt.Errorf("%w: %g is outside %g-%g", provider.ErrTemperatureOutOfRange, options.Temperature, r.min, r.max)
+	}
+	// TemperatureSet keeps a temperature clamped to 0 from reading as unset
+	options.Temperature = min(max(options.Temperature, r.min), r.max)
+	options.TemperatureSet = true
+	return options, nil
+}
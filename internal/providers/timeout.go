@@ -0,0 +1,19 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// withQueryTimeout bounds a query by options.PerRequestTimeout when it is set. The returned
+// timeout replaces the provider's configured timeout for the query's HTTP requests, so a
+// per-request timeout may be shorter or longer than the configured one.
+func withQueryTimeout(ctx context.Context, configured time.Duration, options provider.QueryOptions) (context.Context, context.CancelFunc, time.Duration) {
+	if options.PerRequestTimeout <= 0 {
+		return ctx, func() {}, configured
+	}
+	ctx, cancel := context.WithTimeout(ctx, options.PerRequestTimeout)
+	return ctx, cancel, options.PerRequestTimeout
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// openAIDefaultTranscriptionModel is used when a transcription request doesn't name a model
+const openAIDefaultTranscriptionModel = "whisper-1"
+
+// transcriptionsURL derives an OpenAI-compatible transcription endpoint from a chat completions endpoint.
+// It returns an empty string if the endpoint doesn't follow the OpenAI layout.
+func transcriptionsURL(chatURL string) string {
+	if strings.HasSuffix(chatURL, "/chat/completions") {
+		return strings.TrimSuffix(chatURL, "/chat/completions") + "/audio/transcriptions"
+	}
+	return ""
+}
+
+// transcriptionForm builds the multipart form of an OpenAI transcription request and returns
+// it with its content type
+func transcriptionForm(request provider.TranscriptionRequest, model string) (*bytes.Buffer, string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	name := request.Audio.Name
+	if name == "" {
+		name = "audio"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)))
+	if request.Audio.MimeType != "" {
+		header.Set("Content-Type", request.Audio.MimeType)
+	} else {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(request.Audio.Data); err != nil {
+		return nil, "", err
+	}
+
+	fields := [][2]string{{"model", model}, {"response_format", "json"}}
+	if request.Language != "" {
+		fields = append(fields, [2]string{"language", request.Language})
+	}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, form.FormDataContentType(), nil
+}
+
+// requestOpenAITranscription uploads audio to an OpenAI-compatible /audio/transcriptions endpoint
+func requestOpenAITranscription(ctx context.Context, client httpclient.Client, url string, headers map[string]string, timeout time.Duration, request provider.TranscriptionRequest) (*provider.TranscriptionResult, error) {
+	if len(request.Audio.Data) == 0 {
+		return nil, fmt.Errorf("transcription audio data is empty")
+	}
+
+	model := request.Model
+	if model == "" {
+		model = openAIDefaultTranscriptionModel
+	}
+
+	body, contentType, err := transcriptionForm(request, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	headers["Content-Type"] = contentType
+
+	resp, _, err := client.Do(ctx, url, "POST", headers, body, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, provider.NewAPIError(resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &provider.TranscriptionResult{Text: result.Text, Model: model}, nil
+}
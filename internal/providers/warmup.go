@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
+)
+
+// warmupURL returns the root of the endpoint's host, or an empty string if it isn't a URL
+func warmupURL(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
+
+// warmConnection sends a HEAD request to the root of the endpoint's host so that a connection,
+// with its DNS lookup and TLS handshake done, is waiting in the pool for the first real request.
+// Credentials are not sent and any status will do, since only the connection matters.
+func warmConnection(ctx context.Context, client httpclient.Client, endpoint string, timeout time.Duration) error {
+	target := warmupURL(endpoint)
+	if target == "" {
+		return fmt.Errorf("can't warm up invalid endpoint %q", endpoint)
+	}
+
+	resp, _, err := client.Do(ctx, target, http.MethodHead, nil, nil, timeout)
+	if err != nil {
+		return fmt.Errorf("warmup request failed: %w", err)
+	}
+	return drainResponse(resp)
+}
+
+// drainResponse reads the rest of the body and closes it, which lets the connection be reused
+func drainResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	_, err := io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// warmupHTTPClient records the method, URL and headers of every request
+type warmupHTTPClient struct {
+	mu       sync.Mutex
+	requests []string
+	headers  []map[string]string
+}
+
+func (c *warmupHTTPClient) Do(ctx context.Context, url string, method string, headers map[string]string, body io.Reader, timeout time.Duration) (*http.Response, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, method+" "+url)
+	c.headers = append(c.headers, headers)
+	return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("not found"))}, url, nil
+}
+
+func TestProviders_Warmup(t *testing.T) {
+	config := func(client *warmupHTTPClient) provider.Config {
+		return provider.Config{APIKey: "secret", Models: []string{"model"}, MaxDailyRequests: 1, HTTPClient: client}
+	}
+	newOpenRouter := func(client *warmupHTTPClient) (provider.Provider, error) {
+		return newOpenRouterProvider(config(client), "https://openrouter.ai/api/v1/chat/completions", "", "", nil)
+	}
+	newOpenAI := func(client *warmupHTTPClient) (provider.Provider, error) {
+		return newOpenAIProvider(config(client), "", "", nil, ToolExecutionConfig{})
+	}
+	newBedrock := func(client *warmupHTTPClient) (provider.Provider, error) {
+		return newBedrockProvider(config(client), "us-east-1", "", AWSCredentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"}, "", nil)
+	}
+
+	tests := []struct {
+		name        string
+		newProvider func(*warmupHTTPClient) (provider.Provider, error)
+		want        string
+	}{
+		{"OpenRouter", newOpenRouter, "HEAD https://openrouter.ai/"},
+		{"OpenAI", newOpenAI, "HEAD https://api.openai.com/"},
+		{"Bedrock", newBedrock, "HEAD https://bedrock-runtime.us-east-1.amazonaws.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &warmupHTTPClient{}
+			p, err := tt.newProvider(client)
+			if err != nil {
+				t.Fatalf("Failed to create provider: %v", err)
+			}
+
+			// Any response means the connection is open, so the 404 is not an error
+			if err := p.(provider.Warmer).Warmup(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(client.requests) != 1 || client.requests[0] != tt.want {
+				t.Fatalf("Expected one %s, got %v", tt.want, client.requests)
+			}
+			if _, ok := client.headers[0]["Authorization"]; ok {
+				t.Errorf("Expected no credentials on the warmup request")
+			}
+			if !p.HasRemainingRequests(context.Background()) {
+				t.Errorf("Expected the warmup not to count against the daily limit")
+			}
+		})
+	}
+}
+
+func TestGeminiProvider_Warmup(t *testing.T) {
+	api := &fakeGeminiAPI{}
+	p := newTestGeminiProvider(api)
+	p.limiter = newRateLimiter(1, 0, 0)
+
+	if err := p.Warmup(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if api.warmups != 1 {
+		t.Errorf("Expected the SDK client to be warmed up once, got %d", api.warmups)
+	}
+	if !p.HasRemainingRequests(context.Background()) {
+		t.Errorf("Expected the warmup not to count against the daily limit")
+	}
+
+	p.Close()
+	if err := p.Warmup(context.Background()); err == nil {
+		t.Errorf("Expected an error after Close")
+	}
+}
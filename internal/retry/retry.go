@@ -0,0 +1,72 @@
+// Package retry implements the exponential backoff shared by the router's retries and the
+// retries within a provider
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+const (
+	defaultBaseDelay = 200 * time.Millisecond
+	defaultMaxDelay  = 5 * time.Second
+)
+
+// Policy is a provider.RetryConfig with its defaults applied
+type Policy struct {
+	provider.RetryConfig
+}
+
+// New returns the policy for config, defaulting BaseDelay to 200ms and MaxDelay to 5s
+func New(config provider.RetryConfig) Policy {
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaultBaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultMaxDelay
+	}
+	if config.MaxDelay < config.BaseDelay {
+		config.MaxDelay = config.BaseDelay
+	}
+	return Policy{RetryConfig: config}
+}
+
+// Backoff returns the delay before the given retry (1 for the first), with half of it randomized
+// so that clients failing at the same time don't retry in lockstep
+func (p Policy) Backoff(retry int) time.Duration {
+	delay := p.MaxDelay
+	if shift := retry - 1; shift < 32 && p.BaseDelay<<shift < p.MaxDelay {
+		delay = p.BaseDelay << shift
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// Wait sleeps for the backoff before the given retry. It returns false if ctx is done first.
+func (p Policy) Wait(ctx context.Context, retry int) bool {
+	timer := time.NewTimer(p.Backoff(retry))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Do calls fn until it succeeds, returns an error that isTransient rejects, or runs out of
+// attempts. Waiting between attempts stops when ctx is done; the last error is returned.
+func (p Policy) Do(ctx context.Context, isTransient func(error) bool, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !isTransient(err) {
+			return err
+		}
+		if !p.Wait(ctx, attempt) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	p := New(provider.RetryConfig{MaxAttempts: 3})
+	if p.BaseDelay != 200*time.Millisecond || p.MaxDelay != 5*time.Second {
+		t.Errorf("Expected the default delays, got %v and %v", p.BaseDelay, p.MaxDelay)
+	}
+	if p := New(provider.RetryConfig{BaseDelay: time.Minute, MaxDelay: time.Second}); p.MaxDelay != time.Minute {
+		t.Errorf("Expected MaxDelay to be raised to BaseDelay, got %v", p.MaxDelay)
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := New(provider.RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second, 100: time.Second} {
+		if got := p.Backoff(retry); got < want/2 || got > want {
+			t.Errorf("Retry %d: expected a delay between %v and %v, got %v", retry, want/2, want, got)
+		}
+	}
+}
+
+func TestPolicy_Do(t *testing.T) {
+	transient := errors.New("transient")
+	permanent := errors.New("permanent")
+	isTransient := func(err error) bool { return errors.Is(err, transient) }
+	p := New(provider.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	calls := 0
+	err := p.Do(context.Background(), isTransient, func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = p.Do(context.Background(), isTransient, func() error {
+		calls++
+		return permanent
+	})
+	if !errors.Is(err, permanent) || calls != 1 {
+		t.Errorf("Expected no retries after a permanent error, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,140 @@
+// Package sse reads server-sent event streams
+package sse
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a Reader waits for the stream to send anything before giving up
+const DefaultIdleTimeout = 30 * time.Second
+
+// ErrIdleTimeout is returned when a stream sends nothing, not even a comment, within the idle timeout
+var ErrIdleTimeout = errors.New("event stream idle timeout")
+
+// doneData is the sentinel OpenAI-compatible APIs send as the last event of a stream
+const doneData = "[DONE]"
+
+// Event is a server-sent event
+type Event struct {
+	Name string // The event field, empty for unnamed events
+	Data string // The data lines, joined with newlines
+}
+
+// Reader reads events from a stream. Comment lines such as ": keepalive" are skipped but count
+// as activity, so servers can keep a quiet stream open with them. The stream is closed when
+// nothing arrives within the idle timeout.
+type Reader struct {
+	body     io.ReadCloser
+	lines    *bufio.Reader
+	idle     time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+	done     bool
+}
+
+// NewReader creates a reader for body. A zero idleTimeout uses DefaultIdleTimeout.
+func NewReader(body io.ReadCloser, idleTimeout time.Duration) *Reader {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+	r := &Reader{body: body, lines: bufio.NewReader(body), idle: idleTimeout}
+	r.timer = time.AfterFunc(idleTimeout, func() {
+		// Closing the body unblocks the pending read
+		r.timedOut.Store(true)
+		body.Close()
+	})
+	return r
+}
+
+// Next returns the next event. It returns io.EOF once the stream ends or sends a "[DONE]"
+// event, and ErrIdleTimeout if the stream went quiet for longer than the idle timeout.
+func (r *Reader) Next() (Event, error) {
+	if r.done {
+		return Event{}, io.EOF
+	}
+
+	var event Event
+	var data []string
+	for {
+		line, err := r.lines.ReadString('\n')
+		if line != "" && !r.timedOut.Load() {
+			r.timer.Reset(r.idle)
+		}
+		if err != nil {
+			if r.timedOut.Load() {
+				r.finish()
+				return Event{}, ErrIdleTimeout
+			}
+			if err != io.EOF {
+				r.finish()
+				return Event{}, err
+			}
+			// A final event without the blank line that should end it is still delivered
+			r.finish()
+			line = strings.TrimRight(line, "\r\n")
+			if line != "" {
+				event, data = parseLine(line, event, data)
+			}
+			if data == nil || strings.Join(data, "\n") == doneData {
+				return Event{}, io.EOF
+			}
+			event.Data = strings.Join(data, "\n")
+			return event, nil
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			// A blank line ends the event; events without data are ignored
+			if data == nil {
+				event = Event{}
+				continue
+			}
+			event.Data = strings.Join(data, "\n")
+			if event.Data == doneData {
+				r.finish()
+				return Event{}, io.EOF
+			}
+			return event, nil
+		}
+		event, data = parseLine(line, event, data)
+	}
+}
+
+// Close stops the idle timer and closes the stream
+func (r *Reader) Close() error {
+	r.finish()
+	return nil
+}
+
+// finish stops the idle timer and closes the body once the stream is over
+func (r *Reader) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.timer.Stop()
+	r.body.Close()
+}
+
+// parseLine applies a non-blank line to the event being read. Comments and unknown fields are
+// ignored.
+func parseLine(line string, event Event, data []string) (Event, []string) {
+	if strings.HasPrefix(line, ":") {
+		return event, data
+	}
+
+	field, value, _ := strings.Cut(line, ":")
+	value = strings.TrimPrefix(value, " ")
+	switch field {
+	case "event":
+		event.Name = value
+	case "data":
+		data = append(data, value)
+	}
+	return event, data
+}
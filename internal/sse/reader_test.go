@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReader_Events(t *testing.T) {
+	stream := ": keepalive\n\nevent: message\ndata: {\"a\":1}\n\ndata: first\r\ndata: second\r\n\r\nid: 7\n\ndata: [DONE]\n\ndata: after done\n\n"
+	r := NewReader(io.NopCloser(strings.NewReader(stream)), time.Second)
+
+	event, err := r.Next()
+	if err != nil || event.Name != "message" || event.Data != `{"a":1}` {
+		t.Fatalf("Expected the named event, got %+v, %v", event, err)
+	}
+
+	event, err = r.Next()
+	if err != nil || event.Name != "" || event.Data != "first\nsecond" {
+		t.Fatalf("Expected the data lines joined with a newline, got %+v, %v", event, err)
+	}
+
+	// The event without data is skipped and [DONE] ends the stream
+	for i := 0; i < 2; i++ {
+		if event, err := r.Next(); err != io.EOF {
+			t.Fatalf("Expected io.EOF at [DONE], got %+v, %v", event, err)
+		}
+	}
+}
+
+func TestReader_FinalEventWithoutBlankLine(t *testing.T) {
+	r := NewReader(io.NopCloser(strings.NewReader("data: last")), time.Second)
+
+	event, err := r.Next()
+	if err != nil || event.Data != "last" {
+		t.Fatalf("Expected the unterminated final event, got %+v, %v", event, err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the final event, got %v", err)
+	}
+}
+
+func TestReader_IdleTimeout(t *testing.T) {
+	body, writer := io.Pipe()
+	r := NewReader(body, 100*time.Millisecond)
+
+	go func() {
+		writer.Write([]byte("data: hello\n\n"))
+		// Heartbeats keep the stream alive past the idle timeout, then it goes quiet
+		for i := 0; i < 4; i++ {
+			time.Sleep(50 * time.Millisecond)
+			writer.Write([]byte(": ping\n"))
+		}
+	}()
+
+	event, err := r.Next()
+	if err != nil || event.Data != "hello" {
+		t.Fatalf("Expected the first event, got %+v, %v", event, err)
+	}
+
+	start := time.Now()
+	if _, err := r.Next(); !errors.Is(err, ErrIdleTimeout) {
+		t.Fatalf("Expected ErrIdleTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected heartbeats to delay the idle timeout, timed out after %v", elapsed)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF once the stream is closed, got %v", err)
+	}
+}
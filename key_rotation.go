@@ -0,0 +1,201 @@
+package gollmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// KeyedConfig is a provider config that authenticates with a single API key
+type KeyedConfig interface {
+	GeminiConfig | OpenRouterConfig | FunctionCallingConfig | OpenAIConfig | MistralConfig
+}
+
+// WithKeyRotation creates one provider per API key from config and combines them into a single
+// logical provider, for a pool of keys for the same vendor. config's own APIKey is ignored.
+//
+// Every key gets its own provider and so its own rate-limit counters: MaxDailyReqs,
+// MaxRequestsPerMinute, MaxTokensPerMinute and ModelLimits apply to each key. Requests go to the
+// keys round-robin, skipping keys without quota left. When a key is rejected with a 429, 401 or
+// 403 the request is retried with the next key, so the pool is exhausted before the router falls
+// over to the next provider. Other errors are returned as they are, since another key for the
+// same API won't fix them.
+//
+// The pool reports remaining quota while some key can take a request. HealthCheck and Warmup
+// cover every key; the other optional interfaces, such as Embedder or RawRequester, use the
+// first key.
+func WithKeyRotation[C KeyedConfig](config C, keys []string) (provider.Provider, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one API key is required")
+	}
+
+	pool := &keyRotationProvider{keys: make([]provider.Provider, 0, len(keys))}
+	for i, key := range keys {
+		p, err := newKeyedProvider(config, key)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to create provider for key %d: %w", i+1, err)
+		}
+		pool.keys = append(pool.keys, p)
+	}
+	pool.providerWrapper = providerWrapper{pool.keys[0]}
+	return pool, nil
+}
+
+// newKeyedProvider creates the provider for config with its API key replaced by key
+func newKeyedProvider(config any, key string) (provider.Provider, error) {
+	switch c := config.(type) {
+	case GeminiConfig:
+		c.APIKey = key
+		return NewGeminiProvider(c)
+	case OpenRouterConfig:
+		c.APIKey = key
+		return NewOpenRouterProvider(c)
+	case FunctionCallingConfig:
+		c.APIKey = key
+		return NewFunctionCallingProvider(c)
+	case OpenAIConfig:
+		c.APIKey = key
+		return NewOpenAIProvider(c)
+	case MistralConfig:
+		c.APIKey = key
+		return NewMistralProvider(c)
+	default:
+		return nil, fmt.Errorf("unsupported config type %T", config)
+	}
+}
+
+// keyRotationProvider spreads requests over one provider per API key. The embedded wrapper
+// forwards to keys[0], which GetRank, Name and Models report for the whole pool; it always
+// exists because WithKeyRotation rejects an empty list of keys.
+type keyRotationProvider struct {
+	providerWrapper
+	keys []provider.Provider
+
+	mu   sync.Mutex
+	next int // Key to try first for the next request
+}
+
+// Query sends the query with the next key that has quota left (legacy method)
+func (k *keyRotationProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
+	result, err := k.QueryWithOptions(ctx, messages, provider.QueryOptions{Temperature: temperature, TemperatureSet: true, ForceModel: forceModel})
+	if err != nil {
+		return "", "", err
+	}
+	return result.Content, result.Model, nil
+}
+
+// QueryWithOptions sends the query with the next key that has quota left, moving on to the
+// following keys while a key is rate limited or rejected
+func (k *keyRotationProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	start := k.rotate()
+
+	var lastErr error
+	for i := range k.keys {
+		p := k.keys[(start+i)%len(k.keys)]
+		if err := checkRateLimits(ctx, p, messages); err != nil {
+			lastErr = err
+			continue
+		}
+
+		result, err := p.QueryWithOptions(ctx, messages, options)
+		if err == nil {
+			return result, nil
+		}
+		if !isKeyError(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all %d keys failed, last error: %w", len(k.keys), lastErr)
+}
+
+// rotate returns the key to try first and advances the round-robin
+func (k *keyRotationProvider) rotate() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	start := k.next
+	k.next = (k.next + 1) % len(k.keys)
+	return start
+}
+
+// isKeyError reports whether err is specific to the key used, so another key may succeed
+func isKeyError(err error) bool {
+	status := apiStatus(err)
+	return isRateLimitError(err) || status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// HasRemainingRequests reports whether any key can take a request now
+func (k *keyRotationProvider) HasRemainingRequests(ctx context.Context) bool {
+	return k.anyKeyAvailable(ctx, 0)
+}
+
+// HasRemainingRequestsPerMinute reports whether any key can take a request now
+func (k *keyRotationProvider) HasRemainingRequestsPerMinute(ctx context.Context) bool {
+	return k.anyKeyAvailable(ctx, 0)
+}
+
+// HasRemainingTokensPerMinute reports whether any key can take a request of the estimated tokens
+// now
+func (k *keyRotationProvider) HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool {
+	return k.anyKeyAvailable(ctx, estimatedTokens)
+}
+
+// anyKeyAvailable reports whether keyAvailable holds for any key
+func (k *keyRotationProvider) anyKeyAvailable(ctx context.Context, tokens int) bool {
+	for _, p := range k.keys {
+		if keyAvailable(ctx, p, tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyAvailable reports whether a key passes the checks of checkRateLimits for a request of the
+// given tokens, as QueryWithOptions requires before using it
+func keyAvailable(ctx context.Context, p provider.Provider, tokens int) bool {
+	return checkRequestLimits(ctx, p) == nil && checkTokenBudget(ctx, p, tokens) == nil
+}
+
+// ResetLimits resets the counters of every key
+func (k *keyRotationProvider) ResetLimits() {
+	for _, p := range k.keys {
+		p.ResetLimits()
+	}
+}
+
+// Close closes the provider of every key
+func (k *keyRotationProvider) Close() {
+	for _, p := range k.keys {
+		p.Close()
+	}
+}
+
+// HealthCheck checks every key and joins the failures
+func (k *keyRotationProvider) HealthCheck(ctx context.Context) error {
+	return k.forEachKey(func(p provider.Provider) error {
+		return providerWrapper{p}.HealthCheck(ctx)
+	})
+}
+
+// Warmup opens a connection with every key's provider
+func (k *keyRotationProvider) Warmup(ctx context.Context) error {
+	return k.forEachKey(func(p provider.Provider) error {
+		return providerWrapper{p}.Warmup(ctx)
+	})
+}
+
+// forEachKey calls fn with each key's provider in turn and joins the errors by key
+func (k *keyRotationProvider) forEachKey(fn func(provider.Provider) error) error {
+	var errs []error
+	for i, p := range k.keys {
+		if err := fn(p); err != nil {
+			errs = append(errs, fmt.Errorf("key %d: %w", i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,146 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// newKeyServer answers chat completions, rejecting the given keys with a 429, and records the
+// key of every request
+func newKeyServer(t *testing.T, rejected ...string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+
+		for _, k := range rejected {
+			if k == key {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"error": {"message": "quota exceeded for key"}}`))
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]interface{}{"role": "assistant", "content": "ok from " + key}, "finish_reason": "stop"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestWithKeyRotation_ExhaustedKey(t *testing.T) {
+	server, keys := newKeyServer(t)
+	p, err := gollmrouter.WithKeyRotation(gollmrouter.FunctionCallingConfig{
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		MaxDailyReqs: 1,
+	}, []string{"key-a", "key-b"})
+	if err != nil {
+		t.Fatalf("Failed to create key pool: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(p)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for i := 0; i < 2; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Query %d: unexpected error: %v", i+1, err)
+		}
+		if i == 0 && !p.HasRemainingRequests(context.Background()) {
+			t.Errorf("Expected the pool to have requests left while one key does")
+		}
+	}
+
+	if got := keys(); len(got) != 2 || got[0] != "key-a" || got[1] != "key-b" {
+		t.Errorf("Expected the second key once the first was used up, got %v", got)
+	}
+	if p.HasRemainingRequests(context.Background()) {
+		t.Errorf("Expected no requests left once every key is used up")
+	}
+	_, err = router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	var routerErr *gollmrouter.RouterError
+	if !errors.As(err, &routerErr) || !routerErr.AllRateLimited() {
+		t.Errorf("Expected a rate limit error once every key is used up, got %v", err)
+	}
+}
+
+func TestWithKeyRotation_RemainingQuotaPerKey(t *testing.T) {
+	server, _ := newKeyServer(t)
+	p, err := gollmrouter.WithKeyRotation(gollmrouter.FunctionCallingConfig{
+		URL:                  server.URL,
+		Models:               []string{"test-model"},
+		MaxRequestsPerMinute: 1,
+		MaxTokensPerMinute:   1000,
+	}, []string{"key-a", "key-b"})
+	if err != nil {
+		t.Fatalf("Failed to create key pool: %v", err)
+	}
+	defer p.Close()
+
+	ctx := context.Background()
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for i := 0; i < 2; i++ {
+		if !p.HasRemainingTokensPerMinute(ctx, 10) {
+			t.Fatalf("Query %d: expected a key to have quota left", i+1)
+		}
+		if _, err := p.QueryWithOptions(ctx, messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Query %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	// Both keys still have tokens left but no requests, so no key can take a request
+	if p.HasRemainingRequestsPerMinute(ctx) || p.HasRemainingTokensPerMinute(ctx, 10) || p.HasRemainingRequests(ctx) {
+		t.Error("Expected no quota once every key is out of requests this minute")
+	}
+}
+
+func TestWithKeyRotation_RejectedKey(t *testing.T) {
+	server, keys := newKeyServer(t, "key-a")
+	p, err := gollmrouter.WithKeyRotation(gollmrouter.FunctionCallingConfig{
+		URL:    server.URL,
+		Models: []string{"test-model"},
+	}, []string{"key-a", "key-b"})
+	if err != nil {
+		t.Fatalf("Failed to create key pool: %v", err)
+	}
+	defer p.Close()
+
+	result, err := p.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "ok from key-b" {
+		t.Errorf("Expected the next key to answer, got %q", result.Content)
+	}
+	if got := keys(); len(got) != 2 || got[0] != "key-a" || got[1] != "key-b" {
+		t.Errorf("Expected the rejected key to be followed by the next, got %v", got)
+	}
+}
+
+func TestWithKeyRotation_RequiresKeys(t *testing.T) {
+	if _, err := gollmrouter.WithKeyRotation(gollmrouter.OpenAIConfig{Models: []string{"gpt-4o"}}, nil); err == nil {
+		t.Errorf("Expected an error without keys")
+	}
+}
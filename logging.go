@@ -0,0 +1,48 @@
+package gollmrouter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// WithRequestID returns a context that carries the request id. The router adds it to its log
+// lines and spans, and middleware, providers and tool executors can read it with
+// RequestIDFromContext.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return provider.ContextWithRequestID(ctx, id)
+}
+
+// RequestIDFromContext returns the request id set with WithRequestID, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	return provider.RequestIDFromContext(ctx)
+}
+
+// WithLogger makes the router log provider attempts: failures at warn level, skipped providers
+// at info level and successes at debug level. Log lines include the request id from the
+// context, if any. Without it, the router doesn't log.
+func WithLogger(logger *slog.Logger) RouterOption {
+	return func(r *Router) {
+		r.logger = logger
+	}
+}
+
+// log writes a log line with the request id from ctx, if the router has a logger
+func (r *Router) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if r.logger == nil {
+		return
+	}
+	if id := provider.RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	r.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// requestIDAttributes returns the span attribute for the request id from ctx, if any
+func requestIDAttributes(ctx context.Context) []provider.Attribute {
+	if id := provider.RequestIDFromContext(ctx); id != "" {
+		return []provider.Attribute{provider.Attr("request.id", id)}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// recordingHandler is a slog.Handler that keeps every record's message and attributes
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []map[string]string
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := map[string]string{"msg": record.Message, "level": record.Level.String()}
+	record.Attrs(func(attr slog.Attr) bool {
+		fields[attr.Key] = attr.Value.String()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, fields)
+	return nil
+}
+
+func TestRouter_LogsIncludeRequestID(t *testing.T) {
+	exhausted := &mockProvider{name: "exhausted", rank: 3, exhausted: true}
+	failing := &mockProvider{name: "primary", rank: 2, err: errors.New("primary down")}
+	backup := &mockProvider{name: "backup", rank: 1, content: "ok"}
+
+	handler := &recordingHandler{}
+	tracer := &memoryTracer{}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{exhausted, failing, backup},
+		gollmrouter.WithLogger(slog.New(handler)), gollmrouter.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	ctx := gollmrouter.WithRequestID(context.Background(), "req-123")
+	if id := gollmrouter.RequestIDFromContext(ctx); id != "req-123" {
+		t.Fatalf("Expected the request id from the context, got %q", id)
+	}
+	if _, err := router.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct{ msg, level, provider string }{
+		{"provider skipped", "INFO", "exhausted"},
+		{"provider attempt failed", "WARN", "primary"},
+		{"provider attempt succeeded", "DEBUG", "backup"},
+	}
+	if len(handler.records) != len(expected) {
+		t.Fatalf("Expected %d log lines, got %v", len(expected), handler.records)
+	}
+	for i, want := range expected {
+		record := handler.records[i]
+		if record["msg"] != want.msg || record["level"] != want.level || record["provider"] != want.provider {
+			t.Errorf("Expected %q at %s for %s, got %v", want.msg, want.level, want.provider, record)
+		}
+		if record["request_id"] != "req-123" {
+			t.Errorf("Expected the request id on %q, got %v", record["msg"], record)
+		}
+	}
+
+	for _, span := range append(tracer.spans("Router.QueryWithOptions"), tracer.spans("Router.ProviderAttempt")...) {
+		if span.attributes["request.id"] != "req-123" {
+			t.Errorf("Expected the request id on span %s, got %v", span.name, span.attributes)
+		}
+	}
+}
+
+func TestRouter_LogsWithoutRequestID(t *testing.T) {
+	handler := &recordingHandler{}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{&mockProvider{name: "only", content: "ok"}},
+		gollmrouter.WithLogger(slog.New(handler)))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(handler.records) != 1 {
+		t.Fatalf("Expected 1 log line, got %v", handler.records)
+	}
+	if _, ok := handler.records[0]["request_id"]; ok {
+		t.Errorf("Expected no request id without one in the context, got %v", handler.records[0])
+	}
+}
@@ -0,0 +1,248 @@
+package gollmrouter_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/ai"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// fakeMCPServer answers MCP JSON-RPC requests with a single "echo" tool
+type fakeMCPServer struct {
+	initialized bool
+}
+
+func (s *fakeMCPServer) handle(message []byte) []byte {
+	var request struct {
+		ID     *int64                 `json:"id"`
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil
+	}
+
+	var result interface{}
+	switch request.Method {
+	case "notifications/initialized":
+		s.initialized = true
+		return nil
+	case "initialize":
+		result = map[string]interface{}{
+			"protocolVersion": ai.MCPProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0"},
+		}
+	case "tools/list":
+		result = map[string]interface{}{
+			"tools": []map[string]interface{}{{
+				"name":        "echo",
+				"description": "Echo the input text",
+				"inputSchema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"text": map[string]interface{}{"type": "string"}},
+				},
+			}},
+		}
+	case "tools/call":
+		arguments, _ := request.Params["arguments"].(map[string]interface{})
+		text, _ := arguments["text"].(string)
+		result = map[string]interface{}{
+			"content": []map[string]interface{}{{"type": "text", "text": text}},
+			"isError": request.Params["name"] != "echo",
+		}
+	default:
+		response, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"error":   map[string]interface{}{"code": -32601, "message": "method not found"},
+		})
+		return response
+	}
+
+	response, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	return response
+}
+
+// newPipeMCPExecutor connects an executor to a fake MCP server over in-memory pipes
+func newPipeMCPExecutor(t *testing.T, server *fakeMCPServer) *ai.MCPToolExecutor {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	go func() {
+		defer serverWriter.Close()
+		scanner := bufio.NewScanner(serverReader)
+		for scanner.Scan() {
+			// Interleave a server notification to make sure the client skips it
+			serverWriter.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/message","params":{}}` + "\n"))
+			if response := server.handle(scanner.Bytes()); response != nil {
+				serverWriter.Write(append(response, '\n'))
+			}
+		}
+	}()
+
+	executor, err := ai.NewMCPToolExecutor(context.Background(), ai.NewMCPStreamTransport(clientReader, clientWriter))
+	if err != nil {
+		t.Fatalf("Failed to create MCP executor: %v", err)
+	}
+	t.Cleanup(func() { executor.Close() })
+	return executor
+}
+
+func TestMCPToolExecutor_Stream(t *testing.T) {
+	server := &fakeMCPServer{}
+	executor := newPipeMCPExecutor(t, server)
+
+	var _ gollmrouter.ToolExecutor = executor
+
+	tools := executor.GetAvailableTools()
+	if len(tools) != 1 || tools[0].Function.Name != "echo" {
+		t.Fatalf("Expected the echo tool, got %+v", tools)
+	}
+	if tools[0].Function.Parameters["type"] != "object" {
+		t.Errorf("Expected input schema to be used as parameters, got %v", tools[0].Function.Parameters)
+	}
+
+	result, err := executor.ExecuteTool(context.Background(), provider.ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: provider.ToolCallFunction{Name: "echo", Arguments: map[string]interface{}{"text": "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ID != "call_1" || result.Content != "hello" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !server.initialized {
+		t.Error("Expected initialized notification to be sent")
+	}
+}
+
+func TestMCPToolExecutor_ToolError(t *testing.T) {
+	executor := newPipeMCPExecutor(t, &fakeMCPServer{})
+
+	_, err := executor.ExecuteTool(context.Background(), provider.ToolCall{
+		ID:       "call_1",
+		Function: provider.ToolCallFunction{Name: "missing", Arguments: map[string]interface{}{"text": "boom"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected tool error containing the server message, got %v", err)
+	}
+}
+
+func TestMCPToolExecutor_HTTP(t *testing.T) {
+	server := &fakeMCPServer{}
+	var sessionHeaders []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionHeaders = append(sessionHeaders, r.Header.Get("Mcp-Session-Id"))
+		body, _ := io.ReadAll(r.Body)
+		response := server.handle(body)
+		if response == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		// Answer tool calls as an event stream and everything else as plain JSON
+		if strings.Contains(string(body), "tools/call") {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("event: message\ndata: " + string(response) + "\n\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(response)
+	}))
+	defer httpServer.Close()
+
+	executor, err := ai.NewMCPHTTPToolExecutor(context.Background(), httpServer.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create MCP executor: %v", err)
+	}
+	defer executor.Close()
+
+	result, err := executor.ExecuteTool(context.Background(), provider.ToolCall{
+		ID:       "call_1",
+		Function: provider.ToolCallFunction{Name: "echo", Arguments: map[string]interface{}{"text": "over http"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "over http" {
+		t.Errorf("Expected echoed content, got %v", result.Content)
+	}
+
+	if sessionHeaders[0] != "" || sessionHeaders[len(sessionHeaders)-1] != "session-1" {
+		t.Errorf("Expected session id to be sent after initialize, got %v", sessionHeaders)
+	}
+}
+
+func TestMCPToolExecutor_HTTPEventStreamIdleTimeout(t *testing.T) {
+	server := &fakeMCPServer{}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		response := server.handle(body)
+		if response == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if !strings.Contains(string(body), "tools/call") {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(response)
+			return
+		}
+
+		// Keep the stream open after a keepalive comment, answering only calls that ask for it
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": keepalive\n\n"))
+		if !strings.Contains(string(body), "hang") {
+			w.Write([]byte("data: " + string(response) + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer httpServer.Close()
+
+	transport := ai.NewMCPHTTPTransport(httpServer.URL, nil, 0)
+	transport.IdleTimeout = 100 * time.Millisecond
+	executor, err := ai.NewMCPToolExecutor(context.Background(), transport)
+	if err != nil {
+		t.Fatalf("Failed to create MCP executor: %v", err)
+	}
+	defer executor.Close()
+
+	call := func(text string) (*provider.ToolCallResult, error) {
+		return executor.ExecuteTool(context.Background(), provider.ToolCall{
+			ID:       "call_1",
+			Function: provider.ToolCallFunction{Name: "echo", Arguments: map[string]interface{}{"text": text}},
+		})
+	}
+
+	// The response is returned without waiting for the stream to close
+	result, err := call("still open")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "still open" {
+		t.Errorf("Expected echoed content, got %v", result.Content)
+	}
+
+	start := time.Now()
+	if _, err := call("hang"); err == nil || !strings.Contains(err.Error(), "idle timeout") {
+		t.Fatalf("Expected an idle timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the idle timeout to end the request promptly, took %v", elapsed)
+	}
+}
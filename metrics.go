@@ -0,0 +1,49 @@
+package gollmrouter
+
+import "time"
+
+// Outcomes reported to a MetricsCollector for each provider
+const (
+	OutcomeSuccess     = "success"
+	OutcomeError       = "error"
+	OutcomeRateLimited = "rate_limited"
+	OutcomeSkipped     = "skipped" // Not attempted because too little time was left before the deadline
+)
+
+// MetricsCollector receives metrics for every provider attempt made by the router.
+// Implementations must be safe for concurrent use. See the metrics/prometheus package
+// for an adapter that exposes the metrics in the Prometheus text format.
+type MetricsCollector interface {
+	// IncRequest counts a provider attempt. The model is empty when it is not known,
+	// e.g. for providers skipped because of rate limits.
+	IncRequest(provider, model, outcome string)
+	// ObserveLatency records how long a provider attempt took
+	ObserveLatency(provider string, d time.Duration)
+	// IncTokens counts the tokens used by a successful request, as reported by the
+	// provider or estimated when the provider does not report usage
+	IncTokens(provider string, n int)
+}
+
+// ModelAttemptCollector can be implemented by a MetricsCollector to also count the models a
+// provider tried within each successful attempt, including models that failed before another
+// one answered. The outcome is OutcomeSuccess or OutcomeError.
+type ModelAttemptCollector interface {
+	IncModelAttempt(provider, model, outcome string)
+}
+
+// NoopMetrics is a MetricsCollector that discards all metrics. It is used by default.
+type NoopMetrics struct{}
+
+func (NoopMetrics) IncRequest(provider, model, outcome string)      {}
+func (NoopMetrics) ObserveLatency(provider string, d time.Duration) {}
+func (NoopMetrics) IncTokens(provider string, n int)                {}
+
+// WithMetrics makes the router report metrics for every provider attempt to the collector
+func WithMetrics(collector MetricsCollector) RouterOption {
+	return func(r *Router) {
+		if collector == nil {
+			collector = NoopMetrics{}
+		}
+		r.metrics = collector
+	}
+}
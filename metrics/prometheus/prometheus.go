@@ -0,0 +1,218 @@
+// Package prometheus exposes go-llm-router metrics in the Prometheus text exposition format
+// without depending on the Prometheus client library.
+//
+//	collector := prometheus.NewCollector("llm_router")
+//	router, err := gollmrouter.NewRouterWithOptions(providers, gollmrouter.WithMetrics(collector))
+//	http.Handle("/metrics", collector)
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram buckets in seconds
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Collector implements gollmrouter.MetricsCollector and serves the collected metrics over HTTP.
+// It exports:
+//   - <namespace>_requests_total{provider,model,outcome}: provider attempts by outcome
+//   - <namespace>_request_duration_seconds{provider}: provider attempt latency histogram
+//   - <namespace>_tokens_total{provider}: tokens used by successful requests
+//   - <namespace>_model_attempts_total{provider,model,outcome}: models tried within successful
+//     provider attempts, by outcome
+type Collector struct {
+	namespace string
+	buckets   []float64
+
+	mu            sync.Mutex
+	requests      map[requestKey]uint64
+	latency       map[string]*histogram
+	tokens        map[string]uint64
+	modelAttempts map[requestKey]uint64
+}
+
+type requestKey struct {
+	provider string
+	model    string
+	outcome  string
+}
+
+type histogram struct {
+	counts []uint64 // cumulative counts per bucket
+	count  uint64
+	sum    float64
+}
+
+// NewCollector creates a collector whose metric names start with namespace (default "llm_router")
+func NewCollector(namespace string) *Collector {
+	if namespace == "" {
+		namespace = "llm_router"
+	}
+	return &Collector{
+		namespace:     namespace,
+		buckets:       DefaultBuckets,
+		requests:      make(map[requestKey]uint64),
+		latency:       make(map[string]*histogram),
+		tokens:        make(map[string]uint64),
+		modelAttempts: make(map[requestKey]uint64),
+	}
+}
+
+// IncRequest counts a provider attempt
+func (c *Collector) IncRequest(provider, model, outcome string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[requestKey{provider: provider, model: model, outcome: outcome}]++
+}
+
+// IncModelAttempt counts a model tried by a provider
+func (c *Collector) IncModelAttempt(provider, model, outcome string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modelAttempts[requestKey{provider: provider, model: model, outcome: outcome}]++
+}
+
+// ObserveLatency records the duration of a provider attempt
+func (c *Collector) ObserveLatency(provider string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.latency[provider]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.latency[provider] = h
+	}
+
+	seconds := d.Seconds()
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// IncTokens counts tokens used by a provider
+func (c *Collector) IncTokens(provider string, n int) {
+	if n <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[provider] += uint64(n)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counter := &countingWriter{w: bufio.NewWriter(w)}
+
+	name := c.namespace + "_requests_total"
+	fmt.Fprintf(counter, "# HELP %s Provider attempts by outcome.\n# TYPE %s counter\n", name, name)
+	for _, key := range sortedRequestKeys(c.requests) {
+		fmt.Fprintf(counter, "%s{provider=%s,model=%s,outcome=%s} %d\n",
+			name, quote(key.provider), quote(key.model), quote(key.outcome), c.requests[key])
+	}
+
+	name = c.namespace + "_request_duration_seconds"
+	fmt.Fprintf(counter, "# HELP %s Provider attempt latency in seconds.\n# TYPE %s histogram\n", name, name)
+	for _, provider := range sortedKeys(c.latency) {
+		h := c.latency[provider]
+		for i, bound := range c.buckets {
+			fmt.Fprintf(counter, "%s_bucket{provider=%s,le=\"%s\"} %d\n",
+				name, quote(provider), strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(counter, "%s_bucket{provider=%s,le=\"+Inf\"} %d\n", name, quote(provider), h.count)
+		fmt.Fprintf(counter, "%s_sum{provider=%s} %s\n", name, quote(provider), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(counter, "%s_count{provider=%s} %d\n", name, quote(provider), h.count)
+	}
+
+	name = c.namespace + "_tokens_total"
+	fmt.Fprintf(counter, "# HELP %s Tokens used by successful requests.\n# TYPE %s counter\n", name, name)
+	for _, provider := range sortedKeys(c.tokens) {
+		fmt.Fprintf(counter, "%s{provider=%s} %d\n", name, quote(provider), c.tokens[provider])
+	}
+
+	name = c.namespace + "_model_attempts_total"
+	fmt.Fprintf(counter, "# HELP %s Models tried within successful provider attempts, by outcome.\n# TYPE %s counter\n", name, name)
+	for _, key := range sortedRequestKeys(c.modelAttempts) {
+		fmt.Fprintf(counter, "%s{provider=%s,model=%s,outcome=%s} %d\n",
+			name, quote(key.provider), quote(key.model), quote(key.outcome), c.modelAttempts[key])
+	}
+
+	if counter.err != nil {
+		return counter.n, counter.err
+	}
+	return counter.n, counter.w.Flush()
+}
+
+// ServeHTTP serves the metrics for scraping
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// quote formats a label value, escaping backslashes, quotes and newlines
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedRequestKeys returns the keys of m ordered by provider, model and outcome
+func sortedRequestKeys(m map[requestKey]uint64) []requestKey {
+	keys := make([]requestKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.provider != b.provider {
+			return a.provider < b.provider
+		}
+		if a.model != b.model {
+			return a.model < b.model
+		}
+		return a.outcome < b.outcome
+	})
+	return keys
+}
+
+// countingWriter tracks the bytes written and the first error
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,175 @@
+package gollmrouter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/metrics/prometheus"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// fakeMetrics records the series reported by the router
+type fakeMetrics struct {
+	mu            sync.Mutex
+	requests      []string
+	latencies     map[string]int
+	tokens        map[string]int
+	modelAttempts []string
+}
+
+func newFakeMetrics() *fakeMetrics {
+	return &fakeMetrics{latencies: map[string]int{}, tokens: map[string]int{}}
+}
+
+func (f *fakeMetrics) IncRequest(provider, model, outcome string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, provider+"/"+model+"/"+outcome)
+}
+
+func (f *fakeMetrics) ObserveLatency(provider string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latencies[provider]++
+}
+
+func (f *fakeMetrics) IncTokens(provider string, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens[provider] += n
+}
+
+func (f *fakeMetrics) IncModelAttempt(provider, model, outcome string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.modelAttempts = append(f.modelAttempts, provider+"/"+model+"/"+outcome)
+}
+
+func TestRouter_MetricsFallback(t *testing.T) {
+	exhausted := &mockProvider{name: "exhausted", rank: 3, exhausted: true}
+	failing := &mockProvider{name: "failing", rank: 2, err: errors.New("boom")}
+	working := &mockProvider{name: "working", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		return &provider.QueryResult{Content: "ok", Model: "working-model", Usage: &provider.Usage{TotalTokens: 42}}, nil
+	}}
+
+	metrics := newFakeMetrics()
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{exhausted, failing, working}, gollmrouter.WithMetrics(metrics))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"exhausted//rate_limited",
+		"failing//error",
+		"working/working-model/success",
+	}
+	if strings.Join(metrics.requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected requests %v, got %v", expected, metrics.requests)
+	}
+
+	if metrics.latencies["exhausted"] != 0 || metrics.latencies["failing"] != 1 || metrics.latencies["working"] != 1 {
+		t.Errorf("Expected latency for attempted providers only, got %v", metrics.latencies)
+	}
+
+	if len(metrics.tokens) != 1 || metrics.tokens["working"] != 42 {
+		t.Errorf("Expected reported usage to be counted for the successful provider, got %v", metrics.tokens)
+	}
+}
+
+func TestRouter_MetricsEstimateTokensWithoutUsage(t *testing.T) {
+	metrics := newFakeMetrics()
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{&mockProvider{name: "only", content: "ok"}}, gollmrouter.WithMetrics(metrics))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: "Hello, world! How are you today?"}}
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := provider.DefaultTokenEstimator.EstimateTokens(messages); metrics.tokens["only"] != want {
+		t.Errorf("Expected %d estimated tokens, got %d", want, metrics.tokens["only"])
+	}
+}
+
+func TestRouter_ModelAttempts(t *testing.T) {
+	p := &mockProvider{name: "gateway", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		return &provider.QueryResult{Content: "ok", Model: "secondary", ModelAttempts: []provider.ModelAttempt{
+			{Model: "primary", Error: "API request failed with status 503: overloaded"},
+			{Model: "secondary"},
+		}}, nil
+	}}
+
+	metrics := newFakeMetrics()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{p}, gollmrouter.WithMetrics(metrics), gollmrouter.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ModelAttempts) != 2 {
+		t.Errorf("Expected the model attempts on the result, got %+v", result.ModelAttempts)
+	}
+
+	expected := []string{"gateway/primary/error", "gateway/secondary/success"}
+	if strings.Join(metrics.modelAttempts, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected model attempts %v, got %v", expected, metrics.modelAttempts)
+	}
+	if !strings.Contains(logs.String(), "model attempt failed") || !strings.Contains(logs.String(), "model=primary") {
+		t.Errorf("Expected the failed model to be logged, got %q", logs.String())
+	}
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	collector := prometheus.NewCollector("")
+	var _ gollmrouter.MetricsCollector = collector
+	var _ gollmrouter.ModelAttemptCollector = collector
+
+	collector.IncRequest("Gemini", "gemini-2.0-flash", gollmrouter.OutcomeSuccess)
+	collector.IncRequest("Gemini", "gemini-2.0-flash", gollmrouter.OutcomeSuccess)
+	collector.IncRequest("OpenRouter", "", gollmrouter.OutcomeRateLimited)
+	collector.ObserveLatency("Gemini", 300*time.Millisecond)
+	collector.IncTokens("Gemini", 120)
+	collector.IncModelAttempt("OpenRouter", "openai/gpt-4o", gollmrouter.OutcomeError)
+
+	var buf bytes.Buffer
+	if _, err := collector.WriteTo(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	for _, line := range []string{
+		"# TYPE llm_router_requests_total counter",
+		`llm_router_requests_total{provider="Gemini",model="gemini-2.0-flash",outcome="success"} 2`,
+		`llm_router_requests_total{provider="OpenRouter",model="",outcome="rate_limited"} 1`,
+		"# TYPE llm_router_request_duration_seconds histogram",
+		`llm_router_request_duration_seconds_bucket{provider="Gemini",le="0.25"} 0`,
+		`llm_router_request_duration_seconds_bucket{provider="Gemini",le="0.5"} 1`,
+		`llm_router_request_duration_seconds_bucket{provider="Gemini",le="+Inf"} 1`,
+		`llm_router_request_duration_seconds_count{provider="Gemini"} 1`,
+		`llm_router_tokens_total{provider="Gemini"} 120`,
+		"# TYPE llm_router_model_attempts_total counter",
+		`llm_router_model_attempts_total{provider="OpenRouter",model="openai/gpt-4o",outcome="error"} 1`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
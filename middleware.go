@@ -0,0 +1,46 @@
+package gollmrouter
+
+import (
+	"context"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// Handler handles a query the way Router.QueryWithOptions does
+type Handler func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error)
+
+// Middleware wraps a Handler. A middleware can modify the messages and options before calling
+// next, post-process the result, or return without calling next to skip the providers entirely.
+type Middleware func(next Handler) Handler
+
+// Use adds middlewares to the router. They run around QueryWithOptions in the order they were
+// added, so the first middleware sees the request first and the result last.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// handler returns the router's query handler wrapped in its middlewares
+func (r *Router) handler() Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler := Handler(r.queryWithOptions)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+	return handler
+}
+
+// SystemPromptMiddleware sets the system prompt of queries that don't specify one
+func SystemPromptMiddleware(prompt string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+			if options.SystemPrompt == "" {
+				options.SystemPrompt = prompt
+			}
+			return next(ctx, messages, options)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouter_MiddlewareOrder(t *testing.T) {
+	var order []string
+	mock := &mockProvider{name: "only", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		order = append(order, "provider")
+		return &provider.QueryResult{Content: messages[0].Content}, nil
+	}}
+	router, err := gollmrouter.NewRouter(mock)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	tag := func(name string) gollmrouter.Middleware {
+		return func(next gollmrouter.Handler) gollmrouter.Handler {
+			return func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+				order = append(order, name+" before")
+				messages = append([]provider.Message{{Role: "user", Content: messages[0].Content + " " + name}}, messages[1:]...)
+				result, err := next(ctx, messages, options)
+				if err == nil {
+					result.Content += " <- " + name
+				}
+				order = append(order, name+" after")
+				return result, err
+			}
+		}
+	}
+	router.Use(tag("first"))
+	router.Use(tag("second"))
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "first before,second before,provider,second after,first after"
+	if strings.Join(order, ",") != expected {
+		t.Errorf("Expected order %q, got %q", expected, strings.Join(order, ","))
+	}
+	if result.Content != "hi first second <- second <- first" {
+		t.Errorf("Expected messages and result to pass through both middlewares, got %q", result.Content)
+	}
+}
+
+func TestRouter_MiddlewareShortCircuit(t *testing.T) {
+	mock := &mockProvider{name: "only", content: "from provider"}
+	router, err := gollmrouter.NewRouter(mock)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	router.Use(func(next gollmrouter.Handler) gollmrouter.Handler {
+		return func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+			return &provider.QueryResult{Content: "from middleware"}, nil
+		}
+	})
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "from middleware" {
+		t.Errorf("Expected the middleware's result, got %q", result.Content)
+	}
+	if mock.callCount() != 0 {
+		t.Errorf("Expected no provider dispatch, provider called %d times", mock.callCount())
+	}
+}
+
+func TestSystemPromptMiddleware(t *testing.T) {
+	var prompts []string
+	mock := &mockProvider{name: "only", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		prompts = append(prompts, options.SystemPrompt)
+		return &provider.QueryResult{Content: "ok"}, nil
+	}}
+	router, err := gollmrouter.NewRouter(mock)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	router.Use(gollmrouter.SystemPromptMiddleware("Be concise."))
+
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{SystemPrompt: "Answer in French."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(prompts) != 2 || prompts[0] != "Be concise." || prompts[1] != "Answer in French." {
+		t.Errorf("Expected the default prompt only when none is set, got %q", prompts)
+	}
+}
@@ -0,0 +1,92 @@
+package gollmrouter
+
+import (
+	"context"
+	"sort"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// WithModelAlias wraps a provider so the application can use its own logical model names. aliases
+// maps each logical name to the provider's model id, e.g. {"gpt-4o": "openai/gpt-4o"} for
+// OpenRouter or a deployment name for Azure.
+//
+// A ForceModel that names an alias is sent as the provider's id, and a result's Model is reported
+// by its logical name, as are the ids returned by Models. Names without an alias pass through
+// unchanged. When several logical names map to the same id, results use the first in sorted order.
+//
+// The wrapper's optional interfaces, such as Embedder or HealthChecker, are those of the provider.
+func WithModelAlias(p provider.Provider, aliases map[string]string) provider.Provider {
+	logicalNames := make([]string, 0, len(aliases))
+	for logical := range aliases {
+		logicalNames = append(logicalNames, logical)
+	}
+	sort.Strings(logicalNames)
+
+	toProvider := make(map[string]string, len(aliases))
+	toLogical := make(map[string]string, len(aliases))
+	for _, logical := range logicalNames {
+		id := aliases[logical]
+		toProvider[logical] = id
+		if _, taken := toLogical[id]; !taken {
+			toLogical[id] = logical
+		}
+	}
+
+	return &modelAliasProvider{providerWrapper: providerWrapper{p}, toProvider: toProvider, toLogical: toLogical}
+}
+
+// modelAliasProvider translates model names between the caller's logical names and the ids of
+// the provider it wraps
+type modelAliasProvider struct {
+	providerWrapper
+	toProvider map[string]string // Logical name to provider id
+	toLogical  map[string]string // Provider id to logical name
+}
+
+// Query sends the query with forceModel translated to the provider's id and returns the model
+// by its logical name
+func (m *modelAliasProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
+	content, model, err := m.Provider.Query(ctx, messages, temperature, m.providerModel(forceModel))
+	return content, m.logicalModel(model), err
+}
+
+// QueryWithOptions sends the query with ForceModel translated to the provider's id and reports
+// the result's models by their logical names
+func (m *modelAliasProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	options.ForceModel = m.providerModel(options.ForceModel)
+	result, err := m.Provider.QueryWithOptions(ctx, messages, options)
+	if result != nil {
+		result.Model = m.logicalModel(result.Model)
+		for i := range result.ModelAttempts {
+			result.ModelAttempts[i].Model = m.logicalModel(result.ModelAttempts[i].Model)
+		}
+	}
+	return result, err
+}
+
+// Models returns the provider's models by their logical names
+func (m *modelAliasProvider) Models() []string {
+	ids := m.Provider.Models()
+	models := make([]string, len(ids))
+	for i, id := range ids {
+		models[i] = m.logicalModel(id)
+	}
+	return models
+}
+
+// providerModel returns the provider's id for a logical model name
+func (m *modelAliasProvider) providerModel(model string) string {
+	if id, ok := m.toProvider[model]; ok {
+		return id
+	}
+	return model
+}
+
+// logicalModel returns the logical name of a provider model id
+func (m *modelAliasProvider) logicalModel(model string) string {
+	if logical, ok := m.toLogical[model]; ok {
+		return logical
+	}
+	return model
+}
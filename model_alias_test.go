@@ -0,0 +1,71 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestWithModelAlias(t *testing.T) {
+	var forced []string
+	openRouter := &mockProvider{name: "openrouter", rank: 1, weight: 3, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		forced = append(forced, options.ForceModel)
+		model := options.ForceModel
+		if model == "" {
+			model = "openrouter-model"
+		}
+		return &provider.QueryResult{Content: "ok", Model: model}, nil
+	}}
+	aliased := gollmrouter.WithModelAlias(openRouter, map[string]string{
+		"gpt-4o": "openai/gpt-4o",
+		"fast":   "openrouter-model",
+	})
+
+	router, err := gollmrouter.NewRouter(aliased)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+
+	// Logical names are sent as the provider's ids and reported back by their logical names
+	result, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ForceModel: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if forced[0] != "openai/gpt-4o" {
+		t.Errorf("Expected the provider to receive its model id, got %q", forced[0])
+	}
+	if result.Model != "gpt-4o" {
+		t.Errorf("Expected the logical model name in the result, got %q", result.Model)
+	}
+
+	// Names without an alias pass through unchanged
+	result, err = router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{ForceModel: "anthropic/claude-3.5-sonnet"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if forced[1] != "anthropic/claude-3.5-sonnet" || result.Model != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("Expected an unaliased model to pass through, sent %q and got %q", forced[1], result.Model)
+	}
+
+	// The provider's default model is reported by its alias, through the legacy Query too
+	_, model, err := aliased.Query(context.Background(), messages, 0.7, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if forced[2] != "" || model != "fast" {
+		t.Errorf("Expected the default model by its logical name, sent %q and got %q", forced[2], model)
+	}
+
+	if models := aliased.Models(); len(models) != 1 || models[0] != "fast" {
+		t.Errorf("Expected the configured models by their logical names, got %v", models)
+	}
+	if weighted, ok := aliased.(provider.Weighted); !ok || weighted.GetWeight() != 3 {
+		t.Errorf("Expected the wrapper to keep the provider's weight")
+	}
+	if aliased.Name() != "openrouter" || aliased.GetRank() != 1 {
+		t.Errorf("Expected the wrapper to keep the provider's name and rank, got %s/%d", aliased.Name(), aliased.GetRank())
+	}
+}
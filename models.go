@@ -0,0 +1,58 @@
+package gollmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// ModelRefresher is implemented by providers that can list the models their API serves
+type ModelRefresher = provider.ModelRefresher
+
+// AvailableModels returns the models of each provider by name, in fallback order.
+// Providers sharing a name are told apart by their position in the router.
+func (r *Router) AvailableModels() map[string][]string {
+	providers := r.getProviders()
+	names := uniqueProviderNames(providers)
+
+	models := make(map[string][]string, len(providers))
+	for i, p := range providers {
+		models[names[i]] = p.Models()
+	}
+	return models
+}
+
+// RefreshModels concurrently asks every provider that implements ModelRefresher for the
+// models its API serves, so that AvailableModels reports them. Queries still use each
+// provider's configured models. The returned error joins the failures of all providers.
+func (r *Router) RefreshModels(ctx context.Context) error {
+	providers := r.getProviders()
+	names := uniqueProviderNames(providers)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+
+	for i, p := range providers {
+		refresher, ok := p.(provider.ModelRefresher)
+		if !ok || !implements[provider.ModelRefresher](p) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, refresher provider.ModelRefresher) {
+			defer wg.Done()
+			if err := refresher.RefreshModels(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				mu.Unlock()
+			}
+		}(names[i], refresher)
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+func TestRouter_AvailableModels(t *testing.T) {
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		URL:    "http://localhost/v1/chat/completions",
+		Models: []string{"gpt-4o-mini", "gpt-4o"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(fc, &mockProvider{name: "mock"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	models := router.AvailableModels()
+	if !reflect.DeepEqual(models["FunctionCalling"], []string{"gpt-4o-mini", "gpt-4o"}) {
+		t.Errorf("Expected the configured models, got %v", models["FunctionCalling"])
+	}
+	if !reflect.DeepEqual(models["mock"], []string{"mock-model"}) {
+		t.Errorf("Expected the mock provider's models, got %v", models["mock"])
+	}
+
+	// The returned list is a copy
+	models["FunctionCalling"][0] = "changed"
+	if fc.Models()[0] != "gpt-4o-mini" {
+		t.Error("Expected modifying the result not to change the provider's models")
+	}
+}
+
+func TestRouter_RefreshModels(t *testing.T) {
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o","object":"model"},{"id":"gpt-4o-mini","object":"model"},{"id":"o3-mini","object":"model"}]}`))
+	}))
+	defer server.Close()
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		URL:    server.URL + "/v1/chat/completions",
+		Models: []string{"gpt-4o-mini"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(fc, &mockProvider{name: "mock"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if err := router.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/v1/models" || authorization != "Bearer test-key" {
+		t.Errorf("Expected an authorized request to /v1/models, got %s with %q", path, authorization)
+	}
+
+	models := router.AvailableModels()
+	if !reflect.DeepEqual(models["FunctionCalling"], []string{"gpt-4o", "gpt-4o-mini", "o3-mini"}) {
+		t.Errorf("Expected the listed models, got %v", models["FunctionCalling"])
+	}
+	if !reflect.DeepEqual(models["mock"], []string{"mock-model"}) {
+		t.Errorf("Expected providers without a models endpoint to keep their models, got %v", models["mock"])
+	}
+}
+
+func TestRouter_RefreshModelsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid api key"}}`))
+	}))
+	defer server.Close()
+
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "bad-key",
+		URL:    server.URL + "/v1/chat/completions",
+		Models: []string{"gpt-4o-mini"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(fc)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if err := router.RefreshModels(context.Background()); err == nil {
+		t.Fatal("Expected an error for a rejected models request")
+	}
+	if models := router.AvailableModels()["FunctionCalling"]; !reflect.DeepEqual(models, []string{"gpt-4o-mini"}) {
+		t.Errorf("Expected the configured models after a failed refresh, got %v", models)
+	}
+}
@@ -0,0 +1,17 @@
+package provider
+
+import "context"
+
+// AWSCredentials are the keys used to sign requests to AWS
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Set for temporary credentials
+}
+
+// AWSCredentialsProvider supplies the credentials for each request to AWS. Retrieve is called
+// before every request, so it can return refreshed temporary credentials; it should cache them
+// until they are about to expire. A credentials provider from the AWS SDK can be adapted to it.
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
@@ -0,0 +1,72 @@
+package provider
+
+// TrimToContextWindow drops the oldest messages until the estimated token count fits in maxTokens,
+// using DefaultTokenEstimator. See TrimToContextWindowWithEstimator.
+func TrimToContextWindow(messages []Message, maxTokens int, keepSystem bool) []Message {
+	return TrimToContextWindowWithEstimator(DefaultTokenEstimator, messages, maxTokens, keepSystem)
+}
+
+// TrimToContextWindowWithEstimator drops the oldest messages until the estimated token count fits in maxTokens.
+// The most recent user message is always kept, as are system messages when keepSystem is true.
+// An assistant message with tool calls is dropped together with the tool messages answering it,
+// since APIs reject tool results whose call isn't in the history.
+// If the kept messages alone exceed maxTokens they are returned as is.
+// A maxTokens of 0 disables trimming. The input slice is not modified.
+func TrimToContextWindowWithEstimator(estimator TokenEstimator, messages []Message, maxTokens int, keepSystem bool) []Message {
+	if estimator == nil {
+		estimator = DefaultTokenEstimator
+	}
+	if maxTokens <= 0 || estimator.EstimateTokens(messages) <= maxTokens {
+		return messages
+	}
+
+	lastUser := -1
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			lastUser = i
+			break
+		}
+	}
+
+	keep := make([]bool, len(messages))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	trimmed := messages
+	for i, message := range messages {
+		if !keep[i] || i == lastUser || (keepSystem && message.Role == "system") {
+			continue
+		}
+
+		keep[i] = false
+		dropToolResults(messages[i+1:], keep[i+1:], message.ToolCalls)
+		trimmed = make([]Message, 0, len(messages))
+		for j, kept := range keep {
+			if kept {
+				trimmed = append(trimmed, messages[j])
+			}
+		}
+		if estimator.EstimateTokens(trimmed) <= maxTokens {
+			break
+		}
+	}
+
+	return trimmed
+}
+
+// dropToolResults marks the tool messages answering toolCalls as dropped
+func dropToolResults(messages []Message, keep []bool, toolCalls []ToolCall) {
+	if len(toolCalls) == 0 {
+		return
+	}
+	ids := make(map[string]bool, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		ids[toolCall.ID] = true
+	}
+	for i, message := range messages {
+		if message.Role == "tool" && ids[message.ToolCallID] {
+			keep[i] = false
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package provider
+
+// RawExchange is a raw HTTP request and response captured by a provider in debug mode
+type RawExchange struct {
+	Method         string
+	URL            string
+	RequestHeaders map[string]string // Credentials are redacted
+	RequestBody    string
+	StatusCode     int
+	ResponseBody   string
+	Error          string // Set when no response was received
+}
+
+// DebugRecorder is implemented by providers that can capture raw exchanges in debug mode.
+// LastRawExchange returns nil when debug mode is off or no request has been sent yet.
+type DebugRecorder interface {
+	LastRawExchange() *RawExchange
+}
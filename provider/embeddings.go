@@ -0,0 +1,24 @@
+package provider
+
+import "context"
+
+// EmbeddingRequest asks for vector embeddings of one or more texts
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResult holds one vector per input text, in the order of the request
+type EmbeddingResult struct {
+	Vectors [][]float32 `json:"vectors"`
+	Model   string      `json:"model"`
+	Usage   Usage       `json:"usage"`
+}
+
+// Embedder is implemented by providers that can create embeddings
+type Embedder interface {
+	// SupportsEmbeddings reports whether the provider is configured to create embeddings
+	SupportsEmbeddings() bool
+	// Embeddings creates vector embeddings for the request's input texts
+	Embeddings(ctx context.Context, request EmbeddingRequest) (*EmbeddingResult, error)
+}
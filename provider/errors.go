@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrProviderClosed is returned by requests made after a provider's Close was called
+var ErrProviderClosed = errors.New("provider is closed")
+
+// ErrToolsNotSupported is returned when a query has tools and the provider or model can't call them
+var ErrToolsNotSupported = errors.New("provider does not support tool calls")
+
+// ErrResponseTooLarge is returned when a response body is larger than the provider's MaxResponseBytes
+var ErrResponseTooLarge = errors.New("response exceeded max size")
+
+// ErrProviderBusy is returned when a provider already has MaxConcurrent requests in flight and
+// FailWhenBusy is set
+var ErrProviderBusy = errors.New("provider has too many requests in flight")
+
+// ErrRateLimitExceeded is returned for a provider or model that was skipped because it has used
+// up its daily or per-minute requests
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+// ErrTokenBudgetExceeded is returned for a provider or model whose remaining tokens per minute
+// can't fit the request's estimated tokens
+var ErrTokenBudgetExceeded = errors.New("request exceeds token budget")
+
+// ErrTemperatureOutOfRange is returned when a query's temperature is outside the range the
+// provider's API accepts and the provider doesn't clamp it
+var ErrTemperatureOutOfRange = errors.New("temperature out of range")
+
+// ErrMissingAPIKey is returned by a provider constructor when the config has no API key and the
+// API requires one
+var ErrMissingAPIKey = errors.New("API key is required")
+
+// ToolExecutionError is returned when a tool call fails and the provider's ToolFailurePolicy is
+// ToolFailureAbortQuery
+type ToolExecutionError struct {
+	ToolName   string
+	ToolCallID string
+	Err        error
+}
+
+// Error returns the tool's name and error
+func (e *ToolExecutionError) Error() string {
+	return fmt.Sprintf("tool %s failed: %v", e.ToolName, e.Err)
+}
+
+// Unwrap returns the tool's error
+func (e *ToolExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// APIError is returned by HTTP providers when the API responds with an error status.
+// Retryable reports whether another attempt with the same provider could succeed; whether the
+// router tries other providers is decided by IsTerminalStatus.
+type APIError struct {
+	StatusCode int
+	Retryable  bool
+	Body       string
+
+	// Message, Type and Code are taken from a JSON error body, e.g.
+	// {"error": {"message": ..., "type": ..., "code": ...}}. They are empty when the body isn't
+	// JSON, such as an HTML error page from a proxy.
+	Message string
+	Type    string
+	Code    string
+}
+
+// NewAPIError creates an APIError, classifying rate limits, timeouts and server errors as retryable
+func NewAPIError(statusCode int, body string) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Retryable:  IsRetryableStatus(statusCode),
+		Body:       body,
+	}
+	apiErr.Message, apiErr.Type, apiErr.Code = parseErrorBody(body)
+	return apiErr
+}
+
+// Error returns the status code and the error message from the body, or the raw body when it
+// has none
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+	}
+	if e.Type != "" {
+		return fmt.Sprintf("API request failed with status %d: %s (%s)", e.StatusCode, e.Message, e.Type)
+	}
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// errorBody is the error format of OpenAI-compatible APIs. Mistral and Bedrock put the fields at
+// the top level instead of under "error", and some gateways send "error" as a plain string.
+type errorBody struct {
+	Error json.RawMessage `json:"error"`
+	errorFields
+}
+
+type errorFields struct {
+	Message string          `json:"message"`
+	Type    string          `json:"type"`
+	Code    json.RawMessage `json:"code"` // A string for OpenAI, a number for OpenRouter
+}
+
+// parseErrorBody returns the message, type and code of a JSON error body, or empty strings
+// when the body isn't JSON or has no message
+func parseErrorBody(body string) (message, errType, code string) {
+	var parsed errorBody
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return "", "", ""
+	}
+
+	fields := parsed.errorFields
+	if len(parsed.Error) > 0 && string(parsed.Error) != "null" {
+		var nested errorFields
+		if json.Unmarshal(parsed.Error, &nested) == nil {
+			fields = nested
+		} else if json.Unmarshal(parsed.Error, &fields.Message) != nil {
+			return "", "", ""
+		}
+	}
+	if fields.Message == "" {
+		return "", "", ""
+	}
+
+	code = strings.Trim(string(fields.Code), `"`)
+	if code == "null" {
+		code = ""
+	}
+	return fields.Message, fields.Type, code
+}
+
+// ContentBlockedError is returned when a provider withheld its answer, e.g. because of a safety
+// filter. It is not an APIError, so the router falls back to the next provider.
+type ContentBlockedError struct {
+	Model  string
+	Reason string // The provider's block or finish reason, e.g. "SAFETY"
+	Detail string // Optional explanation, such as the blocked categories
+}
+
+// Error returns the model and the reason the content was blocked
+func (e *ContentBlockedError) Error() string {
+	msg := fmt.Sprintf("content blocked for model %s: %s", e.Model, e.Reason)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+// IsTerminalStatus reports whether a request that failed with the HTTP status code would likely
+// fail with any provider: a malformed request (400, 422) or one the caller isn't allowed to make
+// (401, 403). Other statuses, such as 404 for a model one provider lacks or 402 for an account out
+// of credits, may succeed with another provider.
+func IsTerminalStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryableStatus reports whether a request that failed with the HTTP status code may succeed if retried
+func IsRetryableStatus(statusCode int) bool {
+	switch {
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	case statusCode >= 500:
+		return true
+	default:
+		return statusCode < 400
+	}
+}
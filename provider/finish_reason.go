@@ -0,0 +1,56 @@
+package provider
+
+import "strings"
+
+// FinishReason is a finish reason normalized across providers
+type FinishReason string
+
+const (
+	// FinishStop means the model finished its answer or hit a stop sequence
+	FinishStop FinishReason = "stop"
+	// FinishLength means the answer was cut off by the output token limit
+	FinishLength FinishReason = "length"
+	// FinishToolCalls means the model stopped to have tools called
+	FinishToolCalls FinishReason = "tool_calls"
+	// FinishContentFilter means the answer was withheld or cut off by a safety or content filter
+	FinishContentFilter FinishReason = "content_filter"
+	// FinishOther covers every other reason, including an unknown or missing one
+	FinishOther FinishReason = "other"
+)
+
+// NormalizeFinishReason maps a provider's raw finish reason, such as OpenAI's "length", Gemini's
+// "MAX_TOKENS" or Bedrock's "max_tokens", to a FinishReason
+func NormalizeFinishReason(raw string) FinishReason {
+	switch strings.ToLower(raw) {
+	case "stop", "end_turn", "stop_sequence", "eos":
+		return FinishStop
+	case "length", "max_tokens", "model_context_window_exceeded":
+		return FinishLength
+	case "tool_calls", "function_call", "tool_use":
+		return FinishToolCalls
+	case "content_filter", "content_filtered", "guardrail_intervened",
+		"safety", "recitation", "blocklist", "prohibited_content", "spii", "image_safety":
+		return FinishContentFilter
+	default:
+		return FinishOther
+	}
+}
+
+// NormalizedFinishReason returns the result's FinishReason normalized across providers. Providers
+// such as Gemini report a plain stop along with tool calls, which is normalized to FinishToolCalls.
+func (r *QueryResult) NormalizedFinishReason() FinishReason {
+	return normalizeWithToolCalls(r.FinishReason, len(r.ToolCalls) > 0)
+}
+
+// NormalizedFinishReason returns the completion's FinishReason normalized across providers
+func (c *Completion) NormalizedFinishReason() FinishReason {
+	return normalizeWithToolCalls(c.FinishReason, len(c.ToolCalls) > 0)
+}
+
+func normalizeWithToolCalls(raw string, hasToolCalls bool) FinishReason {
+	reason := NormalizeFinishReason(raw)
+	if reason == FinishStop && hasToolCalls {
+		return FinishToolCalls
+	}
+	return reason
+}
@@ -0,0 +1,26 @@
+package provider
+
+import "context"
+
+// ImageRequest asks for one or more images generated from a prompt
+type ImageRequest struct {
+	Prompt string `json:"prompt"`
+	Model  string `json:"model,omitempty"` // Optional, defaults to the provider's image model
+	Size   string `json:"size,omitempty"`  // e.g. "1024x1024"; providers without pixel sizes use its aspect ratio
+	N      int    `json:"n,omitempty"`     // Number of images, default 1
+}
+
+// ImageResult holds the generated images
+type ImageResult struct {
+	Images []File `json:"images"`
+	Model  string `json:"model"`
+	Usage  *Usage `json:"usage,omitempty"`
+}
+
+// ImageGenerator is implemented by providers that can generate images
+type ImageGenerator interface {
+	// SupportsImageGeneration reports whether the provider is configured to generate images
+	SupportsImageGeneration() bool
+	// GenerateImage generates images for the request's prompt
+	GenerateImage(ctx context.Context, request ImageRequest) (*ImageResult, error)
+}
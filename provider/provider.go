@@ -2,6 +2,8 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
 	"time"
 
 	"github.com/FramnkRulez/go-llm-router/internal/httpclient"
@@ -9,10 +11,11 @@ import (
 
 // File represents a file attachment with metadata and data
 type File struct {
-	Type     string `json:"type"`      // "image", "document", etc.
-	Data     []byte `json:"data"`      // file data (base64 encoded for JSON)
-	MimeType string `json:"mime_type"` // MIME type
-	Name     string `json:"name"`      // filename
+	Type     string `json:"type"`          // "image", "document", etc.
+	Data     []byte `json:"data"`          // file data (base64 encoded for JSON)
+	MimeType string `json:"mime_type"`     // MIME type
+	Name     string `json:"name"`          // filename
+	URL      string `json:"url,omitempty"` // remote location, used instead of Data when set
 }
 
 // Message represents a chat message with role, content, and optional file attachments
@@ -20,6 +23,20 @@ type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 	Files   []File `json:"files,omitempty"`
+
+	// Name identifies the participant that wrote the message, e.g. an agent in a multi-agent chat.
+	// It is sent as "name" to OpenAI-compatible APIs; Gemini and Bedrock have no equivalent and
+	// ignore it.
+	Name string `json:"name,omitempty"`
+	// ToolCalls are the tool calls requested by an assistant message in earlier history
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID links a "tool" message carrying a tool's result to the call it answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Cacheable asks providers that support prompt caching to cache the conversation up to and
+	// including this message, e.g. a long system prompt reused across requests. OpenRouter sends
+	// it as an Anthropic-style cache_control marker and Bedrock as a cache point; other
+	// providers ignore it.
+	Cacheable bool `json:"cacheable,omitempty"`
 }
 
 // ToolCall represents a tool call request from the LLM
@@ -57,18 +74,186 @@ type ToolFunction struct {
 
 // QueryOptions holds options for LLM queries including tool calls
 type QueryOptions struct {
-	Temperature float64 `json:"temperature"`
-	ForceModel  string  `json:"force_model,omitempty"`
-	Tools       []Tool  `json:"tools,omitempty"`
-	ToolChoice  string  `json:"tool_choice,omitempty"` // "auto", "none", or specific tool name
+	Temperature       float64       `json:"temperature"`
+	TemperatureSet    bool          `json:"temperature_set,omitempty"` // Sends Temperature even when it is 0; a zero Temperature is otherwise treated as unset
+	ForceModel        string        `json:"force_model,omitempty"`
+	ForceProvider     string        `json:"force_provider,omitempty"` // Only the provider with this Name() is tried, without fallback
+	Tools             []Tool        `json:"tools,omitempty"`
+	ToolChoice        string        `json:"tool_choice,omitempty"`         // "auto", "none", "required", or the name of a tool to force
+	ParallelToolCalls *bool         `json:"parallel_tool_calls,omitempty"` // false asks OpenAI-compatible APIs for at most one tool call per response and runs tools one at a time; nil leaves the API's default
+	SystemPrompt      string        `json:"system_prompt,omitempty"`       // Sent ahead of the messages as the system instruction
+	N                 int           `json:"n,omitempty"`                   // Number of completions to generate; values above 1 fill QueryResult.Completions
+	Seed              *int          `json:"seed,omitempty"`                // Requests deterministic sampling where the provider supports it; nil leaves it unset
+	LogProbs          bool          `json:"logprobs,omitempty"`            // Returns the log probability of each generated token in QueryResult.LogProbs
+	TopLogProbs       int           `json:"top_logprobs,omitempty"`        // Number of most likely alternatives to return per token with LogProbs
+	PerRequestTimeout time.Duration `json:"per_request_timeout,omitempty"` // Bounds this call only, overriding the provider's configured Timeout
+	ThinkingBudget    *int          `json:"thinking_budget,omitempty"`     // Tokens a thinking model may spend reasoning (Gemini 2.5, OpenRouter); 0 disables thinking where allowed, nil leaves the model's default
+	ReasoningEffort   string        `json:"reasoning_effort,omitempty"`    // "low", "medium" or "high" for reasoning models (OpenAI o-series, OpenRouter); empty leaves the model's default
+	JSONMode          bool          `json:"json_mode,omitempty"`           // Asks for a JSON object response: response_format json_object for OpenAI-compatible APIs, a JSON MIME type for Gemini; Bedrock ignores it
+}
+
+// HasTemperature reports whether the options request a temperature. A zero Temperature counts
+// as unset unless TemperatureSet is true, so the provider's own default applies.
+func (o QueryOptions) HasTemperature() bool {
+	return o.TemperatureSet || o.Temperature != 0
 }
 
 // QueryResult represents the result of an LLM query
 type QueryResult struct {
 	Content      string     `json:"content"`
+	Reasoning    string     `json:"reasoning,omitempty"` // Reasoning trace, when the provider returns it separately from the answer
 	Model        string     `json:"model"`
 	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
-	FinishReason string     `json:"finish_reason,omitempty"`
+	FinishReason string     `json:"finish_reason,omitempty"` // Raw provider value; see NormalizedFinishReason
+	Usage        *Usage     `json:"usage,omitempty"`
+	// SystemFingerprint identifies the backend configuration that served the request (OpenAI).
+	// A change means results for the same Seed may no longer be reproducible.
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+	// LogProbs holds the log probability of each generated token when QueryOptions.LogProbs is
+	// set and the provider supports it
+	LogProbs []TokenLogProb `json:"logprobs,omitempty"`
+	// CostUSD is the estimated cost of the request, set by a router configured with pricing
+	CostUSD float64 `json:"cost_usd,omitempty"`
+	// Completions holds every choice when QueryOptions.N is greater than 1. The fields above
+	// describe the first one.
+	Completions []Completion `json:"completions,omitempty"`
+	// Raw is the provider's full response, for fields the library doesn't model (citations,
+	// annotations, vendor extensions). Only set when the provider's IncludeRawResponse is enabled.
+	Raw json.RawMessage `json:"raw,omitempty"`
+	// ModelAttempts lists the models the provider tried in order, ending with the one that
+	// served the request, so failovers from a primary model are visible
+	ModelAttempts []ModelAttempt `json:"model_attempts,omitempty"`
+}
+
+// ModelAttempt is one model a provider tried while serving a query
+type ModelAttempt struct {
+	Model    string        `json:"model"`
+	Error    string        `json:"error,omitempty"` // Why the model failed; empty for the model that succeeded
+	Duration time.Duration `json:"duration"`
+}
+
+// Completion is one of several choices generated for a request
+type Completion struct {
+	Content      string         `json:"content"`
+	Reasoning    string         `json:"reasoning,omitempty"`
+	ToolCalls    []ToolCall     `json:"tool_calls,omitempty"`
+	FinishReason string         `json:"finish_reason,omitempty"`
+	LogProbs     []TokenLogProb `json:"logprobs,omitempty"`
+}
+
+// TokenLogProb is the log probability of a generated token, with the most likely alternatives
+// the model considered in its place
+type TokenLogProb struct {
+	Token           string             `json:"token"`
+	LogProb         float64            `json:"logprob"`
+	TopAlternatives []TokenAlternative `json:"top_alternatives,omitempty"`
+}
+
+// TokenAlternative is a token the model could have generated instead, with its log probability
+type TokenAlternative struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+}
+
+// Usage reports the tokens consumed by a request, as returned by the provider's API
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// AddUsage sums the token usage of two requests, either of which may be unknown (nil)
+func AddUsage(a, b *Usage) *Usage {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+}
+
+// Clone returns a deep copy of the result, so that either can be modified without affecting
+// the other
+func (r *QueryResult) Clone() *QueryResult {
+	if r == nil {
+		return nil
+	}
+
+	clone := *r
+	clone.ToolCalls = cloneToolCalls(r.ToolCalls)
+	if r.Usage != nil {
+		usage := *r.Usage
+		clone.Usage = &usage
+	}
+	clone.LogProbs = cloneLogProbs(r.LogProbs)
+	if r.Completions != nil {
+		clone.Completions = make([]Completion, len(r.Completions))
+		for i, completion := range r.Completions {
+			completion.ToolCalls = cloneToolCalls(completion.ToolCalls)
+			completion.LogProbs = cloneLogProbs(completion.LogProbs)
+			clone.Completions[i] = completion
+		}
+	}
+	clone.Raw = slices.Clone(r.Raw)
+	clone.ModelAttempts = slices.Clone(r.ModelAttempts)
+	return &clone
+}
+
+// cloneToolCalls copies tool calls along with their arguments
+func cloneToolCalls(calls []ToolCall) []ToolCall {
+	if calls == nil {
+		return nil
+	}
+	clone := make([]ToolCall, len(calls))
+	for i, call := range calls {
+		call.Function.Arguments, _ = cloneJSONValue(call.Function.Arguments).(map[string]interface{})
+		clone[i] = call
+	}
+	return clone
+}
+
+// cloneLogProbs copies log probabilities along with their alternatives
+func cloneLogProbs(logProbs []TokenLogProb) []TokenLogProb {
+	if logProbs == nil {
+		return nil
+	}
+	clone := make([]TokenLogProb, len(logProbs))
+	for i, logProb := range logProbs {
+		logProb.TopAlternatives = slices.Clone(logProb.TopAlternatives)
+		clone[i] = logProb
+	}
+	return clone
+}
+
+// cloneJSONValue copies the maps and slices of a decoded JSON value
+func cloneJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		clone := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			clone[key] = cloneJSONValue(item)
+		}
+		return clone
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneJSONValue(item)
+		}
+		return clone
+	default:
+		return value
+	}
 }
 
 // Provider interface for LLM providers
@@ -83,12 +268,104 @@ type Provider interface {
 	HasRemainingRequestsPerMinute(ctx context.Context) bool
 	HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool
 	GetRank() int
+
+	// ResetLimits clears the daily, per-minute and token counters and starts new windows. It is
+	// meant for operational overrides, such as after upgrading an API plan mid-day.
+	ResetLimits()
+
+	// Close releases the provider's resources. The built-in providers cancel in-flight requests,
+	// save their rate-limit counters to the RateStore and return ErrProviderClosed from later
+	// requests.
 	Close()
 
+	// Models returns the models the provider can serve, in fallback order
+	Models() []string
+
 	// Name returns the name of the provider for error reporting
 	Name() string
 }
 
+// Weighted is implemented by providers that have a load-balancing weight.
+// Providers that don't implement it have a weight of 1.
+type Weighted interface {
+	GetWeight() int
+}
+
+// TimeoutAware is implemented by providers that have a per-request timeout.
+// The router bounds each attempt by the shorter of this timeout and the caller's deadline.
+type TimeoutAware interface {
+	GetTimeout() time.Duration
+}
+
+// ToolCapable is implemented by providers that know whether they can handle tool calls. The
+// router skips providers whose SupportsTools returns false for queries with tools; providers
+// that don't implement it are assumed to support them.
+type ToolCapable interface {
+	SupportsTools() bool
+}
+
+// HealthChecker is implemented by providers that can verify their credentials and connectivity
+// with a cheap request. HealthCheck returns nil when the provider is usable.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Warmer is implemented by providers that can open their API connection ahead of the first
+// request, so it doesn't pay for DNS lookups and TLS handshakes. Warmup doesn't count against
+// the rate limits.
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// ModelRefresher is implemented by providers that can list the models their API serves.
+// After RefreshModels succeeds, Models returns the listed models instead of the configured ones.
+type ModelRefresher interface {
+	RefreshModels(ctx context.Context) error
+}
+
+// ModelStrategy controls which of a provider's models is tried first on each request. The
+// remaining models are still tried in declared order as fallbacks.
+type ModelStrategy int
+
+const (
+	// ModelStrategyInOrder always tries the first model first (default)
+	ModelStrategyInOrder ModelStrategy = iota
+	// ModelStrategyRoundRobin rotates which model is tried first on each request
+	ModelStrategyRoundRobin
+	// ModelStrategyRandom tries a randomly chosen model first
+	ModelStrategyRandom
+)
+
+// RetryConfig configures retrying transient errors, by the router (RouterRetryConfig) or within
+// a provider
+type RetryConfig struct {
+	MaxAttempts int           // Attempts per request or provider, including the first; 0 or 1 disables retries
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each further retry (default 200ms)
+	MaxDelay    time.Duration // Upper bound for the delay (default 5s)
+}
+
+// ToolFailurePolicy controls what a function calling provider does when one of the model's tool
+// calls fails
+type ToolFailurePolicy int
+
+const (
+	// ToolFailureReturnToModel sends the error to the model as the tool's result, so it can retry
+	// the call or answer without it (default)
+	ToolFailureReturnToModel ToolFailurePolicy = iota
+	// ToolFailureContinue leaves the failed call and its result out of the conversation and
+	// continues with the others
+	ToolFailureContinue
+	// ToolFailureAbortQuery fails the query with a ToolExecutionError
+	ToolFailureAbortQuery
+)
+
+// Limits are the rate limits of a single model. A zero field takes the provider-wide value.
+type Limits struct {
+	MaxDailyRequests     int
+	MaxRequestsPerMinute int
+	MaxTokensPerMinute   int
+}
+
 // Config holds common configuration for providers
 type Config struct {
 	APIKey               string
@@ -97,6 +374,50 @@ type Config struct {
 	MaxRequestsPerMinute int
 	MaxTokensPerMinute   int
 	Rank                 int // Higher rank = higher priority (0 is lowest)
+	Weight               int // Relative share of traffic among providers with the same rank (weighted strategy)
 	Timeout              time.Duration
 	HTTPClient           httpclient.Client
+	TokenEstimator       TokenEstimator // Defaults to DefaultTokenEstimator when nil
+	MaxContextTokens     int            // Oldest messages are trimmed to fit before sending; 0 disables trimming
+	MaxResponseBytes     int64          // Larger response bodies fail with ErrResponseTooLarge (default 32 MB; HTTP providers only)
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses and keeps the last exchange
+	ModelStrategy        ModelStrategy  // How load is spread across Models; QueryOptions.ForceModel overrides it
+	DisableTools         bool           // Makes SupportsTools report false so the router skips the provider for queries with tools
+	Retry                RetryConfig    // Retries transient errors within the provider (Gemini only)
+
+	// RequestModifier is called with each chat request body of OpenAI-compatible providers just
+	// before it is marshaled, after the library has set its own fields
+	RequestModifier func(body map[string]interface{})
+
+	// AutoResizeImages downscales attached images larger than MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG before sending
+	AutoResizeImages  bool
+	MaxImageDimension int // Default 2048
+	MaxImageBytes     int // Default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the provider's response body, or the marshaled
+	// SDK response for Gemini
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once; 0 means no limit.
+	// Further requests wait for a free slot, or fail with ErrProviderBusy when FailWhenBusy is set.
+	MaxConcurrent int
+	FailWhenBusy  bool
+
+	// RoleMap renames message roles before they are sent, e.g. {"system": "developer"}. Roles
+	// without an entry are sent unchanged. Gemini applies it on top of its own role conversion.
+	RoleMap map[string]string
+
+	// QueryParams are added to the query string of every request URL of OpenAI-compatible
+	// providers, replacing parameters of the same name in the configured URL
+	QueryParams map[string]string
+
+	// ClampTemperature clamps a temperature outside the API's range to the nearest bound instead
+	// of failing the query with ErrTemperatureOutOfRange
+	ClampTemperature bool
+
+	// ModelLimits gives models their own rate limits, counted separately from the provider-wide
+	// limits, which still apply to models without an entry
+	ModelLimits map[string]Limits
 }
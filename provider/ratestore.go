@@ -0,0 +1,32 @@
+package provider
+
+import "time"
+
+// RateCounters is a snapshot of a provider's rate-limit counters and the windows they belong to
+type RateCounters struct {
+	RequestsToday      int       `json:"requests_today"`
+	RequestsThisMinute int       `json:"requests_this_minute"`
+	TokensThisMinute   int       `json:"tokens_this_minute"`
+	DayStart           time.Time `json:"day_start"`
+	MinuteStart        time.Time `json:"minute_start"`
+}
+
+// RateStore persists rate-limit counters so quotas survive restarts.
+// Keys identify a provider instance and stay stable across restarts.
+// Implementations must be safe for concurrent use.
+type RateStore interface {
+	// Load returns the saved counters for the key, or zero counters if none were saved
+	Load(providerKey string) (RateCounters, error)
+	// Save stores the counters for the key
+	Save(providerKey string, counters RateCounters) error
+}
+
+// SharedRateStore is a RateStore whose counters are shared by several processes, such as replicas
+// using the same API key. Rate limiters add their usage with Increment instead of saving snapshots,
+// and load the combined counters before checking a limit.
+type SharedRateStore interface {
+	RateStore
+	// Increment atomically adds requests and tokens to the current day and minute windows
+	// and returns the combined counters
+	Increment(providerKey string, requests, tokens int) (RateCounters, error)
+}
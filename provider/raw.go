@@ -0,0 +1,14 @@
+package provider
+
+import "context"
+
+// RawRequester is implemented by HTTP providers that can send requests to endpoints of their API
+// that the library doesn't model, such as moderations or rerank
+type RawRequester interface {
+	// SupportsRawRequests reports whether the provider knows its API's base URL
+	SupportsRawRequests() bool
+	// DoRaw sends body to path, resolved against the API's base URL, with the provider's
+	// credentials and returns the response body. A nil body is sent as a GET; any other body is
+	// marshaled to JSON and POSTed.
+	DoRaw(ctx context.Context, path string, body interface{}) ([]byte, error)
+}
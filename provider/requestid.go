@@ -0,0 +1,17 @@
+package provider
+
+import "context"
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a context that carries a caller-chosen request id, e.g. a trace
+// or correlation id, so it can be read by middleware, providers and tool executors
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request id carried by ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
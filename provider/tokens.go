@@ -0,0 +1,60 @@
+package provider
+
+// TokenEstimator estimates the number of tokens a set of messages will consume.
+// Providers use it for their per-minute token accounting and the router uses the
+// same estimator to decide whether a provider can accept a request.
+type TokenEstimator interface {
+	EstimateTokens(messages []Message) int
+}
+
+// DefaultTokenEstimator is used when no estimator is configured
+var DefaultTokenEstimator TokenEstimator = HeuristicEstimator{}
+
+// HeuristicEstimator approximates token counts at ~4 characters per token,
+// which is a reasonable average for English text
+type HeuristicEstimator struct{}
+
+// EstimateTokens returns the approximate number of tokens in the messages
+func (HeuristicEstimator) EstimateTokens(messages []Message) int {
+	totalChars := 0
+	for _, msg := range messages {
+		totalChars += len(msg.Content)
+		for _, file := range msg.Files {
+			totalChars += len(file.Data)
+		}
+	}
+	return totalChars / 4
+}
+
+// EncoderEstimator counts tokens with a real tokenizer.
+// Encode should return the token ids for a piece of text, which makes it easy to plug in
+// a BPE tokenizer such as tiktoken-go:
+//
+//	enc, _ := tiktoken.GetEncoding("cl100k_base")
+//	estimator := provider.NewEncoderEstimator(func(text string) []int {
+//		return enc.Encode(text, nil, nil)
+//	})
+//
+// File attachments are not text, so they are still estimated with the heuristic.
+type EncoderEstimator struct {
+	Encode func(text string) []int
+}
+
+// NewEncoderEstimator creates a token estimator backed by the given encode function
+func NewEncoderEstimator(encode func(text string) []int) *EncoderEstimator {
+	return &EncoderEstimator{Encode: encode}
+}
+
+// EstimateTokens returns the number of tokens the encoder produces for the messages
+func (e *EncoderEstimator) EstimateTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		if msg.Content != "" {
+			total += len(e.Encode(msg.Content))
+		}
+		for _, file := range msg.Files {
+			total += len(file.Data) / 4
+		}
+	}
+	return total
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolCallDelta is a fragment of a tool call from a streamed chat completion chunk
+// (choices[].delta.tool_calls[] in the OpenAI format). The id, type and name usually arrive
+// in the first fragment and the JSON arguments are spread over the following ones.
+type ToolCallDelta struct {
+	Index    int                   `json:"index"`
+	ID       string                `json:"id,omitempty"`
+	Type     string                `json:"type,omitempty"`
+	Function ToolCallFunctionDelta `json:"function"`
+}
+
+// ToolCallFunctionDelta is the function part of a ToolCallDelta
+type ToolCallFunctionDelta struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// ToolCallAccumulator reassembles tool calls from streamed deltas.
+// Fragments are matched to a call by id when they carry one and by index otherwise, so it
+// handles APIs that reuse index 0 for every call as well as APIs that only send the id once.
+// It is not safe for concurrent use.
+type ToolCallAccumulator struct {
+	calls   []*pendingToolCall
+	byIndex map[int]*pendingToolCall
+	byID    map[string]*pendingToolCall
+}
+
+// pendingToolCall is a tool call whose fragments are still arriving
+type pendingToolCall struct {
+	id        string
+	callType  string
+	name      string
+	arguments []byte
+	emitted   bool
+}
+
+// NewToolCallAccumulator creates an empty accumulator
+func NewToolCallAccumulator() *ToolCallAccumulator {
+	return &ToolCallAccumulator{
+		byIndex: make(map[int]*pendingToolCall),
+		byID:    make(map[string]*pendingToolCall),
+	}
+}
+
+// Add ingests a delta. It returns the tool call and true the first time the call's arguments
+// form a complete JSON object, so the call can be executed before the stream ends.
+func (a *ToolCallAccumulator) Add(delta ToolCallDelta) (ToolCall, bool) {
+	call := a.pending(delta)
+	if delta.Type != "" {
+		call.callType = delta.Type
+	}
+	call.name += delta.Function.Name
+	call.arguments = append(call.arguments, delta.Function.Arguments...)
+
+	if call.emitted || call.name == "" || len(call.arguments) == 0 || !json.Valid(call.arguments) {
+		return ToolCall{}, false
+	}
+
+	toolCall, err := call.toolCall()
+	if err != nil {
+		return ToolCall{}, false
+	}
+	call.emitted = true
+	return toolCall, true
+}
+
+// ToolCalls returns all accumulated tool calls in the order they started.
+// It should be called once the stream has ended; calls without arguments get an empty argument map.
+// An error is returned if any call's arguments are not a complete JSON object.
+func (a *ToolCallAccumulator) ToolCalls() ([]ToolCall, error) {
+	toolCalls := make([]ToolCall, 0, len(a.calls))
+	for _, call := range a.calls {
+		toolCall, err := call.toolCall()
+		if err != nil {
+			return nil, err
+		}
+		toolCalls = append(toolCalls, toolCall)
+	}
+	return toolCalls, nil
+}
+
+// pending returns the call a delta belongs to, starting a new one when needed
+func (a *ToolCallAccumulator) pending(delta ToolCallDelta) *pendingToolCall {
+	if delta.ID != "" {
+		if call, ok := a.byID[delta.ID]; ok {
+			return call
+		}
+		// The id may arrive after the first fragment at this index
+		if call, ok := a.byIndex[delta.Index]; ok && call.id == "" {
+			call.id = delta.ID
+			a.byID[delta.ID] = call
+			return call
+		}
+	} else if call, ok := a.byIndex[delta.Index]; ok {
+		return call
+	}
+
+	call := &pendingToolCall{id: delta.ID, callType: "function"}
+	a.calls = append(a.calls, call)
+	a.byIndex[delta.Index] = call
+	if delta.ID != "" {
+		a.byID[delta.ID] = call
+	}
+	return call
+}
+
+// toolCall decodes the accumulated arguments into a ToolCall
+func (c *pendingToolCall) toolCall() (ToolCall, error) {
+	arguments := map[string]interface{}{}
+	if len(c.arguments) > 0 {
+		if err := json.Unmarshal(c.arguments, &arguments); err != nil {
+			return ToolCall{}, fmt.Errorf("incomplete arguments for tool call %q (%s): %w", c.id, c.name, err)
+		}
+	}
+
+	return ToolCall{
+		ID:   c.id,
+		Type: c.callType,
+		Function: ToolCallFunction{
+			Name:      c.name,
+			Arguments: arguments,
+		},
+	}, nil
+}
@@ -0,0 +1,67 @@
+package provider
+
+import "context"
+
+// Tracer creates spans. Its shape mirrors OpenTelemetry's trace.Tracer so an
+// OpenTelemetry tracer can be adapted with a few lines of code.
+type Tracer interface {
+	// Start creates a span that is a child of the span in ctx, if any,
+	// and returns a context containing the new span
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation
+type Span interface {
+	// SetAttributes attaches key/value pairs to the span
+	SetAttributes(attributes ...Attribute)
+	// RecordError marks the span as failed with err
+	RecordError(err error)
+	// End completes the span
+	End()
+}
+
+// Attribute is a key/value pair attached to a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates a span attribute
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// NoopTracer is a Tracer that records nothing. It is used when no tracer is configured.
+type NoopTracer struct{}
+
+// Start returns ctx unchanged and a span that does nothing
+func (NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attributes ...Attribute) {}
+func (noopSpan) RecordError(err error)                 {}
+func (noopSpan) End()                                  {}
+
+type tracerContextKey struct{}
+
+// ContextWithTracer returns a context that carries the tracer, so providers can trace
+// work done on behalf of a request (such as tool executions)
+func ContextWithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerContextKey{}, tracer)
+}
+
+// TracerFromContext returns the tracer carried by ctx, or a NoopTracer
+func TracerFromContext(ctx context.Context) Tracer {
+	if tracer, ok := ctx.Value(tracerContextKey{}).(Tracer); ok && tracer != nil {
+		return tracer
+	}
+	return NoopTracer{}
+}
+
+// StartSpan starts a span using the tracer carried by ctx
+func StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	return TracerFromContext(ctx).Start(ctx, name)
+}
@@ -0,0 +1,24 @@
+package provider
+
+import "context"
+
+// TranscriptionRequest asks for the text spoken in an audio file
+type TranscriptionRequest struct {
+	Audio    File   `json:"audio"`
+	Model    string `json:"model,omitempty"`    // Optional, defaults to the provider's transcription model
+	Language string `json:"language,omitempty"` // Optional ISO-639-1 code of the spoken language, e.g. "en"
+}
+
+// TranscriptionResult holds the transcribed text
+type TranscriptionResult struct {
+	Text  string `json:"text"`
+	Model string `json:"model"`
+}
+
+// Transcriber is implemented by providers that can transcribe audio
+type Transcriber interface {
+	// SupportsTranscription reports whether the provider is configured to transcribe audio
+	SupportsTranscription() bool
+	// Transcribe converts the request's audio to text
+	Transcribe(ctx context.Context, request TranscriptionRequest) (*TranscriptionResult, error)
+}
@@ -1,10 +1,14 @@
 package gollmrouter
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -31,6 +35,12 @@ type ToolCall = provider.ToolCall
 // ToolCallFunction represents the function details in a tool call
 type ToolCallFunction = provider.ToolCallFunction
 
+// ToolCallDelta is a fragment of a tool call from a streamed response
+type ToolCallDelta = provider.ToolCallDelta
+
+// ToolCallAccumulator reassembles tool calls from streamed deltas
+type ToolCallAccumulator = provider.ToolCallAccumulator
+
 // ToolCallResult represents the result of executing a tool call
 type ToolCallResult = provider.ToolCallResult
 
@@ -46,9 +56,115 @@ type QueryOptions = provider.QueryOptions
 // QueryResult represents the result of an LLM query
 type QueryResult = provider.QueryResult
 
+// Completion is one of several choices generated when QueryOptions.N is greater than 1
+type Completion = provider.Completion
+
+// ToolCapable is implemented by providers that report whether they can handle tool calls
+type ToolCapable = provider.ToolCapable
+
+// TokenLogProb is the log probability of a generated token, returned with QueryOptions.LogProbs
+type TokenLogProb = provider.TokenLogProb
+
+// TokenAlternative is a likely alternative to a generated token
+type TokenAlternative = provider.TokenAlternative
+
+// FinishReason is a finish reason normalized across providers; see QueryResult.NormalizedFinishReason
+type FinishReason = provider.FinishReason
+
+// Normalized finish reasons
+const (
+	FinishStop          = provider.FinishStop
+	FinishLength        = provider.FinishLength
+	FinishToolCalls     = provider.FinishToolCalls
+	FinishContentFilter = provider.FinishContentFilter
+	FinishOther         = provider.FinishOther
+)
+
+// Usage reports the tokens consumed by a request
+type Usage = provider.Usage
+
+// APIError is returned by HTTP providers when the API responds with an error status
+type APIError = provider.APIError
+
+// ErrProviderClosed is returned by requests made after a provider's Close was called
+var ErrProviderClosed = provider.ErrProviderClosed
+
+// ErrResponseTooLarge is returned when a response body is larger than the provider's MaxResponseBytes
+var ErrResponseTooLarge = provider.ErrResponseTooLarge
+
+// ErrProviderBusy is returned when a provider with FailWhenBusy already has MaxConcurrent requests in flight
+var ErrProviderBusy = provider.ErrProviderBusy
+
+// ErrTemperatureOutOfRange is returned when a query's temperature is outside the range the
+// provider accepts and ClampTemperature isn't set
+var ErrTemperatureOutOfRange = provider.ErrTemperatureOutOfRange
+
+// ErrMissingAPIKey is returned by a provider constructor when the config has no API key and the
+// API requires one
+var ErrMissingAPIKey = provider.ErrMissingAPIKey
+
+// ContentBlockedError is returned when a provider withheld its answer, e.g. for safety
+type ContentBlockedError = provider.ContentBlockedError
+
+// Tracer creates spans for router queries, provider attempts and tool executions
+type Tracer = provider.Tracer
+
+// Span is a single traced operation
+type Span = provider.Span
+
+// Attribute is a key/value pair attached to a span
+type Attribute = provider.Attribute
+
 // ToolExecutor interface for executing tool calls
 type ToolExecutor = providers.ToolExecutor
 
+// TokenEstimator estimates the number of tokens a set of messages will consume
+type TokenEstimator = provider.TokenEstimator
+
+// HeuristicEstimator approximates token counts at ~4 characters per token
+type HeuristicEstimator = provider.HeuristicEstimator
+
+// EncoderEstimator counts tokens with a real tokenizer such as tiktoken-go
+type EncoderEstimator = provider.EncoderEstimator
+
+// RawExchange is a raw HTTP request and response captured by a provider in debug mode
+type RawExchange = provider.RawExchange
+
+// DebugRecorder is implemented by providers that keep their last raw exchange in debug mode
+type DebugRecorder = provider.DebugRecorder
+
+// AWSCredentials are the keys used to sign requests to AWS
+type AWSCredentials = provider.AWSCredentials
+
+// AWSCredentialsProvider supplies the credentials for each request to Bedrock
+type AWSCredentialsProvider = provider.AWSCredentialsProvider
+
+// ModelStrategy controls which of a provider's models is tried first on each request
+type ModelStrategy = provider.ModelStrategy
+
+// Model strategies; the remaining models are still tried in order as fallbacks
+const (
+	ModelStrategyInOrder    = provider.ModelStrategyInOrder
+	ModelStrategyRoundRobin = provider.ModelStrategyRoundRobin
+	ModelStrategyRandom     = provider.ModelStrategyRandom
+)
+
+// ToolFailurePolicy controls what a function calling provider does when a tool call fails
+type ToolFailurePolicy = provider.ToolFailurePolicy
+
+// Tool failure policies
+const (
+	ToolFailureReturnToModel = provider.ToolFailureReturnToModel
+	ToolFailureContinue      = provider.ToolFailureContinue
+	ToolFailureAbortQuery    = provider.ToolFailureAbortQuery
+)
+
+// ToolExecutionError is returned when a tool call fails under ToolFailureAbortQuery
+type ToolExecutionError = provider.ToolExecutionError
+
+// Limits are the rate limits of a single model, see ModelLimits on the provider configs
+type Limits = provider.Limits
+
 // GeminiConfig holds configuration for creating a Gemini provider
 type GeminiConfig struct {
 	APIKey               string
@@ -56,7 +172,53 @@ type GeminiConfig struct {
 	MaxDailyReqs         int
 	MaxRequestsPerMinute int
 	MaxTokensPerMinute   int
+	ModelLimits          map[string]Limits // Optional, per-model limits counted separately; unset fields and unlisted models use the limits above
 	Rank                 int
+	Weight               int            // Share of traffic among same-rank providers with StrategyWeighted
+	TokenEstimator       TokenEstimator // Optional, defaults to ~4 characters per token
+	MaxContextTokens     int            // Optional, trims the oldest messages to fit before sending
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses (credentials redacted); see LastRawExchange
+	ModelStrategy        ModelStrategy  // Optional, spreads requests across Models (default ModelStrategyInOrder)
+	Timeout              time.Duration  // Optional limit for each Gemini request; zero means no additional timeout
+	DisableTools         bool           // Optional, marks the provider as unable to handle tools so the router skips it for queries with tools
+
+	// Retry retries transient errors (rate limits, timeouts, 5xx) of each model with backoff
+	// before trying the next model. Authentication and permission errors are never retried.
+	// Router retries (WithRetry) multiply with these: 3 attempts each can make 9 calls.
+	Retry RouterRetryConfig
+
+	// UseVertex authenticates through Vertex AI with Google application default credentials
+	// instead of APIKey; Project and Location are required with it
+	UseVertex bool
+	Project   string
+	Location  string
+
+	// AutoResizeImages downscales attached images that exceed MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG, for APIs that reject large images
+	AutoResizeImages  bool
+	MaxImageDimension int // Optional, default 2048
+	MaxImageBytes     int // Optional, default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the full response, for provider-specific fields
+	// the library doesn't model. Off by default to avoid keeping a copy of every response.
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once, for APIs that throttle
+	// concurrent requests; 0 means no limit. Further requests wait for a free slot until their
+	// context is done or, with FailWhenBusy, fail at once with ErrProviderBusy so the router
+	// falls back to the next provider.
+	MaxConcurrent int
+	FailWhenBusy  bool
+
+	// RoleMap overrides how message roles are converted to Gemini's "user" and "model" roles,
+	// e.g. {"tool": "model"}. By default assistant messages are sent as "model" and all others as
+	// "user"; system messages are always sent as the system instruction.
+	RoleMap map[string]string
+	// ClampTemperature clamps a temperature outside the API's accepted range (0-1 for the Gemini 1.0 models, 0-2 for later ones) to the
+	// nearest bound. By default such queries fail with ErrTemperatureOutOfRange before a request
+	// is sent.
+	ClampTemperature bool
 }
 
 // OpenRouterConfig holds configuration for creating an OpenRouter provider
@@ -66,10 +228,60 @@ type OpenRouterConfig struct {
 	MaxDailyReqs         int
 	MaxRequestsPerMinute int
 	MaxTokensPerMinute   int
+	ModelLimits          map[string]Limits // Optional, per-model limits counted separately; unset fields and unlisted models use the limits above
 	Rank                 int
+	Weight               int // Share of traffic among same-rank providers with StrategyWeighted
 	Referer              string
 	XTitle               string
 	Timeout              time.Duration
+	TokenEstimator       TokenEstimator // Optional, defaults to ~4 characters per token
+	MaxContextTokens     int            // Optional, trims the oldest messages to fit before sending
+	MaxResponseBytes     int64          // Optional, larger response bodies fail with ErrResponseTooLarge (default 32 MB)
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses (credentials redacted); see LastRawExchange
+	ModelStrategy        ModelStrategy  // Optional, spreads requests across Models (default ModelStrategyInOrder)
+	UserAgent            string         // Optional, overrides the default "go-llm-router/1.0" User-Agent header
+	DisableTools         bool           // Optional, marks the provider as unable to handle tools so the router skips it for queries with tools
+
+	// ProviderPreferences is sent as OpenRouter's "provider" object, e.g. {"order": ["Together"],
+	// "allow_fallbacks": false}. FallbackModels is sent as "models" so OpenRouter itself falls
+	// back to them. Both are left out of requests when empty.
+	ProviderPreferences map[string]interface{}
+	FallbackModels      []string
+
+	// RequestModifier, when set, is called with each chat request body just before it is sent,
+	// after the library has set its own fields, so it can add or override any field
+	RequestModifier func(body map[string]interface{})
+
+	// AutoResizeImages downscales attached images that exceed MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG, for APIs that reject large images
+	AutoResizeImages  bool
+	MaxImageDimension int // Optional, default 2048
+	MaxImageBytes     int // Optional, default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the full response, for provider-specific fields
+	// the library doesn't model. Off by default to avoid keeping a copy of every response.
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once, for APIs that throttle
+	// concurrent requests; 0 means no limit. Further requests wait for a free slot until their
+	// context is done or, with FailWhenBusy, fail at once with ErrProviderBusy so the router
+	// falls back to the next provider.
+	MaxConcurrent int
+	FailWhenBusy  bool
+
+	// RoleMap renames message roles before they are sent, e.g. {"system": "developer"} for OpenAI
+	// reasoning models that expect developer messages. Roles without an entry are sent unchanged.
+	RoleMap map[string]string
+
+	// QueryParams are added to every request URL, e.g. {"api-version": "2024-10-21"} for Azure
+	// OpenAI or a gateway's routing key. They are merged with any query in the configured URL,
+	// replacing parameters of the same name.
+	QueryParams map[string]string
+	// ClampTemperature clamps a temperature outside the API's accepted range (0-2) to the
+	// nearest bound. By default such queries fail with ErrTemperatureOutOfRange before a request
+	// is sent.
+	ClampTemperature bool
 }
 
 // FunctionCallingConfig holds configuration for creating a function calling provider
@@ -80,58 +292,499 @@ type FunctionCallingConfig struct {
 	MaxDailyReqs         int
 	MaxRequestsPerMinute int
 	MaxTokensPerMinute   int
+	ModelLimits          map[string]Limits // Optional, per-model limits counted separately; unset fields and unlisted models use the limits above
+	Rank                 int
+	Weight               int // Share of traffic among same-rank providers with StrategyWeighted
+	Timeout              time.Duration
+	ToolExecutor         ToolExecutor
+	MaxConcurrentTools   int            // Tool calls from one response run in parallel, up to this many at once (default 4)
+	ToolTimeout          time.Duration  // Optional limit for each tool execution; timed-out tools count as failed
+	TokenEstimator       TokenEstimator // Optional, defaults to ~4 characters per token
+	MaxContextTokens     int            // Optional, trims the oldest messages to fit before sending
+	MaxResponseBytes     int64          // Optional, larger response bodies fail with ErrResponseTooLarge (default 32 MB)
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses (credentials redacted); see LastRawExchange
+	ModelStrategy        ModelStrategy  // Optional, spreads requests across Models (default ModelStrategyInOrder)
+	UserAgent            string         // Optional, overrides the default "go-llm-router/1.0" User-Agent header
+	DisableTools         bool           // Optional, marks the provider as unable to handle tools so the router skips it for queries with tools
+
+	// ToolFailurePolicy decides what happens when a tool call fails: the error is sent to the model
+	// as the tool's result (default), the result is left out, or the query fails
+	ToolFailurePolicy ToolFailurePolicy
+
+	// RequestModifier, when set, is called with each chat request body just before it is sent,
+	// after the library has set its own fields, so it can add or override any field
+	RequestModifier func(body map[string]interface{})
+
+	// AutoResizeImages downscales attached images that exceed MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG, for APIs that reject large images
+	AutoResizeImages  bool
+	MaxImageDimension int // Optional, default 2048
+	MaxImageBytes     int // Optional, default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the full response, for provider-specific fields
+	// the library doesn't model. Off by default to avoid keeping a copy of every response.
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once, for APIs that throttle
+	// concurrent requests; 0 means no limit. Further requests wait for a free slot until their
+	// context is done or, with FailWhenBusy, fail at once with ErrProviderBusy so the router
+	// falls back to the next provider.
+	MaxConcurrent int
+	FailWhenBusy  bool
+
+	// RoleMap renames message roles before they are sent, e.g. {"system": "developer"} for OpenAI
+	// reasoning models that expect developer messages. Roles without an entry are sent unchanged.
+	RoleMap map[string]string
+
+	// QueryParams are added to every request URL, e.g. {"api-version": "2024-10-21"} for Azure
+	// OpenAI or a gateway's routing key. They are merged with any query in the configured URL,
+	// replacing parameters of the same name.
+	QueryParams map[string]string
+	// ClampTemperature clamps a temperature outside the API's accepted range (0-2) to the
+	// nearest bound. By default such queries fail with ErrTemperatureOutOfRange before a request
+	// is sent.
+	ClampTemperature bool
+}
+
+// OpenAIConfig holds configuration for creating an OpenAI provider
+type OpenAIConfig struct {
+	APIKey               string
+	Models               []string
+	OrgID                string // Optional, sent as the OpenAI-Organization header
+	BaseURL              string // Optional, defaults to https://api.openai.com/v1 (e.g. for an OpenAI-compatible proxy)
+	MaxDailyReqs         int
+	MaxRequestsPerMinute int
+	MaxTokensPerMinute   int
+	ModelLimits          map[string]Limits // Optional, per-model limits counted separately; unset fields and unlisted models use the limits above
+	Rank                 int
+	Weight               int // Share of traffic among same-rank providers with StrategyWeighted
+	Timeout              time.Duration
+	ToolExecutor         ToolExecutor
+	MaxConcurrentTools   int            // Tool calls from one response run in parallel, up to this many at once (default 4)
+	ToolTimeout          time.Duration  // Optional limit for each tool execution; timed-out tools count as failed
+	TokenEstimator       TokenEstimator // Optional, defaults to ~4 characters per token
+	MaxContextTokens     int            // Optional, trims the oldest messages to fit before sending
+	MaxResponseBytes     int64          // Optional, larger response bodies fail with ErrResponseTooLarge (default 32 MB)
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses (credentials redacted); see LastRawExchange
+	ModelStrategy        ModelStrategy  // Optional, spreads requests across Models (default ModelStrategyInOrder)
+	UserAgent            string         // Optional, overrides the default "go-llm-router/1.0" User-Agent header
+	DisableTools         bool           // Optional, marks the provider as unable to handle tools so the router skips it for queries with tools
+
+	// ToolFailurePolicy decides what happens when a tool call fails: the error is sent to the model
+	// as the tool's result (default), the result is left out, or the query fails
+	ToolFailurePolicy ToolFailurePolicy
+
+	// RequestModifier, when set, is called with each chat request body just before it is sent,
+	// after the library has set its own fields, so it can add or override any field
+	RequestModifier func(body map[string]interface{})
+
+	// AutoResizeImages downscales attached images that exceed MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG, for APIs that reject large images
+	AutoResizeImages  bool
+	MaxImageDimension int // Optional, default 2048
+	MaxImageBytes     int // Optional, default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the full response, for provider-specific fields
+	// the library doesn't model. Off by default to avoid keeping a copy of every response.
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once, for APIs that throttle
+	// concurrent requests; 0 means no limit. Further requests wait for a free slot until their
+	// context is done or, with FailWhenBusy, fail at once with ErrProviderBusy so the router
+	// falls back to the next provider.
+	MaxConcurrent int
+	FailWhenBusy  bool
+
+	// RoleMap renames message roles before they are sent, e.g. {"system": "developer"} for OpenAI
+	// reasoning models that expect developer messages. Roles without an entry are sent unchanged.
+	RoleMap map[string]string
+
+	// QueryParams are added to every request URL, e.g. {"api-version": "2024-10-21"} for Azure
+	// OpenAI or a gateway's routing key. They are merged with any query in the configured URL,
+	// replacing parameters of the same name.
+	QueryParams map[string]string
+	// ClampTemperature clamps a temperature outside the API's accepted range (0-2) to the
+	// nearest bound. By default such queries fail with ErrTemperatureOutOfRange before a request
+	// is sent.
+	ClampTemperature bool
+}
+
+// MistralConfig holds configuration for creating a Mistral provider
+type MistralConfig struct {
+	APIKey               string
+	Models               []string
+	BaseURL              string // Optional, defaults to https://api.mistral.ai/v1
+	SafePrompt           bool   // Optional, asks Mistral to prepend its safety prompt (sent as safe_prompt)
+	MaxDailyReqs         int
+	MaxRequestsPerMinute int
+	MaxTokensPerMinute   int
+	ModelLimits          map[string]Limits // Optional, per-model limits counted separately; unset fields and unlisted models use the limits above
 	Rank                 int
+	Weight               int // Share of traffic among same-rank providers with StrategyWeighted
 	Timeout              time.Duration
 	ToolExecutor         ToolExecutor
+	MaxConcurrentTools   int            // Tool calls from one response run in parallel, up to this many at once (default 4)
+	ToolTimeout          time.Duration  // Optional limit for each tool execution; timed-out tools count as failed
+	TokenEstimator       TokenEstimator // Optional, defaults to ~4 characters per token
+	MaxContextTokens     int            // Optional, trims the oldest messages to fit before sending
+	MaxResponseBytes     int64          // Optional, larger response bodies fail with ErrResponseTooLarge (default 32 MB)
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses (credentials redacted); see LastRawExchange
+	ModelStrategy        ModelStrategy  // Optional, spreads requests across Models (default ModelStrategyInOrder)
+	UserAgent            string         // Optional, overrides the default "go-llm-router/1.0" User-Agent header
+	DisableTools         bool           // Optional, marks the provider as unable to handle tools so the router skips it for queries with tools
+
+	// ToolFailurePolicy decides what happens when a tool call fails: the error is sent to the model
+	// as the tool's result (default), the result is left out, or the query fails
+	ToolFailurePolicy ToolFailurePolicy
+
+	// RequestModifier, when set, is called with each chat request body just before it is sent,
+	// after the library has set its own fields, so it can add or override any field
+	RequestModifier func(body map[string]interface{})
+
+	// AutoResizeImages downscales attached images that exceed MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG, for APIs that reject large images
+	AutoResizeImages  bool
+	MaxImageDimension int // Optional, default 2048
+	MaxImageBytes     int // Optional, default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the full response, for provider-specific fields
+	// the library doesn't model. Off by default to avoid keeping a copy of every response.
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once, for APIs that throttle
+	// concurrent requests; 0 means no limit. Further requests wait for a free slot until their
+	// context is done or, with FailWhenBusy, fail at once with ErrProviderBusy so the router
+	// falls back to the next provider.
+	MaxConcurrent int
+	FailWhenBusy  bool
+
+	// RoleMap renames message roles before they are sent, e.g. {"system": "developer"} for OpenAI
+	// reasoning models that expect developer messages. Roles without an entry are sent unchanged.
+	RoleMap map[string]string
+
+	// QueryParams are added to every request URL, e.g. {"api-version": "2024-10-21"} for Azure
+	// OpenAI or a gateway's routing key. They are merged with any query in the configured URL,
+	// replacing parameters of the same name.
+	QueryParams map[string]string
+	// ClampTemperature clamps a temperature outside the API's accepted range (0-2) to the
+	// nearest bound. By default such queries fail with ErrTemperatureOutOfRange before a request
+	// is sent.
+	ClampTemperature bool
+}
+
+// BedrockConfig holds configuration for creating an AWS Bedrock provider.
+// Credentials default to the AWS environment variables, then the shared credentials file, which
+// are read again before every request. Instance and container roles, web identity, SSO and
+// credential_process need a CredentialsProvider, such as one backed by the AWS SDK.
+type BedrockConfig struct {
+	Region               string   // Defaults to AWS_REGION or AWS_DEFAULT_REGION
+	ModelIDs             []string // Bedrock model or inference profile ids, tried in order
+	AccessKeyID          string   // Optional explicit credentials
+	SecretAccessKey      string
+	SessionToken         string
+	Profile              string                 // Optional profile in the shared credentials file (default AWS_PROFILE or "default")
+	CredentialsProvider  AWSCredentialsProvider // Optional, supplies the credentials for each request instead of the fields above
+	Endpoint             string                 // Optional, overrides https://bedrock-runtime.<region>.amazonaws.com
+	MaxDailyReqs         int
+	MaxRequestsPerMinute int
+	MaxTokensPerMinute   int
+	ModelLimits          map[string]Limits // Optional, per-model limits counted separately; unset fields and unlisted models use the limits above
+	Rank                 int
+	Weight               int // Share of traffic among same-rank providers with StrategyWeighted
+	Timeout              time.Duration
+	TokenEstimator       TokenEstimator // Optional, defaults to ~4 characters per token
+	MaxContextTokens     int            // Optional, trims the oldest messages to fit before sending
+	MaxResponseBytes     int64          // Optional, larger response bodies fail with ErrResponseTooLarge (default 32 MB)
+	RateStore            RateStore      // Optional, persists rate-limit counters across restarts
+	Debug                bool           // Logs raw requests and responses (credentials redacted); see LastRawExchange
+	ModelStrategy        ModelStrategy  // Optional, spreads requests across Models (default ModelStrategyInOrder)
+	UserAgent            string         // Optional, overrides the default "go-llm-router/1.0" User-Agent header
+	DisableTools         bool           // Optional, marks the provider as unable to handle tools so the router skips it for queries with tools
+
+	// AutoResizeImages downscales attached images that exceed MaxImageDimension pixels on either
+	// side or MaxImageBytes bytes and re-encodes them as JPEG, for APIs that reject large images
+	AutoResizeImages  bool
+	MaxImageDimension int // Optional, default 2048
+	MaxImageBytes     int // Optional, default 5 MB
+
+	// IncludeRawResponse sets QueryResult.Raw to the full response, for provider-specific fields
+	// the library doesn't model. Off by default to avoid keeping a copy of every response.
+	IncludeRawResponse bool
+
+	// MaxConcurrent limits the requests in flight to the provider at once, for APIs that throttle
+	// concurrent requests; 0 means no limit. Further requests wait for a free slot until their
+	// context is done or, with FailWhenBusy, fail at once with ErrProviderBusy so the router
+	// falls back to the next provider.
+	MaxConcurrent int
+	FailWhenBusy  bool
+	// ClampTemperature clamps a temperature outside the API's accepted range (0-1) to the
+	// nearest bound. By default such queries fail with ErrTemperatureOutOfRange before a request
+	// is sent.
+	ClampTemperature bool
+}
+
+// defaultUserAgent identifies the library when a config doesn't set its own UserAgent
+const defaultUserAgent = "go-llm-router/1.0"
+
+// userAgent returns the configured User-Agent, or the default when it is empty
+func userAgent(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	return defaultUserAgent
 }
 
 // NewGeminiProvider creates a new Gemini provider with the given configuration
 func NewGeminiProvider(config GeminiConfig) (provider.Provider, error) {
-	return providers.NewGeminiProvider(
-		config.APIKey,
-		config.Models,
-		config.MaxDailyReqs,
-		config.MaxRequestsPerMinute,
-		config.MaxTokensPerMinute,
-		config.Rank,
-	)
+	var vertex *providers.VertexConfig
+	if config.UseVertex {
+		vertex = &providers.VertexConfig{Project: config.Project, Location: config.Location}
+	}
+
+	return providers.NewGeminiProvider(provider.Config{
+		APIKey:               config.APIKey,
+		Models:               config.Models,
+		MaxDailyRequests:     config.MaxDailyReqs,
+		MaxRequestsPerMinute: config.MaxRequestsPerMinute,
+		MaxTokensPerMinute:   config.MaxTokensPerMinute,
+		ModelLimits:          config.ModelLimits,
+		Rank:                 config.Rank,
+		Weight:               config.Weight,
+		TokenEstimator:       config.TokenEstimator,
+		MaxContextTokens:     config.MaxContextTokens,
+		RateStore:            config.RateStore,
+		Debug:                config.Debug,
+		ModelStrategy:        config.ModelStrategy,
+		DisableTools:         config.DisableTools,
+		AutoResizeImages:     config.AutoResizeImages,
+		MaxImageDimension:    config.MaxImageDimension,
+		MaxImageBytes:        config.MaxImageBytes,
+		IncludeRawResponse:   config.IncludeRawResponse,
+		MaxConcurrent:        config.MaxConcurrent,
+		FailWhenBusy:         config.FailWhenBusy,
+		ClampTemperature:     config.ClampTemperature,
+		RoleMap:              config.RoleMap,
+		Timeout:              config.Timeout,
+		Retry:                config.Retry,
+	}, vertex)
 }
 
 // NewOpenRouterProvider creates a new OpenRouter provider with the given configuration
 func NewOpenRouterProvider(config OpenRouterConfig) (provider.Provider, error) {
-	httpClient := httpclient.New("go-llm-router/1.0")
-
-	return providers.NewOpenRouterProvider(
-		config.APIKey,
-		OpenRouterAPIEndpoint,
-		config.Timeout,
-		config.Models,
-		config.Referer,
-		config.XTitle,
-		httpClient,
-		config.MaxDailyReqs,
-		config.MaxRequestsPerMinute,
-		config.MaxTokensPerMinute,
-		config.Rank,
-	)
+	httpClient := httpclient.New(userAgent(config.UserAgent))
+
+	return providers.NewOpenRouterProvider(provider.Config{
+		APIKey:               config.APIKey,
+		Models:               config.Models,
+		MaxDailyRequests:     config.MaxDailyReqs,
+		MaxRequestsPerMinute: config.MaxRequestsPerMinute,
+		MaxTokensPerMinute:   config.MaxTokensPerMinute,
+		ModelLimits:          config.ModelLimits,
+		Rank:                 config.Rank,
+		Weight:               config.Weight,
+		Timeout:              config.Timeout,
+		HTTPClient:           httpClient,
+		TokenEstimator:       config.TokenEstimator,
+		MaxContextTokens:     config.MaxContextTokens,
+		MaxResponseBytes:     config.MaxResponseBytes,
+		RateStore:            config.RateStore,
+		Debug:                config.Debug,
+		ModelStrategy:        config.ModelStrategy,
+		DisableTools:         config.DisableTools,
+		AutoResizeImages:     config.AutoResizeImages,
+		MaxImageDimension:    config.MaxImageDimension,
+		MaxImageBytes:        config.MaxImageBytes,
+		IncludeRawResponse:   config.IncludeRawResponse,
+		MaxConcurrent:        config.MaxConcurrent,
+		FailWhenBusy:         config.FailWhenBusy,
+		ClampTemperature:     config.ClampTemperature,
+		RoleMap:              config.RoleMap,
+		QueryParams:          config.QueryParams,
+		RequestModifier:      config.RequestModifier,
+	}, OpenRouterAPIEndpoint, config.Referer, config.XTitle, &providers.OpenRouterRouting{
+		ProviderPreferences: config.ProviderPreferences,
+		FallbackModels:      config.FallbackModels,
+	})
 }
 
 // NewFunctionCallingProvider creates a new function calling provider with the given configuration
 func NewFunctionCallingProvider(config FunctionCallingConfig) (provider.Provider, error) {
-	httpClient := httpclient.New("go-llm-router/1.0")
-
-	return providers.NewFunctionCallingProvider(
-		config.APIKey,
-		config.URL,
-		config.Timeout,
-		config.Models,
-		httpClient,
-		config.MaxDailyReqs,
-		config.MaxRequestsPerMinute,
-		config.MaxTokensPerMinute,
-		config.Rank,
-		config.ToolExecutor,
-	)
+	httpClient := httpclient.New(userAgent(config.UserAgent))
+
+	return providers.NewFunctionCallingProvider(provider.Config{
+		APIKey:               config.APIKey,
+		Models:               config.Models,
+		MaxDailyRequests:     config.MaxDailyReqs,
+		MaxRequestsPerMinute: config.MaxRequestsPerMinute,
+		MaxTokensPerMinute:   config.MaxTokensPerMinute,
+		ModelLimits:          config.ModelLimits,
+		Rank:                 config.Rank,
+		Weight:               config.Weight,
+		Timeout:              config.Timeout,
+		HTTPClient:           httpClient,
+		TokenEstimator:       config.TokenEstimator,
+		MaxContextTokens:     config.MaxContextTokens,
+		MaxResponseBytes:     config.MaxResponseBytes,
+		RateStore:            config.RateStore,
+		Debug:                config.Debug,
+		ModelStrategy:        config.ModelStrategy,
+		DisableTools:         config.DisableTools,
+		AutoResizeImages:     config.AutoResizeImages,
+		MaxImageDimension:    config.MaxImageDimension,
+		MaxImageBytes:        config.MaxImageBytes,
+		IncludeRawResponse:   config.IncludeRawResponse,
+		MaxConcurrent:        config.MaxConcurrent,
+		FailWhenBusy:         config.FailWhenBusy,
+		ClampTemperature:     config.ClampTemperature,
+		RoleMap:              config.RoleMap,
+		QueryParams:          config.QueryParams,
+		RequestModifier:      config.RequestModifier,
+	}, config.URL, config.ToolExecutor, providers.ToolExecutionConfig{
+		MaxConcurrentTools: config.MaxConcurrentTools,
+		ToolTimeout:        config.ToolTimeout,
+		FailurePolicy:      config.ToolFailurePolicy,
+	})
+}
+
+// NewOpenAIProvider creates a provider for the OpenAI chat completions API. It works like a
+// function calling provider whose URL defaults to the OpenAI endpoint.
+func NewOpenAIProvider(config OpenAIConfig) (provider.Provider, error) {
+	httpClient := httpclient.New(userAgent(config.UserAgent))
+
+	return providers.NewOpenAIProvider(provider.Config{
+		APIKey:               config.APIKey,
+		Models:               config.Models,
+		MaxDailyRequests:     config.MaxDailyReqs,
+		MaxRequestsPerMinute: config.MaxRequestsPerMinute,
+		MaxTokensPerMinute:   config.MaxTokensPerMinute,
+		ModelLimits:          config.ModelLimits,
+		Rank:                 config.Rank,
+		Weight:               config.Weight,
+		Timeout:              config.Timeout,
+		HTTPClient:           httpClient,
+		TokenEstimator:       config.TokenEstimator,
+		MaxContextTokens:     config.MaxContextTokens,
+		MaxResponseBytes:     config.MaxResponseBytes,
+		RateStore:            config.RateStore,
+		Debug:                config.Debug,
+		ModelStrategy:        config.ModelStrategy,
+		DisableTools:         config.DisableTools,
+		AutoResizeImages:     config.AutoResizeImages,
+		MaxImageDimension:    config.MaxImageDimension,
+		MaxImageBytes:        config.MaxImageBytes,
+		IncludeRawResponse:   config.IncludeRawResponse,
+		MaxConcurrent:        config.MaxConcurrent,
+		FailWhenBusy:         config.FailWhenBusy,
+		ClampTemperature:     config.ClampTemperature,
+		RoleMap:              config.RoleMap,
+		QueryParams:          config.QueryParams,
+		RequestModifier:      config.RequestModifier,
+	}, config.BaseURL, config.OrgID, config.ToolExecutor, providers.ToolExecutionConfig{
+		MaxConcurrentTools: config.MaxConcurrentTools,
+		ToolTimeout:        config.ToolTimeout,
+		FailurePolicy:      config.ToolFailurePolicy,
+	})
+}
+
+// NewMistralProvider creates a provider for the Mistral chat completions API, with native tool
+// calling. Mistral-specific request fields such as safe_prompt are only sent when configured.
+func NewMistralProvider(config MistralConfig) (provider.Provider, error) {
+	httpClient := httpclient.New(userAgent(config.UserAgent))
+
+	return providers.NewMistralProvider(provider.Config{
+		APIKey:               config.APIKey,
+		Models:               config.Models,
+		MaxDailyRequests:     config.MaxDailyReqs,
+		MaxRequestsPerMinute: config.MaxRequestsPerMinute,
+		MaxTokensPerMinute:   config.MaxTokensPerMinute,
+		ModelLimits:          config.ModelLimits,
+		Rank:                 config.Rank,
+		Weight:               config.Weight,
+		Timeout:              config.Timeout,
+		HTTPClient:           httpClient,
+		TokenEstimator:       config.TokenEstimator,
+		MaxContextTokens:     config.MaxContextTokens,
+		MaxResponseBytes:     config.MaxResponseBytes,
+		RateStore:            config.RateStore,
+		Debug:                config.Debug,
+		ModelStrategy:        config.ModelStrategy,
+		DisableTools:         config.DisableTools,
+		AutoResizeImages:     config.AutoResizeImages,
+		MaxImageDimension:    config.MaxImageDimension,
+		MaxImageBytes:        config.MaxImageBytes,
+		IncludeRawResponse:   config.IncludeRawResponse,
+		MaxConcurrent:        config.MaxConcurrent,
+		FailWhenBusy:         config.FailWhenBusy,
+		ClampTemperature:     config.ClampTemperature,
+		RoleMap:              config.RoleMap,
+		QueryParams:          config.QueryParams,
+		RequestModifier:      config.RequestModifier,
+	}, config.BaseURL, providers.MistralOptions{
+		SafePrompt: config.SafePrompt,
+	}, config.ToolExecutor, providers.ToolExecutionConfig{
+		MaxConcurrentTools: config.MaxConcurrentTools,
+		ToolTimeout:        config.ToolTimeout,
+		FailurePolicy:      config.ToolFailurePolicy,
+	})
+}
+
+// NewBedrockProvider creates a new AWS Bedrock provider that uses the Converse API
+func NewBedrockProvider(config BedrockConfig) (provider.Provider, error) {
+	httpClient := httpclient.New(userAgent(config.UserAgent))
+
+	return providers.NewBedrockProvider(provider.Config{
+		Models:               config.ModelIDs,
+		MaxDailyRequests:     config.MaxDailyReqs,
+		MaxRequestsPerMinute: config.MaxRequestsPerMinute,
+		MaxTokensPerMinute:   config.MaxTokensPerMinute,
+		ModelLimits:          config.ModelLimits,
+		Rank:                 config.Rank,
+		Weight:               config.Weight,
+		Timeout:              config.Timeout,
+		HTTPClient:           httpClient,
+		TokenEstimator:       config.TokenEstimator,
+		MaxContextTokens:     config.MaxContextTokens,
+		MaxResponseBytes:     config.MaxResponseBytes,
+		RateStore:            config.RateStore,
+		Debug:                config.Debug,
+		ModelStrategy:        config.ModelStrategy,
+		DisableTools:         config.DisableTools,
+		AutoResizeImages:     config.AutoResizeImages,
+		MaxImageDimension:    config.MaxImageDimension,
+		MaxImageBytes:        config.MaxImageBytes,
+		IncludeRawResponse:   config.IncludeRawResponse,
+		MaxConcurrent:        config.MaxConcurrent,
+		FailWhenBusy:         config.FailWhenBusy,
+		ClampTemperature:     config.ClampTemperature,
+	}, config.Region, config.Endpoint, providers.AWSCredentials{
+		AccessKeyID:     config.AccessKeyID,
+		SecretAccessKey: config.SecretAccessKey,
+		SessionToken:    config.SessionToken,
+	}, config.Profile, config.CredentialsProvider)
+}
+
+// NewEncoderEstimator creates a token estimator backed by a tokenizer's encode function
+func NewEncoderEstimator(encode func(text string) []int) *EncoderEstimator {
+	return provider.NewEncoderEstimator(encode)
+}
+
+// TrimToContextWindow drops the oldest messages until the estimated token count fits in maxTokens.
+// The most recent user message is always kept, as are system messages when keepSystem is true.
+func TrimToContextWindow(messages []Message, maxTokens int, keepSystem bool) []Message {
+	return provider.TrimToContextWindow(messages, maxTokens, keepSystem)
+}
+
+// NormalizeFinishReason maps a provider's raw finish reason to a FinishReason
+func NormalizeFinishReason(raw string) FinishReason {
+	return provider.NormalizeFinishReason(raw)
+}
+
+// NewToolCallAccumulator creates an accumulator for tool call deltas from a streamed response
+func NewToolCallAccumulator() *ToolCallAccumulator {
+	return provider.NewToolCallAccumulator()
 }
 
 // NewTool creates a new tool definition
@@ -167,6 +820,18 @@ func NewToolCallResult(id string, content interface{}) *ToolCallResult {
 	}
 }
 
+// NewToolCallResultJSON creates a tool call result whose content is v encoded as a JSON string,
+// the form OpenAI-compatible APIs expect for structured results. Non-string content passed to
+// NewToolCallResult is encoded the same way when it is sent; this reports values that can't be
+// encoded up front instead.
+func NewToolCallResultJSON(id string, v interface{}) (*ToolCallResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	return NewToolCallResult(id, string(data)), nil
+}
+
 // NewFileAttachment creates a new file attachment from file data
 func NewFileAttachment(fileType, mimeType, name string, data []byte) FileAttachment {
 	return FileAttachment{
@@ -177,8 +842,41 @@ func NewFileAttachment(fileType, mimeType, name string, data []byte) FileAttachm
 	}
 }
 
-// NewFileAttachmentFromPath creates a file attachment from a file path
+// NewFileAttachmentFromURL creates a file attachment that refers to a remote file instead of holding its data.
+// Providers that accept URLs pass it through; providers that need the bytes download the file when the request is made.
+func NewFileAttachmentFromURL(fileURL, mimeType string) FileAttachment {
+	fileType := "document"
+	if strings.HasPrefix(mimeType, "image/") {
+		fileType = "image"
+	}
+
+	name := fileURL
+	if parsed, err := url.Parse(fileURL); err == nil {
+		name = path.Base(parsed.Path)
+	}
+
+	return FileAttachment{
+		Type:     fileType,
+		MimeType: mimeType,
+		Name:     name,
+		URL:      fileURL,
+	}
+}
+
+// DefaultMaxFileAttachmentSize is the largest file NewFileAttachmentFromPath will read (20MB)
+const DefaultMaxFileAttachmentSize int64 = 20 * 1024 * 1024
+
+// NewFileAttachmentFromPath creates a file attachment from a file path.
+// Files larger than DefaultMaxFileAttachmentSize are rejected.
 func NewFileAttachmentFromPath(filePath string) (FileAttachment, error) {
+	return NewFileAttachmentFromPathWithMaxSize(filePath, DefaultMaxFileAttachmentSize)
+}
+
+// NewFileAttachmentFromPathWithMaxSize creates a file attachment from a file path,
+// returning an error if the file is larger than maxSize bytes.
+// The MIME type comes from the file extension, or from the file's content when the
+// extension is missing or unknown.
+func NewFileAttachmentFromPathWithMaxSize(filePath string, maxSize int64) (FileAttachment, error) {
 	// Read file data
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -186,16 +884,29 @@ func NewFileAttachmentFromPath(filePath string) (FileAttachment, error) {
 	}
 	defer file.Close()
 
-	data, err := io.ReadAll(file)
+	info, err := file.Stat()
+	if err != nil {
+		return FileAttachment{}, err
+	}
+	if info.Size() > maxSize {
+		return FileAttachment{}, fmt.Errorf("file %s is %d bytes, which exceeds the maximum attachment size of %d bytes", filePath, info.Size(), maxSize)
+	}
+
+	// Limit the read in case the file grows after the size check
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
 	if err != nil {
 		return FileAttachment{}, err
 	}
+	if int64(len(data)) > maxSize {
+		return FileAttachment{}, fmt.Errorf("file %s exceeds the maximum attachment size of %d bytes", filePath, maxSize)
+	}
 
 	// Determine file type and MIME type
 	ext := strings.ToLower(filepath.Ext(filePath))
 	mimeType := mime.TypeByExtension(ext)
-	if mimeType == "" {
-		mimeType = "application/octet-stream"
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		// Sniff the content when the extension doesn't tell us anything
+		mimeType = http.DetectContentType(data)
 	}
 
 	// Determine file type based on MIME type
@@ -264,11 +975,16 @@ func ValidateGeminiMessage(message Message) error {
 	validRoles := map[string]bool{
 		"user":      true,
 		"assistant": true,
-		"system":    true, // Will be converted to "user" by Gemini
+		"system":    true, // Sent to Gemini as the system instruction
+		"tool":      true, // Sent to Gemini as a function response
 	}
 
 	if !validRoles[message.Role] {
-		return fmt.Errorf("invalid role for Gemini: %s (only 'user', 'assistant', and 'system' are supported)", message.Role)
+		return fmt.Errorf("invalid role for Gemini: %s (only 'user', 'assistant', 'system', and 'tool' are supported)", message.Role)
+	}
+
+	if message.Role == "tool" && message.ToolCallID == "" {
+		return fmt.Errorf("tool message for Gemini has no ToolCallID to match it to its function call")
 	}
 
 	return nil
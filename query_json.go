@@ -0,0 +1,67 @@
+package gollmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// ErrInvalidJSON is returned by QueryJSON when the response content can't be unmarshaled into
+// the target
+var ErrInvalidJSON = errors.New("response is not valid JSON")
+
+// maxJSONErrorContent is how much of an invalid response QueryJSON includes in its error
+const maxJSONErrorContent = 200
+
+// QueryJSON sends the query with JSONMode set and unmarshals the response content into out,
+// which must be a pointer as for json.Unmarshal. A markdown code fence around the JSON, which
+// models often add anyway, is stripped first. Content that still isn't valid JSON for out fails
+// with ErrInvalidJSON.
+//
+// OpenAI requires the word "JSON" to appear in the messages when JSON mode is on, so the prompt
+// should ask for JSON explicitly.
+func (r *Router) QueryJSON(ctx context.Context, messages []provider.Message, options provider.QueryOptions, out interface{}) error {
+	options.JSONMode = true
+	result, err := r.QueryWithOptions(ctx, messages, options)
+	if err != nil {
+		return err
+	}
+
+	content := stripCodeFence(result.Content)
+	if err := json.Unmarshal([]byte(content), out); err != nil {
+		if len(content) > maxJSONErrorContent {
+			content = content[:maxJSONErrorContent] + "..."
+		}
+		return fmt.Errorf("%w from model %s: %v: %q", ErrInvalidJSON, result.Model, err, content)
+	}
+	return nil
+}
+
+// stripCodeFence returns the content of the first markdown code fence in content, e.g.
+// "```json\n{...}\n```", or content itself when it has no fence or already starts with JSON
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+		return content
+	}
+
+	start := strings.Index(content, "```")
+	if start < 0 {
+		return content
+	}
+	// The rest of the opening fence's line is the language tag
+	body := content[start+3:]
+	newline := strings.IndexByte(body, '\n')
+	if newline < 0 {
+		return content
+	}
+	body = body[newline+1:]
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return strings.TrimSpace(body)
+}
@@ -0,0 +1,65 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+type weather struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+}
+
+func TestRouter_QueryJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unfenced", `{"city": "Paris", "temperature": 21.5}`},
+		{"fenced", "```json\n{\"city\": \"Paris\", \"temperature\": 21.5}\n```"},
+		{"fence without a language", "```\n{\"city\": \"Paris\", \"temperature\": 21.5}\n```"},
+		{"fence after prose", "Here is the weather:\n\n```json\n{\"city\": \"Paris\", \"temperature\": 21.5}\n```\nEnjoy!"},
+	}
+
+	for _, test := range tests {
+		var jsonMode bool
+		p := &mockProvider{name: "mock", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+			jsonMode = options.JSONMode
+			return &provider.QueryResult{Content: test.content, Model: "mock-model"}, nil
+		}}
+		router, err := gollmrouter.NewRouter(p)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+
+		var out weather
+		err = router.QueryJSON(context.Background(), []provider.Message{{Role: "user", Content: "Weather in Paris as JSON"}}, provider.QueryOptions{}, &out)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if !jsonMode {
+			t.Errorf("%s: expected the query to be sent with JSONMode", test.name)
+		}
+		if out.City != "Paris" || out.Temperature != 21.5 {
+			t.Errorf("%s: unexpected result %+v", test.name, out)
+		}
+	}
+}
+
+func TestRouter_QueryJSONInvalidContent(t *testing.T) {
+	p := &mockProvider{name: "mock", rank: 1, content: "Sorry, I can't check the weather."}
+	router, err := gollmrouter.NewRouter(p)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	var out weather
+	err = router.QueryJSON(context.Background(), []provider.Message{{Role: "user", Content: "Weather in Paris as JSON"}}, provider.QueryOptions{}, &out)
+	if !errors.Is(err, gollmrouter.ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package gollmrouter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// RateStore persists rate-limit counters so quotas survive restarts
+type RateStore = provider.RateStore
+
+// RateCounters is a snapshot of a provider's rate-limit counters
+type RateCounters = provider.RateCounters
+
+// SharedRateStore is a RateStore shared by several processes, see ratestore/redis
+type SharedRateStore = provider.SharedRateStore
+
+// FileRateStore is a RateStore that keeps the counters of all providers in a single JSON file.
+// It is meant for a single process; use a shared store when several instances share a quota.
+type FileRateStore struct {
+	mu       sync.Mutex
+	path     string
+	counters map[string]RateCounters
+}
+
+// NewFileRateStore creates a store backed by the JSON file at path, loading any counters saved
+// there before. The file is created on the first save.
+func NewFileRateStore(path string) (*FileRateStore, error) {
+	store := &FileRateStore{path: path, counters: make(map[string]RateCounters)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate store: %w", err)
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.counters); err != nil {
+			return nil, fmt.Errorf("failed to parse rate store %s: %w", path, err)
+		}
+	}
+	return store, nil
+}
+
+// Load returns the saved counters for the key, or zero counters if none were saved
+func (s *FileRateStore) Load(providerKey string) (RateCounters, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counters[providerKey], nil
+}
+
+// Save stores the counters for the key and rewrites the file
+func (s *FileRateStore) Save(providerKey string, counters RateCounters) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counters[providerKey] = counters
+	data, err := json.MarshalIndent(s.counters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rate store: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write rate store: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path, so a crash never
+// leaves a truncated file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
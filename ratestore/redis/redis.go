@@ -0,0 +1,201 @@
+// Package redis stores go-llm-router rate-limit counters in Redis so that several replicas
+// sharing an API key also share its daily and per-minute budgets. It is a separate module so
+// that the router itself doesn't depend on a Redis client.
+//
+//	store := redis.NewStore(redis.Config{Addr: "localhost:6379"})
+//	defer store.Close()
+//	p, err := gollmrouter.NewOpenRouterProvider(gollmrouter.OpenRouterConfig{
+//		APIKey:    apiKey,
+//		Models:    []string{"openai/gpt-4o-mini"},
+//		RateStore: store,
+//	})
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultKeyPrefix = "llm_router:"
+	defaultTimeout   = 5 * time.Second
+
+	// Window keys outlive their window slightly so clock skew between replicas doesn't drop counts
+	dayKeyTTL    = 25 * time.Hour
+	minuteKeyTTL = 2 * time.Minute
+)
+
+// Config configures the Redis connection
+type Config struct {
+	Addr      string        // host:port of the Redis server
+	Password  string        // optional password
+	DB        int           // optional database number
+	TLSConfig *tls.Config   // optional TLS configuration; nil connects without TLS
+	KeyPrefix string        // prefix for all keys (default "llm_router:")
+	Timeout   time.Duration // dial and per-command timeout (default 5s)
+}
+
+// Store implements provider.SharedRateStore. Each counter lives under its own key per day
+// or minute window, is updated with INCRBY and expires once the window has passed.
+type Store struct {
+	client    goredis.UniversalClient
+	ownClient bool // Whether Close closes the client
+	keyPrefix string
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+// Interface compliance check
+var _ provider.SharedRateStore = (*Store)(nil)
+
+// NewStore creates a store with its own client; the connection is opened on first use
+func NewStore(config Config) *Store {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	client := goredis.NewClient(&goredis.Options{
+		Addr:         config.Addr,
+		Password:     config.Password,
+		DB:           config.DB,
+		TLSConfig:    config.TLSConfig,
+		DialTimeout:  config.Timeout,
+		ReadTimeout:  config.Timeout,
+		WriteTimeout: config.Timeout,
+	})
+	store := NewStoreWithClient(client, config.KeyPrefix)
+	store.ownClient = true
+	store.timeout = config.Timeout
+	return store
+}
+
+// NewStoreWithClient creates a store that uses an existing client, such as a cluster or
+// sentinel client. keyPrefix defaults to "llm_router:". Close leaves the client open.
+func NewStoreWithClient(client goredis.UniversalClient, keyPrefix string) *Store {
+	if keyPrefix == "" {
+		keyPrefix = defaultKeyPrefix
+	}
+	return &Store{client: client, keyPrefix: keyPrefix, timeout: defaultTimeout, now: time.Now}
+}
+
+// windowKeys returns the keys of the daily request, per-minute request and per-minute token
+// counters for the windows containing now
+func (s *Store) windowKeys(providerKey string, now time.Time) (day, minuteRequests, minuteTokens string, dayStart, minuteStart time.Time) {
+	dayStart = now.Truncate(24 * time.Hour)
+	minuteStart = now.Truncate(time.Minute)
+	base := s.keyPrefix + providerKey
+	day = fmt.Sprintf("%s:day:%d:requests", base, dayStart.Unix())
+	minuteRequests = fmt.Sprintf("%s:minute:%d:requests", base, minuteStart.Unix())
+	minuteTokens = fmt.Sprintf("%s:minute:%d:tokens", base, minuteStart.Unix())
+	return
+}
+
+// Load returns the counters for the current windows
+func (s *Store) Load(providerKey string) (provider.RateCounters, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	day, minuteRequests, minuteTokens, dayStart, minuteStart := s.windowKeys(providerKey, s.now())
+	var commands [3]*goredis.StringCmd
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, key := range []string{day, minuteRequests, minuteTokens} {
+			commands[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	// A missing key is an empty window, reported as redis.Nil
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return provider.RateCounters{}, fmt.Errorf("failed to load rate counters: %w", err)
+	}
+
+	var values [3]int
+	for i, command := range commands {
+		value, err := command.Int()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return provider.RateCounters{}, fmt.Errorf("invalid counter value %q: %w", command.Val(), err)
+		}
+		values[i] = value
+	}
+	return provider.RateCounters{
+		RequestsToday:      values[0],
+		RequestsThisMinute: values[1],
+		TokensThisMinute:   values[2],
+		DayStart:           dayStart,
+		MinuteStart:        minuteStart,
+	}, nil
+}
+
+// Save overwrites the counters for the current windows. Counters from windows that have
+// already passed are not written.
+func (s *Store) Save(providerKey string, counters provider.RateCounters) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	day, minuteRequests, minuteTokens, dayStart, minuteStart := s.windowKeys(providerKey, s.now())
+	saveDay := counters.DayStart.Equal(dayStart)
+	saveMinute := counters.MinuteStart.Equal(minuteStart)
+	if !saveDay && !saveMinute {
+		return nil
+	}
+
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		if saveDay {
+			pipe.Set(ctx, day, counters.RequestsToday, dayKeyTTL)
+		}
+		if saveMinute {
+			pipe.Set(ctx, minuteRequests, counters.RequestsThisMinute, minuteKeyTTL)
+			pipe.Set(ctx, minuteTokens, counters.TokensThisMinute, minuteKeyTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save rate counters: %w", err)
+	}
+	return nil
+}
+
+// Increment atomically adds requests and tokens to the current windows and returns the
+// combined counters of every process using the same key
+func (s *Store) Increment(providerKey string, requests, tokens int) (provider.RateCounters, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	day, minuteRequests, minuteTokens, dayStart, minuteStart := s.windowKeys(providerKey, s.now())
+	var dayCount, minuteRequestCount, minuteTokenCount *goredis.IntCmd
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		dayCount = pipe.IncrBy(ctx, day, int64(requests))
+		pipe.Expire(ctx, day, dayKeyTTL)
+		minuteRequestCount = pipe.IncrBy(ctx, minuteRequests, int64(requests))
+		pipe.Expire(ctx, minuteRequests, minuteKeyTTL)
+		minuteTokenCount = pipe.IncrBy(ctx, minuteTokens, int64(tokens))
+		pipe.Expire(ctx, minuteTokens, minuteKeyTTL)
+		return nil
+	})
+	if err != nil {
+		return provider.RateCounters{}, fmt.Errorf("failed to increment rate counters: %w", err)
+	}
+
+	return provider.RateCounters{
+		RequestsToday:      int(dayCount.Val()),
+		RequestsThisMinute: int(minuteRequestCount.Val()),
+		TokensThisMinute:   int(minuteTokenCount.Val()),
+		DayStart:           dayStart,
+		MinuteStart:        minuteStart,
+	}, nil
+}
+
+// Close closes the client created by NewStore. A client passed to NewStoreWithClient is left open.
+func (s *Store) Close() error {
+	if !s.ownClient {
+		return nil
+	}
+	return s.client.Close()
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestStore returns a store backed by a fresh miniredis server, with its clock fixed at now
+func newTestStore(t *testing.T, now time.Time) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	store := NewStore(Config{Addr: server.Addr(), KeyPrefix: "test:"})
+	store.now = func() time.Time { return now }
+	t.Cleanup(func() { store.Close() })
+	return store, server
+}
+
+func TestStore_IncrementSetsWindowExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 30, 15, 0, time.UTC)
+	store, server := newTestStore(t, now)
+
+	counters, err := store.Increment("key", 1, 100)
+	if err != nil {
+		t.Fatalf("Failed to increment: %v", err)
+	}
+	if counters.RequestsToday != 1 || counters.RequestsThisMinute != 1 || counters.TokensThisMinute != 100 {
+		t.Errorf("Expected the incremented counters, got %+v", counters)
+	}
+
+	day, minuteRequests, minuteTokens, _, _ := store.windowKeys("key", now)
+	if ttl := server.TTL(day); ttl != dayKeyTTL {
+		t.Errorf("Expected the daily key to expire after %v, got %v", dayKeyTTL, ttl)
+	}
+	for _, key := range []string{minuteRequests, minuteTokens} {
+		if ttl := server.TTL(key); ttl != minuteKeyTTL {
+			t.Errorf("Expected %s to expire after %v, got %v", key, minuteKeyTTL, ttl)
+		}
+	}
+
+	// Once the minute keys expire only the daily count is left
+	server.FastForward(minuteKeyTTL + time.Second)
+	if server.Exists(minuteRequests) || server.Exists(minuteTokens) {
+		t.Error("Expected the minute keys to expire")
+	}
+	if !server.Exists(day) {
+		t.Error("Expected the daily key to outlive the minute keys")
+	}
+}
+
+func TestStore_WindowRollover(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 58, 40, 0, time.UTC)
+	store, _ := newTestStore(t, now)
+
+	for i := 0; i < 2; i++ {
+		if _, err := store.Increment("key", 1, 50); err != nil {
+			t.Fatalf("Failed to increment: %v", err)
+		}
+	}
+
+	// 23:59:20 is in the next minute, which starts new minute counters but keeps the day's
+	store.now = func() time.Time { return now.Add(40 * time.Second) }
+	counters, err := store.Increment("key", 1, 50)
+	if err != nil {
+		t.Fatalf("Failed to increment: %v", err)
+	}
+	if counters.RequestsToday != 3 || counters.RequestsThisMinute != 1 || counters.TokensThisMinute != 50 {
+		t.Errorf("Expected the minute counters to start over within the day, got %+v", counters)
+	}
+
+	// 00:00:10 is in the next day, so every counter starts over
+	store.now = func() time.Time { return now.Add(90 * time.Second) }
+	loaded, err := store.Load("key")
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loaded.RequestsToday != 0 || loaded.RequestsThisMinute != 0 || loaded.TokensThisMinute != 0 {
+		t.Errorf("Expected empty counters in a new day, got %+v", loaded)
+	}
+	if !loaded.DayStart.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the new day's window, got %v", loaded.DayStart)
+	}
+}
+
+func TestStore_SaveAndLoad(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 30, 15, 0, time.UTC)
+	store, server := newTestStore(t, now)
+
+	counters := provider.RateCounters{
+		RequestsToday:      4,
+		RequestsThisMinute: 2,
+		TokensThisMinute:   300,
+		DayStart:           now.Truncate(24 * time.Hour),
+		MinuteStart:        now.Truncate(time.Minute),
+	}
+	if err := store.Save("key", counters); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	loaded, err := store.Load("key")
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loaded != counters {
+		t.Errorf("Expected the saved counters, got %+v", loaded)
+	}
+	day, _, _, _, _ := store.windowKeys("key", now)
+	if ttl := server.TTL(day); ttl != dayKeyTTL {
+		t.Errorf("Expected saved keys to expire with their window, got %v", ttl)
+	}
+
+	missing, err := store.Load("missing")
+	if err != nil || missing.RequestsToday != 0 {
+		t.Errorf("Expected zero counters for an unknown key, got %+v (%v)", missing, err)
+	}
+}
+
+func TestStore_InvalidCounter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 30, 15, 0, time.UTC)
+	store, server := newTestStore(t, now)
+	day, _, _, _, _ := store.windowKeys("key", now)
+	server.Set(day, "not a number")
+
+	if _, err := store.Increment("key", 1, 10); err == nil {
+		t.Error("Expected an error when a counter isn't an integer")
+	}
+	if _, err := store.Load("key"); err == nil {
+		t.Error("Expected an error when loading a counter that isn't an integer")
+	}
+}
+
+func TestStore_SharedDailyCap(t *testing.T) {
+	chat := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer chat.Close()
+	server := miniredis.RunT(t)
+
+	// Two replicas with their own store and the same API key
+	newReplica := func() provider.Provider {
+		store := NewStore(Config{Addr: server.Addr()})
+		t.Cleanup(func() { store.Close() })
+		p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+			APIKey:       "shared-key",
+			URL:          chat.URL,
+			Models:       []string{"test-model"},
+			MaxDailyReqs: 3,
+			RateStore:    store,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		return p
+	}
+
+	ctx := context.Background()
+	first, second := newReplica(), newReplica()
+	messages := []provider.Message{{Role: "user", Content: "hi"}}
+	for _, p := range []provider.Provider{first, first, second} {
+		if !p.HasRemainingRequests(ctx) {
+			t.Fatal("Expected the combined daily limit not to be reached yet")
+		}
+		if _, err := p.QueryWithOptions(ctx, messages, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if first.HasRemainingRequests(ctx) || second.HasRemainingRequests(ctx) {
+		t.Error("Expected both replicas to see the combined daily cap")
+	}
+}
@@ -0,0 +1,93 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// newChatServer returns a server that answers every chat completion with "ok"
+func newChatServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFileRateStore_RestoresDailyCountAfterRestart(t *testing.T) {
+	server := newChatServer(t)
+	path := filepath.Join(t.TempDir(), "limits.json")
+
+	newProvider := func() provider.Provider {
+		store, err := gollmrouter.NewFileRateStore(path)
+		if err != nil {
+			t.Fatalf("Failed to open rate store: %v", err)
+		}
+		p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+			APIKey:       "test-key",
+			URL:          server.URL,
+			Models:       []string{"test-model"},
+			MaxDailyReqs: 3,
+			RateStore:    store,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+		return p
+	}
+
+	ctx := context.Background()
+	p := newProvider()
+	for i := 0; i < 3; i++ {
+		if _, err := p.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if p.HasRemainingRequests(ctx) {
+		t.Fatal("Expected the daily limit to be reached")
+	}
+
+	// A new process reads the same file
+	restarted := newProvider()
+	if restarted.HasRemainingRequests(ctx) {
+		t.Error("Expected the daily count to be restored after a restart")
+	}
+}
+
+func TestFileRateStore_KeysAreIsolated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	store, err := gollmrouter.NewFileRateStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open rate store: %v", err)
+	}
+
+	day := time.Now().Truncate(24 * time.Hour)
+	if err := store.Save("a", gollmrouter.RateCounters{RequestsToday: 5, DayStart: day}); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if err := store.Save("b", gollmrouter.RateCounters{RequestsToday: 7, DayStart: day}); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	reopened, err := gollmrouter.NewFileRateStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen rate store: %v", err)
+	}
+	a, _ := reopened.Load("a")
+	b, _ := reopened.Load("b")
+	missing, _ := reopened.Load("missing")
+	if a.RequestsToday != 5 || b.RequestsToday != 7 || !a.DayStart.Equal(day) {
+		t.Errorf("Expected saved counters per key, got a=%+v b=%+v", a, b)
+	}
+	if missing.RequestsToday != 0 {
+		t.Errorf("Expected zero counters for an unknown key, got %+v", missing)
+	}
+}
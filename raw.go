@@ -0,0 +1,93 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// RawRequester is implemented by HTTP providers that can send requests to arbitrary endpoints of
+// their API
+type RawRequester = provider.RawRequester
+
+// DoRaw sends a request to an endpoint the library doesn't model, such as moderations or rerank,
+// through the first available provider that supports raw requests. Providers are tried in the
+// same order as for queries and their request limits apply; providers that don't speak HTTP to an
+// OpenAI-compatible API, such as Gemini and Bedrock, are skipped.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - path: The endpoint's path, resolved against the provider's base URL, e.g. "/moderations"
+//   - body: The request body, marshaled to JSON and POSTed; nil sends a GET
+//
+// Returns:
+//   - body: The raw response body
+//   - providerName: The name of the provider that answered
+//   - error: A RouterError if every provider that supports raw requests failed
+func (r *Router) DoRaw(ctx context.Context, path string, body interface{}) ([]byte, string, error) {
+	ctx, span := r.startQuerySpan(ctx, "Router.DoRaw")
+	defer span.End()
+
+	var routerError RouterError
+	supported := 0
+
+	for i, p := range r.orderProviders(r.getProviders()) {
+		requester, ok := p.(provider.RawRequester)
+		if !ok || !requester.SupportsRawRequests() {
+			continue
+		}
+		supported++
+
+		providerName := providerDisplayName(p, i)
+
+		if err := r.checkDeadline(ctx); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeSkipped, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		if err := checkRequestLimits(ctx, p); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeRateLimited, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		attemptCtx, cancel := attemptContext(ctx, p)
+		start := time.Now()
+		data, err := requester.DoRaw(attemptCtx, path, body)
+		r.metrics.ObserveLatency(providerName, time.Since(start))
+		cancel()
+		if err != nil {
+			r.metrics.IncRequest(providerName, "", OutcomeError)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			if r.isTerminal(err) {
+				break
+			}
+			continue
+		}
+
+		r.metrics.IncRequest(providerName, "", OutcomeSuccess)
+		span.SetAttributes(provider.Attr("provider.name", providerName))
+		return data, providerName, nil
+	}
+
+	if supported == 0 {
+		err := fmt.Errorf("no configured provider supports raw requests")
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	span.RecordError(&routerError)
+	return nil, "", &routerError
+}
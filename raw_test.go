@@ -0,0 +1,137 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+// rawRequest is a request received by a test server
+type rawRequest struct {
+	method        string
+	path          string
+	authorization string
+	body          string
+}
+
+// newRawServer answers every request with status and body, and records the requests
+func newRawServer(t *testing.T, status int, body string) (*httptest.Server, *[]rawRequest) {
+	t.Helper()
+
+	var requests []rawRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		requests = append(requests, rawRequest{r.Method, r.URL.Path, r.Header.Get("Authorization"), string(data)})
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRouter_DoRaw(t *testing.T) {
+	failingServer, failingRequests := newRawServer(t, http.StatusServiceUnavailable, `{"error": {"message": "overloaded"}}`)
+	server, requests := newRawServer(t, http.StatusOK, `{"results": [{"flagged": false}]}`)
+
+	failing, err := gollmrouter.NewOpenAIProvider(gollmrouter.OpenAIConfig{
+		APIKey:  "failing-key",
+		BaseURL: failingServer.URL + "/v1",
+		Models:  []string{"gpt-4o"},
+		Rank:    2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	working, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		URL:    server.URL + "/v1/chat/completions",
+		Models: []string{"test-model"},
+		Rank:   1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(failing, working)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	data, providerName, err := router.DoRaw(context.Background(), "/moderations", map[string]interface{}{"input": "hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != `{"results": [{"flagged": false}]}` {
+		t.Errorf("Expected the raw response body, got %s", data)
+	}
+	if providerName != working.Name() {
+		t.Errorf("Expected the answering provider %q, got %q", working.Name(), providerName)
+	}
+
+	if len(*failingRequests) != 1 {
+		t.Errorf("Expected the failing provider to be tried first, got %d requests", len(*failingRequests))
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("Expected one request to the working provider, got %d", len(*requests))
+	}
+	request := (*requests)[0]
+	if request.method != "POST" || request.path != "/v1/moderations" || request.authorization != "Bearer test-key" {
+		t.Errorf("Expected a POST to /v1/moderations with the provider's key, got %+v", request)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(request.body), &body); err != nil || body["input"] != "hello" {
+		t.Errorf("Expected the body to be sent as JSON, got %s", request.body)
+	}
+}
+
+func TestRouter_DoRawGet(t *testing.T) {
+	server, requests := newRawServer(t, http.StatusOK, `{"data": {"id": "gen-1"}}`)
+	p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey: "test-key",
+		URL:    server.URL + "/api/v1/chat/completions",
+		Models: []string{"test-model"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(p)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	if _, _, err := router.DoRaw(context.Background(), "generation?id=gen-1", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].method != "GET" || (*requests)[0].path != "/api/v1/generation" {
+		t.Errorf("Expected a GET to /api/v1/generation, got %+v", *requests)
+	}
+}
+
+func TestRouter_DoRawUnsupported(t *testing.T) {
+	gemini, err := gollmrouter.NewGeminiProvider(gollmrouter.GeminiConfig{
+		APIKey: "test-key",
+		Models: []string{"gemini-2.0-flash"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(gemini, &mockProvider{name: "mock"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	_, _, err = router.DoRaw(context.Background(), "/moderations", map[string]string{"input": "hello"})
+	if err == nil {
+		t.Fatalf("Expected an error when no provider supports raw requests")
+	}
+	if _, ok := gollmrouter.GetRouterError(err); ok {
+		t.Errorf("Expected a plain error rather than a RouterError, got %v", err)
+	}
+}
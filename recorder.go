@@ -0,0 +1,148 @@
+package gollmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// ErrNoRecordedResponse is returned by a replaying recorder when the cassette has no response
+// for a request
+var ErrNoRecordedResponse = errors.New("no recorded response for request")
+
+// cassette is the file a recorder writes and replays
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+// interaction is a recorded request and the result the provider returned for it. The request is
+// kept for readability; replay matches on Hash.
+type interaction struct {
+	Hash     string                `json:"hash"`
+	Messages []provider.Message    `json:"messages"`
+	Options  provider.QueryOptions `json:"options"`
+	Result   *provider.QueryResult `json:"result"`
+}
+
+// WithRecorder wraps a provider to record its responses to a cassette file at path and replay
+// them, for deterministic tests of prompt logic without live API calls.
+//
+// When the file doesn't exist the recorder records: queries go to the provider and each
+// successful result is added to the cassette, which is rewritten after every query. When the
+// file exists it replays: queries are answered from the cassette by a hash of the messages and
+// options, and the provider is never queried. A request that isn't in the cassette fails with
+// ErrNoRecordedResponse. Identical requests recorded several times are replayed in the order
+// they were recorded, repeating the last. Delete the file to record again.
+//
+// Errors are not recorded. Only queries are recorded: the wrapper's other optional interfaces,
+// such as Embedder, are those of the provider and always call it.
+func WithRecorder(p provider.Provider, path string) (provider.Provider, error) {
+	r := &recordingProvider{providerWrapper: providerWrapper{p}, path: path, replayed: make(map[string]int)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	r.replay = true
+	return r, nil
+}
+
+// recordingProvider records the results of the provider it wraps to a cassette, or replays them
+type recordingProvider struct {
+	providerWrapper
+	path   string
+	replay bool
+
+	mu       sync.Mutex
+	cassette cassette
+	replayed map[string]int // Responses replayed so far for each hash
+}
+
+// Query records or replays the query (legacy method)
+func (r *recordingProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
+	result, err := r.QueryWithOptions(ctx, messages, provider.QueryOptions{Temperature: temperature, TemperatureSet: true, ForceModel: forceModel})
+	if err != nil {
+		return "", "", err
+	}
+	return result.Content, result.Model, nil
+}
+
+// QueryWithOptions answers the query from the cassette when replaying, or sends it to the
+// provider and records the result
+func (r *recordingProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	hash, err := requestHash(messages, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash request: %w", err)
+	}
+
+	if r.replay {
+		return r.replayResult(hash)
+	}
+
+	result, err := r.Provider.QueryWithOptions(ctx, messages, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.record(interaction{Hash: hash, Messages: messages, Options: options, Result: result}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// replayResult returns the next recorded result for the hash
+func (r *recordingProvider) replayResult(hash string) (*provider.QueryResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []*provider.QueryResult
+	for _, recorded := range r.cassette.Interactions {
+		if recorded.Hash == hash {
+			matches = append(matches, recorded.Result)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoRecordedResponse, r.path)
+	}
+
+	i := min(r.replayed[hash], len(matches)-1)
+	r.replayed[hash]++
+
+	// Callers may modify the result, so each replay gets its own copy
+	data, err := json.Marshal(matches[i])
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy recorded result: %w", err)
+	}
+	var result provider.QueryResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to copy recorded result: %w", err)
+	}
+	return &result, nil
+}
+
+// record adds the interaction to the cassette and rewrites the file
+func (r *recordingProvider) record(recorded interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, recorded)
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := writeFileAtomic(r.path, data); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestWithRecorder_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	calls := 0
+	live := &mockProvider{name: "live", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		calls++
+		return &provider.QueryResult{
+			Content: fmt.Sprintf("answer %d to %s", calls, messages[len(messages)-1].Content),
+			Model:   "live-model",
+			Usage:   &provider.Usage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7},
+		}, nil
+	}}
+
+	queries := []struct {
+		prompt  string
+		options provider.QueryOptions
+	}{
+		{"What is 2+2?", provider.QueryOptions{}},
+		{"What is 2+2?", provider.QueryOptions{Temperature: 0.5}},
+		{"Name a color.", provider.QueryOptions{}},
+		{"What is 2+2?", provider.QueryOptions{}},
+	}
+	run := func(p provider.Provider) []*provider.QueryResult {
+		router, err := gollmrouter.NewRouter(p)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+		var results []*provider.QueryResult
+		for _, query := range queries {
+			result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: query.prompt}}, query.options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			results = append(results, result)
+		}
+		return results
+	}
+
+	recorder, err := gollmrouter.WithRecorder(live, path)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	recorded := run(recorder)
+	if calls != len(queries) {
+		t.Fatalf("Expected every query to reach the provider while recording, got %d calls", calls)
+	}
+
+	offline := &mockProvider{name: "live", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		t.Errorf("Expected no live calls while replaying")
+		return nil, errors.New("offline")
+	}}
+	replayer, err := gollmrouter.WithRecorder(offline, path)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	replayed := run(replayer)
+
+	// Identical requests are replayed in the order they were recorded
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("Expected replayed results to match the recording:\n%+v\n%+v", recorded, replayed)
+	}
+	if replayed[0].Content != "answer 1 to What is 2+2?" || replayed[3].Content != "answer 4 to What is 2+2?" {
+		t.Errorf("Expected repeated requests to replay in order, got %q and %q", replayed[0].Content, replayed[3].Content)
+	}
+}
+
+func TestWithRecorder_ReplayMiss(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := gollmrouter.WithRecorder(&mockProvider{name: "live", content: "ok"}, path)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	if _, err := recorder.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	replayer, err := gollmrouter.WithRecorder(&mockProvider{name: "live", content: "live"}, path)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	_, err = replayer.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "something new"}}, provider.QueryOptions{})
+	if !errors.Is(err, gollmrouter.ErrNoRecordedResponse) {
+		t.Errorf("Expected ErrNoRecordedResponse for an unrecorded request, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package gollmrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/FramnkRulez/go-llm-router/internal/retry"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// RouterRetryConfig configures retrying a provider after transient errors before falling back.
+// MaxAttempts counts attempts per provider, including the first; BaseDelay (default 200ms) is
+// doubled for each further retry up to MaxDelay (default 5s).
+type RouterRetryConfig = provider.RetryConfig
+
+// WithRetry makes the router retry a provider after transient errors (connection resets, timeouts,
+// 408, 429 and 5xx responses) with exponential backoff and jitter. Other errors fall back to the next
+// provider immediately, and a provider is not retried once it runs out of quota.
+//
+// The router's retries wrap a provider's own: a Gemini provider with Retry.MaxAttempts 3 behind a
+// router with MaxAttempts 3 can call the API 3×3 = 9 times for one model before the router falls
+// back. Usually only one of the two should retry.
+func WithRetry(config RouterRetryConfig) RouterOption {
+	return func(r *Router) {
+		r.retry = retry.New(config)
+	}
+}
+
+// queryProviderWithRetry queries a provider, retrying transient errors as configured with WithRetry
+func (r *Router) queryProviderWithRetry(ctx context.Context, p provider.Provider, name string, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := r.queryProvider(ctx, p, name, messages, options)
+		if err == nil || attempt >= r.retry.MaxAttempts || ctx.Err() != nil || !isTransientError(err) {
+			return result, err
+		}
+
+		if !r.retry.Wait(ctx, attempt) {
+			return nil, err
+		}
+
+		// A retry is a new request, so it has to fit in the provider's quota and the caller's deadline
+		if checkRateLimits(ctx, p, messages) != nil || r.checkDeadline(ctx) != nil {
+			return nil, err
+		}
+	}
+}
+
+// isTransientError reports whether a failed request may succeed if it is sent again
+func isTransientError(err error) bool {
+	var apiErr *provider.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,119 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// flakyProvider fails with err for the first failures calls, then answers "ok"
+func flakyProvider(name string, failures int32, err error) (*mockProvider, *atomic.Int32) {
+	var calls atomic.Int32
+	return &mockProvider{name: name, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		if calls.Add(1) <= failures {
+			return nil, err
+		}
+		return &provider.QueryResult{Content: "ok", Model: name + "-model"}, nil
+	}}, &calls
+}
+
+func TestRouter_RetryTransientErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"service unavailable", provider.NewAPIError(503, "overloaded")},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			flaky, calls := flakyProvider("flaky", 2, tc.err)
+			backup := &mockProvider{name: "backup", content: "backup"}
+			router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{flaky, backup},
+				gollmrouter.WithRetry(gollmrouter.RouterRetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create router: %v", err)
+			}
+
+			result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Model != "flaky-model" || calls.Load() != 3 {
+				t.Errorf("Expected the third attempt on the same provider to succeed, got %q after %d calls", result.Model, calls.Load())
+			}
+			if backup.callCount() != 0 {
+				t.Error("Expected no fallback when a retry succeeds")
+			}
+		})
+	}
+}
+
+func TestRouter_RetryGivesUpAfterMaxAttempts(t *testing.T) {
+	flaky, calls := flakyProvider("flaky", 5, provider.NewAPIError(502, "bad gateway"))
+	backup := &mockProvider{name: "backup", content: "backup"}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{flaky, backup},
+		gollmrouter.WithRetry(gollmrouter.RouterRetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls.Load() != 2 || result.Content != "backup" {
+		t.Errorf("Expected 2 attempts before falling back, got %d calls and %q", calls.Load(), result.Content)
+	}
+}
+
+func TestRouter_RetrySkipsNonRetryableErrors(t *testing.T) {
+	failing, calls := flakyProvider("failing", 5, provider.NewAPIError(400, "bad request"))
+	generic, genericCalls := flakyProvider("generic", 5, errors.New("invalid response"))
+	backup := &mockProvider{name: "backup", content: "backup"}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{generic, failing, backup},
+		gollmrouter.WithRetry(gollmrouter.RouterRetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err == nil {
+		t.Fatal("Expected the non-retryable API error to be returned")
+	}
+	if genericCalls.Load() != 1 || calls.Load() != 1 {
+		t.Errorf("Expected non-transient errors not to be retried, got %d and %d calls", genericCalls.Load(), calls.Load())
+	}
+}
+
+func TestRouter_RetryStopsWhenContextIsCanceled(t *testing.T) {
+	flaky, calls := flakyProvider("flaky", 5, provider.NewAPIError(503, "overloaded"))
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{flaky},
+		gollmrouter.WithRetry(gollmrouter.RouterRetryConfig{MaxAttempts: 3, BaseDelay: time.Hour}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := router.QueryWithOptions(ctx, []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the backoff to end with the context, took %v", elapsed)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d calls", calls.Load())
+	}
+}
@@ -8,9 +8,16 @@ package gollmrouter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/FramnkRulez/go-llm-router/internal/retry"
 	"github.com/FramnkRulez/go-llm-router/provider"
 )
 
@@ -65,51 +72,155 @@ func GetRouterError(err error) (*RouterError, bool) {
 	return routerErr, ok
 }
 
+// ErrToolsNotSupported is returned for a provider that was skipped because the query has tools
+// and the provider's SupportsTools reports false, and by Gemini for models that can't call functions
+var ErrToolsNotSupported = provider.ErrToolsNotSupported
+
+// ErrTokenBudgetExceeded is returned for a provider whose remaining tokens per minute can't fit
+// the request's estimated tokens
+var ErrTokenBudgetExceeded = provider.ErrTokenBudgetExceeded
+
+// ErrRateLimitExceeded is returned for a provider that was skipped because it has used up its
+// daily or per-minute requests
+var ErrRateLimitExceeded = provider.ErrRateLimitExceeded
+
 // Router manages multiple LLM providers and routes requests to available ones.
 // It automatically handles fallback between providers based on quota availability
 // and request success/failure.
 type Router struct {
-	providers []provider.Provider
+	mu                   sync.RWMutex
+	providers            []provider.Provider
+	emptyResponseIsError bool
+	alwaysFallback       bool
+	strategy             Strategy
+	latencyAcrossRanks   bool
+	stats                statsTracker
+	requestCounter       atomic.Uint64
+	minAttemptTime       time.Duration
+	tracer               provider.Tracer
+	metrics              MetricsCollector
+	cache                cacheConfig
+	middlewares          []Middleware
+	pricing              PricingTable
+	cost                 costTracker
+	outputEstimate       int // Output tokens assumed by EstimateCost; 0 uses the default
+	retry                retry.Policy
+	defaults             provider.QueryOptions
+	closeOnce            sync.Once
+	logger               *slog.Logger // nil unless WithLogger is used
+}
+
+// RouterOption configures optional router behavior
+type RouterOption func(*Router)
+
+// WithEmptyResponseIsError makes the router treat a response with empty content and no
+// tool calls as a provider failure and fall back to the next provider.
+// Disabled by default, in which case empty responses are returned to the caller.
+func WithEmptyResponseIsError(enabled bool) RouterOption {
+	return func(r *Router) {
+		r.emptyResponseIsError = enabled
+	}
+}
+
+// WithAlwaysFallback makes the router fall back to the next provider after every error.
+// By default the router stops at API errors with status 400, 401, 403 or 422, since other
+// providers are likely to reject the same request. Other errors, such as 404 for a model a
+// provider doesn't serve, fall back.
+func WithAlwaysFallback(enabled bool) RouterOption {
+	return func(r *Router) {
+		r.alwaysFallback = enabled
+	}
 }
 
 // NewRouter creates a new router with the specified providers.
 // Providers will be tried in order of their rank (highest first), then in the order they are passed.
 // At least one provider must be specified.
 func NewRouter(providers ...provider.Provider) (*Router, error) {
+	return NewRouterWithOptions(providers)
+}
+
+// NewRouterWithOptions creates a new router with the specified providers and options.
+// Providers are ordered the same way as in NewRouter.
+func NewRouterWithOptions(providers []provider.Provider, opts ...RouterOption) (*Router, error) {
 	if len(providers) == 0 {
 		return nil, fmt.Errorf("no providers configured")
 	}
 
-	// Sort providers by rank (highest rank first)
 	sortedProviders := make([]provider.Provider, len(providers))
 	copy(sortedProviders, providers)
+	sortProvidersByRank(sortedProviders)
 
-	// Simple bubble sort by rank (providers with higher rank come first)
-	for i := 0; i < len(sortedProviders)-1; i++ {
-		for j := 0; j < len(sortedProviders)-i-1; j++ {
-			if sortedProviders[j].GetRank() < sortedProviders[j+1].GetRank() {
-				sortedProviders[j], sortedProviders[j+1] = sortedProviders[j+1], sortedProviders[j]
-			}
-		}
+	r := &Router{
+		providers: sortedProviders,
+		tracer:    provider.NoopTracer{},
+		metrics:   NoopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
 
-	return &Router{
-		providers: sortedProviders,
-	}, nil
+	return r, nil
+}
+
+// sortProvidersByRank sorts providers by rank (highest rank first), keeping the
+// original order for providers with the same rank
+func sortProvidersByRank(providers []provider.Provider) {
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].GetRank() > providers[j].GetRank()
+	})
+}
+
+// AddProvider adds a provider to the router at runtime.
+// The provider is placed according to its rank, after any existing providers with the same rank.
+func (r *Router) AddProvider(p provider.Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers = append(r.providers, p)
+	sortProvidersByRank(r.providers)
 }
 
-// estimateTokens provides a rough estimation of tokens in the messages
-// This is a simple approximation: ~4 characters per token for English text
-func estimateTokens(messages []provider.Message) int {
-	totalChars := 0
-	for _, msg := range messages {
-		totalChars += len(msg.Content)
-		for _, file := range msg.Files {
-			totalChars += len(file.Data)
+// RemoveProvider removes the first provider whose Name() matches the given name and closes it.
+// Returns false if no provider with that name is configured.
+func (r *Router) RemoveProvider(name string) bool {
+	r.mu.Lock()
+	var removed provider.Provider
+	for i, p := range r.providers {
+		if p.Name() == name {
+			removed = p
+			r.providers = append(r.providers[:i:i], r.providers[i+1:]...)
+			break
 		}
 	}
-	// Rough approximation: 4 characters per token
-	return totalChars / 4
+	r.mu.Unlock()
+
+	if removed == nil {
+		return false
+	}
+
+	r.stats.remove(removed)
+	removed.Close()
+	return true
+}
+
+// getProviders returns a snapshot of the configured providers in routing order
+func (r *Router) getProviders() []provider.Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	providers := make([]provider.Provider, len(r.providers))
+	copy(providers, r.providers)
+	return providers
+}
+
+// estimateTokens estimates the tokens in the messages using the provider's own
+// token estimator when it has one, so the pre-dispatch check matches the
+// provider's per-minute accounting
+func estimateTokens(p provider.Provider, messages []provider.Message) int {
+	if estimator, ok := p.(provider.TokenEstimator); ok {
+		return estimator.EstimateTokens(messages)
+	}
+	return provider.DefaultTokenEstimator.EstimateTokens(messages)
 }
 
 // Query sends a prompt to available LLM providers and returns the first successful response.
@@ -126,180 +237,548 @@ func estimateTokens(messages []provider.Message) int {
 //   - model: The name of the model that generated the response
 //   - error: Any error that occurred (nil if successful)
 func (r *Router) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
-	// Convert messages to provider format (including file attachments)
-	providerMessages := make([]provider.Message, len(messages))
-	for i, msg := range messages {
-		providerMessages[i] = provider.Message{
-			Role:    msg.Role,
-			Content: msg.Content,
-			Files:   make([]provider.File, len(msg.Files)),
-		}
-		// Copy file attachments
-		copy(providerMessages[i].Files, msg.Files)
+	// The positional temperature is always sent, so 0.0 stays deterministic
+	options := provider.QueryOptions{
+		Temperature:    temperature,
+		TemperatureSet: true,
+		ForceModel:     forceModel,
 	}
 
-	options := provider.QueryOptions{
-		Temperature: temperature,
-		ForceModel:  forceModel,
+	result, err := r.QueryWithOptions(ctx, messages, options)
+	if err != nil {
+		return "", "", err
 	}
 
-	var routerError RouterError
+	return result.Content, result.Model, nil
+}
 
-	for i, provider := range r.providers {
-		providerName := provider.Name()
-		if providerName == "" {
-			providerName = fmt.Sprintf("Provider %d", i+1)
-		}
+// QueryWithOptions sends a prompt to available LLM providers with advanced options including tool calls.
+// It automatically handles fallback between providers and models within each provider.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - messages: Array of chat messages to send (can include file attachments)
+//   - options: Query options including temperature, model, tools, and tool choice
+//
+// Returns:
+//   - result: The query result containing content, model, tool calls, and finish reason
+//   - error: Any error that occurred (nil if successful)
+//
+// Unset options fall back to the router's defaults (see WithDefaultOptions), then middlewares
+// added with Use run around the query.
+func (r *Router) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	return r.handler()(ctx, messages, mergeOptions(r.defaults, options))
+}
 
-		// Check all rate limits
-		if !provider.HasRemainingRequests(ctx) {
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("daily request limit exceeded"),
-			})
-			continue
+// queryWithOptions routes the query to the providers
+func (r *Router) queryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	ctx, span := r.startQuerySpan(ctx, "Router.QueryWithOptions")
+	defer span.End()
+
+	cacheKey, cacheable := r.cacheKey(messages, options)
+	if cacheable {
+		if cached, ok := r.cache.cache.Get(cacheKey); ok {
+			span.SetAttributes(provider.Attr("cache.hit", true))
+			return cached, nil
 		}
+	}
 
-		if !provider.HasRemainingRequestsPerMinute(ctx) {
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("requests per minute limit exceeded"),
-			})
-			continue
+	providers, err := r.candidateProviders(options)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	estimates, budgetErr := r.estimateTokenBudgets(ctx, providers, messages)
+	if budgetErr != nil {
+		span.RecordError(budgetErr)
+		return nil, budgetErr
+	}
+
+	query := r.querySequential
+	if r.strategy == StrategyTieredParallel {
+		query = r.queryTiers
+	}
+	result, providerName, routerError := query(ctx, providers, estimates, copyMessages(messages), options)
+	if result == nil {
+		if len(routerError.Errors) == 0 {
+			err := fmt.Errorf("no providers configured")
+			span.RecordError(err)
+			return nil, err
 		}
+		span.RecordError(routerError)
+		return nil, routerError
+	}
+
+	// Tool calls depend on the caller acting on them, so they are never replayed from the cache
+	if cacheable && len(result.ToolCalls) == 0 {
+		r.cache.cache.Set(cacheKey, result, r.cache.ttl)
+	}
+
+	span.SetAttributes(append(resultAttributes(result), provider.Attr("provider.name", providerName))...)
+	return result, nil
+}
+
+// querySequential tries the providers one at a time in routing order and returns the first
+// successful result with the provider's name, or the errors of every provider
+func (r *Router) querySequential(ctx context.Context, providers []provider.Provider, estimates []int, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, string, *RouterError) {
+	var routerError RouterError
 
-		// Estimate tokens for the request (rough approximation)
-		estimatedTokens := estimateTokens(messages)
-		if !provider.HasRemainingTokensPerMinute(ctx, estimatedTokens) {
+	for i, p := range providers {
+		providerName := providerDisplayName(p, i)
+
+		if err := r.admitProvider(ctx, p, providerName, options, estimates[i]); err != nil {
 			routerError.Errors = append(routerError.Errors, ProviderError{
 				ProviderName: providerName,
-				Error:        fmt.Errorf("tokens per minute limit exceeded"),
+				Error:        err,
 			})
 			continue
 		}
 
-		result, err := provider.QueryWithOptions(ctx, providerMessages, options)
+		result, err := r.queryProviderWithRetry(ctx, p, providerName, messages, options)
 		if err != nil {
 			// Collect the error
 			routerError.Errors = append(routerError.Errors, ProviderError{
 				ProviderName: providerName,
 				Error:        err,
 			})
+			if r.isTerminal(err) {
+				break
+			}
 			continue
 		}
 
-		// Check for empty response and treat as error
-		if result.Content == "" {
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("empty response received"),
-			})
+		return result, providerName, nil
+	}
+
+	return nil, "", &routerError
+}
+
+// queryTiers queries the providers tier by tier, highest rank first. The providers of a tier
+// are queried concurrently; the first success wins and cancels the rest of the tier. Lower tiers
+// are only tried once every provider of the tier above has failed or been skipped.
+func (r *Router) queryTiers(ctx context.Context, providers []provider.Provider, estimates []int, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, string, *RouterError) {
+	var routerError RouterError
+
+	for start := 0; start < len(providers); {
+		end := start + 1
+		for end < len(providers) && providers[end].GetRank() == providers[start].GetRank() {
+			end++
+		}
+
+		result, name, errs := r.queryTier(ctx, providers, start, end, estimates, messages, options)
+		if result != nil {
+			return result, name, nil
+		}
+		routerError.Errors = append(routerError.Errors, errs...)
+		for _, e := range errs {
+			if r.isTerminal(e.Error) {
+				return nil, "", &routerError
+			}
+		}
+		start = end
+	}
+
+	return nil, "", &routerError
+}
+
+// queryTier races the admitted providers in providers[start:end] and returns the first
+// successful result, or the error of every provider in the tier in routing order
+func (r *Router) queryTier(ctx context.Context, providers []provider.Provider, start, end int, estimates []int, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, string, []ProviderError) {
+	errs := make([]ProviderError, end-start)
+	var admitted []int
+	for i := start; i < end; i++ {
+		name := providerDisplayName(providers[i], i)
+		errs[i-start].ProviderName = name
+		if err := r.admitProvider(ctx, providers[i], name, options, estimates[i]); err != nil {
+			errs[i-start].Error = err
 			continue
 		}
+		admitted = append(admitted, i)
+	}
+	if len(admitted) == 0 {
+		return nil, "", errs
+	}
 
-		return result.Content, result.Model, nil
+	tierCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type tierResult struct {
+		index  int
+		result *provider.QueryResult
+		err    error
+	}
+	// Buffered so that losing providers can always deliver their result and exit
+	results := make(chan tierResult, len(admitted))
+
+	for _, i := range admitted {
+		go func(index int) {
+			p := providers[index]
+			result, err := r.queryProviderWithRetry(tierCtx, p, errs[index-start].ProviderName, messages, options)
+			results <- tierResult{index: index, result: result, err: err}
+		}(i)
 	}
 
-	if len(routerError.Errors) == 0 {
-		return "", "", fmt.Errorf("no providers configured")
+	for range admitted {
+		res := <-results
+		if res.err == nil {
+			return res.result, errs[res.index-start].ProviderName, nil
+		}
+		errs[res.index-start].Error = res.err
 	}
+	return nil, "", errs
+}
 
-	return "", "", &routerError
+// admitProvider checks that the provider can be attempted now: it supports the query's tools,
+// there is enough time left before the deadline and it is within its rate limits. If not, the
+// provider is recorded as skipped and the reason is returned.
+func (r *Router) admitProvider(ctx context.Context, p provider.Provider, name string, options provider.QueryOptions, estimatedTokens int) error {
+	if err := checkToolSupport(p, options); err != nil {
+		r.skipProvider(ctx, p, name, OutcomeSkipped, err)
+		return err
+	}
+
+	// Don't start an attempt that can't finish before the caller's deadline
+	if err := r.checkDeadline(ctx); err != nil {
+		r.skipProvider(ctx, p, name, OutcomeSkipped, err)
+		return err
+	}
+
+	// Check all rate limits
+	err := checkRequestLimits(ctx, p)
+	if err == nil {
+		err = checkTokenBudget(ctx, p, estimatedTokens)
+	}
+	if err != nil {
+		r.skipProvider(ctx, p, name, OutcomeRateLimited, err)
+		return err
+	}
+	return nil
 }
 
-// QueryWithOptions sends a prompt to available LLM providers with advanced options including tool calls.
-// It automatically handles fallback between providers and models within each provider.
+// QueryRace sends the prompt to the top n ranked providers that have remaining quota concurrently
+// and returns the first successful response. The remaining in-flight requests are canceled.
+// A RouterError is returned only if every raced provider fails.
 //
 // Parameters:
 //   - ctx: Context for the request
 //   - messages: Array of chat messages to send (can include file attachments)
 //   - options: Query options including temperature, model, tools, and tool choice
-//
-// Returns:
-//   - result: The query result containing content, model, tool calls, and finish reason
-//   - error: Any error that occurred (nil if successful)
-func (r *Router) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
-	// Convert messages to provider format (including file attachments)
-	providerMessages := make([]provider.Message, len(messages))
-	for i, msg := range messages {
-		providerMessages[i] = provider.Message{
-			Role:    msg.Role,
-			Content: msg.Content,
-			Files:   make([]provider.File, len(msg.Files)),
-		}
-		// Copy file attachments
-		copy(providerMessages[i].Files, msg.Files)
+//   - n: Maximum number of providers to query at the same time (must be at least 1)
+func (r *Router) QueryRace(ctx context.Context, messages []provider.Message, options provider.QueryOptions, n int) (*provider.QueryResult, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("race size must be at least 1, got %d", n)
+	}
+	options = mergeOptions(r.defaults, options)
+
+	ctx, span := r.startQuerySpan(ctx, "Router.QueryRace")
+	defer span.End()
+
+	providers, err := r.candidateProviders(options)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
+	estimates, budgetErr := r.estimateTokenBudgets(ctx, providers, messages)
+	if budgetErr != nil {
+		span.RecordError(budgetErr)
+		return nil, budgetErr
+	}
+
+	providerMessages := copyMessages(messages)
+
 	var routerError RouterError
+	type candidate struct {
+		provider provider.Provider
+		name     string
+	}
+	var candidates []candidate
 
-	for i, provider := range r.providers {
-		providerName := provider.Name()
-		if providerName == "" {
-			providerName = fmt.Sprintf("Provider %d", i+1)
+	for i, p := range providers {
+		if len(candidates) == n {
+			break
 		}
 
-		// Check all rate limits
-		if !provider.HasRemainingRequests(ctx) {
+		name := providerDisplayName(p, i)
+		if err := r.admitProvider(ctx, p, name, options, estimates[i]); err != nil {
 			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("daily request limit exceeded"),
+				ProviderName: name,
+				Error:        err,
 			})
 			continue
 		}
+		candidates = append(candidates, candidate{provider: p, name: name})
+	}
 
-		if !provider.HasRemainingRequestsPerMinute(ctx) {
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("requests per minute limit exceeded"),
-			})
-			continue
+	if len(candidates) == 0 {
+		if len(routerError.Errors) == 0 {
+			err := fmt.Errorf("no providers configured")
+			span.RecordError(err)
+			return nil, err
 		}
+		span.RecordError(&routerError)
+		return nil, &routerError
+	}
 
-		// Estimate tokens for the request (rough approximation)
-		estimatedTokens := estimateTokens(messages)
-		if !provider.HasRemainingTokensPerMinute(ctx, estimatedTokens) {
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("tokens per minute limit exceeded"),
-			})
-			continue
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type raceResult struct {
+		index  int
+		result *provider.QueryResult
+		err    error
+	}
+	// Buffered so that losing providers can always deliver their result and exit
+	results := make(chan raceResult, len(candidates))
+
+	for i, c := range candidates {
+		go func(index int, c candidate) {
+			result, err := r.queryProviderWithRetry(raceCtx, c.provider, c.name, providerMessages, options)
+			results <- raceResult{index: index, result: result, err: err}
+		}(i, c)
+	}
+
+	raceErrors := make([]error, len(candidates))
+	for range candidates {
+		res := <-results
+		if res.err == nil {
+			span.SetAttributes(append(resultAttributes(res.result), provider.Attr("provider.name", candidates[res.index].name))...)
+			return res.result, nil
 		}
+		raceErrors[res.index] = res.err
+	}
 
-		result, err := provider.QueryWithOptions(ctx, providerMessages, options)
-		if err != nil {
-			// Collect the error
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
+	for i, c := range candidates {
+		routerError.Errors = append(routerError.Errors, ProviderError{
+			ProviderName: c.name,
+			Error:        raceErrors[i],
+		})
+	}
+
+	span.RecordError(&routerError)
+	return nil, &routerError
+}
+
+// candidateProviders returns the providers to try for a query in routing order. When the query
+// forces a provider, only the first provider with that name is returned, regardless of rank.
+func (r *Router) candidateProviders(options provider.QueryOptions) ([]provider.Provider, error) {
+	providers := r.orderProviders(r.getProviders())
+	if options.ForceProvider == "" {
+		return providers, nil
+	}
+
+	for _, p := range providers {
+		if p.Name() == options.ForceProvider {
+			return []provider.Provider{p}, nil
+		}
+	}
+	return nil, fmt.Errorf("forced provider %q is not configured", options.ForceProvider)
+}
+
+// providerDisplayName returns the provider's name, or a positional name if it has none
+func providerDisplayName(p provider.Provider, index int) string {
+	if name := p.Name(); name != "" {
+		return name
+	}
+	return fmt.Sprintf("Provider %d", index+1)
+}
+
+// uniqueProviderNames returns the display name of each provider, adding the provider's
+// position to names that are already taken so they can be used as map keys
+func uniqueProviderNames(providers []provider.Provider) []string {
+	names := make([]string, len(providers))
+	taken := make(map[string]bool, len(providers))
+	for i, p := range providers {
+		name := providerDisplayName(p, i)
+		if taken[name] {
+			name = fmt.Sprintf("%s (%d)", name, i+1)
+		}
+		taken[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// checkRateLimits checks the provider's daily, per-minute and token limits for the messages
+func checkRateLimits(ctx context.Context, p provider.Provider, messages []provider.Message) error {
+	if err := checkRequestLimits(ctx, p); err != nil {
+		return err
+	}
+	return checkTokenBudget(ctx, p, estimateTokens(p, messages))
+}
+
+// checkToolSupport returns an error if the query has tools and the provider can't handle them
+func checkToolSupport(p provider.Provider, options provider.QueryOptions) error {
+	if len(options.Tools) == 0 {
+		return nil
+	}
+	if capable, ok := p.(provider.ToolCapable); ok && !capable.SupportsTools() {
+		return fmt.Errorf("%w: %d tools requested", ErrToolsNotSupported, len(options.Tools))
+	}
+	return nil
+}
+
+// checkRequestLimits checks the provider's daily and per-minute request limits
+func checkRequestLimits(ctx context.Context, p provider.Provider) error {
+	if !p.HasRemainingRequests(ctx) {
+		return fmt.Errorf("%w: no daily requests left", ErrRateLimitExceeded)
+	}
+
+	if !p.HasRemainingRequestsPerMinute(ctx) {
+		return fmt.Errorf("%w: no requests left this minute", ErrRateLimitExceeded)
+	}
+
+	return nil
+}
+
+// checkTokenBudget checks that the estimated tokens fit the provider's remaining tokens per minute
+func checkTokenBudget(ctx context.Context, p provider.Provider, estimatedTokens int) error {
+	if !p.HasRemainingTokensPerMinute(ctx, estimatedTokens) {
+		return fmt.Errorf("%w: about %d tokens don't fit the remaining tokens per minute", ErrTokenBudgetExceeded, estimatedTokens)
+	}
+	return nil
+}
+
+// estimateTokenBudgets estimates the request's tokens once for each provider. When no provider
+// can fit its estimate, it also returns a RouterError listing every provider's budget error so
+// that the request is rejected without attempting any of them.
+func (r *Router) estimateTokenBudgets(ctx context.Context, providers []provider.Provider, messages []provider.Message) ([]int, *RouterError) {
+	estimates := make([]int, len(providers))
+	var budgetErrors RouterError
+	for i, p := range providers {
+		estimates[i] = estimateTokens(p, messages)
+		if err := checkTokenBudget(ctx, p, estimates[i]); err != nil {
+			budgetErrors.Errors = append(budgetErrors.Errors, ProviderError{
+				ProviderName: providerDisplayName(p, i),
 				Error:        err,
 			})
-			continue
 		}
+	}
 
-		// Check for empty response and treat as error
-		if result.Content == "" {
-			routerError.Errors = append(routerError.Errors, ProviderError{
-				ProviderName: providerName,
-				Error:        fmt.Errorf("empty response received"),
-			})
-			continue
+	if len(providers) == 0 || len(budgetErrors.Errors) < len(providers) {
+		return estimates, nil
+	}
+	for i, p := range providers {
+		r.skipProvider(ctx, p, providerDisplayName(p, i), OutcomeRateLimited, budgetErrors.Errors[i].Error)
+	}
+	return estimates, &budgetErrors
+}
+
+// queryProvider sends the request to a single provider, recording a provider attempt span and metrics
+func (r *Router) queryProvider(ctx context.Context, p provider.Provider, name string, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	ctx, span := r.tracer.Start(ctx, "Router.ProviderAttempt")
+	defer span.End()
+	span.SetAttributes(attemptAttributes(p, name)...)
+	span.SetAttributes(requestIDAttributes(ctx)...)
+
+	attemptCtx, cancel := queryAttemptContext(ctx, p, options)
+	defer cancel()
+
+	start := time.Now()
+	result, err := p.QueryWithOptions(attemptCtx, messages, options)
+	latency := time.Since(start)
+	r.metrics.ObserveLatency(name, latency)
+	if err == nil {
+		err = r.checkResult(result)
+	}
+	if err != nil {
+		// Attempts canceled by the caller (or by losing a race) say nothing about the provider
+		if ctx.Err() == nil {
+			r.stats.recordFailure(p)
 		}
+		span.RecordError(err)
+		span.SetAttributes(provider.Attr("outcome", OutcomeError))
+		if options.ForceModel != "" {
+			span.SetAttributes(provider.Attr("llm.model", options.ForceModel))
+		}
+		r.metrics.IncRequest(name, options.ForceModel, OutcomeError)
+		r.log(ctx, slog.LevelWarn, "provider attempt failed", slog.String("provider", name), slog.Duration("latency", latency), slog.Any("error", err))
+		return nil, err
+	}
 
-		return result, nil
+	r.stats.recordSuccess(p, latency)
+	result.CostUSD = r.pricing.Cost(result.Model, result.Usage)
+	r.cost.add(result.CostUSD)
+	span.SetAttributes(provider.Attr("outcome", OutcomeSuccess))
+	span.SetAttributes(resultAttributes(result)...)
+	r.metrics.IncRequest(name, result.Model, OutcomeSuccess)
+	r.log(ctx, slog.LevelDebug, "provider attempt succeeded", slog.String("provider", name), slog.String("model", result.Model), slog.Duration("latency", latency))
+	r.recordModelAttempts(ctx, name, result.ModelAttempts)
+	if result.Usage != nil {
+		r.metrics.IncTokens(name, result.Usage.TotalTokens)
+	} else {
+		r.metrics.IncTokens(name, estimateTokens(p, messages))
 	}
+	return result, nil
+}
 
-	if len(routerError.Errors) == 0 {
-		return nil, fmt.Errorf("no providers configured")
+// recordModelAttempts reports the models a provider tried before one served the request, so a
+// primary model that keeps failing over to another shows up in the logs and metrics
+func (r *Router) recordModelAttempts(ctx context.Context, name string, attempts []provider.ModelAttempt) {
+	collector, _ := r.metrics.(ModelAttemptCollector)
+	for _, attempt := range attempts {
+		outcome := OutcomeSuccess
+		if attempt.Error != "" {
+			outcome = OutcomeError
+			r.log(ctx, slog.LevelWarn, "model attempt failed", slog.String("provider", name), slog.String("model", attempt.Model), slog.Duration("latency", attempt.Duration), slog.String("error", attempt.Error))
+		}
+		if collector != nil {
+			collector.IncModelAttempt(name, attempt.Model, outcome)
+		}
 	}
+}
 
-	return nil, &routerError
+// skipProvider records a provider that was not attempted, e.g. because it is out of quota
+func (r *Router) skipProvider(ctx context.Context, p provider.Provider, name string, outcome string, reason error) {
+	_, span := r.tracer.Start(ctx, "Router.ProviderAttempt")
+	span.SetAttributes(attemptAttributes(p, name)...)
+	span.SetAttributes(provider.Attr("outcome", outcome), provider.Attr("skip_reason", reason.Error()))
+	span.SetAttributes(requestIDAttributes(ctx)...)
+	span.End()
+
+	r.metrics.IncRequest(name, "", outcome)
+	r.log(ctx, slog.LevelInfo, "provider skipped", slog.String("provider", name), slog.String("outcome", outcome), slog.Any("reason", reason))
+}
+
+// isTerminal reports whether the error should stop the router from falling back to other providers:
+// API errors with a terminal status (see provider.IsTerminalStatus) and tool failures under
+// ToolFailureAbortQuery
+func (r *Router) isTerminal(err error) bool {
+	if r.alwaysFallback {
+		return false
+	}
+	var apiErr *provider.APIError
+	var toolErr *provider.ToolExecutionError
+	return (errors.As(err, &apiErr) && provider.IsTerminalStatus(apiErr.StatusCode)) || errors.As(err, &toolErr)
+}
+
+// checkResult validates a provider's result according to the router's options
+func (r *Router) checkResult(result *provider.QueryResult) error {
+	// Optionally treat an empty response as a failure so the next provider is tried
+	if r.emptyResponseIsError && result.Content == "" && len(result.ToolCalls) == 0 {
+		return fmt.Errorf("empty response received")
+	}
+	return nil
+}
+
+// copyMessages copies messages (including file attachments) so providers can't modify the caller's slice
+func copyMessages(messages []provider.Message) []provider.Message {
+	providerMessages := make([]provider.Message, len(messages))
+	for i, msg := range messages {
+		providerMessages[i] = msg
+		// Copy file attachments
+		providerMessages[i].Files = make([]provider.File, len(msg.Files))
+		copy(providerMessages[i].Files, msg.Files)
+	}
+	return providerMessages
 }
 
 // HasRemainingRequests checks if any provider has remaining requests.
 // This can be used to check if the router can handle new requests
 // before actually making them.
 func (r *Router) HasRemainingRequests(ctx context.Context) bool {
-	for _, provider := range r.providers {
+	for _, provider := range r.getProviders() {
 		if provider.HasRemainingRequests(ctx) &&
 			provider.HasRemainingRequestsPerMinute(ctx) {
 			// For token limits, we need to estimate tokens, but we don't have messages here
@@ -310,10 +789,21 @@ func (r *Router) HasRemainingRequests(ctx context.Context) bool {
 	return false
 }
 
-// Close closes all providers and releases any resources they hold.
-// This should be called when you're done using the router.
-func (r *Router) Close() {
-	for _, provider := range r.providers {
-		provider.Close()
+// ResetAllLimits clears the rate-limit counters of every provider. It is meant for operational
+// overrides, such as after upgrading an API plan mid-day, and takes effect for the next request.
+func (r *Router) ResetAllLimits() {
+	for _, provider := range r.getProviders() {
+		provider.ResetLimits()
 	}
 }
+
+// Close closes all providers and releases any resources they hold. The built-in providers save
+// their final rate-limit counters to their RateStore before Close returns.
+// This should be called when you're done using the router; later calls do nothing.
+func (r *Router) Close() {
+	r.closeOnce.Do(func() {
+		for _, provider := range r.getProviders() {
+			provider.Close()
+		}
+	})
+}
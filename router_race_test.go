@@ -0,0 +1,116 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestRouter_QueryRaceFirstSuccessWins(t *testing.T) {
+	canceled := make(chan struct{})
+	slow := &mockProvider{name: "slow", rank: 2, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		select {
+		case <-ctx.Done():
+			close(canceled)
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return &provider.QueryResult{Content: "slow", Model: "slow-model"}, nil
+		}
+	}}
+	fast := &mockProvider{name: "fast", rank: 1, content: "fast"}
+
+	router, err := gollmrouter.NewRouter(slow, fast)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryRace(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "fast" {
+		t.Errorf("Expected fast provider to win, got %q", result.Content)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("Expected losing provider's context to be canceled")
+	}
+}
+
+func TestRouter_QueryRaceLimitsToTopN(t *testing.T) {
+	first := &mockProvider{name: "first", rank: 3, content: "first"}
+	second := &mockProvider{name: "second", rank: 2, content: "second"}
+	third := &mockProvider{name: "third", rank: 1, content: "third"}
+
+	router, err := gollmrouter.NewRouter(first, second, third)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryRace(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if third.callCount() != 0 {
+		t.Errorf("Expected provider outside the top 2 not to be called, got %d calls", third.callCount())
+	}
+}
+
+func TestRouter_QueryRaceSkipsExhaustedProviders(t *testing.T) {
+	exhausted := &mockProvider{name: "exhausted", rank: 2, exhausted: true}
+	available := &mockProvider{name: "available", rank: 1, content: "available"}
+
+	router, err := gollmrouter.NewRouter(exhausted, available)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryRace(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "available" {
+		t.Errorf("Expected available provider to answer, got %q", result.Content)
+	}
+	if exhausted.callCount() != 0 {
+		t.Errorf("Expected exhausted provider not to be called, got %d calls", exhausted.callCount())
+	}
+}
+
+func TestRouter_QueryRaceAllFail(t *testing.T) {
+	first := &mockProvider{name: "first", rank: 2, err: errors.New("first failed")}
+	second := &mockProvider{name: "second", rank: 1, err: errors.New("second failed")}
+
+	router, err := gollmrouter.NewRouter(first, second)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	_, err = router.QueryRace(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 2)
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok {
+		t.Fatalf("Expected RouterError, got %v", err)
+	}
+	if len(routerErr.Errors) != 2 {
+		t.Fatalf("Expected 2 provider errors, got %d", len(routerErr.Errors))
+	}
+	if routerErr.Errors[0].ProviderName != "first" || routerErr.Errors[1].ProviderName != "second" {
+		t.Errorf("Expected errors in rank order, got %v", routerErr.Errors)
+	}
+}
+
+func TestRouter_QueryRaceInvalidSize(t *testing.T) {
+	router, err := gollmrouter.NewRouter(&mockProvider{name: "only", content: "hi"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryRace(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}, 0); err == nil {
+		t.Error("Expected error for race size 0")
+	}
+}
@@ -1,12 +1,105 @@
 package gollmrouter_test
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 
 	gollmrouter "github.com/FramnkRulez/go-llm-router"
 	"github.com/FramnkRulez/go-llm-router/provider"
 )
 
+// mockProvider is a configurable in-memory provider for router tests
+type mockProvider struct {
+	mu        sync.Mutex
+	name      string
+	rank      int
+	weight    int
+	content   string
+	err       error
+	calls     int
+	closed    bool
+	queryFn   func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error)
+	exhausted bool
+}
+
+var _ provider.Provider = (*mockProvider)(nil)
+
+func (m *mockProvider) Query(ctx context.Context, messages []provider.Message, temperature float64, forceModel string) (string, string, error) {
+	result, err := m.QueryWithOptions(ctx, messages, provider.QueryOptions{Temperature: temperature, TemperatureSet: true, ForceModel: forceModel})
+	if err != nil {
+		return "", "", err
+	}
+	return result.Content, result.Model, nil
+}
+
+func (m *mockProvider) QueryWithOptions(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+	m.mu.Lock()
+	m.calls++
+	queryFn := m.queryFn
+	m.mu.Unlock()
+
+	if queryFn != nil {
+		return queryFn(ctx, messages, options)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &provider.QueryResult{Content: m.content, Model: m.name + "-model", FinishReason: "stop"}, nil
+}
+
+func (m *mockProvider) HasRemainingRequests(ctx context.Context) bool {
+	return !m.exhausted
+}
+
+func (m *mockProvider) HasRemainingRequestsPerMinute(ctx context.Context) bool {
+	return true
+}
+
+func (m *mockProvider) HasRemainingTokensPerMinute(ctx context.Context, estimatedTokens int) bool {
+	return true
+}
+
+func (m *mockProvider) ResetLimits() {
+	m.exhausted = false
+}
+
+func (m *mockProvider) GetRank() int {
+	return m.rank
+}
+
+func (m *mockProvider) GetWeight() int {
+	return m.weight
+}
+
+func (m *mockProvider) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+}
+
+func (m *mockProvider) Name() string {
+	return m.name
+}
+
+func (m *mockProvider) Models() []string {
+	return []string{m.name + "-model"}
+}
+
+func (m *mockProvider) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+func (m *mockProvider) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
 func TestRouter_NoProviders(t *testing.T) {
 	_, err := gollmrouter.NewRouter()
 	if err == nil {
@@ -23,4 +116,205 @@ func TestRouter_HasRemainingRequests_Empty(t *testing.T) {
 	}
 }
 
-// Add more tests with mock providers as needed
+func TestRouter_AddProvider(t *testing.T) {
+	exhausted := &mockProvider{name: "exhausted", rank: 1, exhausted: true}
+	router, err := gollmrouter.NewRouter(exhausted)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, _, err := router.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0, ""); err == nil {
+		t.Fatal("expected error when the only provider is exhausted")
+	}
+
+	added := &mockProvider{name: "added", content: "hello from added"}
+	router.AddProvider(added)
+
+	response, model, err := router.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0, "")
+	if err != nil {
+		t.Fatalf("Expected query to route to added provider, got error: %v", err)
+	}
+	if response != "hello from added" || model != "added-model" {
+		t.Errorf("Expected response from added provider, got %q from %q", response, model)
+	}
+}
+
+func TestRouter_AddProviderSortsByRank(t *testing.T) {
+	low := &mockProvider{name: "low", rank: 1, content: "low"}
+	router, err := gollmrouter.NewRouter(low)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	high := &mockProvider{name: "high", rank: 5, content: "high"}
+	router.AddProvider(high)
+
+	response, _, err := router.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response != "high" {
+		t.Errorf("Expected higher ranked provider to answer, got %q", response)
+	}
+	if low.callCount() != 0 {
+		t.Errorf("Expected lower ranked provider not to be called, got %d calls", low.callCount())
+	}
+}
+
+func TestRouter_RemoveProvider(t *testing.T) {
+	only := &mockProvider{name: "only", content: "hello"}
+	router, err := gollmrouter.NewRouter(only)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if router.RemoveProvider("unknown") {
+		t.Error("Expected removing an unknown provider to return false")
+	}
+
+	if !router.RemoveProvider("only") {
+		t.Fatal("Expected removing a configured provider to return true")
+	}
+	if !only.isClosed() {
+		t.Error("Expected removed provider to be closed")
+	}
+
+	_, _, err = router.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0, "")
+	if err == nil || !strings.Contains(err.Error(), "no providers configured") {
+		t.Errorf("Expected 'no providers configured' error, got %v", err)
+	}
+}
+
+func TestRouter_ResetAllLimits(t *testing.T) {
+	first := &mockProvider{name: "first", rank: 2, content: "first", exhausted: true}
+	second := &mockProvider{name: "second", rank: 1, content: "second", exhausted: true}
+	router, err := gollmrouter.NewRouter(first, second)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if router.HasRemainingRequests(context.Background()) {
+		t.Fatal("Expected both providers to be exhausted")
+	}
+
+	router.ResetAllLimits()
+	if !first.HasRemainingRequests(context.Background()) || !second.HasRemainingRequests(context.Background()) {
+		t.Error("Expected every provider's limits to be reset")
+	}
+
+	response, _, err := router.Query(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, 0, "")
+	if err != nil || response != "first" {
+		t.Errorf("Expected the top ranked provider to answer after the reset, got %q (%v)", response, err)
+	}
+}
+
+func TestRouter_EmptyResponseReturnedByDefault(t *testing.T) {
+	empty := &mockProvider{name: "empty", rank: 2}
+	fallback := &mockProvider{name: "fallback", rank: 1, content: "fallback response"}
+
+	router, err := gollmrouter.NewRouter(empty, fallback)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "" || result.Model != "empty-model" {
+		t.Errorf("Expected empty response from first provider, got %q from %q", result.Content, result.Model)
+	}
+	if fallback.callCount() != 0 {
+		t.Errorf("Expected fallback provider not to be called, got %d calls", fallback.callCount())
+	}
+}
+
+func TestRouter_EmptyResponseIsError(t *testing.T) {
+	empty := &mockProvider{name: "empty", rank: 2}
+	fallback := &mockProvider{name: "fallback", rank: 1, content: "fallback response"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{empty, fallback}, gollmrouter.WithEmptyResponseIsError(true))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "fallback response" {
+		t.Errorf("Expected fallback response, got %q", result.Content)
+	}
+
+	// With only the empty provider the router reports the empty response as a provider error
+	router, err = gollmrouter.NewRouterWithOptions([]provider.Provider{empty}, gollmrouter.WithEmptyResponseIsError(true))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok {
+		t.Fatalf("Expected RouterError, got %v", err)
+	}
+	if len(routerErr.Errors) != 1 || !strings.Contains(routerErr.Errors[0].Error.Error(), "empty response") {
+		t.Errorf("Expected a single empty response error, got %v", routerErr.Errors)
+	}
+}
+
+func TestRouter_EmptyResponseWithToolCallsIsNotError(t *testing.T) {
+	toolCalling := &mockProvider{name: "tools", queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		return &provider.QueryResult{
+			Model:     "tools-model",
+			ToolCalls: []provider.ToolCall{{ID: "call_1", Type: "function", Function: provider.ToolCallFunction{Name: "calculate"}}},
+		}, nil
+	}}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{toolCalling}, gollmrouter.WithEmptyResponseIsError(true))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ToolCalls) != 1 {
+		t.Errorf("Expected tool calls to be returned, got %d", len(result.ToolCalls))
+	}
+}
+
+func TestRouter_ForceProvider(t *testing.T) {
+	var forcedModel string
+	top := &mockProvider{name: "cloud", rank: 10, content: "from cloud"}
+	onPrem := &mockProvider{name: "ollama", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		forcedModel = options.ForceModel
+		return nil, errors.New("ollama is down")
+	}}
+	router, err := gollmrouter.NewRouter(top, onPrem)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{
+		ForceProvider: "ollama",
+		ForceModel:    "llama3",
+	})
+	if err == nil || !strings.Contains(err.Error(), "ollama is down") {
+		t.Fatalf("Expected the forced provider's error without fallback, got %v", err)
+	}
+	if top.callCount() != 0 {
+		t.Error("Expected the higher-ranked provider to be bypassed")
+	}
+	if onPrem.callCount() != 1 || forcedModel != "llama3" {
+		t.Errorf("Expected the forced provider to be called once with the forced model, got %d calls with %q", onPrem.callCount(), forcedModel)
+	}
+
+	_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{ForceProvider: "missing"})
+	if err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("Expected an error naming the unknown provider, got %v", err)
+	}
+	if top.callCount() != 0 || onPrem.callCount() != 1 {
+		t.Error("Expected no provider to be called for an unknown forced provider")
+	}
+}
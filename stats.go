@@ -0,0 +1,95 @@
+package gollmrouter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// latencyEWMAAlpha is the weight of the newest sample in the latency moving average
+const latencyEWMAAlpha = 0.3
+
+// ProviderStats summarizes the requests a router has sent to a provider
+type ProviderStats struct {
+	Successes int64
+	Failures  int64
+	// LatencyEWMA is the exponentially weighted moving average of successful response times,
+	// zero until the first success
+	LatencyEWMA time.Duration
+}
+
+// statsTracker records per-provider statistics
+type statsTracker struct {
+	mu    sync.Mutex
+	stats map[provider.Provider]*ProviderStats
+}
+
+// entryLocked returns the provider's stats, creating them if needed
+func (s *statsTracker) entryLocked(p provider.Provider) *ProviderStats {
+	if s.stats == nil {
+		s.stats = make(map[provider.Provider]*ProviderStats)
+	}
+	entry, ok := s.stats[p]
+	if !ok {
+		entry = &ProviderStats{}
+		s.stats[p] = entry
+	}
+	return entry
+}
+
+// recordSuccess counts a success and folds its latency into the moving average
+func (s *statsTracker) recordSuccess(p provider.Provider, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Zero means "not measured", so very fast responses on coarse clocks still count
+	if latency <= 0 {
+		latency = 1
+	}
+
+	entry := s.entryLocked(p)
+	entry.Successes++
+	if entry.LatencyEWMA == 0 {
+		entry.LatencyEWMA = latency
+	} else {
+		entry.LatencyEWMA = time.Duration(latencyEWMAAlpha*float64(latency) + (1-latencyEWMAAlpha)*float64(entry.LatencyEWMA))
+	}
+}
+
+// recordFailure counts a failed attempt
+func (s *statsTracker) recordFailure(p provider.Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(p).Failures++
+}
+
+// get returns a copy of the provider's stats
+func (s *statsTracker) get(p provider.Provider) ProviderStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.stats[p]; ok {
+		return *entry
+	}
+	return ProviderStats{}
+}
+
+// remove forgets a provider's stats
+func (s *statsTracker) remove(p provider.Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.stats, p)
+}
+
+// Stats returns the statistics of every configured provider by name.
+// Providers sharing a name are told apart by their position in the router.
+func (r *Router) Stats() map[string]ProviderStats {
+	providers := r.getProviders()
+	names := uniqueProviderNames(providers)
+
+	stats := make(map[string]ProviderStats, len(providers))
+	for i, p := range providers {
+		stats[names[i]] = r.stats.get(p)
+	}
+	return stats
+}
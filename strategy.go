@@ -0,0 +1,157 @@
+package gollmrouter
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// Strategy controls how the router orders providers that share the same rank.
+// Providers are always grouped by rank (highest first); the strategy only decides
+// the order of attempts within a group.
+type Strategy int
+
+const (
+	// StrategyPriority tries providers of the same rank in the order they were added (default)
+	StrategyPriority Strategy = iota
+	// StrategyRoundRobin rotates which provider of the same rank is tried first on each request
+	StrategyRoundRobin
+	// StrategyWeighted picks the first provider of the same rank at random, proportionally to its weight
+	StrategyWeighted
+	// StrategyLatency tries providers of the same rank fastest first, by the moving average of their
+	// successful response times. Providers without a measurement yet are tried first so they get one.
+	StrategyLatency
+	// StrategyTieredParallel queries all providers of the same rank at the same time and uses the
+	// first successful response, canceling the others. Lower ranks are tried only if the whole
+	// rank fails.
+	StrategyTieredParallel
+)
+
+// String returns the name of the strategy
+func (s Strategy) String() string {
+	switch s {
+	case StrategyPriority:
+		return "priority"
+	case StrategyRoundRobin:
+		return "round-robin"
+	case StrategyWeighted:
+		return "weighted"
+	case StrategyLatency:
+		return "latency"
+	case StrategyTieredParallel:
+		return "tiered-parallel"
+	default:
+		return "unknown"
+	}
+}
+
+// WithStrategy sets how the router distributes requests across providers of the same rank
+func WithStrategy(strategy Strategy) RouterOption {
+	return func(r *Router) {
+		r.strategy = strategy
+	}
+}
+
+// WithLatencyAcrossRanks makes StrategyLatency order all providers by latency, ignoring their rank
+func WithLatencyAcrossRanks(enabled bool) RouterOption {
+	return func(r *Router) {
+		r.latencyAcrossRanks = enabled
+	}
+}
+
+// providerWeight returns the provider's load-balancing weight (at least 1)
+func providerWeight(p provider.Provider) int {
+	if weighted, ok := p.(provider.Weighted); ok && weighted.GetWeight() > 0 {
+		return weighted.GetWeight()
+	}
+	return 1
+}
+
+// orderProviders returns the providers in the order they should be attempted for a request.
+// The input must already be sorted by rank.
+func (r *Router) orderProviders(providers []provider.Provider) []provider.Provider {
+	// Providers of the same rank are queried together by StrategyTieredParallel, so their order
+	// only affects how their errors are reported
+	if r.strategy == StrategyPriority || r.strategy == StrategyTieredParallel || len(providers) < 2 {
+		return providers
+	}
+
+	if r.strategy == StrategyLatency && r.latencyAcrossRanks {
+		return r.latencyOrder(providers)
+	}
+
+	var offset int
+	if r.strategy == StrategyRoundRobin {
+		offset = int(r.requestCounter.Add(1) - 1)
+	}
+
+	ordered := make([]provider.Provider, 0, len(providers))
+	for start := 0; start < len(providers); {
+		end := start + 1
+		for end < len(providers) && providers[end].GetRank() == providers[start].GetRank() {
+			end++
+		}
+
+		tier := providers[start:end]
+		switch r.strategy {
+		case StrategyRoundRobin:
+			shift := offset % len(tier)
+			ordered = append(ordered, tier[shift:]...)
+			ordered = append(ordered, tier[:shift]...)
+		case StrategyWeighted:
+			ordered = append(ordered, weightedOrder(tier)...)
+		case StrategyLatency:
+			ordered = append(ordered, r.latencyOrder(tier)...)
+		default:
+			ordered = append(ordered, tier...)
+		}
+		start = end
+	}
+
+	return ordered
+}
+
+// weightedOrder orders providers by weighted random sampling without replacement,
+// so the remaining providers are still available for fallback
+func weightedOrder(tier []provider.Provider) []provider.Provider {
+	remaining := make([]provider.Provider, len(tier))
+	copy(remaining, tier)
+
+	ordered := make([]provider.Provider, 0, len(tier))
+	for len(remaining) > 0 {
+		total := 0
+		for _, p := range remaining {
+			total += providerWeight(p)
+		}
+
+		pick := rand.Intn(total)
+		for i, p := range remaining {
+			pick -= providerWeight(p)
+			if pick < 0 {
+				ordered = append(ordered, p)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return ordered
+}
+
+// latencyOrder orders providers by their latency moving average, fastest first.
+// Providers without a measurement keep their relative order ahead of the measured ones.
+func (r *Router) latencyOrder(providers []provider.Provider) []provider.Provider {
+	latencies := make(map[provider.Provider]time.Duration, len(providers))
+	for _, p := range providers {
+		latencies[p] = r.stats.get(p).LatencyEWMA
+	}
+
+	ordered := make([]provider.Provider, len(providers))
+	copy(ordered, providers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return latencies[ordered[i]] < latencies[ordered[j]]
+	})
+	return ordered
+}
@@ -0,0 +1,305 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func TestStrategyPriorityIsDefault(t *testing.T) {
+	first := &mockProvider{name: "first", rank: 1, content: "first"}
+	second := &mockProvider{name: "second", rank: 1, content: "second"}
+
+	router, err := gollmrouter.NewRouter(first, second)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if first.callCount() != 10 || second.callCount() != 0 {
+		t.Errorf("Expected all requests to go to the first provider, got %d/%d", first.callCount(), second.callCount())
+	}
+}
+
+func TestStrategyRoundRobin(t *testing.T) {
+	premium := &mockProvider{name: "premium", rank: 2, exhausted: true}
+	keys := []*mockProvider{
+		{name: "key1", rank: 1, content: "key1"},
+		{name: "key2", rank: 1, content: "key2"},
+		{name: "key3", rank: 1, content: "key3"},
+	}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{premium, keys[0], keys[1], keys[2]}, gollmrouter.WithStrategy(gollmrouter.StrategyRoundRobin))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	for i := 0; i < 300; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	for _, key := range keys {
+		if key.callCount() != 100 {
+			t.Errorf("Expected %s to receive 100 requests, got %d", key.name, key.callCount())
+		}
+	}
+}
+
+func TestStrategyWeighted(t *testing.T) {
+	light := &mockProvider{name: "light", rank: 1, weight: 1, content: "light"}
+	heavy := &mockProvider{name: "heavy", rank: 1, weight: 3, content: "heavy"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{light, heavy}, gollmrouter.WithStrategy(gollmrouter.StrategyWeighted))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	const total = 4000
+	for i := 0; i < total; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Expect a 1:3 split, allowing some slack for randomness
+	share := float64(heavy.callCount()) / total
+	if share < 0.7 || share > 0.8 {
+		t.Errorf("Expected heavy provider to receive ~75%% of requests, got %.2f (%d/%d)", share, heavy.callCount(), light.callCount())
+	}
+}
+
+func TestStrategyWeightedFallsBackWithinTier(t *testing.T) {
+	failing := &mockProvider{name: "failing", rank: 1, weight: 100, err: context.DeadlineExceeded}
+	working := &mockProvider{name: "working", rank: 1, weight: 1, content: "working"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{failing, working}, gollmrouter.WithStrategy(gollmrouter.StrategyWeighted))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Content != "working" {
+			t.Errorf("Expected fallback to working provider, got %q", result.Content)
+		}
+	}
+}
+
+// delayedProvider returns a mock provider that answers after the given delay
+func delayedProvider(name string, rank int, delay time.Duration) *mockProvider {
+	return &mockProvider{name: name, rank: rank, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		time.Sleep(delay)
+		return &provider.QueryResult{Content: name, Model: name + "-model"}, nil
+	}}
+}
+
+func TestStrategyLatency(t *testing.T) {
+	slow := delayedProvider("slow", 1, 40*time.Millisecond)
+	fast := delayedProvider("fast", 1, time.Millisecond)
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{slow, fast}, gollmrouter.WithStrategy(gollmrouter.StrategyLatency))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Each provider is measured once, then the faster one takes the rest
+	if slow.callCount() != 1 || fast.callCount() != 9 {
+		t.Errorf("Expected ordering to converge on the fast provider, got slow=%d fast=%d", slow.callCount(), fast.callCount())
+	}
+
+	stats := router.Stats()
+	if stats["fast"].LatencyEWMA == 0 || stats["fast"].LatencyEWMA >= stats["slow"].LatencyEWMA {
+		t.Errorf("Expected the fast provider to have the lower latency average, got %+v", stats)
+	}
+	if stats["fast"].Successes != 9 || stats["slow"].Successes != 1 {
+		t.Errorf("Expected success counts in stats, got %+v", stats)
+	}
+}
+
+func TestStrategyLatencyRespectsRank(t *testing.T) {
+	premium := delayedProvider("premium", 2, 20*time.Millisecond)
+	fast := delayedProvider("fast", 1, time.Millisecond)
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{premium, fast}, gollmrouter.WithStrategy(gollmrouter.StrategyLatency))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if premium.callCount() != 3 {
+		t.Errorf("Expected the higher rank to be preferred within tiers, premium called %d times", premium.callCount())
+	}
+
+	// Across ranks, the faster provider wins once measured
+	premium = delayedProvider("premium", 2, 20*time.Millisecond)
+	fast = delayedProvider("fast", 1, time.Millisecond)
+	router, err = gollmrouter.NewRouterWithOptions([]provider.Provider{premium, fast},
+		gollmrouter.WithStrategy(gollmrouter.StrategyLatency),
+		gollmrouter.WithLatencyAcrossRanks(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	// Measure both providers, then route by latency
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if premium.callCount() != 1 || fast.callCount() != 3 {
+		t.Errorf("Expected latency to override rank, got premium=%d fast=%d", premium.callCount(), fast.callCount())
+	}
+}
+
+func TestStrategyTieredParallel_QueriesTierConcurrently(t *testing.T) {
+	// Both providers of the top tier must be in flight at once for either to answer
+	var started sync.WaitGroup
+	started.Add(2)
+	canceled := make(chan struct{})
+
+	fast := &mockProvider{name: "fast", rank: 2, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		started.Done()
+		started.Wait()
+		return &provider.QueryResult{Content: "fast"}, nil
+	}}
+	slow := &mockProvider{name: "slow", rank: 2, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		started.Done()
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}}
+	backup := &mockProvider{name: "backup", rank: 1, content: "backup"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{slow, fast, backup}, gollmrouter.WithStrategy(gollmrouter.StrategyTieredParallel))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "fast" {
+		t.Errorf("Expected the first successful response, got %q", result.Content)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slower provider of the tier to be canceled")
+	}
+	if backup.callCount() != 0 {
+		t.Errorf("Expected the lower tier not to be queried, got %d calls", backup.callCount())
+	}
+}
+
+func TestStrategyTieredParallel_FallsOverBetweenTiers(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+
+	primary := &mockProvider{name: "primary", rank: 2, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		time.Sleep(20 * time.Millisecond)
+		record("primary")
+		return nil, errors.New("primary down")
+	}}
+	secondary := &mockProvider{name: "secondary", rank: 2, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		record("secondary")
+		return nil, errors.New("secondary down")
+	}}
+	backup := &mockProvider{name: "backup", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		record("backup")
+		return &provider.QueryResult{Content: "backup"}, nil
+	}}
+	unused := &mockProvider{name: "unused", rank: 0, content: "unused"}
+
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{primary, secondary, backup, unused}, gollmrouter.WithStrategy(gollmrouter.StrategyTieredParallel))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "backup" {
+		t.Errorf("Expected the lower tier to answer, got %q", result.Content)
+	}
+	// The lower tier starts only after every provider of the top tier has failed
+	if len(order) != 3 || order[2] != "backup" {
+		t.Errorf("Expected the top tier to finish before the lower tier, got %v", order)
+	}
+	if unused.callCount() != 0 {
+		t.Errorf("Expected tiers below the winner not to be queried, got %d calls", unused.callCount())
+	}
+
+	// When every tier fails, the errors are reported in routing order
+	backup.queryFn = nil
+	backup.err = errors.New("backup down")
+	unused.err = errors.New("unused down")
+	_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{})
+	var routerErr *gollmrouter.RouterError
+	if !errors.As(err, &routerErr) {
+		t.Fatalf("Expected a RouterError, got %v", err)
+	}
+	var names []string
+	for _, e := range routerErr.Errors {
+		names = append(names, e.ProviderName)
+	}
+	if len(names) != 4 || names[0] != "primary" || names[1] != "secondary" || names[2] != "backup" || names[3] != "unused" {
+		t.Errorf("Expected errors in routing order, got %v", names)
+	}
+}
+
+func TestRouter_StatsCountsFailures(t *testing.T) {
+	failing := &mockProvider{name: "failing", rank: 2, err: context.DeadlineExceeded}
+	working := &mockProvider{name: "working", rank: 1, content: "ok"}
+
+	router, err := gollmrouter.NewRouter(failing, working)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stats := router.Stats()
+	if stats["failing"].Failures != 1 || stats["failing"].Successes != 0 || stats["failing"].LatencyEWMA != 0 {
+		t.Errorf("Unexpected stats for the failing provider: %+v", stats["failing"])
+	}
+	if stats["working"].Successes != 1 || stats["working"].LatencyEWMA == 0 {
+		t.Errorf("Unexpected stats for the working provider: %+v", stats["working"])
+	}
+}
@@ -0,0 +1,94 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// newTokenCappedProvider creates a function calling provider with a tokens-per-minute cap
+func newTokenCappedProvider(t *testing.T, url string, maxTokens int, rank int) provider.Provider {
+	t.Helper()
+
+	p, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:             "test-key",
+		URL:                url,
+		Models:             []string{"test-model"},
+		MaxTokensPerMinute: maxTokens,
+		Rank:               rank,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return p
+}
+
+func TestRouter_RejectsRequestsOverEveryTokenBudget(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	router, err := gollmrouter.NewRouter(
+		newTokenCappedProvider(t, server.URL, 100, 2),
+		newTokenCappedProvider(t, server.URL, 500, 1),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	// About 2,500 tokens, more than either provider allows per minute
+	messages := []provider.Message{{Role: "user", Content: strings.Repeat("word ", 2000)}}
+	_, err = router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if !errors.Is(err, gollmrouter.ErrTokenBudgetExceeded) {
+		t.Fatalf("Expected ErrTokenBudgetExceeded, got %v", err)
+	}
+
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok || len(routerErr.Errors) != 2 {
+		t.Errorf("Expected one budget error per provider, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no provider to be attempted, got %d requests", requests.Load())
+	}
+
+	_, err = router.QueryRace(context.Background(), messages, provider.QueryOptions{}, 2)
+	if !errors.Is(err, gollmrouter.ErrTokenBudgetExceeded) || requests.Load() != 0 {
+		t.Errorf("Expected QueryRace to reject the request without attempts, got %v", err)
+	}
+}
+
+func TestRouter_SkipsProvidersWhoseTokenBudgetIsTooSmall(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	router, err := gollmrouter.NewRouter(
+		newTokenCappedProvider(t, server.URL, 100, 2),
+		newTokenCappedProvider(t, server.URL, 10000, 1),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	messages := []provider.Message{{Role: "user", Content: strings.Repeat("word ", 2000)}}
+	result, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Expected the provider with a large enough budget to answer, got %v", err)
+	}
+	if result.Content != "ok" || requests.Load() != 1 {
+		t.Errorf("Expected a single request to the second provider, got %+v after %d requests", result, requests.Load())
+	}
+}
@@ -0,0 +1,90 @@
+package gollmrouter_test
+
+import (
+	"strings"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+func TestHeuristicEstimatorAgainstKnownCounts(t *testing.T) {
+	// Token counts below are from the cl100k_base tokenizer
+	testCases := []struct {
+		name   string
+		prompt string
+		tokens int
+	}{
+		{"Pangram", "The quick brown fox jumps over the lazy dog.", 10},
+		{"Question", "What is the capital of France?", 7},
+		{"Greeting", "Hello, world! How are you today?", 9},
+		{"Sentence", "Large language models predict the next token in a sequence of text.", 13},
+	}
+
+	estimator := gollmrouter.HeuristicEstimator{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			estimate := estimator.EstimateTokens([]gollmrouter.Message{
+				gollmrouter.NewMessage("user", tc.prompt),
+			})
+
+			// The heuristic should land within 40% of the real count
+			tolerance := float64(tc.tokens) * 0.4
+			if diff := float64(estimate - tc.tokens); diff > tolerance || diff < -tolerance {
+				t.Errorf("Expected estimate within %.1f of %d tokens, got %d", tolerance, tc.tokens, estimate)
+			}
+		})
+	}
+}
+
+func TestHeuristicEstimatorIncludesFiles(t *testing.T) {
+	estimator := gollmrouter.HeuristicEstimator{}
+	file := gollmrouter.NewFileAttachment("image", "image/png", "test.png", make([]byte, 400))
+
+	withoutFile := estimator.EstimateTokens([]gollmrouter.Message{gollmrouter.NewMessage("user", "describe this")})
+	withFile := estimator.EstimateTokens([]gollmrouter.Message{gollmrouter.NewMessage("user", "describe this", file)})
+
+	if withFile-withoutFile != 100 {
+		t.Errorf("Expected file to add 100 tokens, got %d", withFile-withoutFile)
+	}
+}
+
+func TestEncoderEstimator(t *testing.T) {
+	// Whitespace tokenizer standing in for a real BPE encoder
+	estimator := gollmrouter.NewEncoderEstimator(func(text string) []int {
+		return make([]int, len(strings.Fields(text)))
+	})
+
+	estimate := estimator.EstimateTokens([]gollmrouter.Message{
+		gollmrouter.NewMessage("system", "You are helpful"),
+		gollmrouter.NewMessage("user", "What is the capital of France?"),
+	})
+
+	if estimate != 9 {
+		t.Errorf("Expected 9 tokens, got %d", estimate)
+	}
+}
+
+func TestProviderUsesConfiguredTokenEstimator(t *testing.T) {
+	estimator := gollmrouter.NewEncoderEstimator(func(text string) []int {
+		return make([]int, 1000)
+	})
+
+	p, err := gollmrouter.NewOpenRouterProvider(gollmrouter.OpenRouterConfig{
+		APIKey:         "test-key",
+		Models:         []string{"test-model"},
+		TokenEstimator: estimator,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create OpenRouter provider: %v", err)
+	}
+	defer p.Close()
+
+	tokenEstimator, ok := p.(gollmrouter.TokenEstimator)
+	if !ok {
+		t.Fatal("Expected provider to implement TokenEstimator")
+	}
+
+	if got := tokenEstimator.EstimateTokens([]gollmrouter.Message{gollmrouter.NewMessage("user", "hi")}); got != 1000 {
+		t.Errorf("Expected provider to use the configured estimator (1000 tokens), got %d", got)
+	}
+}
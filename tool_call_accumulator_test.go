@@ -0,0 +1,125 @@
+package gollmrouter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+// parseDeltas decodes tool call deltas as they appear in streamed chunks
+func parseDeltas(t *testing.T, chunks ...string) []gollmrouter.ToolCallDelta {
+	t.Helper()
+
+	deltas := make([]gollmrouter.ToolCallDelta, 0, len(chunks))
+	for _, chunk := range chunks {
+		var delta gollmrouter.ToolCallDelta
+		if err := json.Unmarshal([]byte(chunk), &delta); err != nil {
+			t.Fatalf("Invalid test chunk %s: %v", chunk, err)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
+
+func TestToolCallAccumulator_AssemblesFragments(t *testing.T) {
+	deltas := parseDeltas(t,
+		`{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}`,
+		`{"index":0,"function":{"arguments":"{\"loc"}}`,
+		`{"index":0,"function":{"arguments":"ation\": \"Par"}}`,
+		`{"index":0,"function":{"arguments":"is\", \"days\": 3"}}`,
+		`{"index":0,"function":{"arguments":"}"}}`,
+	)
+
+	accumulator := gollmrouter.NewToolCallAccumulator()
+	var completed []gollmrouter.ToolCall
+	for i, delta := range deltas {
+		if call, ok := accumulator.Add(delta); ok {
+			if i != len(deltas)-1 {
+				t.Errorf("Expected the call to complete on the last fragment, completed on fragment %d", i)
+			}
+			completed = append(completed, call)
+		}
+	}
+
+	if len(completed) != 1 {
+		t.Fatalf("Expected 1 completed tool call, got %d", len(completed))
+	}
+	call := completed[0]
+	if call.ID != "call_1" || call.Type != "function" || call.Function.Name != "get_weather" {
+		t.Errorf("Unexpected tool call: %+v", call)
+	}
+	if call.Function.Arguments["location"] != "Paris" || call.Function.Arguments["days"] != float64(3) {
+		t.Errorf("Unexpected arguments: %v", call.Function.Arguments)
+	}
+
+	calls, err := accumulator.ToolCalls()
+	if err != nil || len(calls) != 1 || calls[0].Function.Arguments["location"] != "Paris" {
+		t.Errorf("Expected the final tool calls to match, got %+v (err %v)", calls, err)
+	}
+}
+
+func TestToolCallAccumulator_InterleavedCalls(t *testing.T) {
+	deltas := parseDeltas(t,
+		`{"index":0,"id":"call_a","function":{"name":"first","arguments":"{\"n\":"}}`,
+		`{"index":1,"id":"call_b","function":{"name":"second","arguments":"{\"m\":"}}`,
+		`{"index":1,"function":{"arguments":"2}"}}`,
+		`{"index":0,"function":{"arguments":"1}"}}`,
+	)
+
+	accumulator := gollmrouter.NewToolCallAccumulator()
+	var order []string
+	for _, delta := range deltas {
+		if call, ok := accumulator.Add(delta); ok {
+			order = append(order, call.ID)
+		}
+	}
+	if len(order) != 2 || order[0] != "call_b" || order[1] != "call_a" {
+		t.Errorf("Expected calls to complete as their arguments finish, got %v", order)
+	}
+
+	calls, err := accumulator.ToolCalls()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(calls) != 2 || calls[0].Function.Name != "first" || calls[1].Function.Name != "second" {
+		t.Errorf("Expected calls in the order they started, got %+v", calls)
+	}
+	if calls[0].Function.Arguments["n"] != float64(1) || calls[1].Function.Arguments["m"] != float64(2) {
+		t.Errorf("Expected fragments to be matched by index, got %+v", calls)
+	}
+}
+
+func TestToolCallAccumulator_ReusedIndexWithNewID(t *testing.T) {
+	// Some APIs send every call with index 0 and tell them apart by id
+	deltas := parseDeltas(t,
+		`{"index":0,"id":"call_a","function":{"name":"first","arguments":"{}"}}`,
+		`{"index":0,"id":"call_b","function":{"name":"second","arguments":"{\"x\":true}"}}`,
+	)
+
+	accumulator := gollmrouter.NewToolCallAccumulator()
+	for _, delta := range deltas {
+		accumulator.Add(delta)
+	}
+
+	calls, err := accumulator.ToolCalls()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(calls) != 2 || calls[0].ID != "call_a" || calls[1].ID != "call_b" || calls[1].Function.Arguments["x"] != true {
+		t.Errorf("Expected two separate calls, got %+v", calls)
+	}
+}
+
+func TestToolCallAccumulator_IncompleteArguments(t *testing.T) {
+	accumulator := gollmrouter.NewToolCallAccumulator()
+	for _, delta := range parseDeltas(t, `{"index":0,"id":"call_1","function":{"name":"cut_off","arguments":"{\"a\": 1"}}`) {
+		if _, ok := accumulator.Add(delta); ok {
+			t.Error("Expected incomplete arguments not to produce a tool call")
+		}
+	}
+
+	if _, err := accumulator.ToolCalls(); err == nil {
+		t.Error("Expected an error for a stream that ended mid-arguments")
+	}
+}
@@ -0,0 +1,102 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/ai"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+func greetTool(description string) provider.Tool {
+	return gollmrouter.NewTool("greet", description, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+		},
+	})
+}
+
+func TestSimpleToolExecutor_RegisterTool(t *testing.T) {
+	executor := ai.NewSimpleToolExecutor()
+	builtIn := len(executor.GetAvailableTools())
+
+	executor.RegisterTool(greetTool("Greet someone"), func(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+		return &provider.ToolCallResult{ID: toolCall.ID, Type: "function", Content: "Hello, " + toolCall.Function.Arguments["name"].(string)}, nil
+	})
+
+	tools := executor.GetAvailableTools()
+	if len(tools) != builtIn+1 || tools[len(tools)-1].Function.Name != "greet" {
+		t.Fatalf("Expected greet tool to be listed after the built-ins, got %+v", tools)
+	}
+
+	result, err := executor.ExecuteTool(context.Background(), provider.ToolCall{
+		ID:       "call_1",
+		Function: provider.ToolCallFunction{Name: "greet", Arguments: map[string]interface{}{"name": "Ada"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "Hello, Ada" {
+		t.Errorf("Expected registered handler result, got %v", result.Content)
+	}
+
+	// Built-in tools still work
+	if _, err := executor.ExecuteTool(context.Background(), provider.ToolCall{
+		Function: provider.ToolCallFunction{Name: "calculate", Arguments: map[string]interface{}{"expression": "1 + 1"}},
+	}); err != nil {
+		t.Errorf("Expected built-in tool to still work, got %v", err)
+	}
+}
+
+func TestSimpleToolExecutor_ReRegisterOverwrites(t *testing.T) {
+	executor := ai.NewSimpleToolExecutor()
+	builtIn := len(executor.GetAvailableTools())
+
+	executor.RegisterTool(greetTool("first"), func(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+		return &provider.ToolCallResult{Content: "first"}, nil
+	})
+	executor.RegisterTool(greetTool("second"), func(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+		return &provider.ToolCallResult{Content: "second"}, nil
+	})
+
+	tools := executor.GetAvailableTools()
+	if len(tools) != builtIn+1 {
+		t.Fatalf("Expected re-registering not to duplicate the tool, got %d tools", len(tools))
+	}
+	if tools[len(tools)-1].Function.Description != "second" {
+		t.Errorf("Expected tool definition to be replaced, got %q", tools[len(tools)-1].Function.Description)
+	}
+
+	result, err := executor.ExecuteTool(context.Background(), provider.ToolCall{Function: provider.ToolCallFunction{Name: "greet"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "second" {
+		t.Errorf("Expected the latest handler to be used, got %v", result.Content)
+	}
+}
+
+func TestSimpleToolExecutor_UnregisterTool(t *testing.T) {
+	executor := ai.NewSimpleToolExecutor()
+	builtIn := len(executor.GetAvailableTools())
+
+	executor.RegisterTool(greetTool("Greet someone"), func(ctx context.Context, toolCall provider.ToolCall) (*provider.ToolCallResult, error) {
+		return &provider.ToolCallResult{Content: "hi"}, nil
+	})
+	executor.UnregisterTool("greet")
+
+	if len(executor.GetAvailableTools()) != builtIn {
+		t.Errorf("Expected greet tool to be removed")
+	}
+	if _, err := executor.ExecuteTool(context.Background(), provider.ToolCall{Function: provider.ToolCallFunction{Name: "greet"}}); err == nil {
+		t.Error("Expected error calling an unregistered tool")
+	}
+
+	// Built-in tools cannot be unregistered
+	executor.UnregisterTool("calculate")
+	if len(executor.GetAvailableTools()) != builtIn {
+		t.Errorf("Expected built-in tools to remain")
+	}
+}
@@ -0,0 +1,177 @@
+package gollmrouter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewToolFromStruct creates a tool whose parameters schema is generated from the fields of
+// argStruct, a struct value or pointer. Fields are named by their json tag and are required
+// unless they are pointers or tagged omitempty. A `desc` tag sets a field's description and an
+// `enum` tag its comma-separated allowed values:
+//
+//	type WeatherArgs struct {
+//		City  string `json:"city" desc:"City name"`
+//		Units string `json:"units,omitempty" enum:"celsius,fahrenheit"`
+//	}
+//	tool := gollmrouter.NewToolFromStruct("get_weather", "Get the current weather", WeatherArgs{})
+//
+// Nested structs, slices, arrays, maps with string keys and time.Time are supported.
+// It panics if argStruct is not a struct, since that is a programming error.
+func NewToolFromStruct(name, description string, argStruct interface{}) Tool {
+	t := reflect.TypeOf(argStruct)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gollmrouter: NewToolFromStruct needs a struct, got %T", argStruct))
+	}
+	return NewTool(name, description, structSchema(t, map[reflect.Type]bool{}))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structSchema returns the object schema of a struct type. visiting holds the structs being
+// generated, so recursive types end in a plain object instead of looping forever.
+func structSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	if visiting[t] {
+		return map[string]interface{}{"type": "object"}
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := map[string]interface{}{}
+	required := []string{}
+	addStructFields(t, properties, &required, visiting)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addStructFields adds the schemas of t's fields to properties, following encoding/json's rules
+// for names, ignored fields and embedded structs
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			// Fields of embedded structs are promoted, as encoding/json does
+			if fieldType.Kind() == reflect.Struct {
+				addStructFields(fieldType, properties, required, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := typeSchema(field.Type, visiting)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			schema["description"] = desc
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			// The allowed values of a slice field apply to its items
+			target := schema
+			if items, ok := schema["items"].(map[string]interface{}); ok {
+				target = items
+			}
+			target["enum"] = enumValues(enum, field.Type)
+		}
+		properties[name] = schema
+
+		if field.Type.Kind() != reflect.Pointer && !strings.Contains(","+options+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// typeSchema returns the schema of a field type
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json sends byte slices as base64 strings
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), visiting)}
+		}
+		return map[string]interface{}{"type": "object"}
+	case reflect.Struct:
+		return structSchema(t, visiting)
+	default:
+		// Interfaces and other types accept any value
+		return map[string]interface{}{}
+	}
+}
+
+// enumValues splits a comma-separated enum tag, converting the values to numbers or booleans
+// for fields of those types
+func enumValues(tag string, t reflect.Type) []interface{} {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	parts := strings.Split(tag, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var value interface{} = part
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n, err := strconv.ParseInt(part, 10, 64); err == nil {
+				value = n
+			}
+		case reflect.Float32, reflect.Float64:
+			if f, err := strconv.ParseFloat(part, 64); err == nil {
+				value = f
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(part); err == nil {
+				value = b
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,97 @@
+package gollmrouter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+type searchLocation struct {
+	City    string  `json:"city" desc:"City name"`
+	Country *string `json:"country,omitempty"`
+}
+
+type searchPaging struct {
+	Limit int `json:"limit,omitempty" desc:"Maximum results"`
+}
+
+type searchArgs struct {
+	searchPaging
+	Query    string            `json:"query" desc:"What to search for"`
+	Sort     string            `json:"sort,omitempty" enum:"relevance,date"`
+	Priority int               `json:"priority" enum:"1,2,3"`
+	Exact    bool              `json:"exact"`
+	MinScore float64           `json:"min_score,omitempty"`
+	Tags     []string          `json:"tags,omitempty" enum:"news,blog"`
+	Location searchLocation    `json:"location"`
+	Stops    []searchLocation  `json:"stops,omitempty"`
+	Since    time.Time         `json:"since,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestNewToolFromStruct(t *testing.T) {
+	tool := gollmrouter.NewToolFromStruct("search", "Search the web", &searchArgs{})
+
+	location := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city":    map[string]interface{}{"type": "string", "description": "City name"},
+			"country": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"city"},
+	}
+	expected := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"limit":     map[string]interface{}{"type": "integer", "description": "Maximum results"},
+			"query":     map[string]interface{}{"type": "string", "description": "What to search for"},
+			"sort":      map[string]interface{}{"type": "string", "enum": []interface{}{"relevance", "date"}},
+			"priority":  map[string]interface{}{"type": "integer", "enum": []interface{}{int64(1), int64(2), int64(3)}},
+			"exact":     map[string]interface{}{"type": "boolean"},
+			"min_score": map[string]interface{}{"type": "number"},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string", "enum": []interface{}{"news", "blog"}},
+			},
+			"location": location,
+			"stops":    map[string]interface{}{"type": "array", "items": location},
+			"since":    map[string]interface{}{"type": "string", "format": "date-time"},
+			"labels":   map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+		},
+		"required": []string{"query", "priority", "exact", "location"},
+	}
+
+	if tool.Type != "function" || tool.Function.Name != "search" || tool.Function.Description != "Search the web" {
+		t.Errorf("Unexpected tool: %+v", tool)
+	}
+	if !reflect.DeepEqual(tool.Function.Parameters, expected) {
+		t.Errorf("Unexpected schema\n got: %v\nwant: %v", tool.Function.Parameters, expected)
+	}
+}
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	Children []treeNode `json:"children,omitempty"`
+}
+
+func TestNewToolFromStruct_RecursiveType(t *testing.T) {
+	tool := gollmrouter.NewToolFromStruct("tree", "Walk a tree", treeNode{})
+
+	children := tool.Function.Parameters["properties"].(map[string]interface{})["children"].(map[string]interface{})
+	if !reflect.DeepEqual(children["items"], map[string]interface{}{"type": "object"}) {
+		t.Errorf("Expected the recursive field to end in a plain object, got %v", children)
+	}
+}
+
+func TestNewToolFromStruct_PanicsOnNonStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a non-struct argument")
+		}
+	}()
+	gollmrouter.NewToolFromStruct("bad", "Not a struct", "text")
+}
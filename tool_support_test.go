@@ -0,0 +1,71 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// toolCapableProvider is a mock provider that reports whether it supports tools
+type toolCapableProvider struct {
+	*mockProvider
+	supportsTools bool
+}
+
+func (p *toolCapableProvider) SupportsTools() bool {
+	return p.supportsTools
+}
+
+func TestRouter_SkipsProvidersWithoutToolSupport(t *testing.T) {
+	incapable := &toolCapableProvider{mockProvider: &mockProvider{name: "incapable", rank: 3, content: "no tools"}}
+	unknown := &mockProvider{name: "unknown", rank: 2, err: errors.New("failed")}
+	capable := &toolCapableProvider{mockProvider: &mockProvider{name: "capable", rank: 1, content: "tools"}, supportsTools: true}
+
+	router, err := gollmrouter.NewRouter(incapable, unknown, capable)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	messages := []provider.Message{{Role: "user", Content: "What's the weather?"}}
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+
+	result, err := router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{Tools: tools})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "tools" {
+		t.Errorf("Expected the tool-capable provider to answer, got %q", result.Content)
+	}
+	if incapable.callCount() != 0 {
+		t.Error("Expected the provider without tool support to be skipped")
+	}
+	if unknown.callCount() != 1 {
+		t.Error("Expected a provider that doesn't report tool support to be tried")
+	}
+
+	// Without tools every provider is eligible
+	result, err = router.QueryWithOptions(context.Background(), messages, provider.QueryOptions{})
+	if err != nil || result.Content != "no tools" {
+		t.Errorf("Expected the top ranked provider to answer a query without tools, got %v (%v)", result, err)
+	}
+}
+
+func TestRouter_ToolSupportSkipError(t *testing.T) {
+	incapable := &toolCapableProvider{mockProvider: &mockProvider{name: "incapable", content: "no tools"}}
+	router, err := gollmrouter.NewRouter(incapable)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolFunction{Name: "get_weather"}}}
+	_, err = router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{Tools: tools})
+	routerErr, ok := gollmrouter.GetRouterError(err)
+	if !ok || len(routerErr.Errors) != 1 {
+		t.Fatalf("Expected a RouterError with the skipped provider, got %v", err)
+	}
+	if !errors.Is(routerErr.Errors[0].Error, gollmrouter.ErrToolsNotSupported) {
+		t.Errorf("Expected ErrToolsNotSupported, got %v", routerErr.Errors[0].Error)
+	}
+}
@@ -0,0 +1,51 @@
+package gollmrouter
+
+import (
+	"context"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// WithTracer makes the router create spans for each query, each provider attempt and each tool
+// execution. Without it, no spans are created.
+func WithTracer(tracer provider.Tracer) RouterOption {
+	return func(r *Router) {
+		if tracer == nil {
+			tracer = provider.NoopTracer{}
+		}
+		r.tracer = tracer
+	}
+}
+
+// startQuerySpan starts the span for a router query and makes the tracer available to providers
+func (r *Router) startQuerySpan(ctx context.Context, name string) (context.Context, provider.Span) {
+	ctx, span := r.tracer.Start(ctx, name)
+	span.SetAttributes(requestIDAttributes(ctx)...)
+	return provider.ContextWithTracer(ctx, r.tracer), span
+}
+
+// attemptAttributes describes the provider of an attempt
+func attemptAttributes(p provider.Provider, name string) []provider.Attribute {
+	return []provider.Attribute{
+		provider.Attr("provider.name", name),
+		provider.Attr("provider.rank", p.GetRank()),
+	}
+}
+
+// resultAttributes describes the model, finish reason and token usage of a result
+func resultAttributes(result *provider.QueryResult) []provider.Attribute {
+	attributes := []provider.Attribute{
+		provider.Attr("llm.model", result.Model),
+	}
+	if result.FinishReason != "" {
+		attributes = append(attributes, provider.Attr("llm.finish_reason", result.FinishReason))
+	}
+	if result.Usage != nil {
+		attributes = append(attributes,
+			provider.Attr("llm.usage.prompt_tokens", result.Usage.PromptTokens),
+			provider.Attr("llm.usage.completion_tokens", result.Usage.CompletionTokens),
+			provider.Attr("llm.usage.total_tokens", result.Usage.TotalTokens),
+		)
+	}
+	return attributes
+}
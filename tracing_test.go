@@ -0,0 +1,185 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// recordedSpan is a finished span captured by memoryTracer
+type recordedSpan struct {
+	tracer     *memoryTracer
+	name       string
+	parent     *recordedSpan
+	attributes map[string]interface{}
+	err        error
+}
+
+func (s *recordedSpan) SetAttributes(attributes ...provider.Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, attribute := range attributes {
+		s.attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.ended = append(s.tracer.ended, s)
+}
+
+type spanContextKey struct{}
+
+// memoryTracer records spans in memory, tracking parents through the context
+type memoryTracer struct {
+	mu    sync.Mutex
+	ended []*recordedSpan
+}
+
+func (t *memoryTracer) Start(ctx context.Context, name string) (context.Context, provider.Span) {
+	parent, _ := ctx.Value(spanContextKey{}).(*recordedSpan)
+	span := &recordedSpan{tracer: t, name: name, parent: parent, attributes: map[string]interface{}{}}
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+func (t *memoryTracer) spans(name string) []*recordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var spans []*recordedSpan
+	for _, span := range t.ended {
+		if span.name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestRouter_TracingFallbackSpanTree(t *testing.T) {
+	failing := &mockProvider{name: "primary", rank: 2, err: errors.New("primary down")}
+	backup := &mockProvider{name: "backup", rank: 1, queryFn: func(ctx context.Context, messages []provider.Message, options provider.QueryOptions) (*provider.QueryResult, error) {
+		return &provider.QueryResult{
+			Content:      "hello",
+			Model:        "backup-model",
+			FinishReason: "stop",
+			Usage:        &provider.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		}, nil
+	}}
+
+	tracer := &memoryTracer{}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{failing, backup}, gollmrouter.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	roots := tracer.spans("Router.QueryWithOptions")
+	if len(roots) != 1 {
+		t.Fatalf("Expected 1 query span, got %d", len(roots))
+	}
+	root := roots[0]
+	if root.parent != nil {
+		t.Error("Expected query span to be a root span")
+	}
+
+	attempts := tracer.spans("Router.ProviderAttempt")
+	if len(attempts) != 2 {
+		t.Fatalf("Expected 2 provider attempt spans, got %d", len(attempts))
+	}
+
+	first, second := attempts[0], attempts[1]
+	for _, attempt := range attempts {
+		if attempt.parent != root {
+			t.Errorf("Expected attempt span %v to be a child of the query span", attempt.attributes["provider.name"])
+		}
+	}
+
+	if first.attributes["provider.name"] != "primary" || first.attributes["provider.rank"] != 2 || first.attributes["outcome"] != "error" || first.err == nil {
+		t.Errorf("Unexpected failed attempt span: %v (err %v)", first.attributes, first.err)
+	}
+	if second.attributes["provider.name"] != "backup" || second.attributes["outcome"] != "success" || second.err != nil {
+		t.Errorf("Unexpected successful attempt span: %v (err %v)", second.attributes, second.err)
+	}
+	if second.attributes["llm.model"] != "backup-model" || second.attributes["llm.finish_reason"] != "stop" || second.attributes["llm.usage.total_tokens"] != 5 {
+		t.Errorf("Expected model, finish reason and usage attributes, got %v", second.attributes)
+	}
+}
+
+func TestRouter_TracingRateLimitedSkip(t *testing.T) {
+	exhausted := &mockProvider{name: "exhausted", rank: 2, exhausted: true}
+	available := &mockProvider{name: "available", rank: 1, content: "ok"}
+
+	tracer := &memoryTracer{}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{exhausted, available}, gollmrouter.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	attempts := tracer.spans("Router.ProviderAttempt")
+	if len(attempts) != 2 || attempts[0].attributes["outcome"] != "rate_limited" {
+		t.Errorf("Expected a rate_limited span for the exhausted provider, got %d spans", len(attempts))
+	}
+}
+
+func TestRouter_TracingToolSpans(t *testing.T) {
+	server, _ := newToolCallServer(t, []string{"lookup"})
+	fc, err := gollmrouter.NewFunctionCallingProvider(gollmrouter.FunctionCallingConfig{
+		APIKey:       "test-key",
+		URL:          server.URL,
+		Models:       []string{"test-model"},
+		ToolExecutor: &sleepyToolExecutor{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	tracer := &memoryTracer{}
+	router, err := gollmrouter.NewRouterWithOptions([]provider.Provider{fc}, gollmrouter.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	toolSpans := tracer.spans("tool.execute")
+	if len(toolSpans) != 1 {
+		t.Fatalf("Expected 1 tool span, got %d", len(toolSpans))
+	}
+	if toolSpans[0].attributes["tool.name"] != "lookup" {
+		t.Errorf("Expected tool name attribute, got %v", toolSpans[0].attributes)
+	}
+	if parent := toolSpans[0].parent; parent == nil || parent.name != "Router.ProviderAttempt" {
+		t.Error("Expected tool span to be a child of the provider attempt span")
+	}
+}
+
+func TestRouter_NoTracerByDefault(t *testing.T) {
+	router, err := gollmrouter.NewRouter(&mockProvider{name: "only", content: "ok"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.QueryWithOptions(context.Background(), []provider.Message{{Role: "user", Content: "hi"}}, provider.QueryOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
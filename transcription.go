@@ -0,0 +1,96 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// TranscriptionRequest asks for the text spoken in an audio file
+type TranscriptionRequest = provider.TranscriptionRequest
+
+// TranscriptionResult holds the transcribed text
+type TranscriptionResult = provider.TranscriptionResult
+
+// Transcriber is implemented by providers that can transcribe audio
+type Transcriber = provider.Transcriber
+
+// Transcribe converts speech to text using the first available provider that supports transcription.
+// Providers are tried in the same order as for queries; providers without transcription support are skipped.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - request: The audio and, optionally, the model and spoken language
+//
+// Returns:
+//   - result: The transcribed text
+//   - error: A RouterError if every transcription provider failed
+func (r *Router) Transcribe(ctx context.Context, request TranscriptionRequest) (*TranscriptionResult, error) {
+	ctx, span := r.startQuerySpan(ctx, "Router.Transcribe")
+	defer span.End()
+
+	messages := []provider.Message{{Role: "user", Files: []provider.File{request.Audio}}}
+
+	var routerError RouterError
+	supported := 0
+
+	for i, p := range r.orderProviders(r.getProviders()) {
+		transcriber, ok := p.(provider.Transcriber)
+		if !ok || !transcriber.SupportsTranscription() {
+			continue
+		}
+		supported++
+
+		providerName := providerDisplayName(p, i)
+
+		if err := r.checkDeadline(ctx); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeSkipped, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		if err := checkRateLimits(ctx, p, messages); err != nil {
+			r.skipProvider(ctx, p, providerName, OutcomeRateLimited, err)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			continue
+		}
+
+		attemptCtx, cancel := attemptContext(ctx, p)
+		start := time.Now()
+		result, err := transcriber.Transcribe(attemptCtx, request)
+		r.metrics.ObserveLatency(providerName, time.Since(start))
+		cancel()
+		if err != nil {
+			r.metrics.IncRequest(providerName, request.Model, OutcomeError)
+			routerError.Errors = append(routerError.Errors, ProviderError{
+				ProviderName: providerName,
+				Error:        err,
+			})
+			if r.isTerminal(err) {
+				break
+			}
+			continue
+		}
+
+		r.metrics.IncRequest(providerName, result.Model, OutcomeSuccess)
+		span.SetAttributes(provider.Attr("provider.name", providerName), provider.Attr("llm.model", result.Model))
+		return result, nil
+	}
+
+	if supported == 0 {
+		err := fmt.Errorf("no configured provider supports transcription")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.RecordError(&routerError)
+	return nil, &routerError
+}
@@ -0,0 +1,66 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// mockTranscriber is a mockProvider that can also transcribe audio
+type mockTranscriber struct {
+	*mockProvider
+	transcribeErr error
+}
+
+func (m *mockTranscriber) SupportsTranscription() bool {
+	return true
+}
+
+func (m *mockTranscriber) Transcribe(ctx context.Context, request provider.TranscriptionRequest) (*provider.TranscriptionResult, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+
+	if m.transcribeErr != nil {
+		return nil, m.transcribeErr
+	}
+	return &provider.TranscriptionResult{Text: m.content, Model: m.name + "-transcribe"}, nil
+}
+
+func TestRouter_TranscribeFallback(t *testing.T) {
+	chatOnly := &mockProvider{name: "chat-only", rank: 3}
+	failing := &mockTranscriber{mockProvider: &mockProvider{name: "failing", rank: 2}, transcribeErr: errors.New("boom")}
+	working := &mockTranscriber{mockProvider: &mockProvider{name: "working", rank: 1, content: "hello"}}
+
+	router, err := gollmrouter.NewRouter(chatOnly, failing, working)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	result, err := router.Transcribe(context.Background(), gollmrouter.TranscriptionRequest{
+		Audio: gollmrouter.FileAttachment{Type: "audio", MimeType: "audio/mpeg", Data: []byte("mp3")},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Text != "hello" || result.Model != "working-transcribe" {
+		t.Errorf("Expected the working provider's transcript, got %+v", result)
+	}
+	if chatOnly.callCount() != 0 || failing.callCount() != 1 {
+		t.Error("Expected the chat-only provider to be skipped and the failing one to be tried once")
+	}
+}
+
+func TestRouter_TranscribeUnsupported(t *testing.T) {
+	router, err := gollmrouter.NewRouter(&mockProvider{name: "chat-only"})
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	if _, err := router.Transcribe(context.Background(), gollmrouter.TranscriptionRequest{}); err == nil {
+		t.Error("Expected an error when no provider supports transcription")
+	}
+}
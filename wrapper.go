@@ -0,0 +1,171 @@
+package gollmrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FramnkRulez/go-llm-router/provider"
+)
+
+// providerWrapper is embedded by providers that wrap another one, such as WithModelAlias and
+// WithRecorder. It forwards the Provider methods and every optional interface to the wrapped
+// provider, so a wrapper only implements the methods whose behavior it changes.
+//
+// Interfaces with a Supports method report false when the wrapped provider doesn't implement
+// them. HealthCheck, Warmup and RefreshModels do nothing in that case, and the router leaves
+// the wrapper out as it would the wrapped provider.
+type providerWrapper struct {
+	provider.Provider
+}
+
+var _ provider.Weighted = providerWrapper{}
+var _ provider.TimeoutAware = providerWrapper{}
+var _ provider.ToolCapable = providerWrapper{}
+var _ provider.TokenEstimator = providerWrapper{}
+var _ provider.Embedder = providerWrapper{}
+var _ provider.ImageGenerator = providerWrapper{}
+var _ provider.Transcriber = providerWrapper{}
+var _ provider.RawRequester = providerWrapper{}
+var _ provider.HealthChecker = providerWrapper{}
+var _ provider.Warmer = providerWrapper{}
+var _ provider.ModelRefresher = providerWrapper{}
+var _ provider.DebugRecorder = providerWrapper{}
+
+// unwrapper is implemented by providerWrapper and the providers embedding it
+type unwrapper interface {
+	unwrapProvider() provider.Provider
+}
+
+// unwrapProvider returns the wrapped provider
+func (w providerWrapper) unwrapProvider() provider.Provider {
+	return w.Provider
+}
+
+// implements reports whether p implements T, looking through wrappers to the provider they wrap
+func implements[T any](p provider.Provider) bool {
+	for {
+		wrapper, ok := p.(unwrapper)
+		if !ok {
+			_, ok := p.(T)
+			return ok
+		}
+		p = wrapper.unwrapProvider()
+	}
+}
+
+// GetWeight returns the wrapped provider's weight, 1 if it has none
+func (w providerWrapper) GetWeight() int {
+	return providerWeight(w.Provider)
+}
+
+// GetTimeout returns the wrapped provider's per-request timeout, 0 if it has none
+func (w providerWrapper) GetTimeout() time.Duration {
+	if timed, ok := w.Provider.(provider.TimeoutAware); ok {
+		return timed.GetTimeout()
+	}
+	return 0
+}
+
+// SupportsTools reports whether the wrapped provider can handle tool calls
+func (w providerWrapper) SupportsTools() bool {
+	capable, ok := w.Provider.(provider.ToolCapable)
+	return !ok || capable.SupportsTools()
+}
+
+// EstimateTokens uses the wrapped provider's token estimator
+func (w providerWrapper) EstimateTokens(messages []provider.Message) int {
+	return estimateTokens(w.Provider, messages)
+}
+
+// SupportsEmbeddings reports whether the wrapped provider can create embeddings
+func (w providerWrapper) SupportsEmbeddings() bool {
+	embedder, ok := w.Provider.(provider.Embedder)
+	return ok && embedder.SupportsEmbeddings()
+}
+
+// Embeddings creates embeddings with the wrapped provider
+func (w providerWrapper) Embeddings(ctx context.Context, request provider.EmbeddingRequest) (*provider.EmbeddingResult, error) {
+	embedder, ok := w.Provider.(provider.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("embeddings are not supported by provider %s", w.Provider.Name())
+	}
+	return embedder.Embeddings(ctx, request)
+}
+
+// SupportsImageGeneration reports whether the wrapped provider can generate images
+func (w providerWrapper) SupportsImageGeneration() bool {
+	generator, ok := w.Provider.(provider.ImageGenerator)
+	return ok && generator.SupportsImageGeneration()
+}
+
+// GenerateImage generates images with the wrapped provider
+func (w providerWrapper) GenerateImage(ctx context.Context, request provider.ImageRequest) (*provider.ImageResult, error) {
+	generator, ok := w.Provider.(provider.ImageGenerator)
+	if !ok {
+		return nil, fmt.Errorf("image generation is not supported by provider %s", w.Provider.Name())
+	}
+	return generator.GenerateImage(ctx, request)
+}
+
+// SupportsTranscription reports whether the wrapped provider can transcribe audio
+func (w providerWrapper) SupportsTranscription() bool {
+	transcriber, ok := w.Provider.(provider.Transcriber)
+	return ok && transcriber.SupportsTranscription()
+}
+
+// Transcribe transcribes audio with the wrapped provider
+func (w providerWrapper) Transcribe(ctx context.Context, request provider.TranscriptionRequest) (*provider.TranscriptionResult, error) {
+	transcriber, ok := w.Provider.(provider.Transcriber)
+	if !ok {
+		return nil, fmt.Errorf("transcription is not supported by provider %s", w.Provider.Name())
+	}
+	return transcriber.Transcribe(ctx, request)
+}
+
+// SupportsRawRequests reports whether the wrapped provider can send raw requests
+func (w providerWrapper) SupportsRawRequests() bool {
+	requester, ok := w.Provider.(provider.RawRequester)
+	return ok && requester.SupportsRawRequests()
+}
+
+// DoRaw sends a raw request with the wrapped provider
+func (w providerWrapper) DoRaw(ctx context.Context, path string, body interface{}) ([]byte, error) {
+	requester, ok := w.Provider.(provider.RawRequester)
+	if !ok {
+		return nil, fmt.Errorf("raw requests are not supported by provider %s", w.Provider.Name())
+	}
+	return requester.DoRaw(ctx, path, body)
+}
+
+// HealthCheck runs the wrapped provider's health check, if it has one
+func (w providerWrapper) HealthCheck(ctx context.Context) error {
+	if checker, ok := w.Provider.(provider.HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// Warmup warms up the wrapped provider, if it supports it
+func (w providerWrapper) Warmup(ctx context.Context) error {
+	if warmer, ok := w.Provider.(provider.Warmer); ok {
+		return warmer.Warmup(ctx)
+	}
+	return nil
+}
+
+// RefreshModels refreshes the wrapped provider's models, if it can list them
+func (w providerWrapper) RefreshModels(ctx context.Context) error {
+	if refresher, ok := w.Provider.(provider.ModelRefresher); ok {
+		return refresher.RefreshModels(ctx)
+	}
+	return nil
+}
+
+// LastRawExchange returns the wrapped provider's last raw exchange, nil if it doesn't record them
+func (w providerWrapper) LastRawExchange() *provider.RawExchange {
+	if recorder, ok := w.Provider.(provider.DebugRecorder); ok {
+		return recorder.LastRawExchange()
+	}
+	return nil
+}
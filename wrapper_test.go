@@ -0,0 +1,71 @@
+package gollmrouter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	gollmrouter "github.com/FramnkRulez/go-llm-router"
+)
+
+func TestWrappers_ForwardOptionalInterfaces(t *testing.T) {
+	embedder := &mockEmbedder{mockProvider: &mockProvider{name: "embedder"}, supported: true}
+	checked := &healthCheckProvider{mockProvider: &mockProvider{name: "checked"}, healthErr: errors.New("invalid API key")}
+	unchecked := &mockProvider{name: "unchecked"}
+
+	recorded, err := gollmrouter.WithRecorder(checked, filepath.Join(t.TempDir(), "cassette.json"))
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(
+		gollmrouter.WithModelAlias(embedder, map[string]string{"fast": "embedder-model"}),
+		recorded,
+		gollmrouter.WithModelAlias(unchecked, nil),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := router.Embeddings(ctx, gollmrouter.EmbeddingRequest{Input: []string{"a"}})
+	if err != nil {
+		t.Fatalf("Expected the aliased provider to create embeddings, got %v", err)
+	}
+	if result.Model != "embedder-embed" || embedder.callCount() != 1 {
+		t.Errorf("Expected the wrapped embedder's vectors, got %+v", result)
+	}
+
+	// Only providers whose wrapped provider has a health check are included
+	results := router.HealthCheckAll(ctx)
+	if len(results) != 1 {
+		t.Fatalf("Expected a result for the checked provider only, got %v", results)
+	}
+	if err := results["checked"]; err == nil || err.Error() != "invalid API key" {
+		t.Errorf("Expected the wrapped provider's health check error, got %v", err)
+	}
+}
+
+func TestWithKeyRotation_DoRaw(t *testing.T) {
+	server, requests := newRawServer(t, http.StatusOK, `{"results": []}`)
+	pool, err := gollmrouter.WithKeyRotation(gollmrouter.OpenAIConfig{
+		BaseURL: server.URL + "/v1",
+		Models:  []string{"gpt-4o"},
+	}, []string{"key-a", "key-b"})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	router, err := gollmrouter.NewRouter(pool)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	if _, _, err := router.DoRaw(context.Background(), "/moderations", map[string]string{"input": "hi"}); err != nil {
+		t.Fatalf("Expected the pool to send raw requests, got %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].authorization != "Bearer key-a" {
+		t.Errorf("Expected one request with the first key, got %+v", *requests)
+	}
+}